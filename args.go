@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"lazycut/ui"
+	"lazycut/video"
+	"slices"
+	"strings"
+	"time"
+)
+
+const usage = `Usage: lazycut [flags] <video.mp4>
+       lazycut --demo [flags] [video.mp4]
+       lazycut watch [--profile NAME] <dir>
+       lazycut stats [--clear]
+
+Flags:
+  -v, --version     Print version, helper versions and terminal, and exit
+      --accessible  Plain-text timeline, no colors, spoken-style status line
+      --replay D    Instant-replay jump for the r key (default 5s)
+      --preview-end MODE
+                    At the out-point: stop, loop or continue (default stop)
+      --transcribe  Transcribe the audio with whisper in the background
+      --whisper-model M
+                    ggml model file (whisper.cpp) or model name (openai-whisper)
+      --json        Print a JSON line per export on exit:
+                    {"output": "...", "in": 1.5, "out": 9.25}
+      --autosave D  Save selection and segments every D and restore them on the next start
+                    (default 30s, 0 disables)
+      --repair      Remux a damaged or unfinished input without asking
+      --cmd SCRIPT  Run commands after loading, e.g. 'seek 1:00; in; seek +30; out'
+                    (seek T|+T|-T, in [T], out [T], clear, play, pause, preview,
+                    segment, mute, preset NAME, export)
+      --hooks FILE  Key hooks, a Starlark file binding functions to keys
+                    (default ~/.config/lazycut/hooks.star)
+      --config FILE Startup defaults: quality, mute, aspect, output_dir, [seek]
+                    steps and [chafa] options (default ~/.config/lazycut/config.toml)
+      --manifest FORMAT
+                    After exporting all segments, write <name>_manifest.json or .csv
+                    with each file, range, duration and SHA-256
+      --jobs N      Export up to N segments at once (default 1)
+      --threads N   ffmpeg threads per export (default: CPUs / jobs with --jobs,
+                    otherwise ffmpeg's choice; 0 for ffmpeg's choice)
+      --low-priority
+                    Export at background CPU and disk priority (nice/ionice); also a
+                    toggle in the export dialog
+      --music FILE  Background track for exports, mixed under (and ducked
+                    against) the original audio from the export dialog
+      --encoder NAME
+                    H.264 encoder for re-encoded exports, e.g. h264_nvenc; if it
+                    fails right away the export is redone with libx264
+      --demo        Draw synthetic frames paced by frame count instead of the video,
+                    for reproducible recordings; without a file, opens a generated
+                    test pattern clip
+      --seek MODE   How previews seek: auto (fast for the filmstrip only), fast,
+                    accurate or exact (decode from the start, for broken indexes);
+                    exports always seek accurately (default: config, then auto)
+      --graphics MODE
+                    Preview with kitty graphics (real pixels in kitty and ghostty),
+                    sixel (foot, mlterm, WezTerm, xterm -ti vt340) or symbols (chafa
+                    characters); default auto
+      --palette NAME
+                    In/out marker colors: default (green/orange), okabe-ito
+                    (blue/orange, for red-green color blindness), tritan
+                    (magenta/teal) or mono
+      --markers STYLE
+                    In/out marker glyphs: arrows, letters (I/O), brackets or shapes
+                    (default arrows, letters with --palette mono)
+      --stdin-marks Read lines from stdin and mark each timestamp in them on the
+                    timeline, labeled with the rest of the line ([ and ] jump)
+      --events FILE Mark the events of a JSON, CSV/TSV or log file on the timeline
+                    (a time and a label per event); may be repeated
+      --events-format NAME
+                    Read --events files as json, csv, tsv or log instead of going
+                    by their extension
+      --marks-start T
+                    Wall-clock time when the recording started, subtracted from
+                    the --stdin-marks and --events timestamps, e.g. 14:02:10
+      --edl FILE    Add the events of a CMX 3600 EDL as segments, e.g. a cut list
+                    exported earlier or an edit from Resolve or Premiere`
+
+// options holds everything parsed from the command line.
+type options struct {
+	videoPath    string
+	showVersion  bool
+	accessible   bool
+	replay       time.Duration
+	previewEnd   string
+	transcribe   bool
+	whisperModel string
+	autosave     time.Duration
+	json         bool
+	repair       bool
+	script       string
+	hooks        string
+	config       string
+	manifest     string
+	jobs         int
+	threads      int
+	lowPriority  bool
+	encoder      string
+	music        string
+	graphics     video.GraphicsMode
+	demo         bool
+	seek         *video.SeekMode
+	palette      string
+	markers      string
+	stdinMarks   bool
+	events       []string
+	eventsFormat string
+	edl          string
+	marksStart   time.Duration
+}
+
+var errUsage = errors.New("usage")
+
+// durationFlag is a flag of a duration in any form video.ParseTimecode
+// reads, e.g. "5s", "1m30s", "90" or "1:30".
+type durationFlag struct{ d *time.Duration }
+
+func (f durationFlag) String() string {
+	if f.d == nil {
+		return ""
+	}
+	return f.d.String()
+}
+
+func (f durationFlag) Set(s string) error {
+	d, err := video.ParseTimecode(s)
+	if err != nil {
+		return err
+	}
+	*f.d = d
+	return nil
+}
+
+// parseArgs parses flags and the video path. Flags may appear before or
+// after the path, e.g. `lazycut clip.mp4 --accessible`.
+func parseArgs(args []string) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("lazycut", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&opts.showVersion, "v", false, "")
+	fs.BoolVar(&opts.showVersion, "version", false, "")
+	fs.BoolVar(&opts.accessible, "accessible", false, "")
+	opts.replay, opts.autosave = 5*time.Second, 30*time.Second
+	fs.Var(durationFlag{&opts.replay}, "replay", "")
+	fs.StringVar(&opts.previewEnd, "preview-end", "stop", "")
+	fs.BoolVar(&opts.transcribe, "transcribe", false, "")
+	fs.StringVar(&opts.whisperModel, "whisper-model", "", "")
+	fs.BoolVar(&opts.json, "json", false, "")
+	fs.Var(durationFlag{&opts.autosave}, "autosave", "")
+	fs.BoolVar(&opts.repair, "repair", false, "")
+	fs.StringVar(&opts.script, "cmd", "", "")
+	fs.StringVar(&opts.hooks, "hooks", "", "")
+	fs.StringVar(&opts.config, "config", "", "")
+	fs.StringVar(&opts.manifest, "manifest", "", "")
+	fs.IntVar(&opts.jobs, "jobs", 1, "")
+	fs.IntVar(&opts.threads, "threads", -1, "")
+	fs.BoolVar(&opts.lowPriority, "low-priority", false, "")
+	fs.StringVar(&opts.encoder, "encoder", "", "")
+	fs.StringVar(&opts.music, "music", "", "")
+	fs.StringVar(&opts.palette, "palette", "default", "")
+	fs.StringVar(&opts.markers, "markers", "", "")
+	fs.BoolVar(&opts.stdinMarks, "stdin-marks", false, "")
+	fs.Func("events", "", func(path string) error {
+		opts.events = append(opts.events, path)
+		return nil
+	})
+	fs.StringVar(&opts.eventsFormat, "events-format", "", "")
+	fs.StringVar(&opts.edl, "edl", "", "")
+	var graphics, seek, marksStart string
+	fs.StringVar(&graphics, "graphics", "auto", "")
+	fs.StringVar(&seek, "seek", "", "")
+	fs.BoolVar(&opts.demo, "demo", false, "")
+	fs.StringVar(&marksStart, "marks-start", "", "")
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return opts, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if opts.showVersion {
+		return opts, nil
+	}
+	if len(positional) > 1 || len(positional) == 0 && !opts.demo {
+		return opts, errUsage
+	}
+	if opts.jobs < 1 {
+		return opts, fmt.Errorf("invalid --jobs %d (want 1 or more)", opts.jobs)
+	}
+	if opts.threads < 0 {
+		opts.threads = ui.DefaultThreads(opts.jobs)
+	}
+	if opts.encoder != "" && !video.IsH264Encoder(opts.encoder) {
+		return opts, fmt.Errorf("invalid --encoder %q (want an H.264 encoder such as libx264, h264_nvenc, h264_videotoolbox)", opts.encoder)
+	}
+	mode, err := video.ParseGraphicsMode(graphics)
+	if err != nil {
+		return opts, err
+	}
+	opts.graphics = mode
+	if seek != "" {
+		mode, err := video.ParseSeekMode(seek)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --seek: %w", err)
+		}
+		opts.seek = &mode
+	}
+	if opts.eventsFormat != "" && !slices.Contains(video.MarkFormats(), opts.eventsFormat) {
+		return opts, fmt.Errorf("invalid --events-format %q (want %s)", opts.eventsFormat, strings.Join(video.MarkFormats(), ", "))
+	}
+	if marksStart != "" {
+		if opts.marksStart, err = video.ParseTimecode(marksStart); err != nil {
+			return opts, fmt.Errorf("invalid --marks-start: %w", err)
+		}
+	}
+	if opts.manifest != "" && !slices.Contains(ui.ManifestFormats, opts.manifest) {
+		return opts, fmt.Errorf("invalid --manifest %q (want json or csv)", opts.manifest)
+	}
+	if len(positional) == 1 {
+		opts.videoPath = positional[0]
+	}
+	return opts, nil
+}
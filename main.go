@@ -1,64 +1,322 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"lazycut/ui"
+	"lazycut/ui/theme"
 	"lazycut/video"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 var version = "dev"
 
 func main() {
+	os.Exit(run())
+}
+
+// run holds the program body so deferred cleanup executes before os.Exit.
+func run() int {
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		return runWatch(os.Args[2:])
+	}
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		return runStats(os.Args[2:])
+	}
+
 	// Check command line arguments
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: lazycut <video.mp4>")
-		os.Exit(1)
+	opts, err := parseArgs(os.Args[1:])
+	if err != nil {
+		if err != errUsage {
+			fmt.Println(err)
+		}
+		fmt.Println(usage)
+		return 1
 	}
 
 	// Handle version flag
-	if os.Args[1] == "-v" || os.Args[1] == "--version" {
+	if opts.showVersion {
 		fmt.Printf("lazycut version %s\n", version)
-		os.Exit(0)
+		printSystemInfo(os.Stdout)
+		return 0
 	}
 
-	videoPath := os.Args[1]
+	videoPath := opts.videoPath
 
-	// Check if video file exists
-	if _, err := os.Stat(videoPath); os.IsNotExist(err) {
+	previewEnd, err := ui.ParsePreviewEnd(opts.previewEnd)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	commands, err := ui.ParseCommands(opts.script)
+	if err != nil {
+		fmt.Println("--cmd:", err)
+		return 1
+	}
+	config, err := ui.LoadConfig(opts.config)
+	if err != nil {
+		fmt.Println("config:", err)
+		return 1
+	}
+	// The theme goes first, the mono marker palette follows its text
+	if config.Theme != nil {
+		theme.Set(*config.Theme)
+	}
+	if err := theme.SetMarkers(opts.palette, opts.markers); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	hooks, err := ui.LoadHooks(opts.hooks, config)
+	if err != nil {
+		fmt.Println("hooks:", err)
+		return 1
+	}
+	for q, preset := range config.Chafa {
+		video.ChafaPresets[q] = preset
+	}
+	if opts.seek != nil {
+		config.SeekMode = *opts.seek
+	}
+	video.SetSeekMode(config.SeekMode)
+	video.SetStats(config.Stats)
+	var marks []video.Mark
+	for _, path := range opts.events {
+		events, err := video.LoadEventFile(path, opts.eventsFormat, opts.marksStart)
+		if err != nil {
+			fmt.Println("--events:", err)
+			return 1
+		}
+		marks = append(marks, events...)
+	}
+	if opts.stdinMarks {
+		// The pipe must be read to the end before the UI takes the
+		// terminal, which then comes from the tty instead of stdin
+		piped, err := video.ReadMarks(os.Stdin, opts.marksStart)
+		if err != nil {
+			fmt.Println("--stdin-marks:", err)
+			return 1
+		}
+		marks = append(marks, piped...)
+	}
+
+	// Check if video file exists; --demo without one generates it
+	if _, err := os.Stat(videoPath); os.IsNotExist(err) && videoPath != "" {
 		fmt.Printf("File not found: %s\n", videoPath)
-		os.Exit(1)
+		return 1
+	}
+	if opts.music != "" {
+		if _, err := os.Stat(opts.music); err != nil {
+			fmt.Println("--music:", err)
+			return 1
+		}
 	}
 
+	if opts.demo {
+		// Synthetic frames are text, and a recording should not depend on
+		// saved sessions or viewing settings
+		opts.graphics = video.GraphicsSymbols
+		opts.autosave = 0
+	}
+	ctx, cancel, err := startup(opts.accessible, opts.graphics)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer cancel()
+	defer video.KillHelpers()
+	video.SetDemo(opts.demo)
+	if videoPath == "" {
+		if videoPath, err = video.DemoClip(ctx); err != nil {
+			fmt.Println("--demo:", err)
+			return 1
+		}
+	}
+	if opts.encoder != "" && !video.FFmpegCapabilities().HasEncoder(opts.encoder) {
+		fmt.Printf("--encoder: this ffmpeg has no %s encoder\n", opts.encoder)
+		return 1
+	}
+	var intro, outro video.Bumper
+	if config.Intro != "" {
+		if intro, err = video.ProbeBumper(ctx, config.Intro); err != nil {
+			fmt.Println("config: intro:", err)
+			return 1
+		}
+	}
+	if config.Outro != "" {
+		if outro, err = video.ProbeBumper(ctx, config.Outro); err != nil {
+			fmt.Println("config: outro:", err)
+			return 1
+		}
+	}
+
+	code, _ := edit(ctx, videoPath, opts, ui.Options{
+		Accessible:   opts.accessible,
+		Replay:       opts.replay,
+		PreviewEnd:   previewEnd,
+		Transcribe:   opts.transcribe,
+		WhisperModel: opts.whisperModel,
+		Autosave:     opts.autosave,
+		Commands:     commands,
+		Hooks:        hooks,
+		Manifest:     opts.manifest,
+		Workers:      opts.jobs,
+		Threads:      opts.threads,
+		LowPriority:  opts.lowPriority,
+		Encoder:      opts.encoder,
+		Music:        opts.music,
+		Config:       config,
+		Intro:        intro,
+		Outro:        outro,
+		Version:      version,
+		Marks:        marks,
+	})
+	return code
+}
+
+// startup checks the tools lazycut needs and returns the root context for
+// every external process, cancelled on signal or by calling cancel.
+// Callers also defer video.KillHelpers.
+func startup(accessible bool, graphics video.GraphicsMode) (context.Context, context.CancelFunc, error) {
 	// Check dependencies
 	if err := video.CheckDependencies(); err != nil {
+		return nil, nil, err
+	}
+
+	// Clean up helpers left running by a previous crashed session
+	video.ReapOrphans()
+
+	ctx, cancel := signal.NotifyContext(context.Background(),
+		os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	// Probe the local ffmpeg build for required encoders and filters
+	caps, err := video.DetectCapabilities(ctx)
+	if err == nil {
+		err = caps.Check()
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	// Keep chafa within what the terminal (or NO_COLOR) allows
+	video.SetMaxColors(theme.ChafaColors())
+	video.SetGraphics(graphics)
+
+	// Accessibility mode drops UI colors (not frame colors) for contrast
+	if accessible {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	return ctx, cancel, nil
+}
+
+// edit opens videoPath in the editor and returns the exit code and the
+// files it wrote: a repaired copy of the input and the exports.
+func edit(ctx context.Context, videoPath string, opts options, uiOpts ui.Options) (int, []string) {
+	var written []string
+	// Catch unfinished recordings before they show as a 00:00 timeline
+	opened, err := checkInput(ctx, videoPath, opts.repair)
+	if err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		return 1, nil
+	}
+	if opened != videoPath {
+		written = append(written, opened)
+		videoPath = opened
 	}
 
 	// Create video player
-	player, err := video.NewPlayer(videoPath)
+	prefs := ui.LoadPlayerPrefs(videoPath, uiOpts.Config)
+	if opts.demo {
+		prefs = video.PlayerPrefs{Quality: video.QualityHigh, Muted: true}
+	}
+	player, err := video.NewPlayer(ctx, videoPath, prefs)
 	if err != nil {
 		fmt.Printf("Failed to open video: %v\n", err)
-		os.Exit(1)
+		return 1, written
 	}
 	defer player.Close()
+	if opts.edl != "" {
+		// EDL timecodes count frames of the source
+		cuts, err := video.LoadEDL(opts.edl, player.Properties().FPS)
+		if err != nil {
+			fmt.Println("--edl:", err)
+			return 1, written
+		}
+		uiOpts.Cuts = cuts
+	}
 
 	// Create the UI model with video player
-	m := ui.NewModel(player)
+	m := ui.NewModel(ctx, player, uiOpts)
 
 	// Create the bubbletea program with alternate screen
-	p := tea.NewProgram(
-		m,
+	programOpts := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
-	)
+		tea.WithContext(ctx),
+	}
+	if opts.stdinMarks {
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	p := tea.NewProgram(m, programOpts...)
 
 	// Run the program
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil && ctx.Err() == nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return 1, written
+	}
+
+	fm, ok := final.(ui.Model)
+	if !ok {
+		return 0, written
+	}
+	exports := fm.Exports()
+	for _, e := range exports {
+		written = append(written, e.Output)
 	}
+	switch {
+	case opts.json:
+		// One line per export for wrapper scripts
+		for _, e := range exports {
+			printJSONResult(os.Stdout, e)
+		}
+	case fm.QuitAfterExport() && len(exports) > 0:
+		// After "quit when finished" the user has walked away; leave
+		// the result where they will look for it
+		fmt.Println(exports[len(exports)-1].Output)
+	}
+	return 0, written
+}
+
+// printSystemInfo lists the helper versions and terminal under --version,
+// for pasting into bug reports.
+func printSystemInfo(w io.Writer) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	video.SetGraphics(video.DetectGraphics())
+	for _, line := range video.DetectSystemInfo(ctx).Lines() {
+		fmt.Fprintf(w, "  %-10s %s\n", line[0], line[1])
+	}
+}
+
+// jsonResult is the --json line printed for each export.
+type jsonResult struct {
+	Output string  `json:"output"`
+	In     float64 `json:"in"`
+	Out    float64 `json:"out"`
+}
+
+func printJSONResult(w io.Writer, e ui.ExportResult) {
+	line, _ := json.Marshal(jsonResult{Output: e.Output, In: e.In.Seconds(), Out: e.Out.Seconds()})
+	fmt.Fprintf(w, "%s\n", line)
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"lazycut/video"
+	"os"
+	"strings"
+)
+
+// checkInput warns about a damaged input before the UI starts and offers
+// to remux it, returning the path to open. A file that cannot be repaired
+// here, or a declined repair, is an error: opening it would show an empty
+// timeline that breaks seeking and export.
+func checkInput(ctx context.Context, path string, repair bool) (string, error) {
+	problem := video.CheckInput(ctx, path)
+	if problem == nil {
+		return path, nil
+	}
+	fmt.Printf("%s: %v\n", path, problem)
+	if problem.NoIndex {
+		return "", errors.New("a remux cannot rebuild a missing moov atom; try untrunc (https://github.com/anthwlock/untrunc) with a good recording from the same camera or app")
+	}
+
+	if !repair {
+		if !isTerminal(os.Stdin) {
+			return "", errors.New("run with --repair to remux a fixed copy")
+		}
+		fmt.Print("Remux a repaired copy next to it and open that? [Y/n] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+			return "", errors.New("not repaired")
+		}
+	}
+
+	fmt.Println("Repairing...")
+	fixed, err := video.RepairInput(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Wrote %s\n", fixed)
+	return fixed, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"lazycut/ui"
+	"lazycut/video"
+	"os"
+	"strings"
+)
+
+const statsUsage = `Usage: lazycut stats [flags]
+
+Shows how long exports took on this machine, by encoder and source
+height, as recorded with stats = true in the config. The file never
+leaves the machine; the export progress uses it to estimate the time left.
+
+Flags:
+      --clear        Forget the recorded exports
+      --config FILE  As for the editor`
+
+// speedBuckets are the upper edges of the speed histogram's bars, in
+// seconds of output per second of work; the last bar is everything above.
+var speedBuckets = []float64{0.25, 0.5, 1, 2, 5, 10, 50}
+
+func runStats(args []string) int {
+	var forget bool
+	var config string
+	fs := flag.NewFlagSet("lazycut stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&forget, "clear", false, "")
+	fs.StringVar(&config, "config", "", "")
+	if err := fs.Parse(args); err != nil || fs.NArg() > 0 {
+		if err != nil && err != flag.ErrHelp {
+			fmt.Println(err)
+		}
+		fmt.Println(statsUsage)
+		return 1
+	}
+	path, err := video.StatsPath()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if forget {
+		if err := video.ClearStats(); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		fmt.Printf("Removed %s\n", path)
+		return 0
+	}
+	cfg, err := ui.LoadConfig(config)
+	if err != nil {
+		fmt.Println("config:", err)
+		return 1
+	}
+	stats, err := video.LoadStats()
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	printStats(os.Stdout, stats, path, cfg.Stats)
+	return 0
+}
+
+// printStats writes a summary and speed histogram per export kind.
+func printStats(w io.Writer, stats video.ExportStats, path string, on bool) {
+	if !on {
+		fmt.Fprintln(w, "Recording is off; set stats = true in the config to turn it on.")
+	}
+	if len(stats.Exports) == 0 {
+		fmt.Fprintln(w, "No exports recorded yet.")
+		return
+	}
+	fmt.Fprintf(w, "%d exports recorded in %s\n", len(stats.Exports), path)
+	for _, kind := range stats.Kinds() {
+		samples := stats.Samples(kind)
+		exports := "exports"
+		if len(samples) == 1 {
+			exports = "export"
+		}
+		fmt.Fprintf(w, "\n%s: %d %s, median %s realtime, last %s\n", kind, len(samples), exports,
+			formatSpeed(video.MedianSpeed(samples)), samples[len(samples)-1].When.Format("2006-01-02"))
+
+		counts := make([]int, len(speedBuckets)+1)
+		for _, s := range samples {
+			i := 0
+			for i < len(speedBuckets) && s.Speed() >= speedBuckets[i] {
+				i++
+			}
+			counts[i]++
+		}
+		first, last, most := len(counts), 0, 0
+		for i, n := range counts {
+			if n > 0 {
+				first, last, most = min(first, i), i, max(most, n)
+			}
+		}
+		const width = 30
+		for i := first; i <= last; i++ {
+			bar := strings.Repeat("#", (counts[i]*width+most-1)/most)
+			fmt.Fprintf(w, "  %-10s %s %d\n", bucketLabel(i), bar, counts[i])
+		}
+	}
+}
+
+// bucketLabel names histogram bar i, e.g. "1x-2x".
+func bucketLabel(i int) string {
+	switch i {
+	case 0:
+		return "<" + formatSpeed(speedBuckets[0])
+	case len(speedBuckets):
+		return ">" + formatSpeed(speedBuckets[i-1])
+	}
+	return formatSpeed(speedBuckets[i-1]) + "-" + formatSpeed(speedBuckets[i])
+}
+
+func formatSpeed(speed float64) string {
+	if speed >= 10 {
+		return fmt.Sprintf("%.0fx", speed)
+	}
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", speed), "0"), ".") + "x"
+}
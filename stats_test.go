@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/video"
+)
+
+// sampleAt is an export of kind that made 10 s of output at speed.
+func sampleAt(kind string, speed float64, when string) video.ExportSample {
+	at, _ := time.Parse("2006-01-02", when)
+	return video.ExportSample{
+		Kind:   kind,
+		Length: 10 * time.Second,
+		Took:   time.Duration(float64(10*time.Second) / speed),
+		When:   at,
+	}
+}
+
+func TestPrintStats(t *testing.T) {
+	stats := video.ExportStats{Exports: []video.ExportSample{
+		sampleAt("libx264 1080p", 0.1, "2026-01-02"),
+		sampleAt("copy 1080p", 80, "2026-01-03"),
+		sampleAt("libx264 1080p", 1, "2026-01-04"),
+		sampleAt("libx264 1080p", 1.5, "2026-01-05"),
+		sampleAt("libx264 1080p", 1.9, "2026-01-06"),
+		sampleAt("libx264 1080p", 2, "2026-01-07"),
+	}}
+	var b strings.Builder
+	printStats(&b, stats, "/cfg/stats.json", true)
+
+	// Each bar is at least as fast as its lower edge; empty bars between
+	// used ones stay to keep the scale, the ones outside go
+	want := `6 exports recorded in /cfg/stats.json
+
+libx264 1080p: 5 exports, median 1.5x realtime, last 2026-01-07
+  <0.25x     ########## 1
+  0.25x-0.5x  0
+  0.5x-1x     0
+  1x-2x      ############################## 3
+  2x-5x      ########## 1
+
+copy 1080p: 1 export, median 80x realtime, last 2026-01-03
+  >50x       ############################## 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("printStats wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrintStatsEmpty(t *testing.T) {
+	var b strings.Builder
+	printStats(&b, video.ExportStats{}, "/cfg/stats.json", false)
+	want := "Recording is off; set stats = true in the config to turn it on.\nNo exports recorded yet.\n"
+	if got := b.String(); got != want {
+		t.Errorf("printStats wrote %q, want %q", got, want)
+	}
+}
+
+func TestBucketLabel(t *testing.T) {
+	var labels []string
+	for i := range len(speedBuckets) + 1 {
+		labels = append(labels, bucketLabel(i))
+	}
+	want := "<0.25x 0.25x-0.5x 0.5x-1x 1x-2x 2x-5x 5x-10x 10x-50x >50x"
+	if got := strings.Join(labels, " "); got != want {
+		t.Errorf("labels %s, want %s", got, want)
+	}
+}
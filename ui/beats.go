@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/video"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// beatSnapWindow is how far a trim point may move to land on a beat; a
+// point with no beat this close is left where it was set.
+const beatSnapWindow = 500 * time.Millisecond
+
+// BeatsFoundMsg delivers the result of a background beat detection run.
+type BeatsFoundMsg struct {
+	Beats []time.Duration
+	Err   error
+}
+
+func findBeats(ctx context.Context, path string, peaks *video.Peaks) tea.Cmd {
+	return func() tea.Msg {
+		beats, err := video.FindBeats(ctx, path, peaks)
+		return BeatsFoundMsg{Beats: beats, Err: err}
+	}
+}
+
+// toggleBeatSnap turns snap-to-beat on or off, detecting beats the first
+// time it is turned on.
+func (m *Model) toggleBeatSnap() tea.Cmd {
+	m.beatSnap = !m.beatSnap
+	if !m.beatSnap {
+		m.exportStatus = "Snap to beat off"
+		return nil
+	}
+	if m.beats != nil {
+		m.exportStatus = fmt.Sprintf("Snap to beat on (%d beats)", len(m.beats))
+		return nil
+	}
+	if m.findingBeats {
+		return nil
+	}
+	m.findingBeats = true
+	m.exportStatus = "Detecting beats..."
+	return findBeats(m.ctx, m.player.Path(), m.peaks)
+}
+
+// snapToBeat moves t onto the nearest beat when snapping is on.
+func (m Model) snapToBeat(t time.Duration) time.Duration {
+	if !m.beatSnap {
+		return t
+	}
+	if beat, ok := video.NearestBeat(m.beats, t, beatSnapWindow); ok {
+		return beat
+	}
+	return t
+}
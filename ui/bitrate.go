@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"context"
+	"lazycut/video"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BitrateLoadedMsg delivers the per-second bitrate read at startup.
+type BitrateLoadedMsg struct {
+	Bitrate *video.Bitrate
+	Err     error
+}
+
+func loadBitrate(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		b, err := video.LoadBitrate(ctx, path)
+		return BitrateLoadedMsg{Bitrate: b, Err: err}
+	}
+}
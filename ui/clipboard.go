@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"context"
+	"lazycut/video"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ClipboardMsg reports an export put on the clipboard.
+type ClipboardMsg struct {
+	Path string
+	Err  error
+}
+
+func copyToClipboard(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		return ClipboardMsg{Path: path, Err: video.CopyFileToClipboard(ctx, path)}
+	}
+}
+
+// copyLastExport puts the newest export on the clipboard (y), ready to
+// paste into a chat.
+func (m *Model) copyLastExport() tea.Cmd {
+	if len(m.exports) == 0 {
+		m.exportStatus = "Nothing exported yet"
+		return nil
+	}
+	return copyToClipboard(m.ctx, m.exports[len(m.exports)-1].Output)
+}
+
+// showClipboardStatus reports the copy after the "Exported" status it
+// follows, or on its own after y.
+func (m *Model) showClipboardStatus(msg ClipboardMsg) {
+	switch {
+	case msg.Err != nil && m.exportStatus != "":
+		m.exportStatus += " · clipboard: " + msg.Err.Error()
+	case msg.Err != nil:
+		m.exportStatus = "Clipboard: " + msg.Err.Error()
+	case m.exportStatus != "":
+		m.exportStatus += " · copied to the clipboard"
+	default:
+		m.exportStatus = "Copied " + filepath.Base(msg.Path) + " to the clipboard"
+	}
+}
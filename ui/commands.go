@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"lazycut/video"
+)
+
+// Command is one step of a --cmd startup script or a key hook.
+type Command struct {
+	Name string
+	// At is the time argument of seek, in and out; nil for in/out means
+	// the playhead.
+	At *time.Duration
+	// Anchor makes At an offset: from the playhead ("seek +10"), the
+	// in-point ("out in+59.5") or the out-point ("in out-30").
+	Anchor string
+	// Arg is the text argument of preset.
+	Arg string
+}
+
+const (
+	anchorPlayhead = "playhead"
+	anchorIn       = "in"
+	anchorOut      = "out"
+)
+
+// commandActions maps script commands without arguments to the action
+// that does the same thing; a script presses its key, so it behaves
+// exactly like typing it.
+var commandActions = map[string]action{
+	"in":      actSetIn,
+	"out":     actSetOut,
+	"clear":   actClear,
+	"preview": actPreview,
+	"segment": actAddSegment,
+	"mute":    actMute,
+	"export":  actExport,
+}
+
+// ParseCommands parses a script: commands separated by ";" or newlines,
+// e.g. "seek 1:00; in; seek +30; out; export". Times are timecodes
+// (HH:MM:SS.mmm with leading fields optional), optionally offset from
+// the playhead (+T, -T), the in-point (in+T) or the out-point (out-T).
+func ParseCommands(script string) ([]Command, error) {
+	var cmds []Command
+	for _, stmt := range strings.FieldsFunc(script, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.Fields(stmt)
+		if len(fields) == 0 {
+			continue
+		}
+		cmd := Command{Name: strings.ToLower(fields[0])}
+		args := fields[1:]
+		switch cmd.Name {
+		case "seek", "in", "out":
+			if len(args) > 1 || (cmd.Name == "seek" && len(args) == 0) {
+				return nil, fmt.Errorf("%s takes one time, got %q", cmd.Name, strings.TrimSpace(stmt))
+			}
+			if len(args) == 1 {
+				at, anchor, err := parseTimeArg(args[0])
+				if err != nil {
+					return nil, fmt.Errorf("%s %s: %w", cmd.Name, args[0], err)
+				}
+				cmd.At, cmd.Anchor = &at, anchor
+			}
+		case "preset":
+			if len(args) == 0 {
+				return nil, fmt.Errorf("preset takes a name (%s) or an aspect ratio", presetNames())
+			}
+			cmd.Arg = strings.Join(args, " ")
+			if _, ok := lookupAspect(cmd.Arg); !ok {
+				return nil, fmt.Errorf("unknown preset %q (want %s or an aspect ratio)", cmd.Arg, presetNames())
+			}
+		case "play", "pause", "clear", "preview", "segment", "mute", "export":
+			if len(args) > 0 {
+				return nil, fmt.Errorf("%s takes no arguments, got %q", cmd.Name, strings.TrimSpace(stmt))
+			}
+		default:
+			return nil, fmt.Errorf("unknown command %q (want seek, in, out, clear, play, pause, preview, segment, mute, preset or export)", fields[0])
+		}
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// parseTimeArg splits "in+59.5", "+10" or "1:00" into a duration and
+// what it is relative to ("" for absolute).
+func parseTimeArg(arg string) (time.Duration, string, error) {
+	anchor := ""
+	for _, a := range []string{anchorIn, anchorOut} {
+		rest, ok := strings.CutPrefix(strings.ToLower(arg), a)
+		if ok && (rest == "" || rest[0] == '+' || rest[0] == '-') {
+			anchor, arg = a, rest
+			break
+		}
+	}
+	if arg == "" {
+		return 0, anchor, nil
+	}
+	sign := time.Duration(1)
+	if arg[0] == '+' || arg[0] == '-' {
+		if anchor == "" {
+			anchor = anchorPlayhead
+		}
+		if arg[0] == '-' {
+			sign = -1
+		}
+		arg = arg[1:]
+	}
+	d, err := video.ParseTimecode(arg)
+	if err != nil {
+		return 0, "", err
+	}
+	return sign * d, anchor, nil
+}
+
+// lookupAspect finds a platform preset by name or an aspect option by
+// label, case-insensitively.
+func lookupAspect(name string) (video.AspectRatio, bool) {
+	for _, p := range video.Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p.Aspect, true
+		}
+	}
+	for _, opt := range video.AspectRatioOptions {
+		if strings.EqualFold(opt.Label, name) {
+			return opt.Ratio, true
+		}
+	}
+	return 0, false
+}
+
+func presetNames() string {
+	names := make([]string, len(video.Presets))
+	for i, p := range video.Presets {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// commandsMsg runs the startup script once the program is up.
+type commandsMsg struct {
+	cmds []Command
+}
+
+func runCommands(cmds []Command) tea.Cmd {
+	return func() tea.Msg { return commandsMsg{cmds: cmds} }
+}
+
+// resolveTime turns a command's time argument into a position.
+func (m Model) resolveTime(c Command) (time.Duration, error) {
+	at := *c.At
+	switch c.Anchor {
+	case anchorPlayhead:
+		at += m.player.Position()
+	case anchorIn:
+		if m.player.Trim.InPoint == nil {
+			return 0, fmt.Errorf("no in-point set")
+		}
+		at += *m.player.Trim.InPoint
+	case anchorOut:
+		if m.player.Trim.OutPoint == nil {
+			return 0, fmt.Errorf("no out-point set")
+		}
+		at += *m.player.Trim.OutPoint
+	}
+	return at, nil
+}
+
+// runScript executes a script in order. Every step goes through the same
+// handlers as its key, so a selection that would be refused from the
+// keyboard stops the script with the same message. origin names the
+// script in that message ("--cmd", "hook x").
+func (m Model) runScript(origin string, cmds []Command) (Model, tea.Cmd) {
+	var batch []tea.Cmd
+	m.inScript = true
+	for _, c := range cmds {
+		var cmd tea.Cmd
+		var refused string
+		m, cmd, refused = m.runCommand(c)
+		batch = append(batch, cmd)
+		if refused != "" {
+			m.exportStatus = origin + " stopped at " + c.Name + ": " + refused
+			break
+		}
+	}
+	m.inScript = false
+	return m, tea.Batch(batch...)
+}
+
+// runCommand does one step of a script, returning why it was refused, or
+// "" when it went through.
+func (m Model) runCommand(c Command) (Model, tea.Cmd, string) {
+	if c.At != nil {
+		at, err := m.resolveTime(c)
+		if err != nil {
+			return m, nil, err.Error()
+		}
+		m.player.Seek(at)
+	}
+
+	switch c.Name {
+	case "seek":
+		return m, nil, ""
+	case "play":
+		if !m.player.IsPlaying() {
+			m.player.Play()
+		}
+		return m, nil, ""
+	case "pause":
+		m.player.Pause()
+		return m, nil, ""
+	case "preset":
+		if !m.canCrop {
+			return m, nil, "this ffmpeg cannot crop"
+		}
+		aspect, _ := lookupAspect(c.Arg)
+		m.exportAspectRatio = video.AspectRatioIndex(aspect)
+		m.aspectChosen = true
+		m.exportPreview, m.exportPreviewErr = "", ""
+		return m, nil, ""
+	}
+
+	before := m.player.Trim
+	m, cmd := m.pressKey(m.keys.key(commandActions[c.Name]))
+	if (c.Name == "in" || c.Name == "out") && before == m.player.Trim {
+		// acceptTrim refused it and left the reason in the status line
+		return m, cmd, m.exportStatus
+	}
+	return m, cmd, ""
+}
+
+// pressKey feeds a key to Update as if it was typed.
+func (m Model) pressKey(key string) (Model, tea.Cmd) {
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	if key == "enter" {
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	updated, cmd := m.Update(msg)
+	return updated.(Model), cmd
+}
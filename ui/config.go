@@ -0,0 +1,419 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"lazycut/ui/theme"
+	"lazycut/video"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config is the startup defaults from config.toml. Zero fields keep the
+// built-in defaults.
+type Config struct {
+	// Quality and Muted start files not opened before; files that were
+	// keep what they were last viewed with.
+	Quality *video.QualityPreset
+	Muted   *bool
+
+	// Aspect is the export aspect label new exports start on, e.g. "9:16";
+	// "" picks one by the source orientation.
+	Aspect string
+
+	// OutputDir is where exports and cut lists go instead of beside the
+	// input.
+	OutputDir string
+
+	// SafeNames gives exports ASCII names without spaces, see
+	// video.SafeName.
+	SafeNames bool
+
+	// TrashReplaced moves files an export replaces to the trash instead
+	// of writing over them.
+	TrashReplaced bool
+
+	// Intro and Outro are clips joined before and after every export.
+	Intro string
+	Outro string
+
+	// SeekStep and LongSeekStep are how far h/l and H/L jump; SeekMode is
+	// how ffmpeg seeks for the preview, see video.SetSeekMode.
+	SeekStep     time.Duration
+	LongSeekStep time.Duration
+	SeekMode     video.SeekMode
+
+	// Chafa holds the symbol presets with the config's chafa options
+	// applied, nil without any.
+	Chafa map[video.QualityPreset]video.ChafaConfig
+
+	// Keys rebinds main view actions: action name to its new keys.
+	Keys map[string][]string
+
+	// Theme is the UI theme picked with theme, built in or from a
+	// [themes.NAME] table; nil keeps the default.
+	Theme *theme.Theme
+
+	// Stats records export durations on this machine for better time
+	// estimates, see video.SetStats.
+	Stats bool
+
+	themeName string
+	themes    map[string][][2]string // [themes.NAME] key/value pairs, in order
+}
+
+// DefaultConfigPath is where the config is read from without --config.
+func DefaultConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lazycut", "config.toml"), nil
+}
+
+// LoadConfig reads a config file, the subset of TOML lazycut needs:
+// "key = value" lines of strings, numbers and booleans under optional
+// [seek], [chafa], [chafa.low] or [chafa.high] tables, e.g.
+//
+//	quality = "low"
+//	aspect = "9:16"
+//	output_dir = "~/Videos/clips"
+//	intro = "~/Videos/series/intro.mp4"
+//
+//	[seek]
+//	step = "2s"
+//	long_step = "10s"
+//	mode = "fast"
+//
+//	[chafa]
+//	dither = "ordered"
+//
+// [chafa] options apply to both quality presets. A [keys] table rebinds
+// actions, see newKeymap:
+//
+//	[keys]
+//	seek_back = "j left"
+//	seek_forward = "k right"
+//
+// A [themes.NAME] table defines a theme from an optional built-in base
+// and colors, see theme.Theme.SetColor; theme picks it by name:
+//
+//	theme = "mine"
+//
+//	[themes.mine]
+//	base = "light"
+//	accent = "#d75f00"
+//
+// An empty path reads DefaultConfigPath, which may be missing.
+func LoadConfig(path string) (Config, error) {
+	optional := path == ""
+	if optional {
+		var err error
+		if path, err = DefaultConfigPath(); err != nil {
+			return Config{}, nil
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+	defer f.Close()
+
+	var cfg Config
+	table := ""
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, ok := strings.CutPrefix(line, "["); ok {
+			name, _, _ = strings.Cut(name, "]")
+			table = strings.TrimSpace(name)
+			switch custom, ok := strings.CutPrefix(table, "themes."); {
+			case table == "seek", table == "chafa", table == "chafa.low", table == "chafa.high", table == "keys":
+			case ok && custom != "" && !strings.Contains(custom, "."):
+				if cfg.themes == nil {
+					cfg.themes = map[string][][2]string{}
+				}
+				if _, taken := cfg.themes[custom]; taken {
+					return Config{}, fmt.Errorf("%s:%d: theme %s defined twice", path, n, custom)
+				}
+				cfg.themes[custom] = nil
+			default:
+				return Config{}, fmt.Errorf("%s:%d: unknown table [%s]", path, n, table)
+			}
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return Config{}, fmt.Errorf("%s:%d: want \"key = value\"", path, n)
+		}
+		key = strings.TrimSpace(key)
+		value, err := parseConfigValue(strings.TrimSpace(raw))
+		if err == nil {
+			err = cfg.set(table, key, value)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Config{}, err
+	}
+	if _, err := newKeymap(cfg.Keys); err != nil {
+		return Config{}, fmt.Errorf("%s: [keys]: %w", path, err)
+	}
+	if cfg.themeName != "" {
+		t, err := cfg.theme(cfg.themeName)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+		cfg.Theme = &t
+	}
+	return cfg, nil
+}
+
+// theme builds the theme called name: a [themes.NAME] table of the
+// config, or a built-in one.
+func (cfg Config) theme(name string) (theme.Theme, error) {
+	entries, ok := cfg.themes[name]
+	if !ok {
+		return theme.Lookup(name)
+	}
+	// The entries were checked as the table was read
+	t := theme.Themes[0]
+	for _, kv := range entries {
+		if kv[0] == "base" {
+			t, _ = theme.Lookup(kv[1])
+		}
+	}
+	t.Name = name
+	for _, kv := range entries {
+		if kv[0] != "base" {
+			_ = t.SetColor(kv[0], kv[1])
+		}
+	}
+	return t, nil
+}
+
+// parseConfigValue reads a TOML string ("basic" or 'literal'), integer,
+// float or boolean, with an optional comment after it. Strings come back
+// unquoted, everything else as written.
+func parseConfigValue(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		// Find the closing quote, skipping escaped ones
+		end := 1
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(s) {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		if rest := strings.TrimSpace(s[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		return strconv.Unquote(s[:end+1])
+	case strings.HasPrefix(s, "'"):
+		value, rest, ok := strings.Cut(s[1:], "'")
+		if !ok {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after string", rest)
+		}
+		return value, nil
+	}
+	value, _, _ := strings.Cut(s, "#")
+	value = strings.TrimSpace(value)
+	if _, err := strconv.ParseFloat(value, 64); err != nil && value != "true" && value != "false" {
+		return "", fmt.Errorf("invalid value %q (strings need quotes)", value)
+	}
+	return value, nil
+}
+
+// set applies key = value from table to the config.
+func (cfg *Config) set(table, key, value string) error {
+	if preset, ok := strings.CutPrefix(table, "chafa"); ok {
+		if cfg.Chafa == nil {
+			cfg.Chafa = map[video.QualityPreset]video.ChafaConfig{}
+			for q, c := range video.ChafaPresets {
+				cfg.Chafa[q] = c
+			}
+		}
+		for q, c := range cfg.Chafa {
+			if preset != "" && preset != "."+strings.ToLower(q.String()) {
+				continue
+			}
+			if err := c.Set(key, value); err != nil {
+				return err
+			}
+			cfg.Chafa[q] = c
+		}
+		return nil
+	}
+
+	if name, ok := strings.CutPrefix(table, "themes."); ok {
+		if key != "base" {
+			var t theme.Theme
+			if err := t.SetColor(key, value); err != nil {
+				return err
+			}
+		} else if _, err := theme.Lookup(value); err != nil {
+			return err
+		}
+		cfg.themes[name] = append(cfg.themes[name], [2]string{key, value})
+		return nil
+	}
+
+	if table == "keys" {
+		if cfg.Keys == nil {
+			cfg.Keys = map[string][]string{}
+		}
+		cfg.Keys[key] = parseKeys(value)
+		return nil
+	}
+
+	switch table + "." + key {
+	case ".quality":
+		var q video.QualityPreset
+		if err := q.UnmarshalText([]byte(value)); err != nil {
+			return err
+		}
+		cfg.Quality = &q
+	case ".theme":
+		cfg.themeName = value
+	case ".mute":
+		muted, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid mute %q (want true or false)", value)
+		}
+		cfg.Muted = &muted
+	case ".safe_names":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid safe_names %q (want true or false)", value)
+		}
+		cfg.SafeNames = on
+	case ".trash":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid trash %q (want true or false)", value)
+		}
+		if on && !video.HasTrash() {
+			return fmt.Errorf("trash is not supported on %s", runtime.GOOS)
+		}
+		cfg.TrashReplaced = on
+	case ".stats":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid stats %q (want true or false)", value)
+		}
+		cfg.Stats = on
+	case ".aspect":
+		if value == "auto" {
+			cfg.Aspect = ""
+			return nil
+		}
+		var labels []string
+		for _, opt := range video.AspectRatioOptions {
+			if strings.EqualFold(opt.Label, value) {
+				cfg.Aspect = opt.Label
+				return nil
+			}
+			labels = append(labels, opt.Label)
+		}
+		return fmt.Errorf("invalid aspect %q (want auto, %s)", value, strings.Join(labels, ", "))
+	case ".output_dir":
+		dir, err := expandHome(value)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("output_dir: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("output_dir %s is not a directory", dir)
+		}
+		cfg.OutputDir = dir
+	case ".intro", ".outro":
+		path, err := expandHome(value)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(path); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		} else if info.IsDir() {
+			return fmt.Errorf("%s %s is a directory", key, path)
+		}
+		if key == "intro" {
+			cfg.Intro = path
+		} else {
+			cfg.Outro = path
+		}
+	case "seek.mode":
+		mode, err := video.ParseSeekMode(value)
+		if err != nil {
+			return err
+		}
+		cfg.SeekMode = mode
+	case "seek.step", "seek.long_step":
+		step, err := parseStep(value)
+		if err != nil {
+			return fmt.Errorf("invalid seek %s %q (want a duration such as \"2s\" or seconds)", key, value)
+		}
+		if key == "step" {
+			cfg.SeekStep = step
+		} else {
+			cfg.LongSeekStep = step
+		}
+	default:
+		if table != "" {
+			key = table + "." + key
+		}
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return nil
+}
+
+// expandHome replaces a leading ~ or ~/ with the home directory. Another
+// user's home (~bob) is refused rather than taken for a folder in ours.
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok {
+		return path, nil
+	}
+	if rest != "" && rest[0] != '/' && rest[0] != filepath.Separator {
+		return "", fmt.Errorf("%s: only ~ and ~/ are expanded, not ~user", path)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// parseStep reads a positive duration: "500ms", "2s", plain seconds or
+// anything else video.ParseTimecode takes.
+func parseStep(s string) (time.Duration, error) {
+	d, err := video.ParseTimecode(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("not positive")
+	}
+	return d, nil
+}
@@ -0,0 +1,200 @@
+package ui_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/ui"
+	"lazycut/video"
+)
+
+// loadConfig writes text as a config file and loads it.
+func loadConfig(t *testing.T, text string) (ui.Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return ui.LoadConfig(path)
+}
+
+func TestLoadConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.Mkdir(filepath.Join(home, "clips"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(t, `
+# Startup defaults
+quality = "low"   # a comment after a value
+mute = true
+aspect = '9:16'
+output_dir = "~/clips"
+
+[seek]
+step = "500ms"
+long_step = 10
+
+[ chafa ]
+dither = "ordered"
+
+[chafa.high]
+colors = "256"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Quality == nil || *cfg.Quality != video.QualityLow {
+		t.Errorf("Quality = %v, want low", cfg.Quality)
+	}
+	if cfg.Muted == nil || !*cfg.Muted {
+		t.Errorf("Muted = %v, want true", cfg.Muted)
+	}
+	if cfg.Aspect != "9:16" {
+		t.Errorf("Aspect = %q, want 9:16", cfg.Aspect)
+	}
+	if want := filepath.Join(home, "clips"); cfg.OutputDir != want {
+		t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, want)
+	}
+	if cfg.SeekStep != 500*time.Millisecond || cfg.LongSeekStep != 10*time.Second {
+		t.Errorf("seek steps %v and %v, want 500ms and 10s", cfg.SeekStep, cfg.LongSeekStep)
+	}
+
+	// [chafa] applies to both presets, [chafa.high] to one of them
+	low, high := cfg.Chafa[video.QualityLow], cfg.Chafa[video.QualityHigh]
+	if low.Dither != "ordered" || high.Dither != "ordered" {
+		t.Errorf("dither %q and %q, want ordered for both", low.Dither, high.Dither)
+	}
+	if want := video.ChafaPresets[video.QualityLow].Colors; low.Colors != want {
+		t.Errorf("low colors %q, want the preset's %q", low.Colors, want)
+	}
+	if high.Colors != "256" {
+		t.Errorf("high colors %q, want 256", high.Colors)
+	}
+}
+
+func TestLoadConfigEmpty(t *testing.T) {
+	cfg, err := loadConfig(t, "\n# nothing set\n\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Quality != nil || cfg.Muted != nil || cfg.Aspect != "" || cfg.OutputDir != "" ||
+		cfg.SeekStep != 0 || cfg.LongSeekStep != 0 || cfg.Chafa != nil {
+		t.Errorf("empty config set %+v", cfg)
+	}
+}
+
+func TestLoadConfigQuoting(t *testing.T) {
+	for _, c := range []struct {
+		name, value, want string
+	}{
+		{"basic", `"16:9"`, "16:9"},
+		{"literal", `'16:9'`, "16:9"},
+		{"comment after basic", `"16:9" # wide`, "16:9"},
+		{"comment after literal", `'16:9'# wide`, "16:9"},
+		{"case", `"Original"`, "Original"},
+		{"auto", `"auto"`, ""},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, err := loadConfig(t, "aspect = "+c.value+"\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Aspect != c.want {
+				t.Errorf("aspect = %s: got %q, want %q", c.value, cfg.Aspect, c.want)
+			}
+		})
+	}
+
+	// Escapes and a # inside the quotes are part of the string
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, `a "b" #1`)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(t, `output_dir = "~/a \"b\" #1" # quoted`+"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.OutputDir != dir {
+		t.Errorf("OutputDir = %q, want %q", cfg.OutputDir, dir)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(home, "bob"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name, text, want string
+	}{
+		{"unknown table", "[playback]\n", ":1: unknown table [playback]"},
+		{"unknown key", "\n\ncolour = 1\n", `:3: unknown setting "colour"`},
+		{"unknown key in a table", "[seek]\njump = 1\n", `:2: unknown setting "seek.jump"`},
+		{"unknown chafa option", "[chafa.low]\nfont = 1\n", `unknown chafa option "font"`},
+		{"no equals sign", "quality\n", `want "key = value"`},
+		{"bare string", "quality = low\n", "strings need quotes"},
+		{"unterminated basic", `quality = "low` + "\n", "unterminated string"},
+		{"unterminated literal", "quality = 'low\n", "unterminated string"},
+		{"text after string", `quality = "low" high` + "\n", `unexpected "high" after string`},
+		{"bad quality", `quality = "best"` + "\n", "best"},
+		{"bad mute", `mute = "yes"` + "\n", `invalid mute "yes"`},
+		{"bad aspect", `aspect = "5:4"` + "\n", `invalid aspect "5:4"`},
+		{"bad seek step", "[seek]\nstep = \"-2s\"\n", `invalid seek step "-2s"`},
+		{"missing output_dir", `output_dir = "~/nowhere"` + "\n", "output_dir:"},
+		{"output_dir a file", `output_dir = "~/file"` + "\n", "is not a directory"},
+		{"another user's home", `output_dir = "~bob"` + "\n", "not ~user"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := loadConfig(t, c.text)
+			if err == nil {
+				t.Fatalf("%q loaded", c.text)
+			}
+			if !strings.Contains(err.Error(), c.want) {
+				t.Errorf("error %q lacks %q", err, c.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigTrash(t *testing.T) {
+	cfg, err := loadConfig(t, "trash = false\n")
+	if err != nil || cfg.TrashReplaced {
+		t.Errorf("trash = false: %v, %v", cfg.TrashReplaced, err)
+	}
+	cfg, err = loadConfig(t, "trash = true\n")
+	if video.HasTrash() {
+		if err != nil || !cfg.TrashReplaced {
+			t.Errorf("trash = true: %v, %v", cfg.TrashReplaced, err)
+		}
+	} else if err == nil || !strings.Contains(err.Error(), "trash is not supported on "+runtime.GOOS) {
+		t.Errorf("trash = true without a trash: %v", err)
+	}
+	if _, err := loadConfig(t, `trash = "yes"`+"\n"); err == nil || !strings.Contains(err.Error(), `invalid trash "yes"`) {
+		t.Errorf(`trash = "yes": %v`, err)
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if _, err := ui.LoadConfig(""); err != nil {
+		t.Errorf("missing default config: %v", err)
+	}
+	if _, err := ui.LoadConfig(filepath.Join(dir, "nope.toml")); err == nil {
+		t.Error("missing --config file loaded")
+	}
+}
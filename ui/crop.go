@@ -0,0 +1,129 @@
+package ui
+
+import (
+	"context"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CropPreviewMsg delivers a rendered crop-adjust frame. gen drops renders
+// superseded by a later key press.
+type CropPreviewMsg struct {
+	Frame string
+	Err   error
+	gen   int
+}
+
+func renderCropPreview(ctx context.Context, player *video.Player, opts video.ExportOptions, width, height, gen int) tea.Cmd {
+	return func() tea.Msg {
+		frame, err := player.RenderExportPreview(ctx, opts, width, height)
+		return CropPreviewMsg{Frame: frame, Err: err, gen: gen}
+	}
+}
+
+// openCropAdjust enters crop-adjust mode, where the preview shows the
+// export crop of the current frame and keys slide the window.
+func (m *Model) openCropAdjust() tea.Cmd {
+	if !m.canCrop {
+		m.exportStatus = "Cropping needs an H.264 encoder (e.g. libx264)"
+		return nil
+	}
+	if !m.cropsFrame() {
+		// Pick the crop a vertical/horizontal conversion would want
+		idx := m.defaultAspectIndex()
+		if !cropsAspect(idx) {
+			idx = video.AspectRatioIndex(video.Aspect9x16)
+			if p := m.player.Properties(); p.Height > p.Width {
+				idx = video.AspectRatioIndex(video.Aspect16x9)
+			}
+		}
+		m.exportAspectRatio = idx
+	}
+	m.player.Pause()
+	m.previewMode = false
+	m.cropAdjust = true
+	m.cropPreview, m.cropPreviewErr = "", ""
+	return m.refreshCropPreview()
+}
+
+// cropsAspect reports whether AspectRatioOptions[idx] crops the frame.
+func cropsAspect(idx int) bool {
+	opt := video.AspectRatioOptions[idx]
+	return opt.Ratio != video.AspectOriginal && !opt.Fill
+}
+
+// refreshCropPreview re-renders the cropped frame for the current offset.
+func (m *Model) refreshCropPreview() tea.Cmd {
+	opts := video.DefaultExportOptions(m.player.Path(), m.player.Properties())
+	opts.AspectRatio = video.AspectRatioOptions[m.exportAspectRatio].Ratio
+	opts.CropOffset = m.exportCropOffset
+	m.cropPreviewGen++
+	dims := CalculatePanelDimensions(m.width, m.height)
+	return renderCropPreview(m.ctx, m.player, opts, dims.PreviewContentWidth, dims.PreviewContentHeight-1, m.cropPreviewGen)
+}
+
+func (m Model) handleCropAdjustKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	step := cropOffsetStep
+	switch msg.String() {
+	case "esc", "enter", "C", "q":
+		m.cropAdjust = false
+		m.cropPreview, m.cropPreviewErr = "", ""
+		m.aspectChosen = true
+		m.exportStatus = "Crop " + video.AspectRatioOptions[m.exportAspectRatio].Label + ": " +
+			m.formatCropOffset(m.exportCropOffset)
+		return m, nil
+	case "h", "left", "k", "up":
+		m.adjustCropOffset(-step)
+	case "l", "right", "j", "down":
+		m.adjustCropOffset(step)
+	case "H":
+		m.adjustCropOffset(-5 * step)
+	case "L":
+		m.adjustCropOffset(5 * step)
+	case "0":
+		m.exportCropOffset = 0
+	case "a":
+		// Next cropping aspect; fills and Original have no window to move
+		for i := 0; i < len(video.AspectRatioOptions); i++ {
+			m.cycleAspect(1)
+			if cropsAspect(m.exportAspectRatio) {
+				break
+			}
+		}
+	case "r":
+		return m, m.startReframe()
+	default:
+		return m, nil
+	}
+	return m, m.refreshCropPreview()
+}
+
+func (m Model) renderCropAdjust(width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+
+	title := titleStyle.Render("CROP "+video.AspectRatioOptions[m.exportAspectRatio].Label) +
+		dimStyle.Render("  "+m.formatCropOffset(m.exportCropOffset)+
+			"  ←→ move  0 center  a aspect  r suggest  Enter done")
+
+	frame := panels.ClipFrame(m.cropPreview, width, height-1)
+	if m.cropPreviewErr != "" {
+		frame = panels.Wrap(panels.Sanitize(m.cropPreviewErr), width)
+	}
+	if frame == "" {
+		frame = dimStyle.Render("rendering...")
+	}
+	body := lipgloss.NewStyle().
+		Width(width).
+		Height(height-1).
+		Align(lipgloss.Center, lipgloss.Center).
+		Render(frame)
+	return title + "\n" + body
+}
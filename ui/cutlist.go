@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"lazycut/video"
+	"path/filepath"
+	"strings"
+)
+
+// cutListLabels name the export modal's Output choices: index 0 renders
+// video, the rest write video.CutListFormats[i-1] instead.
+var cutListLabels = []string{"Video", "EDL", "CSV", "JSON"}
+
+// cutListFormat is the chosen cut list format, "" when rendering video.
+func (m Model) cutListFormat() string {
+	if m.exportCutList == 0 {
+		return ""
+	}
+	return video.CutListFormats[m.exportCutList-1]
+}
+
+// cutList collects the ranges a cut list holds: every segment, then the
+// current selection unless it is one of them.
+func (m Model) cutList() []video.Cut {
+	var cuts []video.Cut
+	for _, seg := range m.segments {
+		cuts = append(cuts, video.Cut{In: seg.In, Out: seg.Out, Note: seg.Note})
+	}
+	if m.player.Trim.Validate(m.player.MinSelection()) != nil {
+		return cuts
+	}
+	in, out := *m.player.Trim.InPoint, *m.player.Trim.OutPoint
+	for _, cut := range cuts {
+		if cut.In == in && cut.Out == out {
+			return cuts
+		}
+	}
+	return append(cuts, video.Cut{In: in, Out: out, Note: m.note})
+}
+
+// cutListPath is where the cut list goes: the typed filename with the
+// format's extension, or <input>_cuts, beside the input or in the
+// configured output directory.
+func (m Model) cutListPath() string {
+	format := m.cutListFormat()
+	input := m.player.Path()
+	stem := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input)) + "_cuts"
+	if m.exportFilename != "" {
+		stem = strings.TrimSuffix(m.exportFilename, filepath.Ext(m.exportFilename))
+	}
+	if !filepath.IsAbs(stem) {
+		stem = filepath.Join(cmp.Or(m.outputDir, filepath.Dir(input)), stem)
+	}
+	return freeName(stem, "."+format)
+}
+
+// writeCutList writes the cut list in place of an export. It is small
+// enough to write right away.
+func (m *Model) writeCutList() {
+	m.showExportModal = false
+	cuts := m.cutList()
+	if len(cuts) == 0 {
+		m.exportStatus = "Cannot write cut list: " + m.player.Trim.Validate(m.player.MinSelection()).Error()
+		return
+	}
+	path := m.cutListPath()
+	err := video.WriteCutList(path, m.cutListFormat(), m.player.Path(), m.player.Properties().FPS, cuts)
+	if err != nil {
+		m.exportStatus = "Cut list failed: " + err.Error()
+		return
+	}
+	noun := "cuts"
+	if len(cuts) == 1 {
+		noun = "cut"
+	}
+	m.exportStatus = fmt.Sprintf("Wrote cut list %s (%d %s)", filepath.Base(path), len(cuts), noun)
+}
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/video"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BlackDetectedMsg delivers the black runs found by the startup scan.
+type BlackDetectedMsg struct {
+	Intervals []video.Interval
+	Err       error
+}
+
+func detectBlack(ctx context.Context, path string, duration time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		intervals, err := video.DetectBlack(ctx, path, duration)
+		return BlackDetectedMsg{Intervals: intervals, Err: err}
+	}
+}
+
+// edgeTolerance is how close to the start or end of the file a detected
+// run must reach to count as leading or trailing.
+func (m Model) edgeTolerance() time.Duration {
+	return 2 * time.Second / time.Duration(m.player.FPS())
+}
+
+// trimBlack sets in/out to skip black frames at the start and end.
+func (m *Model) trimBlack() {
+	if !m.blackScanned {
+		m.exportStatus = "Still scanning for black frames..."
+		return
+	}
+	in, out, ok := video.TrimEdges(m.blackRanges, m.player.Duration(), m.edgeTolerance())
+	if !ok {
+		m.exportStatus = "No leading or trailing black frames"
+		return
+	}
+	if !m.acceptTrim(video.TrimState{InPoint: &in, OutPoint: &out}) {
+		return
+	}
+	m.exportStatus = fmt.Sprintf("Trimmed black: %s - %s", formatTimestamp(in), formatTimestamp(out))
+}
+
+// silencePadding is left before the first and after the last sound so a
+// breath or a soft first syllable is not clipped.
+const silencePadding = 250 * time.Millisecond
+
+// SilenceDetectedMsg delivers the silent runs found for the silence trim.
+type SilenceDetectedMsg struct {
+	Intervals []video.Interval
+	Err       error
+}
+
+func detectSilence(ctx context.Context, path string, duration time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		intervals, err := video.DetectSilence(ctx, path, duration)
+		return SilenceDetectedMsg{Intervals: intervals, Err: err}
+	}
+}
+
+// trimSilence sets in/out to skip leading and trailing silence, scanning
+// the audio the first time.
+func (m *Model) trimSilence() tea.Cmd {
+	if m.detectingSilence {
+		return nil
+	}
+	if !m.silenceScanned {
+		m.detectingSilence = true
+		m.exportStatus = "Detecting silence..."
+		return detectSilence(m.ctx, m.player.Path(), m.player.Duration())
+	}
+	in, out, ok := video.TrimEdges(m.silentRanges, m.player.Duration(), m.edgeTolerance())
+	if !ok {
+		m.exportStatus = fmt.Sprintf("No leading or trailing silence over %s", video.SilenceMinDuration)
+		return nil
+	}
+	in = max(0, in-silencePadding)
+	out = min(m.player.Duration(), out+silencePadding)
+	if !m.acceptTrim(video.TrimState{InPoint: &in, OutPoint: &out}) {
+		return nil
+	}
+	m.exportStatus = fmt.Sprintf("Trimmed silence: %s - %s", formatTimestamp(in), formatTimestamp(out))
+	return nil
+}
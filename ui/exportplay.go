@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"context"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ExportPlayFrameMsg delivers the next frame of an export preview.
+// gen drops frames from a preview that was stopped or restarted.
+type ExportPlayFrameMsg struct {
+	Frame video.PreviewFrame
+	gen   int
+}
+
+// ExportPlayDoneMsg reports that an export preview ended.
+type ExportPlayDoneMsg struct {
+	Err error
+	gen int
+}
+
+func runExportPlay(ctx context.Context, player *video.Player, opts video.ExportOptions, width, height int, frames chan video.PreviewFrame, gen int) tea.Cmd {
+	return func() tea.Msg {
+		err := player.PreviewExport(ctx, opts, width, height, frames)
+		return ExportPlayDoneMsg{Err: err, gen: gen}
+	}
+}
+
+func waitExportPlayFrame(frames <-chan video.PreviewFrame, gen int) tea.Cmd {
+	return func() tea.Msg {
+		frame, ok := <-frames
+		if !ok {
+			return nil
+		}
+		return ExportPlayFrameMsg{Frame: frame, gen: gen}
+	}
+}
+
+// startExportPlay plays the selection in the preview panel as it will be
+// exported, through the crop, speed and freeze filters.
+func (m *Model) startExportPlay() tea.Cmd {
+	if err := m.player.Trim.Validate(m.player.MinSelection()); err != nil {
+		m.exportStatus = "Cannot preview export: " + err.Error()
+		return nil
+	}
+	m.stopExportPlay()
+	m.player.Pause()
+	m.previewMode = false
+
+	opts := m.exportOptions()
+	// Only the selection plays, not what is joined around it
+	opts.Intro, opts.Outro = video.Bumper{}, video.Bumper{}
+	opts.TitleCard = video.TitleCard{}
+	dims := CalculatePanelDimensions(m.width, m.height)
+	ctx, cancel := context.WithCancel(m.ctx)
+	frames := make(chan video.PreviewFrame, 1)
+	m.exportPlayGen++
+	m.exportPlaying = true
+	m.exportPlayCancel = cancel
+	m.exportPlayFrames = frames
+	m.exportPlayFrame = video.PreviewFrame{}
+	m.exportPlayLength = opts.OutputDuration()
+	m.exportPlayStabilized = opts.Stabilize
+	return tea.Batch(
+		runExportPlay(ctx, m.player, opts, dims.PreviewContentWidth, dims.PreviewContentHeight-1, frames, m.exportPlayGen),
+		waitExportPlayFrame(frames, m.exportPlayGen),
+	)
+}
+
+// stopExportPlay ends a running export preview, if any.
+func (m *Model) stopExportPlay() {
+	if m.exportPlayCancel != nil {
+		m.exportPlayCancel()
+		m.exportPlayCancel = nil
+	}
+	m.exportPlaying = false
+}
+
+func (m Model) handleExportPlayKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "v", "esc", " ", "q":
+		m.stopExportPlay()
+	case "ctrl+c":
+		m.stopExportPlay()
+		return m.requestQuit()
+	}
+	return m, nil
+}
+
+func (m Model) renderExportPlay(width, height int) string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+
+	note := "  no sound"
+	if m.exportPlayStabilized {
+		note += ", stabilization not shown"
+	}
+	title := titleStyle.Render("EXPORT PREVIEW") +
+		dimStyle.Render("  "+video.FormatTimecode(m.exportPlayFrame.Pos)+" / "+video.FormatTimecode(m.exportPlayLength)+
+			note+"  v/Esc stop")
+
+	frame := panels.ClipFrame(m.exportPlayFrame.Frame, width, height-1)
+	if frame == "" {
+		frame = dimStyle.Render("rendering...")
+	}
+	body := lipgloss.NewStyle().
+		Width(width).
+		Height(height-1).
+		Align(lipgloss.Center, lipgloss.Center).
+		Render(frame)
+	return title + "\n" + body
+}
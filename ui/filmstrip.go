@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"context"
+	"lazycut/ui/panels"
+	"lazycut/video"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FilmstripMsg delivers the thumbnails of the timeline filmstrip.
+type FilmstripMsg struct {
+	Frames []string
+	Err    error
+}
+
+func loadFilmstrip(ctx context.Context, player *video.Player, count int) tea.Cmd {
+	return func() tea.Msg {
+		frames, err := player.RenderFilmstrip(ctx, count, panels.FilmstripWidth, panels.FilmstripHeight)
+		return FilmstripMsg{Frames: frames, Err: err}
+	}
+}
+
+// refreshFilmstrip renders the filmstrip again when a resize changed how
+// many thumbnails fit, dropping a render still running for the old size.
+func (m *Model) refreshFilmstrip(width int) tea.Cmd {
+	count := panels.FilmstripCount(width)
+	if m.accessible || count == m.filmstripCount {
+		return nil
+	}
+	m.filmstripCount = count
+	if m.filmstripCancel != nil {
+		m.filmstripCancel()
+	}
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.filmstripCancel = cancel
+	return loadFilmstrip(ctx, m.player, count)
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.starlark.net/starlark"
+)
+
+// Hook binds a key to a function of a Starlark hooks file. Script is
+// how the help screen names it.
+type Hook struct {
+	Key    string
+	Script string
+
+	fn starlark.Callable
+}
+
+// DefaultHooksPath is where hooks are read from without --hooks.
+func DefaultHooksPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lazycut", "hooks.star"), nil
+}
+
+// LoadHooks reads a Starlark hooks file, see loadStarlarkHooks, e.g.
+//
+//	def short():
+//	    run("out in+59.5; preset Shorts; export")
+//
+//	bind("x", short)
+//
+// The keys that quit with cfg's [keys] cannot be bound. An empty path
+// reads DefaultHooksPath, which may be missing.
+func LoadHooks(path string, cfg Config) ([]Hook, error) {
+	optional := path == ""
+	if optional {
+		var err error
+		if path, err = DefaultHooksPath(); err != nil {
+			return nil, nil
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// LoadConfig has checked the bindings
+	keys, _ := newKeymap(cfg.Keys)
+	return loadStarlarkHooks(path, keys)
+}
+
+// checkHookKey rejects keys a hook must not take: those no binding can
+// (see checkBindKey) and the keys of quit, which must always work.
+func checkHookKey(key string, keys keymap) error {
+	if err := checkBindKey(key); err != nil {
+		return err
+	}
+	if keys.action(key) == actQuit {
+		return fmt.Errorf("cannot bind %s: it quits", key)
+	}
+	return nil
+}
+
+// runHook runs the hook bound to key, if any. Keys typed by a running
+// hook skip hooks, so a hook on i can still set the in-point.
+func (m Model) runHook(key string) (Model, tea.Cmd, bool) {
+	h, ok := m.hooks[key]
+	if !ok || m.inScript {
+		return m, nil, false
+	}
+	m.repeatCount = 0
+	m, cmd := m.runStarlark("hook "+key, h.fn)
+	return m, cmd, true
+}
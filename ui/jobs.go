@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/video"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportJob is one running export. Batches run up to exportWorkers of
+// them at once.
+type exportJob struct {
+	id           int
+	opts         video.ExportOptions
+	progress     float64
+	progressChan <-chan float64
+	cancel       context.CancelFunc
+	started      time.Time
+	predicted    time.Duration // from past exports like it, 0 without any
+	command      string        // the ffmpeg command, for the export modal
+}
+
+// pass splits a job's progress into the current ffmpeg pass: stabilized
+// exports report the analysis pass as the first half.
+func (j exportJob) pass() (progress float64, label string) {
+	if !j.opts.Stabilize {
+		return j.progress, ""
+	}
+	if j.progress < 0.5 {
+		return j.progress * 2, "Pass 1/2: analyzing motion"
+	}
+	return (j.progress - 0.5) * 2, "Pass 2/2: stabilizing and encoding"
+}
+
+// remaining is the time the job should still take, e.g. "~1m20s left",
+// or "" until there is something to go by.
+func (j exportJob) remaining() string {
+	left, ok := video.Remaining(time.Since(j.started), j.progress, j.predicted)
+	if !ok {
+		return ""
+	}
+	return "~" + formatETA(left) + " left"
+}
+
+// formatETA rounds d to whole seconds, or minutes past an hour: "45s",
+// "3m05s", "1h20m".
+func formatETA(d time.Duration) string {
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%ds", int(d.Round(time.Second).Seconds()))
+}
+
+// DefaultThreads is the per-export thread cap for workers parallel
+// exports: an even share of the CPUs, so one re-encode cannot take them
+// all while stream copies finish next to it. One worker gets ffmpeg's own
+// default.
+func DefaultThreads(workers int) int {
+	if workers <= 1 {
+		return 0
+	}
+	return max(1, runtime.NumCPU()/workers)
+}
+
+// startExport runs one export in the background, its time predicted from
+// stats (see video.LoadStats), which a batch reads once for all its jobs.
+func (m *Model) startExport(opts video.ExportOptions, stats video.ExportStats) tea.Cmd {
+	if opts.Threads == 0 {
+		opts.Threads = m.exportThreads
+	}
+	m.nextJobID++
+	progressChan := make(chan float64, 100)
+	ctx, cancel := context.WithCancel(m.ctx)
+	predicted, _ := stats.PredictExport(opts, opts.OutputPath())
+	m.exportJobs = append(m.exportJobs, exportJob{
+		id:           m.nextJobID,
+		opts:         opts,
+		progressChan: progressChan,
+		cancel:       cancel,
+		started:      time.Now(),
+		predicted:    predicted,
+		command:      video.BuildFFmpegCommand(opts),
+	})
+	m.exporting = true
+	return startExportWithChan(ctx, m.nextJobID, opts, progressChan)
+}
+
+// startNextQueued starts queued exports until exportWorkers are running.
+func (m *Model) startNextQueued() tea.Cmd {
+	var cmds []tea.Cmd
+	for len(m.exportQueue) > 0 && len(m.exportJobs) < max(1, m.exportWorkers) {
+		opts := m.exportQueue[0]
+		m.exportQueue = m.exportQueue[1:]
+		cmds = append(cmds, m.startExport(opts, m.queueStats))
+	}
+	if len(m.exportJobs) == 0 {
+		m.queueTotal = 0
+	}
+	return tea.Batch(cmds...)
+}
+
+// job returns the running export with id, or nil once it has finished.
+func (m *Model) job(id int) *exportJob {
+	for i := range m.exportJobs {
+		if m.exportJobs[i].id == id {
+			return &m.exportJobs[i]
+		}
+	}
+	return nil
+}
+
+// finishJob removes a finished export from the running ones.
+func (m *Model) finishJob(id int) (exportJob, bool) {
+	for i, j := range m.exportJobs {
+		if j.id == id {
+			j.cancel()
+			m.exportJobs = append(m.exportJobs[:i:i], m.exportJobs[i+1:]...)
+			m.exporting = len(m.exportJobs) > 0
+			return j, true
+		}
+	}
+	return exportJob{}, false
+}
+
+// cancelExports kills every running export.
+func (m *Model) cancelExports() {
+	for _, j := range m.exportJobs {
+		j.cancel()
+	}
+}
+
+// jobStatus is the compact progress shown in the footer while exports run
+// with the export modal closed.
+func (m Model) jobStatus() string {
+	if !m.exporting || m.showExportModal {
+		return ""
+	}
+	var running float64
+	for _, j := range m.exportJobs {
+		running += j.progress
+	}
+	label, progress := "Exporting", running
+	if m.queueTotal > 0 {
+		done := m.queueTotal - len(m.exportQueue) - len(m.exportJobs)
+		label = fmt.Sprintf("Exporting %d/%d", done+len(m.exportJobs), m.queueTotal)
+		progress = (float64(done) + running) / float64(m.queueTotal)
+	}
+	const width = 10
+	filled := max(0, min(width, int(progress*width)))
+	status := fmt.Sprintf("%s [%s%s] %.0f%%", label,
+		strings.Repeat("=", filled), strings.Repeat("-", width-filled), progress*100)
+	if len(m.exportJobs) == 1 && len(m.exportQueue) == 0 {
+		if left := m.exportJobs[0].remaining(); left != "" {
+			status += "  " + left
+		}
+	}
+	return status + "  Enter details"
+}
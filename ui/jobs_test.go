@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/video"
+)
+
+// batchModel is a model in the middle of a segment batch of total
+// exports, with the given jobs running and the clipboard on.
+func batchModel(total int, jobs ...int) Model {
+	m := Model{exportClipboard: true, exporting: true, showExportModal: true, queueTotal: total}
+	for _, id := range jobs {
+		m.exportJobs = append(m.exportJobs, exportJob{
+			id:     id,
+			opts:   video.ExportOptions{InPoint: time.Duration(id) * time.Second, OutPoint: time.Duration(id+1) * time.Second},
+			cancel: func() {},
+		})
+	}
+	return m
+}
+
+func done(t *testing.T, m Model, msg ExportDoneMsg) Model {
+	t.Helper()
+	next, cmd := m.update(msg)
+	if cmd != nil {
+		t.Errorf("job %d returned a command: the clipboard or a manifest", msg.Job)
+	}
+	return next.(Model)
+}
+
+func TestBatchFailureKeepsBatch(t *testing.T) {
+	m := batchModel(3, 1, 2, 3)
+	m = done(t, m, ExportDoneMsg{Job: 1, Output: "a.mp4"})
+	m = done(t, m, ExportDoneMsg{Job: 2, Err: errors.New("disk full")})
+	if m.queueTotal != 3 || m.queueFailed != 1 {
+		t.Fatalf("after the failure: queueTotal %d, queueFailed %d", m.queueTotal, m.queueFailed)
+	}
+	if !strings.Contains(m.exportStatus, "disk full") {
+		t.Errorf("status %q does not give the error", m.exportStatus)
+	}
+
+	m = done(t, m, ExportDoneMsg{Job: 3, Output: "c.mp4"})
+	if want := "Exported 2 of 3 segments, 1 failed: disk full"; m.exportStatus != want {
+		t.Errorf("status %q, want %q", m.exportStatus, want)
+	}
+	if m.queueTotal != 0 || m.queueFailed != 0 || m.showExportModal {
+		t.Errorf("batch not wrapped up: queueTotal %d, queueFailed %d, modal %v", m.queueTotal, m.queueFailed, m.showExportModal)
+	}
+	if len(m.lastBatch) != 2 {
+		t.Errorf("lastBatch %v, want both exports", m.lastBatch)
+	}
+}
+
+func TestBatchFailureDropsQueue(t *testing.T) {
+	m := batchModel(4, 1, 2)
+	m.exportQueue = []video.ExportOptions{{}, {}}
+	m = done(t, m, ExportDoneMsg{Job: 1, Err: errors.New("disk full")})
+	if len(m.exportQueue) != 0 || m.queueTotal != 2 {
+		t.Fatalf("queue %d, queueTotal %d; want the queued two dropped", len(m.exportQueue), m.queueTotal)
+	}
+	m = done(t, m, ExportDoneMsg{Job: 2, Output: "b.mp4"})
+	if want := "Exported 1 of 2 segments, 1 failed: disk full (2 queued exports dropped)"; m.exportStatus != want {
+		t.Errorf("status %q, want %q", m.exportStatus, want)
+	}
+}
+
+func TestBatchAllFailed(t *testing.T) {
+	m := batchModel(2, 1, 2)
+	m = done(t, m, ExportDoneMsg{Job: 1, Err: errors.New("no space")})
+	m = done(t, m, ExportDoneMsg{Job: 2, Err: errors.New("disk full")})
+	if want := "Exported 0 of 2 segments, 2 failed: disk full"; m.exportStatus != want {
+		t.Errorf("status %q, want %q", m.exportStatus, want)
+	}
+}
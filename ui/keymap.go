@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// action is something a key does in the main view. Its name is how the
+// [keys] table of the config file refers to it.
+type action string
+
+const (
+	actNone            action = ""
+	actPlayPause       action = "play_pause"
+	actSeekBack        action = "seek_back"
+	actSeekForward     action = "seek_forward"
+	actSeekBackLong    action = "seek_back_long"
+	actSeekForwardLong action = "seek_forward_long"
+	actFrameBack       action = "frame_back"
+	actFrameForward    action = "frame_forward"
+	actPlayFrames      action = "play_frames"
+	actReplay          action = "replay"
+	actGoStart         action = "go_start"
+	actGoEnd           action = "go_end"
+	actPrevMark        action = "prev_mark"
+	actNextMark        action = "next_mark"
+	actMute            action = "mute"
+	actQuality         action = "quality"
+	actSetIn           action = "set_in"
+	actSetOut          action = "set_out"
+	actTrimForm        action = "trim_form"
+	actPreview         action = "preview"
+	actExportPlay      action = "export_play"
+	actPreviewEnd      action = "preview_end"
+	actFindLoop        action = "find_loop"
+	actBeatSnap        action = "beat_snap"
+	actKeyframeSnap    action = "keyframe_snap"
+	actTrimBlack       action = "trim_black"
+	actTrimSilence     action = "trim_silence"
+	actCrop            action = "crop"
+	actClear           action = "clear"
+	actAddSegment      action = "add_segment"
+	actSegments        action = "segments"
+	actDuplicate       action = "duplicate"
+	actExportSegments  action = "export_segments"
+	actRename          action = "rename"
+	actExport          action = "export"
+	actTranscript      action = "transcript"
+	actTranscribe      action = "transcribe"
+	actUndo            action = "undo"
+	actNote            action = "note"
+	actPoster          action = "poster"
+	actSnapshot        action = "snapshot"
+	actSnapshots       action = "snapshots"
+	actCopyExport      action = "copy_export"
+	actFullPath        action = "full_path"
+	actHelp            action = "help"
+	actQuit            action = "quit"
+)
+
+// defaultKeys are the built-in bindings of every action.
+var defaultKeys = map[action][]string{
+	actPlayPause:       {" "},
+	actSeekBack:        {"h"},
+	actSeekForward:     {"l"},
+	actSeekBackLong:    {"H"},
+	actSeekForwardLong: {"L"},
+	actFrameBack:       {","},
+	actFrameForward:    {"."},
+	actPlayFrames:      {";"},
+	actReplay:          {"r"},
+	actGoStart:         {"0"},
+	actGoEnd:           {"G", "$"},
+	actPrevMark:        {"["},
+	actNextMark:        {"]"},
+	actMute:            {"m"},
+	actQuality:         {"tab"},
+	actSetIn:           {"i"},
+	actSetOut:          {"o"},
+	actTrimForm:        {"e"},
+	actPreview:         {"p"},
+	actExportPlay:      {"v"},
+	actPreviewEnd:      {"P"},
+	actFindLoop:        {"O"},
+	actBeatSnap:        {"b"},
+	actKeyframeSnap:    {"K"},
+	actTrimBlack:       {"B"},
+	actTrimSilence:     {"Z"},
+	actCrop:            {"C"},
+	actClear:           {"d", "esc"},
+	actAddSegment:      {"a"},
+	actSegments:        {"S"},
+	actDuplicate:       {"D"},
+	actExportSegments:  {"E"},
+	actRename:          {"R"},
+	actExport:          {"enter"},
+	actTranscript:      {"t"},
+	actTranscribe:      {"T"},
+	actUndo:            {"u"},
+	actNote:            {"N"},
+	actPoster:          {"F"},
+	actSnapshot:        {"s"},
+	actSnapshots:       {"g"},
+	actCopyExport:      {"y"},
+	actFullPath:        {"f"},
+	actHelp:            {"?"},
+	actQuit:            {"q"},
+}
+
+// keymap looks up the action of a key in the main view.
+type keymap struct {
+	actions map[string]action
+	keys    map[action][]string
+}
+
+// newKeymap is defaultKeys with overrides, each replacing all the keys of
+// its action. A key taken by an override is removed from the action it
+// had by default; an action left without any key is an error.
+func newKeymap(overrides map[string][]string) (keymap, error) {
+	k := keymap{actions: map[string]action{}, keys: map[action][]string{}}
+	for a, keys := range defaultKeys {
+		k.keys[a] = slices.Clone(keys)
+	}
+	taken := map[string]action{}
+	for name, keys := range overrides {
+		a := action(name)
+		if _, ok := defaultKeys[a]; !ok {
+			return keymap{}, fmt.Errorf("unknown action %q", name)
+		}
+		if len(keys) == 0 {
+			return keymap{}, fmt.Errorf("%s: no keys", name)
+		}
+		for _, key := range keys {
+			if err := checkBindKey(key); err != nil {
+				return keymap{}, fmt.Errorf("%s: %w", name, err)
+			}
+			if other, ok := taken[key]; ok && other != a {
+				return keymap{}, fmt.Errorf("%s is bound to both %s and %s", keyName(key), other, a)
+			}
+			taken[key] = a
+		}
+		k.keys[a] = keys
+	}
+	for a, keys := range k.keys {
+		if _, ok := overrides[string(a)]; ok {
+			continue
+		}
+		k.keys[a] = slices.DeleteFunc(keys, func(key string) bool {
+			_, ok := taken[key]
+			return ok
+		})
+		if len(k.keys[a]) == 0 {
+			return keymap{}, fmt.Errorf("%s has no key left (its %s is bound to %s); give it one",
+				a, keyName(defaultKeys[a][0]), taken[defaultKeys[a][0]])
+		}
+	}
+	for a, keys := range k.keys {
+		for _, key := range keys {
+			k.actions[key] = a
+		}
+	}
+	return k, nil
+}
+
+// checkBindKey rejects keys that cannot be rebound: digits are repeat
+// counts and ctrl+c always quits.
+func checkBindKey(key string) error {
+	switch {
+	case countKey(key):
+		return fmt.Errorf("cannot bind %s: digits are repeat counts", key)
+	case key == "ctrl+c":
+		return fmt.Errorf("cannot bind ctrl+c: it always quits")
+	}
+	return nil
+}
+
+// countKey reports whether key starts a repeat count.
+func countKey(key string) bool {
+	return len(key) == 1 && key[0] >= '1' && key[0] <= '9'
+}
+
+// parseKeys reads the keys of a [keys] entry: key names as the help screen
+// shows them, separated by spaces, e.g. "j left". "space" is the space bar.
+func parseKeys(s string) []string {
+	var keys []string
+	for _, key := range strings.Fields(s) {
+		switch strings.ToLower(key) {
+		case "space":
+			key = " "
+		case "enter", "esc", "tab":
+			key = strings.ToLower(key)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// action returns what key does, actNone for an unbound key.
+func (k keymap) action(key string) action {
+	if key == "ctrl+c" {
+		return actQuit
+	}
+	return k.actions[key]
+}
+
+// key is the first key of a, which scripts press to run it.
+func (k keymap) key(a action) string {
+	return k.keys[a][0]
+}
+
+// label names the keys of the actions for the help screen: all the keys of
+// one action, or the first key of each of several, e.g. "h / l".
+func (k keymap) label(actions ...action) string {
+	keys := k.keys[actions[0]]
+	if len(actions) > 1 {
+		keys = nil
+		for _, a := range actions {
+			keys = append(keys, k.key(a))
+		}
+	}
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = keyName(key)
+	}
+	return strings.Join(names, " / ")
+}
+
+// keyName is how the help screen writes a key.
+func keyName(key string) string {
+	switch key {
+	case " ":
+		return "Space"
+	case "enter", "esc", "tab":
+		return strings.ToUpper(key[:1]) + key[1:]
+	}
+	return key
+}
@@ -0,0 +1,106 @@
+package ui_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"lazycut/ui"
+	"lazycut/ui/uitest"
+)
+
+// openKeys opens the fake video with keys as the config's [keys] table.
+func openKeys(t *testing.T, keys string) *uitest.Terminal {
+	t.Helper()
+	cfg, err := loadConfig(t, "[keys]\n"+keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	term, _ := openFake(t, ui.Options{Config: cfg}, 120, 40)
+	return term
+}
+
+var inPointLine = regexp.MustCompile(`│ In +([0-9:.]+)`)
+
+// inPointAfter types keys and then i, returning where the view says the
+// in-point went.
+func inPointAfter(t *testing.T, term *uitest.Terminal, keys ...string) string {
+	t.Helper()
+	term.Press(append(keys, "i")...)
+	if at := inPointLine.FindStringSubmatch(term.View()); at != nil {
+		return at[1]
+	}
+	t.Fatalf("no in-point on screen:\n%s", term.View())
+	return ""
+}
+
+func TestKeymapRemapSeek(t *testing.T) {
+	for _, tc := range []struct {
+		name, keys string
+		typed      []string
+		want       string
+	}{
+		{"defaults", "", []string{"l", "l", "l", "h"}, "00:02"},
+		{"swapped", "seek_back = \"l\"\nseek_forward = \"h\"\n", []string{"h", "h", "h", "l"}, "00:02"},
+		// h and l no longer seek once j/k and the arrows took over
+		{"moved", "seek_back = \"j left\"\nseek_forward = \"k right\"\n", []string{"k", "right", "l", "l", "left"}, "00:01"},
+		{"count", "seek_forward = \"k\"\n", []string{"3", "k"}, "00:03"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			term := openKeys(t, tc.keys)
+			if got := inPointAfter(t, term, tc.typed...); got != tc.want {
+				t.Errorf("in-point %s after %q, want %s", got, tc.typed, tc.want)
+			}
+		})
+	}
+}
+
+func TestKeymapTakenKey(t *testing.T) {
+	// G and $ both go to the end; giving $ to replay leaves G
+	term := openKeys(t, "set_in = \"I\"\nreplay = \"$\"\n")
+	term.Press("G", "I")
+	if !strings.Contains(term.View(), "00:10") {
+		t.Errorf("G did not go to the end:\n%s", term.View())
+	}
+	term.Press("?")
+	view := term.View()
+	for _, want := range []string{"G        Go to end", "$        Replay", "I        Set in"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("help lacks %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestKeymapHelpLabels(t *testing.T) {
+	term := openKeys(t, "seek_back = \"j left\"\nseek_forward = \"k right\"\nplay_pause = \"space p\"\npreview = \"V\"\n")
+	term.Press("?")
+	view := term.View()
+	for _, want := range []string{"j / k", "Space / p", "V        "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("help lacks %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestKeymapErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, keys, want string
+	}{
+		{"unknown action", "jump = \"j\"\n", `unknown action "jump"`},
+		{"no keys", "seek_back = \"\"\n", "seek_back: no keys"},
+		{"digit", "seek_back = \"5\"\n", "seek_back: cannot bind 5: digits are repeat counts"},
+		{"ctrl+c", "help = \"ctrl+c\"\n", "help: cannot bind ctrl+c"},
+		{"one key, two actions", "seek_back = \"j\"\nseek_forward = \"j\"\n", "j is bound to both"},
+		{"action left without keys", "mute = \"h\"\n", "seek_back has no key left (its h is bound to mute)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadConfig(t, "[keys]\n"+tc.keys)
+			if err == nil {
+				t.Fatalf("%q loaded", tc.keys)
+			}
+			if !strings.Contains(err.Error(), "[keys]") || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q, want [keys] and %q", err, tc.want)
+			}
+		})
+	}
+}
@@ -6,12 +6,12 @@ const (
 	minPanelHeight = 5
 	// Border (2) + padding (2 left + 2 right) = 6 horizontal overhead per panel
 	horizontalOverhead = 6
-    // Border (2) = 2 vertical overhead per panel (no title line)
-    verticalOverhead = 2
-    // Timeline fixed height (includes border)
-    // Content: time line + marker line + progress bar + cursor line + help = 5 lines
-    // Plus vertical overhead (2) = 7
-    timelineFixedHeight = 7
+	// Border (2) = 2 vertical overhead per panel (no title line)
+	verticalOverhead = 2
+	// Timeline fixed height (includes border)
+	// Content: time line + waveform + waveform lane + filmstrip (3) + marker line + progress bar + cursor line + help = 10 lines
+	// Plus vertical overhead (2) = 12
+	timelineFixedHeight = 12
 	// Properties panel fixed width
 	propertiesFixedWidth = 30
 )
@@ -27,12 +27,12 @@ type PanelDimensions struct {
 	TimelineWidth    int
 	TimelineHeight   int
 	// Content dimensions (what gets passed to panel Render)
-	PreviewContentWidth    int
-	PreviewContentHeight   int
+	PreviewContentWidth     int
+	PreviewContentHeight    int
 	PropertiesContentWidth  int
 	PropertiesContentHeight int
-	TimelineContentWidth   int
-	TimelineContentHeight  int
+	TimelineContentWidth    int
+	TimelineContentHeight   int
 }
 
 // CalculatePanelDimensions calculates panel dimensions based on terminal size
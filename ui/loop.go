@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/video"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loopWindow is how far either side of the in/out points the loop assist
+// looks for matching frames.
+const loopWindow = time.Second
+
+// LoopFoundMsg delivers the result of a background loop search.
+type LoopFoundMsg struct {
+	Match video.LoopMatch
+	Err   error
+}
+
+func findLoop(ctx context.Context, path string, in, out time.Duration, fps float64, minLen time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		match, err := video.FindLoop(ctx, path, in, out, fps, loopWindow, minLen)
+		return LoopFoundMsg{Match: match, Err: err}
+	}
+}
+
+// startLoopSearch looks for the most seamless start/end frame pair near
+// the current selection.
+func (m *Model) startLoopSearch() tea.Cmd {
+	if !m.player.Trim.IsComplete() {
+		m.exportStatus = "Loop assist needs in and out points"
+		return nil
+	}
+	if m.findingLoop {
+		return nil
+	}
+	m.findingLoop = true
+	m.exportStatus = "Searching for a seamless loop..."
+	in, out := *m.player.Trim.InPoint, *m.player.Trim.OutPoint
+	// Keep the loop at least half the chosen length
+	minLen := max((out-in)/2, m.player.MinSelection())
+	return findLoop(m.ctx, m.player.Path(), in, out, m.player.Properties().FPS, minLen)
+}
+
+// applyLoop selects a found loop; exports of exactly this selection are
+// then cut on its frames instead of on keyframes.
+func (m *Model) applyLoop(match video.LoopMatch) {
+	start, end := match.Start, match.End
+	if !m.acceptTrim(video.TrimState{InPoint: &start, OutPoint: &end}) {
+		return
+	}
+	m.loopMatch = &match
+	m.player.Seek(start)
+	m.exportStatus = fmt.Sprintf("Loop %s - %s (frame difference %.1f%%)",
+		video.FormatTimecode(start), video.FormatTimecode(end), match.Diff/255*100)
+}
+
+// isLoopSelection reports whether the selection is still the loop found
+// by the assist.
+func (m Model) isLoopSelection() bool {
+	t := m.player.Trim
+	return m.loopMatch != nil && t.IsComplete() &&
+		*t.InPoint == m.loopMatch.Start && *t.OutPoint == m.loopMatch.End
+}
@@ -0,0 +1,57 @@
+package ui_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lazycut/ui"
+	"lazycut/ui/uitest"
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+func TestMain(m *testing.M) {
+	videotest.RunHelper()
+	os.Exit(m.Run())
+}
+
+// probeJSON is ffprobe's answer for a 10 s 1920x1080 30 fps video with
+// AAC audio.
+const probeJSON = `{
+	"streams": [
+		{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30/1"},
+		{"codec_type": "audio", "codec_name": "aac"}
+	],
+	"format": {"duration": "10.000000", "size": "1000000", "bit_rate": "800000"}
+}`
+
+// openFake starts the UI with opts on a 10 s video that only the fake
+// ffprobe knows, with ffmpeg, ffplay and chafa faked too, and the config
+// and state directories in a temp dir.
+func openFake(t *testing.T, opts ui.Options, width, height int) (*uitest.Terminal, *videotest.FakeRunner) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+
+	fake := videotest.NewFakeRunner(t.TempDir())
+	fake.Respond("ffprobe", videotest.Response{Stdout: []byte(probeJSON)})
+	t.Cleanup(video.SetRunner(fake))
+
+	input := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(input, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	player, err := video.NewPlayer(ctx, input, video.DefaultPlayerPrefs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(player.Close)
+	return uitest.New(ui.NewModel(ctx, player, opts), width, height), fake
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ManifestFormats are the accepted --manifest values.
+var ManifestFormats = []string{"json", "csv"}
+
+// manifestEntry is one output listed in a batch manifest.
+type manifestEntry struct {
+	File     string  `json:"file"`
+	In       float64 `json:"in"`
+	Out      float64 `json:"out"`
+	Duration float64 `json:"duration"` // of the output, after speed and freezes
+	SHA256   string  `json:"sha256"`
+}
+
+// ManifestWrittenMsg reports the manifest written after a segment batch.
+type ManifestWrittenMsg struct {
+	Path string
+	Err  error
+}
+
+// writeManifest hashes the batch outputs and writes the manifest next to
+// the first one, in the background because hashing reads every file.
+func writeManifest(format, input string, results []ExportResult) tea.Cmd {
+	return func() tea.Msg {
+		path, err := saveManifest(format, input, results)
+		return ManifestWrittenMsg{Path: path, Err: err}
+	}
+}
+
+func saveManifest(format, input string, results []ExportResult) (string, error) {
+	if len(results) == 0 {
+		return "", fmt.Errorf("no exports to list")
+	}
+	entries := make([]manifestEntry, len(results))
+	for i, r := range results {
+		sum, err := fileSHA256(r.Output)
+		if err != nil {
+			return "", err
+		}
+		entries[i] = manifestEntry{
+			File:     filepath.Base(r.Output),
+			In:       r.In.Seconds(),
+			Out:      r.Out.Seconds(),
+			Duration: r.Duration.Seconds(),
+			SHA256:   sum,
+		}
+	}
+
+	base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	path := freeName(filepath.Join(filepath.Dir(results[0].Output), base+"_manifest"), "."+format)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if format == "csv" {
+		err = writeManifestCSV(f, entries)
+	} else {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(entries)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return path, err
+}
+
+func writeManifestCSV(w io.Writer, entries []manifestEntry) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"file", "in", "out", "duration", "sha256"})
+	secs := func(s float64) string { return strconv.FormatFloat(s, 'f', 3, 64) }
+	for _, e := range entries {
+		cw.Write([]string{e.File, secs(e.In), secs(e.Out), secs(e.Duration), e.SHA256})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// freeName returns stem+ext, or stem_N+ext for the first N not taken.
+func freeName(stem, ext string) string {
+	path := stem + ext
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = fmt.Sprintf("%s_%d%s", stem, i, ext)
+	}
+}
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"lazycut/video"
+	"slices"
+	"time"
+)
+
+// setMarks keeps the marks that fall inside the file, in order, and
+// shows them on the timeline, saying how many were left out.
+func (m *Model) setMarks(marks []video.Mark) {
+	dur := m.player.Duration()
+	m.marks = nil
+	for _, mark := range marks {
+		if mark.At <= dur {
+			m.marks = append(m.marks, mark)
+		}
+	}
+	// Marks from several files come one file after another
+	slices.SortStableFunc(m.marks, func(a, b video.Mark) int {
+		return cmp.Compare(a.At, b.At)
+	})
+	m.timeline.SetMarks(m.marks)
+	if len(marks) == 0 {
+		return
+	}
+	m.exportStatus = fmt.Sprintf("%d marks ([ and ] to jump)", len(m.marks))
+	if dropped := len(marks) - len(m.marks); dropped > 0 {
+		m.exportStatus += fmt.Sprintf(", %d past the end left out", dropped)
+	}
+}
+
+// jumpMark seeks to the next mark after the playhead (dir 1) or the one
+// before it (dir -1). Anything within half a frame counts as on the
+// playhead, so repeated presses move on.
+func (m *Model) jumpMark(dir int) {
+	if len(m.marks) == 0 {
+		m.exportStatus = "No marks: load events with --events or --stdin-marks"
+		return
+	}
+	pos := m.player.Position()
+	half := time.Second / time.Duration(2*max(1, m.player.FPS()))
+	idx := -1
+	if dir > 0 {
+		for i, mark := range m.marks {
+			if mark.At > pos+half {
+				idx = i
+				break
+			}
+		}
+	} else {
+		for i := len(m.marks) - 1; i >= 0; i-- {
+			if m.marks[i].At < pos-half {
+				idx = i
+				break
+			}
+		}
+	}
+	if idx < 0 {
+		m.exportStatus = "No more marks"
+		return
+	}
+	mark := m.marks[idx]
+	m.player.Seek(mark.At)
+	m.exportStatus = fmt.Sprintf("Mark %d/%d at %s", idx+1, len(m.marks), video.FormatTimecode(mark.At))
+	if mark.Label != "" {
+		m.exportStatus += ": " + mark.Label
+	}
+}
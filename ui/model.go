@@ -1,9 +1,19 @@
 package ui
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"lazycut/ui/panels"
+	"lazycut/ui/theme"
 	"lazycut/video"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,16 +26,30 @@ const (
 	PanelTimeline
 )
 
+// TickMsg is a slow heartbeat. Redraws are driven by the messages that
+// change the screen: keys, FrameReadyMsg from the player, export progress.
 type TickMsg time.Time
 
+const tickInterval = time.Second
+
+// FrameReadyMsg reports that the player has a new frame or has changed
+// playback state on its own.
+type FrameReadyMsg struct{}
+
 type ExportDoneMsg struct {
-	Output string
-	Err    error
+	Job      int
+	Output   string
+	Fallback string // encoder or smart cut substitution, see video.ExportWithProgress
+	Err      error
 }
 
-type ExportProgressMsg float64
+type ExportProgressMsg struct {
+	Job      int
+	Progress float64
+}
 
 type Model struct {
+	ctx          context.Context
 	width        int
 	height       int
 	player       *video.Player
@@ -36,34 +60,753 @@ type Model struct {
 	previewMode  bool
 	exportStatus string
 
-	showExportModal    bool
-	exportFilename     string
-	exportAspectRatio  int // index into video.AspectRatioOptions
-	exportFocusField   int // 0: filename, 1: aspect ratio
-	exporting          bool
-	exportProgress     float64
-	exportProgressChan <-chan float64
+	showExportModal   bool
+	exportPlan        *exportPlan // what the modal shows of its options, see planExport
+	exportFilename    string
+	exportAspectRatio int // index into video.AspectRatioOptions
+	exportFocusField  int // one of the exportField* constants
+	exportFreezeIn    int // index into freezeSteps
+	exportFreezeOut   int
+	exportSpeed       int    // index into speedSteps
+	exportFastStart   bool   // -movflags +faststart for MP4/MOV outputs
+	exportSmartCut    bool   // re-encode only the ends of copied cuts
+	exportContainer   int    // index into video.ContainerOptions
+	exportCRF         int    // index into crfSteps, 0 is the encoder's default
+	exportBitrate     int    // index into bitrateSteps, 0 sets none
+	exportAnimation   int    // index into animationSteps
+	exportCutList     int    // index into cutListLabels, 0 renders video
+	exportStabilize   bool   // two-pass vid.stab
+	exportLowPriority bool   // nice/ionice the export ffmpeg
+	exportClipboard   bool   // put the finished export on the clipboard
+	clipboardTool     string // see video.ClipboardTool, "" without one
+	musicPath         string // --music track, "" without one
+	exportMusicLevel  int    // music volume in tenths, 0 leaves it out
+	exportDuck        int    // ducking in tenths, 0 mixes at a fixed level
+	titleText         string // typed title card text, "" uses the note
+	exportTitleCard   int    // index into titleCardSteps, 0 is off
+	canTitleCard      bool   // ffmpeg has drawtext
+	reelCrossfade     int    // index into crossfadeSteps
+	exportEncoder     string // H.264 encoder for re-encodes, "" for ffmpeg's default
+	exportFallback    string // encoder substitution during the current batch
+	canStabilize      bool
+	exportCropOffset  float64 // see video.ExportOptions.CropOffset
+	reframing         bool
+	cropAdjust        bool // crop-adjust mode: preview shows the export crop
+	cropPreview       string
+	cropPreviewErr    string // why cropPreview could not be rendered
+	cropPreviewGen    int
+	aspectChosen      bool // crop set up in crop-adjust mode; keep it for exports
+	exporting         bool
+	exportJobs        []exportJob // running exports
+	nextJobID         int
+	exportWorkers     int                   // queued exports run at once
+	exportThreads     int                   // -threads per export, 0 for ffmpeg's default
+	exportQueue       []video.ExportOptions // exports waiting to run
+	queueTotal        int                   // size of the current batch, 0 for single exports
+	queueStart        int                   // len(exports) when the batch started
+	queueFailed       int                   // exports of the batch that failed
+	queueErr          string                // why the last of them failed
+	queueStats        video.ExportStats     // recorded export times, read once per batch
+	canCrop           bool                  // ffmpeg has an H.264 encoder for cropped exports
+	canBlurFill       bool                  // ...and the filters for blurred-background fills
+	exportPreview     string                // composition preview of the frame under the playhead
+	exportPreviewErr  string                // why it could not be rendered
+	exportPreviewBusy bool
+
+	accessible bool
+	replayStep time.Duration
+	previewEnd PreviewEnd
+
+	keys         keymap
+	seekStep     time.Duration // h/l
+	longSeekStep time.Duration // H/L
+	outputDir    string        // where exports go, "" beside the input
+	safeNames    bool          // see video.ExportOptions.SafeNames
+	trash        bool          // see video.ExportOptions.TrashReplaced
+	configAspect string        // aspect label new exports start on, "" by orientation
+	intro        video.Bumper  // joined before exports, Path "" without one
+	outro        video.Bumper  // joined after them
+	joinBumpers  bool          // export modal: add the intro and outro
+
+	showHelpModal bool
+	undoStack     []trimSnapshot
+
+	showTrimForm bool
+	trimForm     trimForm
+
+	transcript     []video.Cue
+	words          []video.Cue // word-level cues from whisper, if run
+	showTranscript bool
+	transcriptView transcriptView
+	transcribing   bool
+	whisperModel   string
+
+	findingLoop bool
+	loopMatch   *video.LoopMatch // last loop found by the assist
+
+	// Vim-style input
+	repeatCount int
+
+	// Session autosave and quit protection
+	autosaveInterval time.Duration
+	savedSession     session
+	exportedTrim     video.TrimState // selection of the last successful export
+	showQuitConfirm  bool
+
+	segments        []Segment
+	showSegments    bool
+	segmentCursor   int
+	quitAfterExport bool // quit as soon as the running export succeeds
+	exports         []ExportResult
+
+	peaks *video.Peaks // audio envelope, nil until loaded or without audio
+
+	filmstripCount  int // thumbnails in the filmstrip being shown or rendered
+	filmstripCancel context.CancelFunc
+
+	showFullPath bool // panel title shows the full path, not the basename
+
+	exportPlaying        bool // the preview panel plays the export result
+	exportPlayFrame      video.PreviewFrame
+	exportPlayLength     time.Duration
+	exportPlayStabilized bool
+	exportPlayCancel     context.CancelFunc
+	exportPlayFrames     chan video.PreviewFrame
+	warmed               warmKey // trim points whose frames are cached
+	exportPlayGen        int
+
+	beatSnap     bool // trim points snap to the nearest beat
+	beats        []time.Duration
+	findingBeats bool
+
+	bitrate *video.Bitrate // packet sizes and keyframes, nil until probed
+
+	startupCommands []Command // --cmd script, run from Init
+	hooks           map[string]Hook
+
+	version string            // lazycut's own, for the help modal
+	system  *video.SystemInfo // helper versions, nil until probed
+
+	blackRanges  []video.Interval
+	blackScanned bool
+
+	note                string // session note, carried by new segments and exports
+	noteForm            noteForm
+	showNoteForm        bool
+	exportNoteSidecar   bool           // also write the note to a .txt beside the export
+	poster              *time.Duration // thumbnail frame for exports, F marks it
+	exportPosterSidecar bool           // write the poster as .jpg even where it could be embedded
+	exportCaptions      bool           // write the transcript of the selection as .srt
+
+	snapshots      []snapshot
+	showSnapshots  bool
+	snapshotCursor int
+
+	manifestFormat string // --manifest: "json", "csv" or ""
+	lastManifest   string // manifest of the last batch, rewritten after a rename
+
+	lastBatch      []int // indices into exports of the last segment batch
+	renameForm     renameForm
+	showRenameForm bool
+
+	silentRanges     []video.Interval
+	silenceScanned   bool
+	detectingSilence bool
+	inScript         bool // keys come from a script, not the keyboard
+	drag             mouseDrag
+	marks            []video.Mark // sorted, all inside the file
+
+}
+
+// Options configures optional Model behavior chosen at startup.
+type Options struct {
+	// Accessible renders the timeline as plain text and announces every
+	// state change in the status line.
+	Accessible bool
+
+	// Replay is how far the replay key jumps back before playing.
+	// Zero uses defaultReplay.
+	Replay time.Duration
+
+	// PreviewEnd is the initial end-of-selection behavior for previews.
+	PreviewEnd PreviewEnd
+
+	// WhisperModel is passed to whisper for background transcription.
+	WhisperModel string
+
+	// Transcribe starts whisper transcription right after startup.
+	Transcribe bool
+
+	// Autosave is how often the session (selection, crop) is saved so
+	// it can be restored on the next start. Zero disables sessions.
+	Autosave time.Duration
+
+	// Commands is the --cmd script, run once after startup.
+	Commands []Command
+
+	// Hooks bind keys to scripts; they take precedence over built-in keys.
+	Hooks []Hook
+
+	// Workers is how many queued (segment) exports run at once; 0 or 1
+	// runs them one after another.
+	Workers int
+
+	// Threads caps the ffmpeg threads of each export; 0 lets ffmpeg
+	// decide.
+	Threads int
+
+	// Encoder is the H.264 encoder of re-encoded exports; "" lets ffmpeg
+	// pick.
+	Encoder string
 
-    showHelpModal bool
-    undoStack     []trimSnapshot
+	// LowPriority starts with background priority on in the export modal.
+	LowPriority bool
 
-    // Vim-style input
-    repeatCount int
+	// Version is lazycut's own version, shown in the help modal.
+	Version string
+
+	// Marks are points of interest shown on the timeline, e.g. events
+	// read with --events or --stdin-marks.
+	Marks []video.Mark
+
+	// Music is a background track the export modal can mix under the
+	// audio of exports; "" leaves the audio mix out.
+	Music string
+
+	// Config holds the config file's defaults.
+	Config Config
+
+	// Intro and Outro are the config's intro and outro clips, probed; a
+	// Path of "" leaves that one out.
+	Intro video.Bumper
+	Outro video.Bumper
+
+	// Cuts are added to the segments at startup, after the ones of a
+	// restored session, e.g. the events of an --edl file.
+	Cuts []video.Cut
+
+	// Manifest is "json" or "csv" to list each finished segment batch
+	// (files, ranges, durations, SHA-256) in a manifest file; "" skips it.
+	Manifest string
 }
 
+const defaultReplay = 5 * time.Second
+
 type trimSnapshot struct {
 	inPoint  *time.Duration
 	outPoint *time.Duration
 }
 
-func NewModel(player *video.Player) Model {
-	return Model{
-		player:     player,
-		preview:    panels.NewPreview(player),
-		properties: panels.NewProperties(player),
-		timeline:   panels.NewTimeline(player),
-		ready:      false,
+func NewModel(ctx context.Context, player *video.Player, opts Options) Model {
+	timeline := panels.NewTimeline(player)
+	timeline.SetAccessible(opts.Accessible)
+	if opts.Replay <= 0 {
+		opts.Replay = defaultReplay
+	}
+	properties := panels.NewProperties(player)
+	properties.SetPreviewEnd(opts.PreviewEnd.String())
+	m := Model{
+		ctx:          ctx,
+		player:       player,
+		preview:      panels.NewPreview(player),
+		properties:   properties,
+		timeline:     timeline,
+		ready:        false,
+		canCrop:      video.FFmpegCapabilities().CanCrop(),
+		canBlurFill:  video.FFmpegCapabilities().CanBlurFill(),
+		canStabilize: video.FFmpegCapabilities().CanStabilize(),
+		canTitleCard: video.FFmpegCapabilities().CanTitleCard(),
+		accessible:   opts.Accessible,
+		replayStep:   opts.Replay,
+		previewEnd:   opts.PreviewEnd,
+		whisperModel: opts.WhisperModel,
+		transcribing: opts.Transcribe,
+
+		autosaveInterval: opts.Autosave,
+		exportFastStart:  true,
+		exportCaptions:   true,
+		exportSpeed:      normalSpeed,
+		exportAnimation:  defaultAnimation,
+
+		startupCommands:   opts.Commands,
+		hooks:             map[string]Hook{},
+		manifestFormat:    opts.Manifest,
+		exportWorkers:     max(1, opts.Workers),
+		exportThreads:     opts.Threads,
+		exportLowPriority: opts.LowPriority,
+		clipboardTool:     video.ClipboardTool(),
+		exportEncoder:     opts.Encoder,
+		musicPath:         opts.Music,
+		seekStep:          cmp.Or(opts.Config.SeekStep, time.Second),
+		longSeekStep:      cmp.Or(opts.Config.LongSeekStep, 5*time.Second),
+		outputDir:         opts.Config.OutputDir,
+		safeNames:         opts.Config.SafeNames,
+		trash:             opts.Config.TrashReplaced,
+		configAspect:      opts.Config.Aspect,
+		intro:             opts.Intro,
+		outro:             opts.Outro,
+		joinBumpers:       true,
+		exportMusicLevel:  3,
+		exportDuck:        6,
+		version:           opts.Version,
+	}
+	// LoadConfig has checked the bindings
+	m.keys, _ = newKeymap(opts.Config.Keys)
+	for _, h := range opts.Hooks {
+		m.hooks[h.Key] = h
+	}
+	m.setMarks(opts.Marks)
+	if opts.Autosave > 0 {
+		if s, ok := loadSession(player.Path()); ok {
+			m.restoreSession(s)
+		}
+	}
+	m.addCuts(opts.Cuts)
+	// Decode the opening frame while the terminal size is still unknown
+	player.Preload()
+	return m
+}
+
+// acceptTrim applies a new trim state unless it would form a selection
+// shorter than one frame, in which case it warns and keeps the old one.
+func (m *Model) acceptTrim(candidate video.TrimState) bool {
+	if candidate.IsComplete() {
+		if err := candidate.Validate(m.player.MinSelection()); err != nil {
+			m.exportStatus = "Selection not changed: " + err.Error()
+			return false
+		}
+	}
+	m.saveTrimState()
+	m.player.Trim = candidate
+	return true
+}
+
+// snapToKeyframe moves the trim point nearer pos, or the only one set, to
+// the nearest keyframe, where a stream copy can start exactly.
+func (m *Model) snapToKeyframe(pos time.Duration) {
+	trim := m.player.Trim
+	if trim.InPoint == nil && trim.OutPoint == nil {
+		m.exportStatus = "Set an in- or out-point to snap"
+		return
+	}
+	if m.bitrate == nil {
+		m.exportStatus = "Keyframes are still loading"
+		return
+	}
+	out := trim.InPoint == nil
+	if trim.InPoint != nil && trim.OutPoint != nil {
+		out = pos > *trim.InPoint+(*trim.OutPoint-*trim.InPoint)/2
+	}
+	point := trim.InPoint
+	if out {
+		point = trim.OutPoint
+	}
+	key, ok := m.bitrate.NearestKeyframe(*point)
+	if !ok {
+		m.exportStatus = "No keyframes found"
+		return
+	}
+	candidate := trim
+	name := "In"
+	if out {
+		candidate.SetOut(key)
+		name = "Out"
+	} else {
+		candidate.SetIn(key)
+	}
+	if !m.acceptTrim(candidate) {
+		return
+	}
+	m.exportStatus = fmt.Sprintf("%s-point snapped to keyframe at %s", name, video.FormatTimecode(key))
+}
+
+// finishPreview applies the end-of-selection behavior once a preview
+// reaches the out-point.
+func (m *Model) finishPreview() {
+	switch m.previewEnd {
+	case PreviewLoop:
+		if m.player.Trim.InPoint != nil {
+			m.player.Seek(*m.player.Trim.InPoint)
+		}
+		return
+	case PreviewContinue:
+		m.previewMode = false
+		m.announce("Preview passed out-point")
+		return
+	}
+	m.player.Pause()
+	m.previewMode = false
+	// Park on the in-point so the next preview needs no manual seek
+	if m.player.Trim.InPoint != nil {
+		m.player.Seek(*m.player.Trim.InPoint)
+	}
+	m.announce("Preview finished")
+}
+
+// pauseForStep pauses playback before a frame step so the step is taken
+// from the frame actually on screen, returning the position to step from.
+func (m *Model) pauseForStep(pos time.Duration) time.Duration {
+	if !m.player.IsPlaying() {
+		return pos
+	}
+	m.player.Pause()
+	m.previewMode = false
+	return m.player.Position()
+}
+
+// announce repeats a state change in the status line when accessibility
+// mode is on, so it is read out instead of only being shown as a glyph.
+func (m *Model) announce(format string, args ...any) {
+	if m.accessible {
+		m.exportStatus = fmt.Sprintf(format, args...)
+	}
+}
+
+// cycleAspect moves the export aspect selection by delta, wrapping around.
+// Without an H.264 encoder only Original (stream copy) is selectable.
+func (m *Model) cycleAspect(delta int) {
+	m.exportAspectRatio = m.nextAspect(m.exportAspectRatio, delta)
+	m.exportPreview, m.exportPreviewErr = "", ""
+}
+
+// nextAspect steps an aspect index by delta, skipping options this ffmpeg
+// cannot produce.
+func (m Model) nextAspect(idx, delta int) int {
+	if !m.canCrop {
+		return 0
+	}
+	n := len(video.AspectRatioOptions)
+	idx = ((idx+delta)%n + n) % n
+	if video.AspectRatioOptions[idx].Fill && !m.canBlurFill {
+		idx = ((idx+delta)%n + n) % n
+	}
+	return idx
+}
+
+// Export modal fields, in focus order.
+const (
+	exportFieldFilename = iota
+	exportFieldContainer
+	exportFieldCRF
+	exportFieldBitrate
+	exportFieldAnimation
+	exportFieldOutput
+	exportFieldAspect
+	exportFieldOffset
+	exportFieldSpeed
+	exportFieldFreezeIn
+	exportFieldFreezeOut
+	exportFieldStabilize
+	exportFieldFastStart
+	exportFieldSmartCut
+	exportFieldNote
+	exportFieldPoster
+	exportFieldCaptions
+	exportFieldPriority
+	exportFieldClipboard
+	exportFieldMusic
+	exportFieldDuck
+	exportFieldBumpers
+	exportFieldTitleCard
+	exportFieldTitle
+	exportFieldCount
+)
+
+// speedSteps are the selectable export speeds, slowest first. From
+// video.TimelapseSpeed up the audio is dropped.
+var speedSteps = []float64{0.25, 0.5, 1, 2, 4, 10, 30, 60, 120}
+
+// normalSpeed is the index of 1x in speedSteps.
+const normalSpeed = 2
+
+// titleCardSteps are the selectable title cards, each length on black
+// and on a blurred still; index 0 is off.
+var titleCardSteps = []video.TitleCard{
+	{},
+	{Duration: 2 * time.Second}, {Duration: 2 * time.Second, Blur: true},
+	{Duration: 3 * time.Second}, {Duration: 3 * time.Second, Blur: true},
+	{Duration: 5 * time.Second}, {Duration: 5 * time.Second, Blur: true},
+}
+
+// crfSteps are the selectable constant qualities of re-encoded video and
+// bitrateSteps the bitrates in kbit/s; index 0 leaves it to the encoder.
+var (
+	crfSteps     = []int{0, 16, 18, 20, 23, 26, 28, 32, 36}
+	bitrateSteps = []int{0, 1000, 2500, 5000, 8000, 12000, 20000, 40000}
+)
+
+// animationSteps are the selectable frame rates and WebP qualities of
+// animated exports, smallest file first. They all loop forever.
+var animationSteps = []video.Animation{
+	{FPS: 10, Quality: 60},
+	{FPS: 15, Quality: 75},
+	{FPS: 24, Quality: 80},
+	{FPS: 30, Quality: 90},
+}
+
+// defaultAnimation is the index of the video package defaults in
+// animationSteps.
+const defaultAnimation = 1
+
+// formatBitrate writes kbit/s in Mbit/s, e.g. "2.5 Mbit/s".
+func formatBitrate(kbps int) string {
+	return strconv.FormatFloat(float64(kbps)/1000, 'f', -1, 64) + " Mbit/s"
+}
+
+// freezeSteps are the selectable freeze-frame lengths; index 0 is off.
+var freezeSteps = []time.Duration{0, 500 * time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second, 5 * time.Second}
+
+func formatFreeze(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+func (m *Model) moveExportFocus(delta int) {
+	m.exportFocusField = max(0, min(exportFieldCount-1, m.exportFocusField+delta))
+}
+
+// adjustExportField changes the value of the focused option field.
+func (m *Model) adjustExportField(delta int) {
+	step := func(i int) int {
+		return max(0, min(len(freezeSteps)-1, i+delta))
+	}
+	switch m.exportFocusField {
+	case exportFieldContainer:
+		m.exportContainer = m.nextContainer(m.exportContainer, delta)
+	case exportFieldCRF:
+		m.exportCRF = max(0, min(len(crfSteps)-1, m.exportCRF+delta))
+	case exportFieldBitrate:
+		m.exportBitrate = max(0, min(len(bitrateSteps)-1, m.exportBitrate+delta))
+	case exportFieldAnimation:
+		m.exportAnimation = max(0, min(len(animationSteps)-1, m.exportAnimation+delta))
+	case exportFieldOutput:
+		m.exportCutList = max(0, min(len(cutListLabels)-1, m.exportCutList+delta))
+	case exportFieldAspect:
+		m.cycleAspect(delta)
+	case exportFieldOffset:
+		if m.cropsFrame() {
+			m.adjustCropOffset(float64(delta) * cropOffsetStep)
+		}
+	case exportFieldSpeed:
+		m.exportSpeed = max(0, min(len(speedSteps)-1, m.exportSpeed+delta))
+	case exportFieldFreezeIn:
+		m.exportFreezeIn = step(m.exportFreezeIn)
+	case exportFieldFreezeOut:
+		m.exportFreezeOut = step(m.exportFreezeOut)
+	case exportFieldStabilize:
+		m.exportStabilize = m.canStabilize && !m.exportStabilize
+	case exportFieldFastStart:
+		m.exportFastStart = !m.exportFastStart
+	case exportFieldSmartCut:
+		m.exportSmartCut = !m.exportSmartCut
+	case exportFieldNote:
+		m.exportNoteSidecar = !m.exportNoteSidecar
+	case exportFieldPoster:
+		m.exportPosterSidecar = !m.exportPosterSidecar
+	case exportFieldCaptions:
+		m.exportCaptions = !m.exportCaptions
+	case exportFieldPriority:
+		m.exportLowPriority = !m.exportLowPriority
+	case exportFieldClipboard:
+		m.exportClipboard = m.clipboardTool != "" && !m.exportClipboard
+	case exportFieldMusic:
+		if m.musicPath != "" {
+			m.exportMusicLevel = max(0, min(10, m.exportMusicLevel+delta))
+		}
+	case exportFieldDuck:
+		if m.musicPath != "" {
+			m.exportDuck = max(0, min(10, m.exportDuck+delta))
+		}
+	case exportFieldBumpers:
+		m.joinBumpers = m.hasBumpers() && !m.joinBumpers
+	case exportFieldTitleCard:
+		if m.canTitleCard {
+			m.exportTitleCard = max(0, min(len(titleCardSteps)-1, m.exportTitleCard+delta))
+		}
+	}
+}
+
+func (m *Model) openExportModal() {
+	m.showExportModal = true
+	m.exportFilename = ""
+	if !m.aspectChosen {
+		m.exportAspectRatio = m.defaultAspectIndex()
+	}
+	m.exportPreview, m.exportPreviewErr = "", ""
+}
+
+// exportRow is one option of the export modal, field being its
+// exportField* constant or -1 for lines without a cursor.
+type exportRow struct {
+	field int
+	text  string
+}
+
+// scrollExportRows lays out the option rows in height lines, scrolled so
+// the focused one shows, with a line saying so above and below what is cut
+// away; they all show when there is room.
+func (m Model) scrollExportRows(rows []exportRow, height int, dim lipgloss.Style) string {
+	var lines []string
+	focusStart, focusEnd := 0, 0
+	for _, row := range rows {
+		if row.field == m.exportFocusField {
+			focusStart = len(lines)
+		}
+		lines = append(lines, strings.Split(row.text, "\n")...)
+		if row.field == m.exportFocusField {
+			focusEnd = len(lines)
+		}
+	}
+	if len(lines) <= height {
+		return strings.Join(lines, "\n")
+	}
+	// One line each for the markers, and the focused row at the least
+	window := max(focusEnd-focusStart, height-2)
+	top := max(0, min(len(lines)-window, focusStart-(window-(focusEnd-focusStart))/2))
+	above, below := "", ""
+	if top > 0 {
+		above = dim.Render("  ↑ more")
+	}
+	if top+window < len(lines) {
+		below = dim.Render("  ↓ more")
+	}
+	return above + "\n" + strings.Join(lines[top:top+window], "\n") + "\n" + below
+}
+
+// exportPlan is the export modal's options with what View shows of them
+// that takes file lookups: the free output name OutputPath searches for,
+// whether a file is already there, and the ffmpeg command.
+type exportPlan struct {
+	opts    video.ExportOptions
+	output  string
+	exists  bool
+	command string
+}
+
+// planExport works out the export plan again when the modal's options
+// changed, and forgets it when the modal closes, so that files written in
+// the meantime are seen the next time it opens.
+func (m *Model) planExport() {
+	if !m.showExportModal || m.exporting || !m.player.Trim.IsComplete() {
+		m.exportPlan = nil
+		return
+	}
+	opts := m.exportOptions()
+	if m.exportPlan != nil && reflect.DeepEqual(m.exportPlan.opts, opts) {
+		return
+	}
+	plan := &exportPlan{opts: opts, output: opts.OutputPath(), command: video.BuildFFmpegCommand(opts)}
+	_, err := os.Stat(plan.output)
+	plan.exists = err == nil
+	m.exportPlan = plan
+}
+
+// exportOptions collects the export modal's settings for the current
+// selection. Callers must have validated the trim state.
+func (m Model) exportOptions() video.ExportOptions {
+	opts := video.DefaultExportOptions(m.player.Path(), m.player.Properties())
+	opts.Output = m.exportFilename
+	opts.OutputDir = m.outputDir
+	opts.SafeNames = m.safeNames
+	opts.TrashReplaced = m.trash
+	opts.InPoint = *m.player.Trim.InPoint
+	opts.OutPoint = *m.player.Trim.OutPoint
+	opts.AspectRatio = video.AspectRatioOptions[m.exportAspectRatio].Ratio
+	opts.FreezeStart = freezeSteps[m.exportFreezeIn]
+	opts.FreezeEnd = freezeSteps[m.exportFreezeOut]
+	opts.CropOffset = m.exportCropOffset
+	opts.FrameExact = m.isLoopSelection()
+	opts.FastStart = m.exportFastStart
+	opts.SmartCut = m.exportSmartCut
+	opts.Container = video.ContainerOptions[m.exportContainer].Container
+	opts.Stabilize = m.exportStabilize
+	opts.Speed = speedSteps[m.exportSpeed]
+	opts.Note = m.note
+	opts.NoteSidecar = m.exportNoteSidecar
+	opts.LowPriority = m.exportLowPriority
+	opts.Encoder = m.exportEncoder
+	opts.CRF = crfSteps[m.exportCRF]
+	opts.Bitrate = bitrateSteps[m.exportBitrate]
+	opts.Animation = animationSteps[m.exportAnimation]
+	opts.Poster = m.poster
+	opts.PosterSidecar = m.exportPosterSidecar
+	if m.exportCaptions {
+		opts.Captions = m.transcript
+	}
+	opts.Music = m.musicBed()
+	opts.Intro, opts.Outro = m.bumpers()
+	opts.TitleCard = m.titleCard(m.note)
+	return opts
+}
+
+// musicBed is the export modal's audio mix.
+func (m Model) musicBed() video.MusicBed {
+	return video.MusicBed{
+		Path:   m.musicPath,
+		Volume: float64(m.exportMusicLevel) / 10,
+		Duck:   float64(m.exportDuck) / 10,
+	}
+}
+
+func (m Model) hasBumpers() bool {
+	return m.intro.Path != "" || m.outro.Path != ""
+}
+
+// titleCard is the card exports get: the typed title, or fallback when
+// none was typed, for the chosen length. No text leaves it out.
+func (m Model) titleCard(fallback string) video.TitleCard {
+	if !m.canTitleCard || m.exportTitleCard == 0 {
+		return video.TitleCard{}
+	}
+	card := titleCardSteps[m.exportTitleCard]
+	card.Text = cmp.Or(strings.TrimSpace(m.titleText), strings.TrimSpace(fallback))
+	return card
+}
+
+// bumpers returns the intro and outro exports get, none when the export
+// modal turned them off.
+func (m Model) bumpers() (intro, outro video.Bumper) {
+	if !m.joinBumpers {
+		return video.Bumper{}, video.Bumper{}
+	}
+	return m.intro, m.outro
+}
+
+// nextContainer steps a container index by delta, skipping WebM when the
+// source is not already VP8/VP9/AV1 and this ffmpeg has no VP9 encoder,
+// and WebP when it has no WebP encoder.
+func (m Model) nextContainer(idx, delta int) int {
+	n := len(video.ContainerOptions)
+	for i := 0; i < n; i++ {
+		idx = (idx + delta + n) % n
+		opt := video.ContainerOptions[idx]
+		switch opt.Container {
+		case video.ContainerWebM:
+			videoOK, _ := video.CopyCompatible(opt.Ext, m.player.Properties().Codec, "")
+			if videoOK || video.FFmpegCapabilities().HasEncoder("libvpx-vp9") {
+				return idx
+			}
+		case video.ContainerWebP:
+			if video.FFmpegCapabilities().CanWebP() {
+				return idx
+			}
+		default:
+			return idx
+		}
+	}
+	return idx
+}
+
+// defaultAspectIndex is the aspect selection a new export starts on.
+func (m Model) defaultAspectIndex() int {
+	if !m.canCrop {
+		return 0
+	}
+	for i, opt := range video.AspectRatioOptions {
+		if opt.Label == m.configAspect && (!opt.Fill || m.canBlurFill) {
+			return i
+		}
 	}
+	return video.AspectRatioIndex(video.DefaultExportOptions(m.player.Path(), m.player.Properties()).AspectRatio)
 }
 
 func (m *Model) saveTrimState() {
@@ -80,187 +823,679 @@ func (m *Model) saveTrimState() {
 }
 
 func (m Model) Init() tea.Cmd {
-	return tickCmd()
+	cmds := []tea.Cmd{
+		tickCmd(),
+		waitForFrame(m.player.Updates()),
+		loadTranscript(m.ctx, m.player.Path()),
+		loadPeaks(m.ctx, m.player.Path()),
+		loadBitrate(m.ctx, m.player.Path()),
+		loadSystemInfo(m.ctx),
+		detectBlack(m.ctx, m.player.Path(), m.player.Duration()),
+	}
+	if m.transcribing {
+		cmds = append(cmds, transcribe(m.ctx, m.player.Path(), m.whisperModel))
+	}
+	if m.autosaveInterval > 0 {
+		cmds = append(cmds, autosaveCmd(m.autosaveInterval))
+	}
+	if len(m.startupCommands) > 0 {
+		cmds = append(cmds, runCommands(m.startupCommands))
+	}
+	return tea.Batch(cmds...)
 }
 
 func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second/30, func(t time.Time) tea.Msg {
+	return tea.Tick(tickInterval, func(t time.Time) tea.Msg {
 		return TickMsg(t)
 	})
 }
 
+// waitForFrame blocks until the player signals an update. Bubble Tea
+// redraws after every message, so each frame is drawn exactly once.
+func waitForFrame(updates <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		<-updates
+		return FrameReadyMsg{}
+	}
+}
+
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	nm := next.(Model)
+
+	// Changed marks are saved immediately, not just on the timer
+	nm.autosave(false)
+	nm.warmTrimFrames()
+	nm.planExport()
+	return nm, cmd
+}
+
+// finishBatch wraps up a segment batch once its last export is done,
+// last being the output of that one or "" if it failed.
+func (m Model) finishBatch(last string) (tea.Model, tea.Cmd) {
+	exported := m.exports[m.queueStart:]
+	if m.queueFailed > 0 {
+		m.exportStatus = fmt.Sprintf("Exported %d of %d segments, %d failed: %s",
+			len(exported), m.queueTotal, m.queueFailed, m.queueErr)
+	} else {
+		m.exportStatus = fmt.Sprintf("Exported %d segments, last: %s (R to rename)", len(exported), last)
+		if m.exportFallback != "" {
+			m.exportStatus += " (" + m.exportFallback + ")"
+		}
+	}
+	m.lastBatch = nil
+	for i := m.queueStart; i < len(m.exports); i++ {
+		m.lastBatch = append(m.lastBatch, i)
+	}
+	m.lastManifest = ""
+	var batch []ExportResult
+	if m.manifestFormat != "" && len(exported) > 0 {
+		batch = slices.Clone(exported)
+	}
+	m.queueTotal, m.queueFailed, m.queueErr = 0, 0, ""
+	m.queueStats = video.ExportStats{}
+	m.showExportModal = false
+	if m.quitAfterExport {
+		if batch != nil {
+			// No time for a background write; the pipeline needs it
+			saveManifest(m.manifestFormat, m.player.Path(), batch)
+		}
+		return m.quit()
+	}
+	if batch != nil {
+		return m, writeManifest(m.manifestFormat, m.player.Path(), batch)
+	}
+	return m, nil
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case ExportProgressMsg:
-		m.exportProgress = float64(msg)
-		if m.exportProgressChan != nil {
-			return m, listenProgress(m.exportProgressChan)
+		if job := m.job(msg.Job); job != nil {
+			job.progress = msg.Progress
+			return m, listenProgress(job.id, job.progressChan)
 		}
 		return m, nil
 
 	case ExportDoneMsg:
-		m.exporting = false
+		job, ok := m.finishJob(msg.Job)
+		if !ok {
+			return m, nil
+		}
+		if msg.Err != nil {
+			// A failed export keeps the program open so the error is seen
+			m.quitAfterExport = false
+			if m.queueTotal == 0 {
+				m.showExportModal = false
+				m.exportStatus = "Export failed: " + msg.Err.Error()
+				return m, nil
+			}
+			m.queueFailed++
+			m.queueErr = msg.Err.Error()
+			if dropped := len(m.exportQueue); dropped > 0 {
+				m.queueErr += fmt.Sprintf(" (%d queued exports dropped)", dropped)
+				m.queueTotal -= dropped
+				m.exportQueue = nil
+			}
+			if m.exporting {
+				// The other workers of the batch finish what they started
+				m.exportStatus = "Export failed: " + m.queueErr
+				return m, nil
+			}
+			return m.finishBatch("")
+		}
+
+		in, out := job.opts.InPoint, job.opts.OutPoint
+		m.exportedTrim = video.TrimState{InPoint: &in, OutPoint: &out}
+		m.exports = append(m.exports, ExportResult{Output: msg.Output, In: in, Out: out, Duration: job.opts.OutputDuration()})
+		m.exportStatus = "Exported: " + msg.Output
+		if msg.Fallback != "" {
+			m.exportStatus += " (" + msg.Fallback + ")"
+			m.exportFallback = msg.Fallback
+		}
+		if len(m.exportQueue) > 0 {
+			return m, m.startNextQueued()
+		}
+		if m.exporting {
+			// Other workers of the batch are still running
+			return m, nil
+		}
+		if m.queueTotal > 0 {
+			return m.finishBatch(msg.Output)
+		}
 		m.showExportModal = false
-		m.exportProgress = 0
-		m.exportProgressChan = nil
+		if m.quitAfterExport {
+			if m.exportClipboard {
+				video.CopyFileToClipboard(m.ctx, msg.Output)
+			}
+			return m.quit()
+		}
+		if m.exportClipboard {
+			return m, copyToClipboard(m.ctx, msg.Output)
+		}
+		return m, nil
+
+	case ClipboardMsg:
+		m.showClipboardStatus(msg)
+		return m, nil
+
+	case ManifestWrittenMsg:
 		if msg.Err != nil {
-			m.exportStatus = "Export failed: " + msg.Err.Error()
+			m.exportStatus = "Manifest not written: " + msg.Err.Error()
 		} else {
-			m.exportStatus = "Exported: " + msg.Output
+			m.exportStatus += " · manifest " + filepath.Base(msg.Path)
+			m.lastManifest = msg.Path
+		}
+		return m, nil
+
+	case AutosaveMsg:
+		m.autosave(true)
+		return m, autosaveCmd(m.autosaveInterval)
+
+	case BlackDetectedMsg:
+		m.blackScanned = true
+		if msg.Err == nil {
+			m.blackRanges = msg.Intervals
+			m.timeline.SetBlackRanges(msg.Intervals)
 		}
 		return m, nil
 
+	case SilenceDetectedMsg:
+		m.detectingSilence = false
+		if msg.Err != nil {
+			m.exportStatus = "Silence detection failed: " + msg.Err.Error()
+			return m, nil
+		}
+		m.silentRanges = msg.Intervals
+		m.silenceScanned = true
+		return m, m.trimSilence()
+
+	case commandsMsg:
+		return m.runScript("--cmd", msg.cmds)
+
+	case SystemInfoMsg:
+		m.system = &msg.Info
+		return m, nil
+
+	case BitrateLoadedMsg:
+		// Without it the timeline keeps its plain cursor line
+		if msg.Err == nil {
+			m.bitrate = msg.Bitrate
+			m.timeline.SetBitrate(msg.Bitrate)
+			m.properties.SetGOP(msg.Bitrate.GOP())
+		}
+		return m, nil
+
+	case PeaksLoadedMsg:
+		// Files without audio simply get no waveform strip
+		if msg.Err == nil {
+			m.peaks = msg.Peaks
+			m.timeline.SetPeaks(msg.Peaks)
+		}
+		return m, nil
+
+	case ExportPlayFrameMsg:
+		if msg.gen != m.exportPlayGen || !m.exportPlaying {
+			return m, nil
+		}
+		m.exportPlayFrame = msg.Frame
+		return m, waitExportPlayFrame(m.exportPlayFrames, msg.gen)
+
+	case ExportPlayDoneMsg:
+		if msg.gen != m.exportPlayGen {
+			return m, nil
+		}
+		wasPlaying := m.exportPlaying
+		m.stopExportPlay()
+		switch {
+		case msg.Err != nil && !errors.Is(msg.Err, context.Canceled):
+			m.exportStatus = "Export preview failed: " + msg.Err.Error()
+		case wasPlaying:
+			m.exportStatus = "Export preview finished"
+		}
+		return m, nil
+
+	case BeatsFoundMsg:
+		m.findingBeats = false
+		if msg.Err != nil {
+			m.beatSnap = false
+			m.exportStatus = "Beat detection failed: " + msg.Err.Error()
+			return m, nil
+		}
+		m.beats = msg.Beats
+		if m.beatSnap {
+			m.exportStatus = fmt.Sprintf("Snap to beat on (%d beats)", len(m.beats))
+		}
+		return m, nil
+
+	case TranscriptLoadedMsg:
+		// A finished whisper run wins over subtitles loaded later
+		if msg.Err == nil && len(m.words) == 0 {
+			m.transcript = msg.Cues
+		}
+		return m, nil
+
+	case TranscribeDoneMsg:
+		m.transcribing = false
+		if msg.Err != nil {
+			m.exportStatus = "Transcription failed: " + msg.Err.Error()
+			return m, nil
+		}
+		m.words = msg.Words
+		m.transcript = video.GroupWords(msg.Words)
+		m.exportStatus = fmt.Sprintf("Transcript ready: %d lines (t to open)", len(m.transcript))
+		return m, nil
+
+	case ExportPreviewMsg:
+		m.exportPreviewBusy = false
+		if msg.Err != nil {
+			m.exportPreview, m.exportPreviewErr = "", "preview failed: "+msg.Err.Error()
+		} else {
+			m.exportPreview, m.exportPreviewErr = msg.Frame, ""
+		}
+		return m, nil
+
+	case SnapshotRenderedMsg:
+		m.setSnapshotThumb(msg)
+		return m, nil
+
+	case SnapshotSavedMsg:
+		if msg.Err != nil {
+			m.exportStatus = "Snapshot failed: " + msg.Err.Error()
+		} else {
+			m.exportStatus = "Saved " + msg.Path
+		}
+		return m, nil
+
+	case ReframeMsg:
+		m.reframing = false
+		if msg.Err != nil {
+			m.exportStatus = "Reframe failed: " + msg.Err.Error()
+			return m, nil
+		}
+		m.exportCropOffset = msg.Offset
+		m.exportPreview, m.exportPreviewErr = "", ""
+		m.exportStatus = "Suggested crop: " + m.formatCropOffset(msg.Offset)
+		if m.cropAdjust {
+			return m, m.refreshCropPreview()
+		}
+		return m, nil
+
+	case CropPreviewMsg:
+		if msg.gen != m.cropPreviewGen || !m.cropAdjust {
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.cropPreview, m.cropPreviewErr = "", "preview failed: "+msg.Err.Error()
+		} else {
+			m.cropPreview, m.cropPreviewErr = msg.Frame, ""
+		}
+		return m, nil
+
+	case LoopFoundMsg:
+		m.findingLoop = false
+		if msg.Err != nil {
+			m.exportStatus = "Loop search failed: " + msg.Err.Error()
+			return m, nil
+		}
+		m.applyLoop(msg.Match)
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
 		dims := CalculatePanelDimensions(m.width, m.height)
 		m.player.SetSize(dims.PreviewContentWidth, dims.PreviewContentHeight)
+		return m, m.refreshFilmstrip(dims.TimelineContentWidth)
+
+	case FilmstripMsg:
+		// A render for an older width may still arrive
+		if msg.Err == nil && len(msg.Frames) == m.filmstripCount {
+			m.timeline.SetFilmstrip(msg.Frames)
+		}
 		return m, nil
 
 	case TickMsg:
+		return m, tickCmd()
+
+	case FrameReadyMsg:
 		if m.previewMode && m.player.IsPlaying() {
 			if m.player.Trim.OutPoint != nil && m.player.Position() >= *m.player.Trim.OutPoint {
-				m.player.Pause()
-				m.previewMode = false
+				m.finishPreview()
 			}
 		}
-		return m, tickCmd()
+		return m, waitForFrame(m.player.Updates())
+
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 
 	case tea.KeyMsg:
+		if m.showQuitConfirm {
+			return m.handleQuitConfirmKey(msg)
+		}
 		if m.showHelpModal {
 			return m.handleHelpModalKey(msg)
 		}
 		if m.showExportModal {
 			return m.handleExportModalKey(msg)
 		}
+		if m.showTrimForm {
+			return m.handleTrimFormKey(msg)
+		}
+		if m.showNoteForm {
+			return m.handleNoteFormKey(msg)
+		}
+		if m.showTranscript {
+			return m.handleTranscriptKey(msg)
+		}
+		if m.showSegments {
+			return m.handleSegmentsKey(msg)
+		}
+		if m.showSnapshots {
+			return m.handleSnapshotsKey(msg)
+		}
+		if m.showRenameForm {
+			return m.handleRenameFormKey(msg)
+		}
+		if m.cropAdjust {
+			return m.handleCropAdjustKey(msg)
+		}
+		if m.exportPlaying {
+			return m.handleExportPlayKey(msg)
+		}
 		m.exportStatus = ""
+		// A 0 after count digits goes on with the count, bound or not
+		if key := msg.String(); key != "0" || m.repeatCount == 0 {
+			if m, cmd, ok := m.runHook(key); ok {
+				return m, cmd
+			}
+		}
 
 		pos := m.player.Position()
 		fps := m.player.FPS()
 		frameDuration := time.Second / time.Duration(fps)
 
-		switch msg.String() {
-		case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-			m.repeatCount = m.repeatCount*10 + int(msg.Runes[0]-'0')
+		switch key := msg.String(); {
+		case len(key) == 1 && key[0] >= '1' && key[0] <= '9':
+			m.repeatCount = m.repeatCount*10 + int(key[0]-'0')
 			m.exportStatus = fmt.Sprintf("%dx", m.repeatCount)
 			return m, nil
-		case "0":
-			if m.repeatCount == 0 {
-				m.player.Seek(0)
-				return m, nil
-			}
+		case key == "0" && m.repeatCount > 0:
 			m.repeatCount *= 10
 			m.exportStatus = fmt.Sprintf("%dx", m.repeatCount)
 			return m, nil
-		case "ctrl+c", "q":
-			m.player.Close()
-			return m, tea.Quit
+		}
+
+		switch m.keys.action(msg.String()) {
+		case actQuit:
+			return m.requestQuit()
+
+		case actGoStart:
+			m.player.Seek(0)
+			return m, nil
 
-		case " ":
+		case actPlayPause:
 			m.player.Toggle()
+			if m.player.IsPlaying() {
+				m.announce("Playing from %s", formatTimestamp(pos))
+			} else {
+				m.announce("Paused at %s", formatTimestamp(m.player.Position()))
+			}
 			return m, nil
 
-		case "h":
+		case actSeekBack:
 			n := m.repeatCount
-			if n <= 0 { n = 1 }
-			m.player.Seek(pos - time.Duration(n)*time.Second)
+			if n <= 0 {
+				n = 1
+			}
+			m.player.Seek(pos - time.Duration(n)*m.seekStep)
 			m.repeatCount = 0
 			return m, nil
 
-		case "l":
+		case actSeekForward:
 			n := m.repeatCount
-			if n <= 0 { n = 1 }
-			m.player.Seek(pos + time.Duration(n)*time.Second)
+			if n <= 0 {
+				n = 1
+			}
+			m.player.Seek(pos + time.Duration(n)*m.seekStep)
 			m.repeatCount = 0
 			return m, nil
 
-		case "H":
+		case actSeekBackLong:
 			n := m.repeatCount
-			if n <= 0 { n = 1 }
-			m.player.Seek(pos - time.Duration(n*5)*time.Second)
+			if n <= 0 {
+				n = 1
+			}
+			m.player.Seek(pos - time.Duration(n)*m.longSeekStep)
 			m.repeatCount = 0
 			return m, nil
 
-		case "L":
+		case actSeekForwardLong:
 			n := m.repeatCount
-			if n <= 0 { n = 1 }
-			m.player.Seek(pos + time.Duration(n*5)*time.Second)
+			if n <= 0 {
+				n = 1
+			}
+			m.player.Seek(pos + time.Duration(n)*m.longSeekStep)
 			m.repeatCount = 0
 			return m, nil
 
-		case ",":
+		case actFrameBack:
 			n := m.repeatCount
-			if n <= 0 { n = 1 }
+			if n <= 0 {
+				n = 1
+			}
+			pos = m.pauseForStep(pos)
 			m.player.Seek(pos - time.Duration(n)*frameDuration)
 			m.repeatCount = 0
 			return m, nil
 
-		case ".":
+		case actFrameForward:
 			n := m.repeatCount
-			if n <= 0 { n = 1 }
+			if n <= 0 {
+				n = 1
+			}
+			pos = m.pauseForStep(pos)
 			m.player.Seek(pos + time.Duration(n)*frameDuration)
 			m.repeatCount = 0
 			return m, nil
 
-		case "$", "G":
+		case actPlayFrames:
+			n := m.repeatCount
+			if n <= 0 {
+				n = 1
+			}
+			pos = m.pauseForStep(pos)
+			m.player.PlayFrames(n)
+			m.repeatCount = 0
+			m.announce("Playing %d frames from %s", n, formatTimestamp(pos))
+			return m, nil
+
+		case actReplay:
+			step := m.replayStep
+			if m.repeatCount > 0 {
+				step = time.Duration(m.repeatCount) * time.Second
+			}
+			m.previewMode = false
+			m.player.Seek(pos - step)
+			m.player.Play()
+			m.repeatCount = 0
+			m.announce("Replaying from %s", formatTimestamp(m.player.Position()))
+			return m, nil
+
+		case actGoEnd:
 			m.player.Seek(m.player.Duration())
 			m.repeatCount = 0
 			return m, nil
 
-		case "i":
-			m.saveTrimState()
-			m.player.Trim.SetIn(pos)
+		case actSetIn:
+			pos = m.snapToBeat(pos)
+			candidate := m.player.Trim
+			candidate.SetIn(pos)
+			if !m.acceptTrim(candidate) {
+				return m, nil
+			}
+			m.announce("In-point set to %s", formatTimestamp(pos))
 			return m, nil
 
-		case "o":
-			m.saveTrimState()
-			m.player.Trim.SetOut(pos)
+		case actSetOut:
+			pos = m.snapToBeat(pos)
+			candidate := m.player.Trim
+			candidate.SetOut(pos)
+			if !m.acceptTrim(candidate) {
+				return m, nil
+			}
+			m.announce("Out-point set to %s", formatTimestamp(pos))
 			return m, nil
 
-		case "p":
+		case actPreview:
 			if m.player.Trim.InPoint != nil {
 				m.player.Seek(*m.player.Trim.InPoint)
 				m.previewMode = true
 				m.player.Play()
+				m.announce("Previewing selection")
 			}
 			return m, nil
 
-		case "enter":
-			if m.player.Trim.IsComplete() {
+		case actTrimForm:
+			m.openTrimForm()
+			return m, nil
+
+		case actTranscript:
+			m.openTranscript()
+			return m, nil
+
+		case actTranscribe:
+			return m, m.startTranscription()
+
+		case actFindLoop:
+			return m, m.startLoopSearch()
+
+		case actBeatSnap:
+			return m, m.toggleBeatSnap()
+
+		case actKeyframeSnap:
+			m.snapToKeyframe(pos)
+			return m, nil
+
+		case actExportPlay:
+			return m, m.startExportPlay()
+
+		case actFullPath:
+			m.showFullPath = !m.showFullPath
+			return m, nil
+
+		case actTrimBlack:
+			m.trimBlack()
+			return m, nil
+
+		case actTrimSilence:
+			return m, m.trimSilence()
+
+		case actNote:
+			m.openNoteForm(-1)
+			return m, nil
+
+		case actPoster:
+			m.togglePoster()
+			return m, nil
+
+		case actSnapshot:
+			return m, m.takeSnapshot()
+
+		case actSnapshots:
+			m.openSnapshots()
+			return m, nil
+
+		case actCrop:
+			return m, m.openCropAdjust()
+
+		case actAddSegment:
+			m.addSegment()
+			return m, nil
+
+		case actSegments:
+			m.showSegments = true
+			return m, nil
+
+		case actDuplicate:
+			m.duplicateSelection()
+			return m, nil
+
+		case actExportSegments:
+			return m, m.queueSegments()
+
+		case actRename:
+			m.openRenameForm()
+			return m, nil
+
+		case actCopyExport:
+			return m, m.copyLastExport()
+
+		case actPrevMark:
+			m.jumpMark(-1)
+			return m, nil
+
+		case actNextMark:
+			m.jumpMark(1)
+			return m, nil
+
+		case actPreviewEnd:
+			m.previewEnd = m.previewEnd.Next()
+			m.properties.SetPreviewEnd(m.previewEnd.String())
+			m.exportStatus = "Preview end: " + m.previewEnd.String()
+			return m, nil
+
+		case actExport:
+			if m.exporting {
 				m.showExportModal = true
-				m.exportFilename = ""
-				m.exportAspectRatio = 0
+				return m, nil
+			}
+			if err := m.player.Trim.Validate(m.player.MinSelection()); err != nil {
+				if m.player.Trim.IsComplete() {
+					m.exportStatus = "Cannot export: " + err.Error()
+				}
+				return m, nil
+			}
+			if m.player.Trim.IsComplete() {
+				m.openExportModal()
 			}
 			return m, nil
 
-		case "esc", "d":
+		case actClear:
 			if m.player.Trim.InPoint != nil || m.player.Trim.OutPoint != nil {
 				m.saveTrimState()
 			}
 			m.player.Trim.Clear()
 			m.previewMode = false
+			m.announce("Selection cleared")
 			return m, nil
 
-		case "?":
+		case actHelp:
 			m.showHelpModal = true
 			return m, nil
 
-		case "u":
-			if len(m.undoStack) > 0 {
-				last := m.undoStack[len(m.undoStack)-1]
-				m.undoStack = m.undoStack[:len(m.undoStack)-1]
-				m.player.Trim.InPoint = last.inPoint
-				m.player.Trim.OutPoint = last.outPoint
-			}
+		case actUndo:
+			m.undo(m.repeatCount)
+			m.repeatCount = 0
 			return m, nil
 
-		case "tab":
-			m.player.CycleQuality()
+		case actQuality:
+			q := m.player.CycleQuality()
+			m.announce("Quality %s", q)
+			// Not worth an error in the status line; the setting just
+			// won't stick
+			_ = m.savePlayerPrefs()
 			return m, nil
 
-		case "m":
+		case actMute:
 			m.player.ToggleMute()
+			if m.player.IsMuted() {
+				m.announce("Muted")
+			} else {
+				m.announce("Sound on")
+			}
+			_ = m.savePlayerPrefs()
 			return m, nil
 		}
 	}
@@ -268,25 +1503,49 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// formatTimestamp formats a duration as MM:SS for status messages
+func formatTimestamp(d time.Duration) string {
+	total := int(d.Seconds())
+	return fmt.Sprintf("%02d:%02d", total/60, total%60)
+}
+
 func renderPanel(content, title string, width, height int) string {
-    innerWidth := width - 2
-    innerHeight := height - 2
-
-    // Combine title and content only if title provided
-    inner := content
-    if strings.TrimSpace(title) != "" {
-        inner = title + "\n" + content
-    }
-	lines := strings.Split(inner, "\n")
+	innerWidth := width - 2
+	innerHeight := height - 2
+
+	lines := strings.Split(content, "\n")
 	for len(lines) < innerHeight {
 		lines = append(lines, "")
 	}
 	paddedContent := strings.Join(lines[:innerHeight], "\n")
 
-	return BorderStyle.
+	panel := BorderStyle().
 		Width(innerWidth).
 		Height(innerHeight).
 		Render(paddedContent)
+	if strings.TrimSpace(title) == "" {
+		return panel
+	}
+
+	// The title sits in the top border so it costs no content row
+	_, rest, _ := strings.Cut(panel, "\n")
+	title = panels.TruncateMiddle(title, width-6)
+	border := lipgloss.NewStyle().Foreground(theme.Border)
+	top := border.Render("╭─ ") + TitleStyle().Render(title) +
+		border.Render(" "+strings.Repeat("─", max(0, width-5-panels.Width(title)))+"╮")
+	return top + "\n" + rest
+}
+
+// fileTitle names the open file: the basename, or the full path when
+// toggled with f.
+func (m Model) fileTitle() string {
+	if m.showFullPath {
+		if abs, err := filepath.Abs(m.player.Path()); err == nil {
+			return abs
+		}
+		return m.player.Path()
+	}
+	return filepath.Base(m.player.Path())
 }
 
 func (m Model) View() string {
@@ -304,32 +1563,76 @@ func (m Model) View() string {
 			Render("Terminal too small")
 	}
 
-    previewContent := m.preview.Render(dims.PreviewContentWidth, dims.PreviewContentHeight)
-    previewPanel := renderPanel(previewContent, "", dims.PreviewWidth, dims.PreviewHeight)
+	previewContent := m.preview.Render(dims.PreviewContentWidth, dims.PreviewContentHeight)
+	if m.cropAdjust {
+		previewContent = m.renderCropAdjust(dims.PreviewContentWidth, dims.PreviewContentHeight)
+	}
+	if m.exportPlaying {
+		previewContent = m.renderExportPlay(dims.PreviewContentWidth, dims.PreviewContentHeight)
+	}
+	previewPanel := renderPanel(previewContent, m.fileTitle(), dims.PreviewWidth, dims.PreviewHeight)
 
-    propertiesContent := m.properties.Render(dims.PropertiesContentWidth, dims.PropertiesContentHeight)
-    propertiesPanel := renderPanel(propertiesContent, "", dims.PropertiesWidth, dims.PropertiesHeight)
+	propertiesContent := m.properties.Render(dims.PropertiesContentWidth, dims.PropertiesContentHeight)
+	propertiesPanel := renderPanel(propertiesContent, "", dims.PropertiesWidth, dims.PropertiesHeight)
 
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top, previewPanel, propertiesPanel)
 
-    m.timeline.SetExportStatus(m.exportStatus)
-    timelineContent := m.timeline.Render(dims.TimelineContentWidth, dims.TimelineContentHeight)
-    timelinePanel := renderPanel(timelineContent, "", dims.TimelineWidth, dims.TimelineHeight)
+	m.timeline.SetExportStatus(m.exportStatus)
+	m.timeline.SetJobStatus(m.jobStatus())
+	m.timeline.SetBeatSnap(m.beatSnap)
+	timelineContent := m.timeline.Render(dims.TimelineContentWidth, dims.TimelineContentHeight)
+	timelinePanel := renderPanel(timelineContent, "", dims.TimelineWidth, dims.TimelineHeight)
 
 	base := lipgloss.JoinVertical(lipgloss.Left, topRow, timelinePanel)
 
+	if m.showQuitConfirm {
+		return m.renderQuitConfirm()
+	}
 	if m.showHelpModal {
 		return m.renderHelpModal(base)
 	}
 	if m.showExportModal {
 		return m.renderExportModal(base)
 	}
+	if m.showTrimForm {
+		return m.renderTrimForm()
+	}
+	if m.showNoteForm {
+		return m.renderNoteForm()
+	}
+	if m.showTranscript {
+		return m.renderTranscript()
+	}
+	if m.showSegments {
+		return m.renderSegments()
+	}
+	if m.showSnapshots {
+		return m.renderSnapshots()
+	}
+	if m.showRenameForm {
+		return m.renderRenameForm()
+	}
+	if !m.cropAdjust && !m.exportPlaying {
+		return m.overlaySixel(base, dims)
+	}
 
 	return base
 }
 
 func (m Model) handleExportModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-    switch msg.Type {
+	if m.exporting {
+		// The form is locked while exporting; Esc leaves the export running
+		// behind the editor and Enter brings this back
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m.requestQuit()
+		case "esc":
+			m.showExportModal = false
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
 	case tea.KeyEsc:
 		if !m.exporting {
 			m.showExportModal = false
@@ -340,85 +1643,83 @@ func (m Model) handleExportModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.exporting {
 			return m, nil
 		}
-		m.exporting = true
-		m.exportProgress = 0
-		progressChan := make(chan float64, 100)
-		m.exportProgressChan = progressChan
-		props := m.player.Properties()
-		opts := video.ExportOptions{
-			Input:       m.player.Path(),
-			Output:      m.exportFilename,
-			InPoint:     *m.player.Trim.InPoint,
-			OutPoint:    *m.player.Trim.OutPoint,
-			AspectRatio: video.AspectRatioOptions[m.exportAspectRatio].Ratio,
-			Width:       props.Width,
-			Height:      props.Height,
+		if m.cutListFormat() != "" {
+			m.writeCutList()
+			return m, nil
 		}
-		return m, startExportWithChan(opts, progressChan)
+		if err := m.player.Trim.Validate(m.player.MinSelection()); err != nil {
+			m.showExportModal = false
+			m.exportStatus = "Cannot export: " + err.Error()
+			return m, nil
+		}
+		stats, _ := video.LoadStats()
+		return m, m.startExport(m.exportOptions(), stats)
 
-	case tea.KeyUp, tea.KeyShiftTab:
-		if m.exportFocusField > 0 {
-			m.exportFocusField--
+	case tea.KeyCtrlP:
+		// Show the framing before committing to a re-encode
+		if m.exporting || m.exportPreviewBusy {
+			return m, nil
 		}
+		m.exportPreviewBusy = true
+		return m, renderExportPreview(m.ctx, m.player, m.exportOptions())
+
+	case tea.KeyCtrlR:
+		if m.exporting {
+			return m, nil
+		}
+		return m, m.startReframe()
+
+	case tea.KeyUp, tea.KeyShiftTab:
+		m.moveExportFocus(-1)
 		return m, nil
 
 	case tea.KeyDown, tea.KeyTab:
-		if m.exportFocusField < 1 {
-			m.exportFocusField++
-		}
+		m.moveExportFocus(1)
 		return m, nil
 
 	case tea.KeyLeft:
-		if m.exportFocusField == 1 {
-			m.exportAspectRatio--
-			if m.exportAspectRatio < 0 {
-				m.exportAspectRatio = len(video.AspectRatioOptions) - 1
-			}
-		}
+		m.adjustExportField(-1)
 		return m, nil
 
 	case tea.KeyRight:
-		if m.exportFocusField == 1 {
-			m.exportAspectRatio = (m.exportAspectRatio + 1) % len(video.AspectRatioOptions)
-		}
+		m.adjustExportField(1)
 		return m, nil
 
 	case tea.KeyBackspace:
-		if m.exportFocusField == 0 && len(m.exportFilename) > 0 {
-			m.exportFilename = m.exportFilename[:len(m.exportFilename)-1]
+		if m.exportFocusField == exportFieldFilename && len(m.exportFilename) > 0 {
+			m.exportFilename = panels.DropLastRune(m.exportFilename)
+		}
+		if m.exportFocusField == exportFieldTitle && len(m.titleText) > 0 {
+			m.titleText = panels.DropLastRune(m.titleText)
 		}
 		return m, nil
 
-    default:
-        // Vim-style navigation aliases in modal
-        switch msg.String() {
-        case "j":
-            if m.exportFocusField < 1 { m.exportFocusField++ }
-            return m, nil
-        case "k":
-            if m.exportFocusField > 0 { m.exportFocusField-- }
-            return m, nil
-        case "h":
-            if m.exportFocusField == 1 {
-                m.exportAspectRatio--
-                if m.exportAspectRatio < 0 {
-                    m.exportAspectRatio = len(video.AspectRatioOptions) - 1
-                }
-            }
-            return m, nil
-        case "l":
-            if m.exportFocusField == 1 {
-                m.exportAspectRatio = (m.exportAspectRatio + 1) % len(video.AspectRatioOptions)
-            }
-            return m, nil
-        }
-        if m.exportFocusField == 0 && len(msg.Runes) > 0 {
-            m.exportFilename += string(msg.Runes)
-        }
-        return m, nil
-    }
-
-	return m, nil
+	default:
+		// The title is prose, where hjkl are letters
+		if m.exportFocusField == exportFieldTitle && len(msg.Runes) > 0 {
+			m.titleText += string(msg.Runes)
+			return m, nil
+		}
+		// Vim-style navigation aliases in modal
+		switch msg.String() {
+		case "j":
+			m.moveExportFocus(1)
+			return m, nil
+		case "k":
+			m.moveExportFocus(-1)
+			return m, nil
+		case "h":
+			m.adjustExportField(-1)
+			return m, nil
+		case "l":
+			m.adjustExportField(1)
+			return m, nil
+		}
+		if m.exportFocusField == exportFieldFilename && len(msg.Runes) > 0 {
+			m.exportFilename += string(msg.Runes)
+		}
+		return m, nil
+	}
 }
 
 func (m Model) handleHelpModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -427,23 +1728,26 @@ func (m Model) handleHelpModalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.showHelpModal = false
 		return m, nil
 	}
+	if m.keys.action(msg.String()) == actHelp {
+		m.showHelpModal = false
+	}
 	return m, nil
 }
 
 func (m Model) renderHelpModal(_ string) string {
 	// Modern, minimal styling
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252")).
+		Foreground(theme.Text).
 		Bold(true)
 	sectionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
+		Foreground(theme.Muted)
 	keyStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252")).
+		Foreground(theme.Text).
 		Bold(true)
 	descStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
+		Foreground(theme.Muted)
 	dimStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))
+		Foreground(theme.Dim)
 
 	// Helper for key-description pairs
 	kd := func(key, desc string) string {
@@ -451,27 +1755,68 @@ func (m Model) renderHelpModal(_ string) string {
 	}
 
 	playback := sectionStyle.Render("PLAYBACK") + "\n" +
-		kd("Space", "Play/Pause") + "\n" +
-		kd("h / l", "Seek ±1 second") + "\n" +
-		kd("H / L", "Seek ±5 seconds") + "\n" +
-		kd(", / .", "Seek ±1 frame") + "\n" +
-		kd("0", "Go to start") + "\n" +
-		kd("G / $", "Go to end") + "\n" +
+		kd(m.keys.label(actPlayPause), "Play/Pause") + "\n" +
+		kd(m.keys.label(actSeekBack, actSeekForward), "Seek ±"+formatFreeze(m.seekStep)) + "\n" +
+		kd(m.keys.label(actSeekBackLong, actSeekForwardLong), "Seek ±"+formatFreeze(m.longSeekStep)) + "\n" +
+		kd(m.keys.label(actFrameBack, actFrameForward), "Seek ±1 frame (pauses)") + "\n" +
+		kd(m.keys.label(actPlayFrames), "Play N frames, pause") + "\n" +
+		kd(m.keys.label(actReplay), "Replay last seconds") + "\n" +
+		kd(m.keys.label(actGoStart), "Go to start") + "\n" +
+		kd(m.keys.label(actGoEnd), "Go to end") + "\n" +
+		kd(m.keys.label(actPrevMark, actNextMark), "Previous/next mark") + "\n" +
 		kd("5l 10.", "Vim-style counts") + "\n" +
-		kd("m", "Toggle mute") + "\n" +
-		kd("Tab", "Cycle quality")
+		kd(m.keys.label(actMute), "Toggle mute") + "\n" +
+		kd(m.keys.label(actQuality), "Cycle quality")
 
 	trim := sectionStyle.Render("TRIM") + "\n" +
-		kd("i", "Set in-point") + "\n" +
-		kd("o", "Set out-point") + "\n" +
-		kd("p", "Preview selection") + "\n" +
-		kd("d / Esc", "Clear selection") + "\n" +
-		kd("Enter", "Export")
+		kd(m.keys.label(actSetIn), "Set in-point") + "\n" +
+		kd(m.keys.label(actSetOut), "Set out-point") + "\n" +
+		kd(m.keys.label(actTrimForm), "Type exact in/out") + "\n" +
+		kd(m.keys.label(actPreview), "Preview selection") + "\n" +
+		kd(m.keys.label(actExportPlay), "Play as exported (crop, speed)") + "\n" +
+		kd(m.keys.label(actPreviewEnd), "Preview end: stop/loop/on") + "\n" +
+		kd(m.keys.label(actFindLoop), "Find seamless loop") + "\n" +
+		kd(m.keys.label(actBeatSnap), "Snap in/out to beats") + "\n" +
+		kd(m.keys.label(actKeyframeSnap), "Snap in/out to nearest keyframe") + "\n" +
+		kd(m.keys.label(actTrimBlack), "Trim leading/trailing black") + "\n" +
+		kd(m.keys.label(actTrimSilence), "Trim leading/trailing silence") + "\n" +
+		kd(m.keys.label(actCrop), "Adjust crop position") + "\n" +
+		kd(m.keys.label(actClear), "Clear selection") + "\n" +
+		kd(m.keys.label(actAddSegment), "Add selection as segment") + "\n" +
+		kd(m.keys.label(actSegments), "Segments (per-segment aspect)") + "\n" +
+		kd(m.keys.label(actDuplicate), "Queue next platform preset") + "\n" +
+		kd(m.keys.label(actExportSegments), "Export all segments") + "\n" +
+		kd(m.keys.label(actRename), "Rename the last batch") + "\n" +
+		kd(m.keys.label(actExport), "Export (while exporting: progress)")
 
 	other := sectionStyle.Render("OTHER") + "\n" +
-		kd("u", "Undo") + "\n" +
-		kd("?", "Toggle help") + "\n" +
-		kd("q", "Quit")
+		kd(m.keys.label(actTranscript), "Transcript (/ search)") + "\n" +
+		kd(m.keys.label(actTranscribe), "Transcribe with whisper") + "\n" +
+		kd(m.keys.label(actUndo), "Undo (3u: three steps)") + "\n" +
+		kd(m.keys.label(actNote), "Note for the exports") + "\n" +
+		kd(m.keys.label(actPoster), "Mark poster frame (again: clear)") + "\n" +
+		kd(m.keys.label(actSnapshot), "Snapshot frame") + "\n" +
+		kd(m.keys.label(actSnapshots), "Snapshots (compare, save PNG)") + "\n" +
+		kd(m.keys.label(actCopyExport), "Copy last export to clipboard") + "\n" +
+		kd(m.keys.label(actFullPath), "Show full file path") + "\n" +
+		kd(m.keys.label(actHelp), "Toggle help") + "\n" +
+		kd(m.keys.label(actQuit), "Quit")
+
+	if len(m.hooks) > 0 {
+		keys := slices.Sorted(maps.Keys(m.hooks))
+		other += "\n\n" + sectionStyle.Render("HOOKS")
+		for _, key := range keys {
+			other += "\n" + kd(key, panels.Truncate(m.hooks[key].Script, 30))
+		}
+	}
+
+	// What issue reports ask for, ready to copy
+	system := sectionStyle.Render("SYSTEM") + "\n" + kd("lazycut", m.version)
+	if m.system != nil {
+		for _, line := range m.system.Lines() {
+			system += "\n" + kd(line[0], panels.Truncate(line[1], 30))
+		}
+	}
 
 	footer := dimStyle.Render("Press any key to close")
 
@@ -479,11 +1824,12 @@ func (m Model) renderHelpModal(_ string) string {
 		playback + "\n\n" +
 		trim + "\n\n" +
 		other + "\n\n" +
+		system + "\n\n" +
 		footer
 
 	modal := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(theme.Border).
 		Padding(1, 3).
 		Width(45).
 		Render(content)
@@ -491,90 +1837,150 @@ func (m Model) renderHelpModal(_ string) string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
 }
 
-func startExportWithChan(opts video.ExportOptions, progressChan chan float64) tea.Cmd {
+func startExportWithChan(ctx context.Context, job int, opts video.ExportOptions, progressChan chan float64) tea.Cmd {
 	return tea.Batch(
 		func() tea.Msg {
-			output, err := video.ExportWithProgress(opts, progressChan)
-			return ExportDoneMsg{Output: output, Err: err}
+			output, fallback, err := video.ExportWithProgress(ctx, opts, progressChan)
+			return ExportDoneMsg{Job: job, Output: output, Fallback: fallback, Err: err}
 		},
-		listenProgress(progressChan),
+		listenProgress(job, progressChan),
 	)
 }
 
-func listenProgress(ch <-chan float64) tea.Cmd {
+// exportPreviewHeight is the height in cells of the composition preview.
+const exportPreviewHeight = 16
+
+// ExportPreviewMsg delivers a composition preview for the export modal.
+type ExportPreviewMsg struct {
+	Frame string
+	Err   error
+}
+
+func renderExportPreview(ctx context.Context, player *video.Player, opts video.ExportOptions) tea.Cmd {
+	return func() tea.Msg {
+		frame, err := player.RenderExportPreview(ctx, opts, 60, exportPreviewHeight)
+		return ExportPreviewMsg{Frame: frame, Err: err}
+	}
+}
+
+func listenProgress(job int, ch <-chan float64) tea.Cmd {
 	return func() tea.Msg {
 		p, ok := <-ch
 		if !ok {
 			return nil
 		}
-		return ExportProgressMsg(p)
+		return ExportProgressMsg{Job: job, Progress: p}
 	}
 }
 
 func (m Model) renderExportModal(_ string) string {
 	// Modern, minimal styling
 	titleStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252")).
+		Foreground(theme.Text).
 		Bold(true)
 	labelStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
+		Foreground(theme.Muted)
 	valueStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("252"))
+		Foreground(theme.Text)
 	accentStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("75")).
+		Foreground(theme.Accent).
 		Bold(true)
 	dimStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240"))
+		Foreground(theme.Dim)
 	cmdStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("240")).
+		Foreground(theme.Dim).
 		Italic(true)
 
 	props := m.player.Properties()
-	opts := video.ExportOptions{
-		Input:       m.player.Path(),
-		Output:      m.exportFilename,
-		InPoint:     *m.player.Trim.InPoint,
-		OutPoint:    *m.player.Trim.OutPoint,
-		AspectRatio: video.AspectRatioOptions[m.exportAspectRatio].Ratio,
-		Width:       props.Width,
-		Height:      props.Height,
+
+	plan := m.exportPlan
+	if plan == nil {
+		// Not yet planned: Update has not run since the modal opened
+		m.planExport()
+		plan = m.exportPlan
 	}
-	ffmpegCmd := video.BuildFFmpegCommand(opts)
 
 	var content string
 
 	if m.exporting {
 		title := titleStyle.Render("Exporting")
+		if m.queueTotal > 0 {
+			started := m.queueTotal - len(m.exportQueue)
+			title = titleStyle.Render(fmt.Sprintf("Exporting %d/%d", started, m.queueTotal))
+		}
+		title += dimStyle.Render("  " + panels.TruncateMiddle(m.fileTitle(), 69-panels.Width(title)-2))
+		if m.quitAfterExport {
+			title += dimStyle.Render("  (quitting when done)")
+		}
 
-		barWidth := 50
-		filled := int(m.exportProgress * float64(barWidth))
-		empty := barWidth - filled
-		progressBar := dimStyle.Render("[") +
-			accentStyle.Render(strings.Repeat("=", filled)) +
-			dimStyle.Render(strings.Repeat("-", empty)+"]")
-		percent := valueStyle.Render(fmt.Sprintf("%3.0f%%", m.exportProgress*100))
+		bar := func(progress float64, width int) string {
+			filled := max(0, min(width, int(progress*float64(width))))
+			return dimStyle.Render("[") +
+				accentStyle.Render(strings.Repeat("=", filled)) +
+				dimStyle.Render(strings.Repeat("-", width-filled)+"]") + " " +
+				valueStyle.Render(fmt.Sprintf("%3.0f%%", progress*100))
+		}
+
+		var rows []string
+		if len(m.exportJobs) == 1 {
+			progress, pass := m.exportJobs[0].pass()
+			if pass != "" {
+				title += "\n" + dimStyle.Render(pass)
+			}
+			row := bar(progress, 42)
+			if left := m.exportJobs[0].remaining(); left != "" {
+				row += dimStyle.Render("  " + left)
+			}
+			rows = append(rows, row)
+		} else {
+			// One row per worker: output name, bar, pass
+			for _, job := range m.exportJobs {
+				progress, pass := job.pass()
+				name := panels.TruncateMiddle(filepath.Base(job.opts.OutputPath()), 20)
+				row := labelStyle.Render(fmt.Sprintf("%-21s", name)) + bar(progress, 36)
+				if pass != "" {
+					row += dimStyle.Render("  " + strings.TrimSpace(strings.SplitN(pass, ":", 2)[0]))
+				} else if left := job.remaining(); left != "" {
+					row += dimStyle.Render("  " + left)
+				}
+				rows = append(rows, row)
+			}
+		}
+
+		keyStyle := lipgloss.NewStyle().Foreground(theme.Text).Bold(true)
+		footer := keyStyle.Render("Esc") + labelStyle.Render(" keep editing  ") +
+			keyStyle.Render("q") + labelStyle.Render(" quit")
 
 		content = title + "\n\n" +
-			progressBar + " " + percent + "\n\n" +
-			cmdStyle.Render(ffmpegCmd)
+			strings.Join(rows, "\n") + "\n\n" +
+			cmdStyle.Render(m.exportJobs[0].command) + "\n\n" +
+			footer
+	} else if plan == nil {
+		// planExport needs both trim points; without them there is
+		// nothing to show but the way back
+		keyStyle := lipgloss.NewStyle().Foreground(theme.Text).Bold(true)
+		content = titleStyle.Render("Export Selection") + "\n\n" +
+			dimStyle.Render("Set the in- and out-point first") + "\n\n" +
+			keyStyle.Render("Esc") + labelStyle.Render(" cancel")
 	} else {
 		title := titleStyle.Render("Export Selection")
+		title += dimStyle.Render("  " + panels.TruncateMiddle(m.fileTitle(), 69-panels.Width(title)-2))
 
 		filename := m.exportFilename
-		filenameDisplay := filename
-		if m.exportFocusField == 0 {
-			filenameDisplay = filename + dimStyle.Render("_")
+		// Modal content is 69 cells; the label takes 12, the cursor 1
+		filenameDisplay := panels.TruncateStart(filename, 56)
+		if m.exportFocusField == exportFieldFilename {
+			filenameDisplay += dimStyle.Render("_")
 		}
-		if filename == "" && m.exportFocusField != 0 {
+		if filename == "" && m.exportFocusField != exportFieldFilename {
 			filenameDisplay = dimStyle.Render("(auto)")
 		}
 
-		fnIndicator := "  "
-		arIndicator := "  "
-		if m.exportFocusField == 0 {
-			fnIndicator = accentStyle.Render("> ")
-		} else {
-			arIndicator = accentStyle.Render("> ")
+		indicator := func(field int) string {
+			if m.exportFocusField == field {
+				return accentStyle.Render("> ")
+			}
+			return "  "
 		}
 
 		var ratioLine string
@@ -586,22 +1992,316 @@ func (m Model) renderExportModal(_ string) string {
 			}
 		}
 
-		keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)
+		offsetLine := dimStyle.Render(" n/a")
+		if m.cropsFrame() {
+			offsetLine = valueStyle.Render(" " + m.formatCropOffset(m.exportCropOffset))
+			if m.reframing {
+				offsetLine += dimStyle.Render("  analyzing...")
+			} else {
+				offsetLine += dimStyle.Render("  ^R suggest")
+			}
+		}
+
+		// The output name decides the container, so follow what is typed
+		opts, output, ffmpegCmd := plan.opts, plan.output, plan.command
+		var replaceLine string
+		if plan.exists && m.cutListFormat() == "" {
+			replaces := "replaces the existing file"
+			if m.trash {
+				replaces = "replaces the existing file, which goes to the trash"
+			}
+			replaceLine = "\n" + strings.Repeat(" ", 12) + lipgloss.NewStyle().Foreground(theme.Warn).Render(replaces)
+		}
+
+		var containerLine string
+		for i, opt := range video.ContainerOptions {
+			if i == m.exportContainer {
+				containerLine += accentStyle.Render("["+opt.Label+"]") + " "
+			} else {
+				containerLine += dimStyle.Render(" "+opt.Label) + "  "
+			}
+		}
+		if problem := opts.CopyProblem(output); problem != "" {
+			containerLine += "\n" + strings.Repeat(" ", 12) + dimStyle.Render(problem)
+		}
+
+		// Quality only matters once the picture is re-encoded
+		reencodes := opts.ReencodesVideo(output)
+		crfLine := dimStyle.Render(" encoder default")
+		if crf := crfSteps[m.exportCRF]; crf > 0 {
+			crfLine = valueStyle.Render(" " + strconv.Itoa(crf))
+			switch problem := opts.RateProblem(output); {
+			case !reencodes:
+				crfLine = dimStyle.Render(" " + strconv.Itoa(crf) + "  video is copied")
+			case problem != "":
+				crfLine += dimStyle.Render("  " + problem)
+			default:
+				crfLine += dimStyle.Render("  constant quality, lower is better")
+			}
+		}
+		bitrateLine := dimStyle.Render(" encoder default")
+		if rate := bitrateSteps[m.exportBitrate]; rate > 0 {
+			bitrateLine = valueStyle.Render(" " + formatBitrate(rate))
+			switch {
+			case opts.Animated():
+				bitrateLine += dimStyle.Render("  " + opts.RateProblem(output))
+			case !reencodes:
+				bitrateLine = dimStyle.Render(" " + formatBitrate(rate) + "  video is copied")
+			case opts.CRF > 0 && opts.RateProblem(output) == "":
+				bitrateLine += dimStyle.Render("  at most, under the CRF")
+			default:
+				bitrateLine += dimStyle.Render("  average")
+			}
+		}
+
+		anim := animationSteps[m.exportAnimation]
+		animationDesc := fmt.Sprintf(" %g fps, quality %d", anim.FPS, anim.Quality)
+		animationLine := dimStyle.Render(animationDesc + "  for WebP and APNG")
+		if opts.Animated() {
+			if strings.EqualFold(filepath.Ext(output), ".apng") {
+				animationDesc = fmt.Sprintf(" %g fps, lossless", anim.FPS)
+			}
+			animationLine = valueStyle.Render(animationDesc) + dimStyle.Render("  loops forever")
+		}
+
+		var outputLine string
+		for i, label := range cutListLabels {
+			if i == m.exportCutList {
+				outputLine += accentStyle.Render("["+label+"]") + " "
+			} else {
+				outputLine += dimStyle.Render(" "+label) + "  "
+			}
+		}
+		if m.cutListFormat() != "" {
+			// Segments and the selection go in the list; nothing is rendered
+			n, ranges := len(m.cutList()), "ranges"
+			if n == 1 {
+				ranges = "range"
+			}
+			outputLine += "\n" + strings.Repeat(" ", 12) +
+				dimStyle.Render(fmt.Sprintf("%d %s, no video rendered", n, ranges))
+			ffmpegCmd = "writes " + filepath.Base(m.cutListPath())
+		}
+
+		fastStartLine := dimStyle.Render(" off")
+		switch {
+		case opts.Container == video.ContainerFragmentedMP4:
+			fastStartLine = dimStyle.Render(" n/a, fragments are indexed as they go")
+		case !video.SupportsFastStart(output):
+			fastStartLine = dimStyle.Render(" n/a for " + filepath.Ext(output))
+		case m.exportFastStart:
+			fastStartLine = valueStyle.Render(" on") + dimStyle.Render("  plays while downloading")
+		}
+
+		smartCutLine := dimStyle.Render(" off")
+		switch problem := opts.SmartCutProblem(output); {
+		case problem != "" && m.exportSmartCut:
+			smartCutLine = dimStyle.Render(" on, n/a: " + problem)
+		case problem != "":
+		case m.exportSmartCut:
+			smartCutLine = valueStyle.Render(" on") + dimStyle.Render("  frame accurate, re-encodes only the ends")
+		default:
+			smartCutLine += dimStyle.Render("  copied cuts start on a keyframe")
+		}
+
+		speedLine := dimStyle.Render(" 1x")
+		if speed := speedSteps[m.exportSpeed]; speed != 1 {
+			speedLine = valueStyle.Render(" "+video.FormatSpeed(speed)) +
+				dimStyle.Render("  "+formatDuration(opts.OutputDuration())+" out")
+			if cost := opts.SlowMotionCost(); cost != "" {
+				speedLine += "\n" + strings.Repeat(" ", 12) + dimStyle.Render(cost)
+			}
+			if opts.DropsAudio() {
+				speedLine += dimStyle.Render(", no audio")
+			}
+		}
+
+		stabilizeLine := dimStyle.Render(" off")
+		switch {
+		case !m.canStabilize:
+			stabilizeLine = dimStyle.Render(" needs ffmpeg with vid.stab (libvidstab)")
+		case m.exportStabilize:
+			stabilizeLine = valueStyle.Render(" on") + dimStyle.Render("  two passes, slower")
+		}
+
+		noteLine := dimStyle.Render(" none  N in the main view to add one")
+		if m.note != "" {
+			noteLine = valueStyle.Render(" comment")
+			if m.exportNoteSidecar {
+				noteLine += valueStyle.Render(" + " + filepath.Base(video.NotePath(output)))
+			}
+			noteLine += "\n" + strings.Repeat(" ", 12) + dimStyle.Render(panels.Truncate(m.note, 57))
+		}
+
+		posterLine := dimStyle.Render(" none  F in the main view to mark one")
+		if m.poster != nil {
+			posterLine = valueStyle.Render(" " + video.FormatTimecode(*m.poster))
+			switch {
+			case opts.EmbedsPoster(output):
+				posterLine += valueStyle.Render(" embedded")
+			case m.exportPosterSidecar:
+				posterLine += valueStyle.Render(" + " + filepath.Base(video.PosterPath(output)))
+			default:
+				format := filepath.Ext(output)
+				if opts.Container == video.ContainerFragmentedMP4 {
+					format = "fMP4"
+				}
+				posterLine += valueStyle.Render(" + "+filepath.Base(video.PosterPath(output))) +
+					dimStyle.Render("  "+format+" holds no cover art")
+			}
+		}
+
+		captionsLine := dimStyle.Render(" none  no transcript")
+		switch n := len(opts.ClipCaptions()); {
+		case len(m.transcript) == 0:
+		case !m.exportCaptions:
+			captionsLine = dimStyle.Render(" off")
+		case n == 0:
+			captionsLine = dimStyle.Render(" none  no transcript lines in the selection")
+		case n == 1:
+			captionsLine = valueStyle.Render(" "+filepath.Base(video.CaptionsPath(output))) + dimStyle.Render("  1 line")
+		default:
+			captionsLine = valueStyle.Render(" "+filepath.Base(video.CaptionsPath(output))) +
+				dimStyle.Render(fmt.Sprintf("  %d lines", n))
+		}
+
+		clipboardLine := dimStyle.Render(" off")
+		switch {
+		case m.clipboardTool == "":
+			clipboardLine = dimStyle.Render(" needs wl-copy (Wayland), xclip (X11) or macOS")
+		case m.exportClipboard:
+			clipboardLine = valueStyle.Render(" copy when done") + dimStyle.Render("  paste into a chat to attach it")
+		}
+
+		// A slider per mix level, in tenths
+		slider := func(level int) string {
+			return " " + accentStyle.Render(strings.Repeat("■", level)) +
+				dimStyle.Render(strings.Repeat("□", 10-level)) +
+				valueStyle.Render(fmt.Sprintf(" %3d%%", level*10))
+		}
+		musicLine := dimStyle.Render(" none  start with --music FILE")
+		duckLine := dimStyle.Render(" n/a")
+		if m.musicPath != "" {
+			track := dimStyle.Render("  " + panels.TruncateMiddle(filepath.Base(m.musicPath), 38))
+			musicLine = slider(m.exportMusicLevel) + track
+			duckLine = slider(m.exportDuck)
+			switch {
+			case m.exportMusicLevel == 0:
+				musicLine = dimStyle.Render(" off") + track
+				duckLine = dimStyle.Render(" n/a")
+			case m.exportDuck == 0:
+				duckLine += dimStyle.Render("  fixed mix")
+			default:
+				duckLine += dimStyle.Render("  music dips under the original")
+			}
+		}
+
+		bumpersLine := dimStyle.Render(" none  set intro / outro in config.toml")
+		if m.hasBumpers() {
+			var names []string
+			for _, b := range []video.Bumper{m.intro, m.outro} {
+				if b.Path != "" {
+					names = append(names, filepath.Base(b.Path))
+				}
+			}
+			bumpersLine = dimStyle.Render(" off")
+			if m.joinBumpers {
+				bumpersLine = valueStyle.Render(" "+panels.TruncateMiddle(strings.Join(names, " + "), 30)) +
+					dimStyle.Render("  +"+formatDuration(m.intro.Duration+m.outro.Duration)+", re-encodes")
+			}
+		}
+
+		cardLine := dimStyle.Render(" off")
+		switch card := titleCardSteps[m.exportTitleCard]; {
+		case !m.canTitleCard:
+			cardLine = dimStyle.Render(" needs drawtext (ffmpeg with libfreetype)")
+		case card.Blur:
+			cardLine = valueStyle.Render(" "+formatFreeze(card.Duration)) + dimStyle.Render("  on a blurred still of the first frame")
+		case card.Duration > 0:
+			cardLine = valueStyle.Render(" "+formatFreeze(card.Duration)) + dimStyle.Render("  on black")
+		}
+		if m.canTitleCard && m.exportTitleCard > 0 && m.titleCard(m.note).Text == "" {
+			cardLine += dimStyle.Render(", skipped: no title")
+		}
+		titleLine := valueStyle.Render(" " + panels.TruncateStart(m.titleText, 55))
+		if m.exportFocusField == exportFieldTitle {
+			titleLine += dimStyle.Render("_")
+		} else if m.titleText == "" {
+			titleLine = dimStyle.Render(" (none)")
+			if m.note != "" {
+				titleLine = dimStyle.Render(" (note) " + panels.Truncate(m.note, 48))
+			}
+		}
+
+		priorityLine := dimStyle.Render(" normal")
+		if m.exportLowPriority {
+			priorityLine = valueStyle.Render(" background") + dimStyle.Render("  slower, keeps the machine responsive")
+		}
+
+		freezeLine := func(step int) string {
+			if step == 0 {
+				return dimStyle.Render(" off")
+			}
+			return valueStyle.Render(" " + formatFreeze(freezeSteps[step]))
+		}
+
+		keyStyle := lipgloss.NewStyle().Foreground(theme.Text).Bold(true)
 		footer := keyStyle.Render("↑↓") + labelStyle.Render(" field  ") +
-			keyStyle.Render("←→") + labelStyle.Render(" ratio  ") +
+			keyStyle.Render("←→") + labelStyle.Render(" change  ") +
+			keyStyle.Render("^P") + labelStyle.Render(" preview  ") +
 			keyStyle.Render("Enter") + labelStyle.Render(" export  ") +
 			keyStyle.Render("Esc") + labelStyle.Render(" cancel")
 
-		content = title + "\n\n" +
-			fnIndicator + labelStyle.Render("Filename  ") + valueStyle.Render(filenameDisplay) + "\n\n" +
-			arIndicator + labelStyle.Render("Aspect    ") + ratioLine + "\n\n" +
-			cmdStyle.Render(ffmpegCmd) + "\n\n" +
-			footer
+		if !m.canCrop {
+			ratioLine += "\n" + strings.Repeat(" ", 12) +
+				dimStyle.Render("cropping needs an H.264 encoder (e.g. libx264)")
+		}
+
+		preview := ""
+		if m.exportPreviewBusy {
+			preview = dimStyle.Render("rendering preview...") + "\n\n"
+		} else if m.exportPreviewErr != "" {
+			preview = lipgloss.NewStyle().Foreground(theme.Warn).Render(panels.Wrap(panels.Sanitize(m.exportPreviewErr), 69)) + "\n\n"
+		} else if m.exportPreview != "" {
+			preview = lipgloss.PlaceHorizontal(69, lipgloss.Center, panels.ClipFrame(m.exportPreview, 69, exportPreviewHeight)) + "\n\n"
+		}
+
+		rows := []exportRow{
+			{exportFieldFilename, indicator(exportFieldFilename) + labelStyle.Render("Filename  ") + valueStyle.Render(filenameDisplay) + replaceLine},
+			{exportFieldContainer, indicator(exportFieldContainer) + labelStyle.Render("Format    ") + containerLine},
+			{exportFieldCRF, indicator(exportFieldCRF) + labelStyle.Render("CRF       ") + crfLine},
+			{exportFieldBitrate, indicator(exportFieldBitrate) + labelStyle.Render("Bitrate   ") + bitrateLine},
+			{exportFieldAnimation, indicator(exportFieldAnimation) + labelStyle.Render("Animation ") + animationLine},
+			{exportFieldOutput, indicator(exportFieldOutput) + labelStyle.Render("Output    ") + outputLine + "\n"},
+			{-1, "  " + labelStyle.Render("Source    ") + dimStyle.Render(" "+video.DescribeAspect(props.Width, props.Height))},
+			{exportFieldAspect, indicator(exportFieldAspect) + labelStyle.Render("Aspect    ") + ratioLine},
+			{exportFieldOffset, indicator(exportFieldOffset) + labelStyle.Render("Crop      ") + offsetLine + "\n"},
+			{exportFieldSpeed, indicator(exportFieldSpeed) + labelStyle.Render("Speed     ") + speedLine},
+			{exportFieldFreezeIn, indicator(exportFieldFreezeIn) + labelStyle.Render("Freeze in ") + freezeLine(m.exportFreezeIn)},
+			{exportFieldFreezeOut, indicator(exportFieldFreezeOut) + labelStyle.Render("Freeze out") + freezeLine(m.exportFreezeOut)},
+			{exportFieldStabilize, indicator(exportFieldStabilize) + labelStyle.Render("Stabilize ") + stabilizeLine},
+			{exportFieldFastStart, indicator(exportFieldFastStart) + labelStyle.Render("Faststart ") + fastStartLine},
+			{exportFieldSmartCut, indicator(exportFieldSmartCut) + labelStyle.Render("Smart cut ") + smartCutLine},
+			{exportFieldNote, indicator(exportFieldNote) + labelStyle.Render("Note      ") + noteLine},
+			{exportFieldPoster, indicator(exportFieldPoster) + labelStyle.Render("Poster    ") + posterLine},
+			{exportFieldCaptions, indicator(exportFieldCaptions) + labelStyle.Render("Captions  ") + captionsLine},
+			{exportFieldPriority, indicator(exportFieldPriority) + labelStyle.Render("Priority  ") + priorityLine},
+			{exportFieldClipboard, indicator(exportFieldClipboard) + labelStyle.Render("Clipboard ") + clipboardLine + "\n"},
+			{exportFieldMusic, indicator(exportFieldMusic) + labelStyle.Render("Music     ") + musicLine},
+			{exportFieldDuck, indicator(exportFieldDuck) + labelStyle.Render("Ducking   ") + duckLine},
+			{exportFieldBumpers, indicator(exportFieldBumpers) + labelStyle.Render("Bumpers   ") + bumpersLine},
+			{exportFieldTitleCard, indicator(exportFieldTitleCard) + labelStyle.Render("Title card") + cardLine},
+			{exportFieldTitle, indicator(exportFieldTitle) + labelStyle.Render("Title     ") + titleLine},
+		}
+		tail := "\n\n" + preview + cmdStyle.Render(ffmpegCmd) + "\n\n" + footer
+		// The border and padding take 4 lines, the title 2
+		fit := m.height - 4 - 2 - lipgloss.Height(lipgloss.NewStyle().Width(69).Render(tail))
+		content = title + "\n\n" + m.scrollExportRows(rows, fit, dimStyle) + tail
 	}
 
 	modal := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(theme.Border).
 		Padding(1, 3).
 		Width(75).
 		Render(content)
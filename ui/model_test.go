@@ -0,0 +1,237 @@
+package ui_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"lazycut/ui"
+	"lazycut/ui/uitest"
+	"lazycut/video"
+)
+
+// timeline finds the timeline bar, the line of markers above it and the
+// playhead under it, as rune slices.
+func timeline(t *testing.T, term *uitest.Terminal) (markers, bar, playhead []rune) {
+	t.Helper()
+	lines := strings.Split(term.View(), "\n")
+	for i, line := range lines {
+		if strings.Contains(line, "[=") && i > 0 && i+1 < len(lines) {
+			return []rune(lines[i-1]), []rune(line), []rune(lines[i+1])
+		}
+	}
+	t.Fatalf("no timeline on screen:\n%s", term.View())
+	return nil, nil, nil
+}
+
+// barFractions is where on the bar each of marks is, as a fraction of
+// the video.
+func barFractions(bar, line []rune, mark rune) []float64 {
+	start := slices.Index(bar, '[') + 1
+	width := slices.Index(bar[start:], ']')
+	var at []float64
+	for col, r := range line {
+		if r == mark {
+			at = append(at, float64(col-start)/float64(width))
+		}
+	}
+	return at
+}
+
+func near(got []float64, want ...float64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		// Within a cell of a 100-odd column bar
+		if math.Abs(got[i]-want[i]) > 0.015 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrimMarkers(t *testing.T) {
+	term, _ := openFake(t, ui.Options{}, 120, 40)
+	term.Press("l", "l", "i")
+	markers, bar, playhead := timeline(t, term)
+	if at := barFractions(bar, markers, '▼'); !near(at, 0.2) {
+		t.Errorf("in-marker at %v, want 0.2", at)
+	}
+	if at := barFractions(bar, playhead, '▲'); !near(at, 0.2) {
+		t.Errorf("playhead at %v, want 0.2", at)
+	}
+
+	term.Press("L", "o")
+	markers, bar, playhead = timeline(t, term)
+	if at := barFractions(bar, markers, '▼'); !near(at, 0.2, 0.7) {
+		t.Errorf("markers at %v, want 0.2 and 0.7", at)
+	}
+	if at := barFractions(bar, playhead, '▲'); !near(at, 0.7) {
+		t.Errorf("playhead at %v, want 0.7", at)
+	}
+	for _, want := range []string{"In          00:02", "Out         00:07", "Length      00:05"} {
+		if !strings.Contains(term.View(), want) {
+			t.Errorf("sidebar does not show %q", want)
+		}
+	}
+
+	term.Press("d")
+	markers, bar, _ = timeline(t, term)
+	if at := barFractions(bar, markers, '▼'); len(at) != 0 {
+		t.Errorf("markers at %v after clearing", at)
+	}
+	if strings.Contains(term.View(), "Selection") {
+		t.Error("selection still in the sidebar after clearing")
+	}
+}
+
+// focused is the label of the export modal field with the cursor.
+func focused(t *testing.T, term *uitest.Terminal) string {
+	t.Helper()
+	for _, line := range strings.Split(term.View(), "\n") {
+		_, rest, ok := strings.Cut(line, "│   > ")
+		if ok {
+			return strings.Fields(rest)[0]
+		}
+	}
+	t.Fatalf("no focused field on screen:\n%s", term.View())
+	return ""
+}
+
+func TestExportModalFocus(t *testing.T) {
+	term, _ := openFake(t, ui.Options{}, 120, 50)
+	term.Press("l", "l", "i", "L", "o", "enter")
+	if err := term.WaitFor("Export Selection", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []struct {
+		key, want string
+	}{
+		{"", "Filename"},
+		{"up", "Filename"},
+		{"down", "Format"},
+		{"tab", "CRF"},
+		{"j", "Bitrate"},
+		{"shift+tab", "CRF"},
+		{"k", "Format"},
+	} {
+		if step.key != "" {
+			term.Press(step.key)
+		}
+		if got := focused(t, term); got != step.want {
+			t.Fatalf("after %q the focus is on %s, want %s", step.key, got, step.want)
+		}
+	}
+
+	// Text goes into the filename only while it has the focus; hjkl move
+	// the cursor even there
+	term.Press("up")
+	term.Type("cut02")
+	if !strings.Contains(term.View(), "Filename  cut02_") {
+		t.Errorf("typed filename not shown:\n%s", term.View())
+	}
+	if !strings.Contains(term.View(), "cut02.mp4") {
+		t.Errorf("command preview does not use the filename:\n%s", term.View())
+	}
+	term.Press("down")
+	term.Type("x")
+	if !strings.Contains(term.View(), "Filename  cut02 ") {
+		t.Errorf("x typed on the format went into the filename:\n%s", term.View())
+	}
+
+	term.Press("esc")
+	if strings.Contains(term.View(), "Export Selection") {
+		t.Error("Esc did not close the modal")
+	}
+}
+
+func TestExportFromModal(t *testing.T) {
+	term, fake := openFake(t, ui.Options{}, 120, 50)
+	term.Press("l", "l", "i", "L", "o", "enter", "enter")
+	// The fake ffmpeg writes no output, so the export ends with an error;
+	// what matters is how it was run
+	if err := term.WaitFor("Export failed", 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	var args []string
+	for _, call := range fake.CallsTo("ffmpeg") {
+		if slices.Contains(call.Args, "-progress") {
+			args = call.Args
+		}
+	}
+	got := strings.Join(args, " ")
+	for _, want := range []string{"-ss 2.000", "-t 5.000", "-c copy"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ffmpeg %s: missing %q", got, want)
+		}
+	}
+}
+
+func TestCutsAsSegments(t *testing.T) {
+	term, _ := openFake(t, ui.Options{Cuts: []video.Cut{
+		{In: time.Second, Out: 3 * time.Second, Note: "goal"},
+		{In: 8 * time.Second, Out: 12 * time.Second},
+		{In: 20 * time.Second, Out: 25 * time.Second},
+	}}, 120, 40)
+	if err := term.WaitFor("Added 2 segments from the cut list (S to edit, E to export all), 1 outside the video left out", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	term.Press("S")
+	for _, want := range []string{"1  00:00:01.000 - 00:00:03.000", "goal", "2  00:00:08.000 - 00:00:10.000"} {
+		if !strings.Contains(term.View(), want) {
+			t.Errorf("segments panel does not show %q:\n%s", want, term.View())
+		}
+	}
+}
+
+func TestExportModalScrolls(t *testing.T) {
+	term, _ := openFake(t, ui.Options{}, 80, 24)
+	term.Press("l", "l", "i", "L", "o", "enter")
+	for _, label := range []string{"Filename", "Format", "CRF", "Bitrate", "Animation", "Output", "Aspect", "Crop",
+		"Speed", "Freeze", "Freeze", "Stabilize", "Faststart", "Smart", "Note", "Poster", "Captions", "Priority",
+		"Clipboard", "Music", "Ducking", "Bumpers", "Title", "Title"} {
+		if h := strings.Count(term.View(), "\n") + 1; h > 24 {
+			t.Fatalf("on %s the screen is %d lines:\n%s", label, h, term.View())
+		}
+		if got := focused(t, term); got != label {
+			t.Fatalf("focus on %s, want %s:\n%s", got, label, term.View())
+		}
+		if !strings.Contains(term.View(), "Enter export") {
+			t.Fatalf("on %s the keys are cut off:\n%s", label, term.View())
+		}
+		term.Press("down")
+	}
+	if !strings.Contains(term.View(), "↑ more") || strings.Contains(term.View(), "↓ more") {
+		t.Errorf("at the last field, want only the marker above:\n%s", term.View())
+	}
+
+	// With room for every field nothing scrolls
+	term.Send(tea.WindowSizeMsg{Width: 120, Height: 50})
+	if strings.Contains(term.View(), "more") {
+		t.Errorf("scrolled with room to spare:\n%s", term.View())
+	}
+}
+
+func TestExportModalReplaces(t *testing.T) {
+	dir := t.TempDir()
+	term, _ := openFake(t, ui.Options{Config: ui.Config{OutputDir: dir}}, 120, 50)
+	if err := os.WriteFile(filepath.Join(dir, "cut02.mp4"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	term.Press("l", "l", "i", "L", "o", "enter")
+	term.Type("cut0")
+	if strings.Contains(term.View(), "replaces the existing file") {
+		t.Fatalf("cut0.mp4 does not exist:\n%s", term.View())
+	}
+	term.Type("2")
+	if !strings.Contains(term.View(), "replaces the existing file") {
+		t.Errorf("no warning for cut02.mp4:\n%s", term.View())
+	}
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"lazycut/ui/panels"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mouseDrag is what a held left button on the timeline is moving.
+type mouseDrag struct {
+	hit  panels.TimelineHit // HitNone when no drag is in progress
+	from trimSnapshot       // selection when a marker drag started, for undo
+}
+
+// handleMouse seeks on a click on the progress bar and scrubs while the
+// button is held; pressing on an in/out marker drags it instead.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.modalOpen() || m.cropAdjust || m.exportPlaying {
+		return m, nil
+	}
+	dims := CalculatePanelDimensions(m.width, m.height)
+	// Content starts inside the border and one cell of padding
+	x, y := msg.X-2, msg.Y-dims.PreviewHeight-1
+	width := dims.TimelineContentWidth
+
+	switch msg.Action {
+	case tea.MouseActionPress:
+		if msg.Button != tea.MouseButtonLeft {
+			return m, nil
+		}
+		hit := m.timeline.HitTest(x, y, width)
+		if hit == panels.HitNone {
+			return m, nil
+		}
+		m.drag = mouseDrag{hit: hit, from: snapshotOf(m.player.Trim)}
+		if hit == panels.HitBar {
+			m.previewMode = false
+		}
+		m.dragTo(m.timeline.PositionAt(x, width))
+	case tea.MouseActionMotion:
+		if m.drag.hit != panels.HitNone {
+			m.dragTo(m.timeline.PositionAt(x, width))
+		}
+	case tea.MouseActionRelease:
+		if m.drag.hit == panels.HitIn || m.drag.hit == panels.HitOut {
+			if change := describeTrimChange(m.drag.from, snapshotOf(m.player.Trim)); change != "no change" {
+				m.undoStack = append(m.undoStack, m.drag.from)
+				m.exportStatus = "Moved " + change
+			}
+		}
+		m.drag = mouseDrag{}
+	}
+	return m, nil
+}
+
+// dragTo moves whatever is being dragged to pos. A marker follows the
+// mouse only as far as the selection stays valid, and the preview shows
+// the frame it is on.
+func (m *Model) dragTo(pos time.Duration) {
+	if m.drag.hit == panels.HitBar {
+		m.player.Seek(pos)
+		return
+	}
+	pos = m.snapToBeat(pos)
+	candidate := m.player.Trim
+	if m.drag.hit == panels.HitIn {
+		candidate.SetIn(pos)
+	} else {
+		candidate.SetOut(pos)
+	}
+	// Dragging past the other point would clear it
+	if m.player.Trim.IsComplete() && !candidate.IsComplete() ||
+		candidate.IsComplete() && candidate.Validate(m.player.MinSelection()) != nil {
+		return
+	}
+	m.player.Trim = candidate
+	m.player.Seek(pos)
+}
+
+// modalOpen reports whether a modal or panel over the main view takes
+// the input.
+func (m Model) modalOpen() bool {
+	return m.showQuitConfirm || m.showHelpModal || m.showExportModal ||
+		m.showTrimForm || m.showNoteForm || m.showTranscript ||
+		m.showSegments || m.showSnapshots || m.showRenameForm
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// noteForm edits the free-text note of the session (N) or of one
+// segment (n in the segments panel).
+type noteForm struct {
+	text    string
+	segment int // index into m.segments, or -1 for the session note
+}
+
+func (m *Model) openNoteForm(segment int) {
+	text := m.note
+	if segment >= 0 {
+		text = m.segments[segment].Note
+	}
+	m.noteForm = noteForm{text: text, segment: segment}
+	m.showNoteForm = true
+}
+
+func (m Model) handleNoteFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.noteForm
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.showNoteForm = false
+	case tea.KeyEnter:
+		text := strings.TrimSpace(f.text)
+		if f.segment >= 0 && f.segment < len(m.segments) {
+			m.segments[f.segment].Note = text
+		} else {
+			m.setNote(text)
+		}
+		m.showNoteForm = false
+	case tea.KeyBackspace:
+		if len(f.text) > 0 {
+			f.text = panels.DropLastRune(f.text)
+		}
+	case tea.KeyCtrlU:
+		f.text = ""
+	default:
+		f.text += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// setNote changes the session note, which new segments and exports of the
+// selection carry.
+func (m *Model) setNote(note string) {
+	m.note = note
+	m.properties.SetNote(note)
+}
+
+func (m Model) renderNoteForm() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(theme.Text)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	title := "Note"
+	if m.noteForm.segment >= 0 {
+		title = fmt.Sprintf("Note for segment %d", m.noteForm.segment+1)
+	}
+
+	footer := keyStyle.Render("Enter") + labelStyle.Render(" save  ") +
+		keyStyle.Render("^U") + labelStyle.Render(" clear  ") +
+		keyStyle.Render("Esc") + labelStyle.Render(" cancel")
+
+	// Modal content is 54 cells; keep the end being typed visible
+	lines := []string{
+		titleStyle.Render(title),
+		"",
+		valueStyle.Render(panels.TruncateStart(m.noteForm.text, 53)) + dimStyle.Render("_"),
+		"",
+		dimStyle.Render("Written as the export's comment metadata"),
+		footer,
+	}
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Width(60).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
@@ -0,0 +1,86 @@
+package panels
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ClipFrame makes chafa output safe to lay out with lipgloss: it keeps
+// only text, SGR (color) sequences and kitty graphics commands, dropping
+// cursor movement, screen clears, mode switches and OSC strings that
+// lipgloss cannot measure, then
+// clips the frame to width x height cells. Every line ends with a reset so
+// a color cut off mid-line cannot bleed into the panel border.
+func ClipFrame(frame string, width, height int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	lines := strings.Split(stripNonSGR(frame), "\n")
+	for len(lines) > 0 && ansi.StringWidth(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		if ansi.StringWidth(line) > width {
+			line = ansi.Truncate(line, width, "")
+		}
+		lines[i] = line + "\x1b[0m"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// stripNonSGR removes every escape sequence except SGR (ESC [ ... m) and
+// kitty graphics (ESC _ G ... ESC \), and every control character except
+// newline.
+func stripNonSGR(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0x1b && i+1 < len(s) && s[i+1] == '[':
+			// CSI: parameters and intermediates, then a final byte
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			if j < len(s) && s[j] == 'm' {
+				b.WriteString(s[i : j+1])
+			}
+			i = j
+		case c == 0x1b && i+1 < len(s) && s[i+1] == ']':
+			// OSC: runs to BEL or ST (ESC \)
+			j := i + 2
+			for j < len(s) && s[j] != 0x07 && !(s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			if j < len(s) && s[j] == 0x1b {
+				j++
+			}
+			i = j
+		case c == 0x1b && i+1 < len(s) && s[i+1] == '_':
+			// APC: runs to ST; kitty graphics (APC G) carry the image
+			// that the frame's placeholder cells show
+			j := i + 2
+			for j < len(s) && !(s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			if j < len(s) {
+				j++
+			}
+			if i+2 < len(s) && s[i+2] == 'G' && j < len(s) {
+				b.WriteString(s[i : j+1])
+			}
+			i = j
+		case c == 0x1b:
+			// Two-byte escape (ESC 7, ESC =, ...)
+			i++
+		case c == '\n' || (c >= 0x20 && c != 0x7f):
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
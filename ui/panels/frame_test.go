@@ -0,0 +1,93 @@
+package panels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Lines as chafa writes them: truecolor (--colors full) and 256-color
+// symbols, with the cursor hidden before the frame and shown after it.
+const (
+	chafaTrue = "\x1b[?25l\x1b[0m\x1b[38;2;250;120;10m\x1b[48;2;5;6;7m▀▄\x1b[38;2;1;2;3m█\x1b[0m\n" +
+		"\x1b[38;2;9;9;9m\x1b[48;2;200;200;200m▄▀▐\x1b[0m\n\x1b[?25h"
+	chafa256 = "\x1b[?25l\x1b[0m\x1b[38;5;208;48;5;16m▀▀\x1b[38;5;15m▖\x1b[0m\n\x1b[?25h"
+)
+
+func TestClipFrame(t *testing.T) {
+	for _, c := range []struct {
+		name          string
+		frame         string
+		width, height int
+		want          string
+	}{
+		{
+			name:  "truecolor fits",
+			frame: chafaTrue, width: 3, height: 2,
+			want: "\x1b[0m\x1b[38;2;250;120;10m\x1b[48;2;5;6;7m▀▄\x1b[38;2;1;2;3m█\x1b[0m\x1b[0m\n" +
+				"\x1b[38;2;9;9;9m\x1b[48;2;200;200;200m▄▀▐\x1b[0m\x1b[0m",
+		},
+		{
+			name:  "truecolor clipped in width and height",
+			frame: chafaTrue, width: 1, height: 1,
+			want: "\x1b[0m\x1b[38;2;250;120;10m\x1b[48;2;5;6;7m▀\x1b[38;2;1;2;3m\x1b[0m\x1b[0m",
+		},
+		{
+			// The cut falls on the next cell's color sequence, which is kept
+			// but colors nothing before the reset
+			name:  "cut inside escape sequence",
+			frame: chafaTrue, width: 2, height: 1,
+			want: "\x1b[0m\x1b[38;2;250;120;10m\x1b[48;2;5;6;7m▀▄\x1b[38;2;1;2;3m\x1b[0m\x1b[0m",
+		},
+		{
+			name:  "256 colors",
+			frame: chafa256, width: 2, height: 5,
+			want: "\x1b[0m\x1b[38;5;208;48;5;16m▀▀\x1b[38;5;15m\x1b[0m\x1b[0m",
+		},
+		{
+			// A frame cut off in the middle of a color sequence
+			name:  "unterminated escape",
+			frame: "\x1b[38;2;1;2;3m▀▀\x1b[38;2;4", width: 4, height: 1,
+			want: "\x1b[38;2;1;2;3m▀▀\x1b[0m",
+		},
+		{
+			// A double-width rune that only half fits is dropped whole
+			name:  "cut inside wide rune",
+			frame: "\x1b[31m日本語\x1b[0m", width: 3, height: 1,
+			want: "\x1b[31m日\x1b[0m\x1b[0m",
+		},
+		{
+			name:  "wide rune fits exactly",
+			frame: "日本語", width: 4, height: 1,
+			want: "日本\x1b[0m",
+		},
+		{
+			name:  "cursor movement, clears and OSC dropped",
+			frame: "\x1b[2J\x1b[H\x1b]0;title\x07\x1b[1;1H▀▀\x1b7\r", width: 5, height: 1,
+			want: "▀▀\x1b[0m",
+		},
+		{
+			name:  "kitty graphics kept",
+			frame: "\x1b_Ga=T,f=100;AAAA\x1b\\\U0010EEEE\U0010EEEE", width: 2, height: 1,
+			want: "\x1b_Ga=T,f=100;AAAA\x1b\\\U0010EEEE\U0010EEEE\x1b[0m",
+		},
+		{
+			name:  "no room",
+			frame: chafaTrue, width: 0, height: 2,
+			want: "",
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := ClipFrame(c.frame, c.width, c.height)
+			if got != c.want {
+				t.Errorf("ClipFrame = %q\n want %q", got, c.want)
+			}
+			for i, line := range strings.Split(got, "\n") {
+				if w := ansi.StringWidth(line); w > c.width {
+					t.Errorf("line %d is %d cells wide, more than %d", i, w, c.width)
+				}
+			}
+		})
+	}
+}
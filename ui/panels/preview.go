@@ -2,6 +2,7 @@ package panels
 
 import (
 	"lazycut/video"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -25,7 +26,7 @@ func (p *Preview) Render(width, height int) string {
 	if frame == "" {
 		// Show placeholder when no frame available
 		placeholder := "Press SPACE to play"
-		if p.player.IsPlaying() {
+		if p.player.IsPlaying() || p.player.Loading() {
 			placeholder = "Loading..."
 		}
 		return lipgloss.NewStyle().
@@ -35,9 +36,32 @@ func (p *Preview) Render(width, height int) string {
 			Render(placeholder)
 	}
 
+	if video.IsSixel(frame) {
+		// Drawn over these cells afterwards, see Sixel
+		return lipgloss.NewStyle().Width(width).Height(height).Render("")
+	}
+
 	return lipgloss.NewStyle().
 		Width(width).
 		Height(height).
 		Align(lipgloss.Center, lipgloss.Center).
-		Render(frame)
+		Render(ClipFrame(frame, width, height))
+}
+
+// Sixel returns the current frame when it is a sixel image, which Render
+// leaves blank cells for, and the offset within the width x height
+// content area that centers it.
+func (p *Preview) Sixel(width, height int) (frame string, x, y int, ok bool) {
+	frame = p.player.CurrentFrame()
+	if !video.IsSixel(frame) {
+		return "", 0, 0, false
+	}
+	// A line break would add a line to the view
+	frame = strings.NewReplacer("\n", "", "\r", "").Replace(frame)
+	props := p.player.Properties()
+	cols, rows := width, height
+	if props.Width > 0 && props.Height > 0 {
+		cols, rows = video.FitCells(props.Width, props.Height, width, height)
+	}
+	return frame, (width - cols) / 2, (height - rows) / 2, true
 }
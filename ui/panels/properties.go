@@ -2,15 +2,21 @@ package panels
 
 import (
 	"fmt"
+	"lazycut/ui/theme"
 	"lazycut/video"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 // Properties represents the video properties panel
 type Properties struct {
-	player *video.Player
+	player     *video.Player
+	previewEnd string
+	note       string
+	poster     *time.Duration
+	gop        time.Duration
 }
 
 // NewProperties creates a new Properties panel
@@ -20,6 +26,27 @@ func NewProperties(player *video.Player) *Properties {
 	}
 }
 
+// SetPreviewEnd sets the end-of-selection preview behavior shown with the
+// selection.
+func (p *Properties) SetPreviewEnd(label string) {
+	p.previewEnd = label
+}
+
+// SetNote sets the session note shown under the file properties.
+func (p *Properties) SetNote(note string) {
+	p.note = note
+}
+
+// SetPoster sets the poster frame shown with the note; nil hides it.
+func (p *Properties) SetPoster(poster *time.Duration) {
+	p.poster = poster
+}
+
+// SetGOP sets the average keyframe interval shown with the bitrate.
+func (p *Properties) SetGOP(gop time.Duration) {
+	p.gop = gop
+}
+
 // Render renders the properties panel
 func (p *Properties) Render(width, height int) string {
 	props := p.player.Properties()
@@ -37,7 +64,7 @@ func (p *Properties) Render(width, height int) string {
 	valueStyle := lipgloss.NewStyle()
 
 	addLine := func(label, value string) {
-		line := labelStyle.Render(label) + valueStyle.Render(value)
+		line := labelStyle.Render(label) + valueStyle.Render(Truncate(value, width-12))
 		lines = append(lines, line)
 	}
 
@@ -45,17 +72,36 @@ func (p *Properties) Render(width, height int) string {
 	addLine("Codec", props.Codec)
 	addLine("FPS", props.FormattedFPS())
 	addLine("Bitrate", props.FormattedBitrate())
+	if p.gop > 0 {
+		// Copied cuts snap to keyframes, so this is their precision
+		addLine("Keyframes", fmt.Sprintf("every %.1fs", p.gop.Seconds()))
+	}
 	addLine("Size", props.FormattedFileSize())
 	addLine("Duration", props.FormattedDuration())
 
 	// Quality indicator with color
 	quality := p.player.Quality()
-	qualityColor := "243" // gray for LOW
+	qualityColor := theme.Faint // gray for LOW
 	if quality == video.QualityHigh {
-		qualityColor = "46" // green
+		qualityColor = theme.Good // green
 	}
-	qualityStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(qualityColor))
+	qualityStyle := lipgloss.NewStyle().Foreground(qualityColor)
 	addLine("Quality", qualityStyle.Render(quality.String()))
+	if p.note != "" {
+		addLine("Note", p.note)
+	}
+	if p.poster != nil {
+		addLine("Poster", video.FormatTimecode(*p.poster))
+	}
+	// A paused frame comes from the first frame at or after the playhead
+	// (the keyframe before, seeking fast); say so when they differ
+	if shown, ok := p.player.FrameTime(); ok {
+		requested := video.FormatTimecode(p.player.Position())
+		if showing := video.FormatTimecode(shown); showing != requested {
+			addLine("Requested", requested)
+			addLine("Showing", showing)
+		}
+	}
 
 	// Selection section (only show if trim points are set)
 	trim := &p.player.Trim
@@ -72,6 +118,9 @@ func (p *Properties) Render(width, height int) string {
 		if trim.IsComplete() {
 			addLine("Length", formatTime(trim.Duration()))
 			addLine("Est. Size", props.EstimateOutputSize(trim.Duration()))
+			if p.previewEnd != "" {
+				addLine("Preview", p.previewEnd)
+			}
 		}
 	}
 
@@ -0,0 +1,120 @@
+package panels
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Text helpers for panel layout. Widths are terminal cells, not bytes or
+// runes: CJK characters and most emoji take two cells, combining marks
+// none, and color sequences are skipped. Measuring any other way lets a
+// wide filename push the panel border out of line.
+
+// Width returns the display width of s in cells.
+func Width(s string) int {
+	return ansi.StringWidth(s)
+}
+
+// Truncate shortens s to at most width cells, ending in "…" when
+// anything was cut. A wide character that would straddle the limit is
+// dropped whole.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if Width(s) <= width {
+		return s
+	}
+	return ansi.Truncate(s, width, "…")
+}
+
+// TruncateStart is Truncate from the other end, keeping the tail of s:
+// the end of a name being typed stays visible.
+func TruncateStart(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	w := Width(s)
+	if w <= width {
+		return s
+	}
+	return "…" + ansi.TruncateLeft(s, w-width+1, "")
+}
+
+// TruncateMiddle shortens s to width cells by cutting from the middle,
+// keeping both the start and the extension of a long filename.
+func TruncateMiddle(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	w := Width(s)
+	if w <= width {
+		return s
+	}
+	head := (width - 1) / 2
+	tail := width - 1 - head
+	return ansi.Truncate(s, head, "") + "…" + ansi.TruncateLeft(s, w-tail, "")
+}
+
+// Sanitize makes text from another program (ffmpeg stderr inside an
+// error, a transcript line) safe to draw: escape sequences are removed,
+// whether they start with ESC or a C1 control,
+// a carriage return keeps only what was written after it, as a terminal
+// would show a progress counter, tabs become spaces and other control
+// characters and invalid UTF-8 are dropped. Line breaks are kept.
+func Sanitize(s string) string {
+	lines := strings.Split(strings.ToValidUTF8(ansi.Strip(c1Bytes.Replace(s)), ""), "\n")
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t':
+				return ' '
+			case unicode.IsControl(r):
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// c1Bytes rewrites C1 controls written as UTF-8 (U+0080 to U+009F) to
+// the single bytes ansi.Strip parses, so a U+009B CSI goes together
+// with its parameters instead of leaving them behind as text.
+var c1Bytes = func() *strings.Replacer {
+	var pairs []string
+	for b := 0x80; b <= 0x9f; b++ {
+		pairs = append(pairs, string(rune(b)), string([]byte{byte(b)}))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// SanitizeLine is Sanitize for one-line places like the status line:
+// line breaks become spaces.
+func SanitizeLine(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(Sanitize(s)), "\n", " ")
+}
+
+// Wrap breaks s into lines of at most width cells, between words where
+// it can and inside them otherwise, so a long path in an error cannot
+// push out a modal's border.
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	return ansi.Wrap(s, width, "")
+}
+
+// DropLastRune removes the last character of s, for backspace in text
+// fields; slicing off the last byte would split a multi-byte character.
+func DropLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
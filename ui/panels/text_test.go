@@ -0,0 +1,87 @@
+package panels
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	for _, c := range []struct {
+		name, in, want string
+	}{
+		{"plain", "Conversion failed!", "Conversion failed!"},
+		{"colors", "\x1b[1;31mError\x1b[0m while decoding", "Error while decoding"},
+		{"cursor movement and clears", "\x1b[2K\x1b[Aframe=10\x1b[?25h", "frame=10"},
+		{"OSC title", "\x1b]0;ffmpeg\x07done", "done"},
+		{"OSC hyperlink", "see \x1b]8;;https://ffmpeg.org\x1b\\the docs\x1b]8;;\x1b\\", "see the docs"},
+		{"bare carriage return", "frame=  1 fps=0.0\rframe= 20 fps=40\rframe= 41 fps=41", "frame= 41 fps=41"},
+		{"carriage return per line", "a\rb\nc\rd", "b\nd"},
+		{"CRLF", "first\r\nsecond\r\n", "first\nsecond\n"},
+		{"backspace", "ab\bc", "abc"},
+		{"bell and NUL", "x\x07y\x00z", "xyz"},
+		{"C1 CSI", "\u009b31mred\u009b0m", "red"},
+		{"C1 OSC", "\u009d0;ffmpeg\u009cdone", "done"},
+		{"C1 controls", "a\u0085b\u0084c", "abc"},
+		{"8-bit C1 CSI", "\x9b31mred\x9b0m", "red"},
+		{"tabs", "a\tb", "a b"},
+		{"invalid UTF-8", "caf\xc3 \xffok", "caf ok"},
+		{"wide and combining", "日本 e\u0301", "日本 e\u0301"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Sanitize(c.in); got != c.want {
+				t.Errorf("Sanitize(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeLine(t *testing.T) {
+	for _, c := range []struct {
+		name, in, want string
+	}{
+		{"one line", "\x1b[31mdisk full\x1b[0m", "disk full"},
+		{"stderr tail", "[mp4 @ 0x1] Could not write header\nConversion failed!\n", "[mp4 @ 0x1] Could not write header Conversion failed!"},
+		{"progress then error", "frame=1\rframe=2\r\nmuxer error\r\n", "frame=2 muxer error"},
+		{"surrounding space", "\n  out of memory \n", "out of memory"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := SanitizeLine(c.in)
+			if got != c.want {
+				t.Errorf("SanitizeLine(%q) = %q, want %q", c.in, got, c.want)
+			}
+			if strings.ContainsAny(got, "\n\r\x1b") {
+				t.Errorf("SanitizeLine(%q) = %q, not one plain line", c.in, got)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	// 69 cells is the export modal's text width
+	const width = 69
+	for _, c := range []struct {
+		name, in string
+		lines    int
+	}{
+		{"fits", "Preview failed: exit status 1", 1},
+		{"words", strings.Repeat("ffmpeg could not open the input ", 4), 2},
+		{"long path", "No such file: /" + strings.Repeat("very-long-folder-name/", 8) + "clip.mp4", 3},
+		{"wide characters", "失敗: " + strings.Repeat("日本語のファイル名", 5), 3},
+		{"kept line breaks", "first\nsecond", 2},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := strings.Split(Wrap(Sanitize(c.in), width), "\n")
+			for i, line := range got {
+				if w := Width(line); w > width {
+					t.Errorf("line %d is %d cells, over %d: %q", i, w, width, line)
+				}
+			}
+			if len(got) != c.lines {
+				t.Errorf("%d lines, want %d: %q", len(got), c.lines, got)
+			}
+		})
+	}
+	if got := Wrap("anything", 0); got != "" {
+		t.Errorf("Wrap at width 0 = %q, want nothing", got)
+	}
+}
@@ -2,7 +2,9 @@ package panels
 
 import (
 	"fmt"
+	"lazycut/ui/theme"
 	"lazycut/video"
+	"math"
 	"strings"
 	"time"
 
@@ -12,6 +14,16 @@ import (
 type Timeline struct {
 	player       *video.Player
 	exportStatus string
+	accessible   bool
+	peaks        *video.Peaks
+	beatSnap     bool
+	black        []video.Interval
+	jobStatus    string
+	poster       *time.Duration
+	bitrate      *video.Bitrate
+	lane         []float32 // whole-file peaks per column, kept across frames
+	filmstrip    []string
+	marks        []video.Mark
 }
 
 func NewTimeline(player *video.Player) *Timeline {
@@ -20,11 +32,81 @@ func NewTimeline(player *video.Player) *Timeline {
 	}
 }
 
+// SetExportStatus shows status in the footer. Statuses quote errors with
+// ffmpeg's stderr in them, so it is sanitized to one clean line.
 func (t *Timeline) SetExportStatus(status string) {
-	t.exportStatus = status
+	t.exportStatus = SanitizeLine(status)
+}
+
+// SetJobStatus shows the progress of exports running behind the main view
+// at the start of the footer; "" hides it.
+func (t *Timeline) SetJobStatus(status string) {
+	t.jobStatus = status
+}
+
+// SetPoster marks the poster frame above the progress bar; nil hides it.
+func (t *Timeline) SetPoster(poster *time.Duration) {
+	t.poster = poster
+}
+
+// SetBitrate draws the per-second bitrate as a heat strip along the
+// cursor line; nil (still loading) leaves it blank.
+func (t *Timeline) SetBitrate(b *video.Bitrate) {
+	t.bitrate = b
+}
+
+// SetAccessible switches the timeline to a plain-text description that reads
+// well in screen readers and does not rely on color or glyph position.
+func (t *Timeline) SetAccessible(accessible bool) {
+	t.accessible = accessible
+}
+
+// SetPeaks sets the audio envelope for the zoomed waveform strip and the
+// whole-file lane; nil (still loading, or no audio) leaves both blank.
+func (t *Timeline) SetPeaks(peaks *video.Peaks) {
+	t.peaks = peaks
+	t.lane = nil
+}
+
+// Size of each filmstrip thumbnail in cells, about 16:9.
+const (
+	FilmstripWidth  = 10
+	FilmstripHeight = 3
+)
+
+// FilmstripCount is how many filmstrip thumbnails fit a timeline of
+// width cells.
+func FilmstripCount(width int) int {
+	return max(1, timelineBarWidth(width)/FilmstripWidth)
+}
+
+// SetFilmstrip sets the thumbnails shown above the progress bar, one per
+// equal part of the file; nil leaves the rows blank.
+func (t *Timeline) SetFilmstrip(frames []string) {
+	t.filmstrip = frames
+}
+
+// SetMarks shows labeled points of interest on the marker line, sorted by
+// time.
+func (t *Timeline) SetMarks(marks []video.Mark) {
+	t.marks = marks
+}
+
+// SetBlackRanges marks runs of black frames on the progress bar.
+func (t *Timeline) SetBlackRanges(black []video.Interval) {
+	t.black = black
+}
+
+// SetBeatSnap shows whether trim points snap to beats.
+func (t *Timeline) SetBeatSnap(on bool) {
+	t.beatSnap = on
 }
 
 func (t *Timeline) Render(width, height int) string {
+	if t.accessible {
+		return t.renderAccessible(width, height)
+	}
+
 	pos := t.player.Position()
 	dur := t.player.Duration()
 	playing := t.player.IsPlaying()
@@ -43,20 +125,92 @@ func (t *Timeline) Render(width, height int) string {
 		muteIcon = "×)"
 	}
 
-	barWidth := width - 3
-	if barWidth < 10 {
-		barWidth = 10
-	}
+	barWidth := timelineBarWidth(width)
 
 	line1 := fmt.Sprintf(" %s %s / %s  %s", playIcon, posStr, durStr, muteIcon)
+	if playing && !t.player.IsMuted() {
+		line1 += " " + t.buildLevelMeter(pos)
+	}
+	if t.beatSnap {
+		line1 += "  ♪ snap"
+	}
+	if mark, ok := t.markAt(pos, barWidth, dur); ok {
+		line1 += "  " + lipgloss.NewStyle().Foreground(theme.Warn).Render("• "+SanitizeLine(mark.Label))
+	}
+	wave := " " + t.buildWaveformStrip(barWidth+2, pos, trim)
+	lane := " " + t.buildWaveformLane(barWidth, dur, trim)
+	film := t.buildFilmstrip(barWidth)
 	line2 := " " + t.buildMarkerLine(barWidth, dur, trim)
 	line3 := " " + t.buildProgressBar(barWidth, pos, dur, trim)
-	line4 := " " + t.buildCursorLine(barWidth, pos, dur)
+	line4 := " " + t.buildCursorLine(barWidth, pos, dur, trim)
 
 	// Single-line footer with keybindings
 	line5 := t.buildFooterHelp(width)
 
-	content := strings.Join([]string{line1, line2, line3, line4, line5}, "\n")
+	lines := append([]string{line1, wave, lane}, film...)
+	content := clipLines(width, append(lines, line2, line3, line4, line5)...)
+
+	return lipgloss.NewStyle().
+		Width(width).
+		Height(height).
+		Render(content)
+}
+
+func (t *Timeline) renderAccessible(width, height int) string {
+	trim := &t.player.Trim
+
+	state := "Paused"
+	if t.player.IsPlaying() {
+		state = "Playing"
+	}
+	sound := "sound on"
+	if t.player.IsMuted() {
+		sound = "muted"
+	}
+	line1 := fmt.Sprintf("%s, pos %s of %s, %s", state,
+		formatDuration(t.player.Position()), formatDuration(t.player.Duration()), sound)
+	switch t.playheadPlace(t.player.Position(), trim) {
+	case playheadOnIn:
+		line1 += ", on in-point"
+	case playheadOnOut:
+		line1 += ", on out-point"
+	case playheadInside:
+		line1 += ", inside selection"
+	}
+	if mark, ok := t.markAt(t.player.Position(), max(1, width), t.player.Duration()); ok {
+		line1 += ", mark: " + SanitizeLine(mark.Label)
+	}
+	if t.beatSnap {
+		line1 += ", snap to beat"
+	}
+
+	var line2 string
+	switch {
+	case trim.IsComplete():
+		line2 = fmt.Sprintf("Selection: in %s, out %s, length %s",
+			formatDuration(*trim.InPoint), formatDuration(*trim.OutPoint), formatDuration(trim.Duration()))
+	case trim.InPoint != nil:
+		line2 = fmt.Sprintf("Selection: in %s, no out-point", formatDuration(*trim.InPoint))
+	case trim.OutPoint != nil:
+		line2 = fmt.Sprintf("Selection: no in-point, out %s", formatDuration(*trim.OutPoint))
+	default:
+		line2 = "Selection: none"
+	}
+	if t.poster != nil {
+		line2 += ", poster frame at " + formatDuration(*t.poster)
+	}
+
+	line3 := ""
+	if t.exportStatus != "" {
+		line3 = "Status: " + t.exportStatus
+	}
+	if t.jobStatus != "" {
+		line3 = strings.TrimPrefix(line3+", "+t.jobStatus, ", ")
+	}
+
+	line4 := "Keys: Space play, h/l seek, i/o set in/out, Enter export, ? help, q quit"
+
+	content := clipLines(width, line1, line2, line3, line4)
 
 	return lipgloss.NewStyle().
 		Width(width).
@@ -64,6 +218,110 @@ func (t *Timeline) Render(width, height int) string {
 		Render(content)
 }
 
+// TimelineHit is what a mouse press on the timeline lands on.
+type TimelineHit int
+
+const (
+	HitNone TimelineHit = iota
+	HitBar              // the progress bar or the lines next to it: seek
+	HitIn               // the in-point marker
+	HitOut              // the out-point marker
+)
+
+// Content rows of the marker line and the progress bar, see Render.
+const (
+	markerRow = 3 + FilmstripHeight
+	barRow    = markerRow + 1
+)
+
+// HitTest tells what is at cell x, y of the timeline content, counted
+// from its top-left corner, when it is width cells wide. A marker is hit
+// one cell either side of it too, so it can be grabbed without aiming.
+func (t *Timeline) HitTest(x, y, width int) TimelineHit {
+	if t.accessible || t.player.Duration() <= 0 {
+		return HitNone
+	}
+	switch y {
+	case markerRow:
+		trim := &t.player.Trim
+		dur := t.player.Duration()
+		barWidth := timelineBarWidth(width)
+		near := func(point *time.Duration) int {
+			if point == nil {
+				return 2
+			}
+			col := 1 + min(barWidth+1, int(float64(*point)/float64(dur)*float64(barWidth))+1)
+			return abs(col - x)
+		}
+		in, out := near(trim.InPoint), near(trim.OutPoint)
+		switch {
+		case in <= 1 && in <= out:
+			return HitIn
+		case out <= 1:
+			return HitOut
+		}
+		return HitBar
+	case barRow, barRow + 1:
+		return HitBar
+	}
+	return HitNone
+}
+
+// PositionAt is the time under column x of the timeline content, clamped
+// to the file.
+func (t *Timeline) PositionAt(x, width int) time.Duration {
+	frac := float64(x-2) / float64(timelineBarWidth(width))
+	return time.Duration(max(0, min(1, frac)) * float64(t.player.Duration()))
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// markAt is the last mark at or before pos, while the playhead is still
+// within a second or a progress bar cell of it.
+func (t *Timeline) markAt(pos time.Duration, barWidth int, dur time.Duration) (video.Mark, bool) {
+	reach := max(time.Second, dur/time.Duration(barWidth))
+	for i := len(t.marks) - 1; i >= 0; i-- {
+		if mark := t.marks[i]; mark.At <= pos {
+			return mark, pos-mark.At < reach && mark.Label != ""
+		}
+	}
+	return video.Mark{}, false
+}
+
+// timelineBarWidth is the progress bar width inside a timeline of width
+// cells.
+func timelineBarWidth(width int) int {
+	return max(10, width-3)
+}
+
+// buildFilmstrip lays the thumbnails out in FilmstripHeight lines, each
+// centered over the part of the progress bar it was taken from.
+func (t *Timeline) buildFilmstrip(barWidth int) []string {
+	lines := make([]string, FilmstripHeight)
+	if len(t.filmstrip) == 0 {
+		return lines
+	}
+	cells := make([]string, len(t.filmstrip))
+	for i, frame := range t.filmstrip {
+		from := i * barWidth / len(t.filmstrip)
+		to := (i + 1) * barWidth / len(t.filmstrip)
+		cells[i] = lipgloss.PlaceHorizontal(to-from, lipgloss.Center,
+			ClipFrame(frame, FilmstripWidth, FilmstripHeight))
+	}
+	strip := strings.Split(lipgloss.JoinHorizontal(lipgloss.Top, cells...), "\n")
+	for i := range lines {
+		if i < len(strip) {
+			lines[i] = "  " + strip[i]
+		}
+	}
+	return lines
+}
+
 func (t *Timeline) buildProgressBar(barWidth int, pos, dur time.Duration, trim *video.TrimState) string {
 	if dur <= 0 {
 		return "[" + repeat("-", barWidth) + "]"
@@ -88,6 +346,8 @@ func (t *Timeline) buildProgressBar(barWidth int, pos, dur time.Duration, trim *
 		}
 	}
 
+	blackStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+
 	var bar strings.Builder
 	bar.WriteString("[")
 	for i := 0; i < barWidth; i++ {
@@ -98,6 +358,8 @@ func (t *Timeline) buildProgressBar(barWidth int, pos, dur time.Duration, trim *
 
 		if inSelection {
 			bar.WriteString("▓")
+		} else if t.isBlack(i, barWidth, dur) {
+			bar.WriteString(blackStyle.Render("▒"))
 		} else if i < posIdx {
 			bar.WriteString("=")
 		} else {
@@ -109,25 +371,59 @@ func (t *Timeline) buildProgressBar(barWidth int, pos, dur time.Duration, trim *
 	return bar.String()
 }
 
+// isBlack reports whether the middle of progress bar cell i falls in a
+// run of black frames.
+func (t *Timeline) isBlack(i, barWidth int, dur time.Duration) bool {
+	mid := time.Duration((float64(i) + 0.5) / float64(barWidth) * float64(dur))
+	for _, iv := range t.black {
+		if mid >= iv.Start && mid < iv.End {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Timeline) buildMarkerLine(barWidth int, dur time.Duration, trim *video.TrimState) string {
 	if dur <= 0 {
 		return repeat(" ", barWidth+2)
 	}
 
-	inStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("34")).Bold(true)
-	outStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("166")).Bold(true)
+	inStyle := lipgloss.NewStyle().Foreground(theme.InMarker).Bold(true)
+	outStyle := lipgloss.NewStyle().Foreground(theme.OutMarker).Bold(true)
 
 	line := make([]string, barWidth+2)
 	for i := range line {
 		line[i] = " "
 	}
 
+	// Keyframe ticks only where there is room to tell them apart
+	if t.bitrate != nil && len(t.bitrate.Keyframes) <= barWidth/2 {
+		keyStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+		for _, k := range t.bitrate.Keyframes {
+			idx := min(len(line)-1, int(float64(k)/float64(dur)*float64(barWidth))+1)
+			line[idx] = keyStyle.Render("'")
+		}
+	}
+
+	markStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	for _, mark := range t.marks {
+		idx := min(len(line)-1, int(float64(mark.At)/float64(dur)*float64(barWidth))+1)
+		line[idx] = markStyle.Render("•")
+	}
+
+	// In/out markers win when they share a column with the poster
+	if t.poster != nil {
+		posterStyle := lipgloss.NewStyle().Foreground(theme.Accent)
+		idx := min(len(line)-1, int(float64(*t.poster)/float64(dur)*float64(barWidth))+1)
+		line[idx] = posterStyle.Render("◆")
+	}
+
 	if trim.InPoint != nil {
 		inIdx := int(float64(*trim.InPoint)/float64(dur)*float64(barWidth)) + 1
 		if inIdx >= len(line) {
 			inIdx = len(line) - 1
 		}
-		line[inIdx] = inStyle.Render("▼")
+		line[inIdx] = inStyle.Render(theme.InGlyph)
 	}
 
 	if trim.OutPoint != nil {
@@ -135,13 +431,13 @@ func (t *Timeline) buildMarkerLine(barWidth int, dur time.Duration, trim *video.
 		if outIdx >= len(line) {
 			outIdx = len(line) - 1
 		}
-		line[outIdx] = outStyle.Render("▼")
+		line[outIdx] = outStyle.Render(theme.OutGlyph)
 	}
 
 	return strings.Join(line, "")
 }
 
-func (t *Timeline) buildCursorLine(barWidth int, pos, dur time.Duration) string {
+func (t *Timeline) buildCursorLine(barWidth int, pos, dur time.Duration, trim *video.TrimState) string {
 	if dur <= 0 {
 		return repeat(" ", barWidth+2)
 	}
@@ -155,9 +451,244 @@ func (t *Timeline) buildCursorLine(barWidth int, pos, dur time.Duration) string
 	if posIdx >= len(line) {
 		posIdx = len(line) - 1
 	}
-	line[posIdx] = '▲'
+	place := t.playheadPlace(pos, trim)
+	glyph := "▲"
+	if place == playheadOutside {
+		glyph = "△"
+	}
+	head := playheadStyle(place).Render(glyph)
+
+	if t.bitrate == nil {
+		return string(line[:posIdx]) + head + string(line[posIdx+1:])
+	}
+	return t.buildHeatLine(line, posIdx, head, barWidth, dur)
+}
+
+// playheadPlace is where the playhead is relative to the selection.
+type playheadPlace int
+
+const (
+	playheadOutside playheadPlace = iota
+	playheadInside
+	playheadOnIn  // on the in-point's frame
+	playheadOnOut // on the out-point's frame
+)
+
+// playheadPlace tells whether pos is inside the selection, and whether it
+// is on the very frame of a trim point, for checking a cut while frame
+// stepping.
+func (t *Timeline) playheadPlace(pos time.Duration, trim *video.TrimState) playheadPlace {
+	half := time.Second / time.Duration(2*max(1, t.player.FPS()))
+	on := func(point *time.Duration) bool {
+		return point != nil && pos >= *point-half && pos < *point+half
+	}
+	switch {
+	case on(trim.InPoint):
+		return playheadOnIn
+	case on(trim.OutPoint):
+		return playheadOnOut
+	case trim.IsComplete() && pos > *trim.InPoint && pos < *trim.OutPoint:
+		return playheadInside
+	}
+	return playheadOutside
+}
+
+// playheadStyle draws the playhead bright inside the selection, muted
+// outside it, and flashing in the marker's color on an in or out frame.
+func playheadStyle(place playheadPlace) lipgloss.Style {
+	switch place {
+	case playheadOnIn:
+		return lipgloss.NewStyle().Foreground(theme.InMarker).Bold(true).Reverse(true).Blink(true)
+	case playheadOnOut:
+		return lipgloss.NewStyle().Foreground(theme.OutMarker).Bold(true).Reverse(true).Blink(true)
+	case playheadInside:
+		return lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	}
+	return lipgloss.NewStyle().Foreground(theme.Muted)
+}
+
+// heatLevels draw the bitrate strip from quiet to busy.
+var heatLevels = []rune("▁▂▃▄▅▆▇█")
+
+// buildHeatLine fills the cursor line around the playhead with the bitrate
+// of each column: taller and warmer where there is more motion (or noise)
+// to encode.
+func (t *Timeline) buildHeatLine(line []rune, posIdx int, head string, barWidth int, dur time.Duration) string {
+	styles := []lipgloss.Style{
+		lipgloss.NewStyle().Foreground(theme.Dim),
+		lipgloss.NewStyle().Foreground(theme.Faint),
+		lipgloss.NewStyle().Foreground(theme.OutMarker),
+		lipgloss.NewStyle().Foreground(theme.Warn),
+	}
+	heat := t.bitrate.Window(dur, barWidth)
+	var b strings.Builder
+	for i := range line {
+		col := i - 1
+		switch {
+		case i == posIdx:
+			b.WriteString(head)
+		case col < 0 || col >= len(heat):
+			b.WriteRune(' ')
+		default:
+			level := min(len(heatLevels)-1, int(heat[col]*float64(len(heatLevels))))
+			style := styles[min(len(styles)-1, int(heat[col]*float64(len(styles))))]
+			b.WriteString(style.Render(string(heatLevels[level])))
+		}
+	}
+	return b.String()
+}
+
+// buildWaveformLane draws the audio of the whole file in line with the
+// progress bar, brighter inside the selection. Silence stays a flat "▁"
+// so pauses between sentences stand out as gaps.
+func (t *Timeline) buildWaveformLane(barWidth int, dur time.Duration, trim *video.TrimState) string {
+	if t.peaks == nil || dur <= 0 {
+		return repeat(" ", barWidth+2)
+	}
+
+	selStyle := lipgloss.NewStyle().Foreground(theme.Text)
+	waveStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	levels := waveformLevels[1:]
+
+	// Resampling a long file every frame is wasted work; only a resize
+	// changes the columns
+	if len(t.lane) != barWidth {
+		t.lane = t.peaks.Window(0, dur, barWidth)
+	}
+	var b strings.Builder
+	b.WriteString(" ")
+	for i, v := range t.lane {
+		if v < 0 || t.peaks.Loudest <= 0 {
+			b.WriteString(" ")
+			continue
+		}
+		level := int(v/t.peaks.Loudest*float32(len(levels)-1) + 0.5)
+		style := waveStyle
+		mid := time.Duration((float64(i) + 0.5) / float64(barWidth) * float64(dur))
+		if trim.IsComplete() && mid >= *trim.InPoint && mid < *trim.OutPoint {
+			style = selStyle
+		}
+		b.WriteString(style.Render(string(levels[level])))
+	}
+	b.WriteString(" ")
+	return b.String()
+}
+
+// waveformSpan is how far either side of the playhead the zoomed waveform
+// strip reaches.
+const waveformSpan = 2 * time.Second
+
+var waveformLevels = []rune(" ▁▂▃▄▅▆▇█")
+
+// buildWaveformStrip draws the audio around the playhead, ±waveformSpan,
+// with the playhead in the middle column and in/out points in their colors
+// so a cut can be placed on a word boundary or beat.
+func (t *Timeline) buildWaveformStrip(width int, pos time.Duration, trim *video.TrimState) string {
+	if t.peaks == nil || width <= 0 {
+		return repeat(" ", width)
+	}
+
+	headStyle := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	if place := t.playheadPlace(pos, trim); place == playheadOnIn || place == playheadOnOut {
+		headStyle = playheadStyle(place)
+	}
+	inStyle := lipgloss.NewStyle().Foreground(theme.InMarker).Bold(true)
+	outStyle := lipgloss.NewStyle().Foreground(theme.OutMarker).Bold(true)
+	waveStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+
+	center := width / 2
+	// Each column covers the same slice of time on both sides of center
+	perColumn := 2 * waveformSpan / time.Duration(width)
+	from := pos - time.Duration(center)*perColumn
+	values := t.peaks.Window(from, from+time.Duration(width)*perColumn, width)
+
+	column := func(at time.Duration) int {
+		if at < from {
+			return -1
+		}
+		return int((at - from) / perColumn)
+	}
+	inCol, outCol := -1, -1
+	if trim.InPoint != nil {
+		inCol = column(*trim.InPoint)
+	}
+	if trim.OutPoint != nil {
+		outCol = column(*trim.OutPoint)
+	}
 
-	return string(line)
+	var b strings.Builder
+	for i, v := range values {
+		glyph := " "
+		if v >= 0 && t.peaks.Loudest > 0 {
+			level := int(v/t.peaks.Loudest*float32(len(waveformLevels)-1) + 0.5)
+			glyph = string(waveformLevels[level])
+		}
+		switch {
+		case i == center:
+			if glyph == " " {
+				glyph = "│"
+			}
+			b.WriteString(headStyle.Render(glyph))
+		case i == inCol:
+			b.WriteString(inStyle.Render("▏"))
+		case i == outCol:
+			b.WriteString(outStyle.Render("▕"))
+		default:
+			b.WriteString(waveStyle.Render(glyph))
+		}
+	}
+	return b.String()
+}
+
+// Level meter: the loudest sample of the last meterSpan, from meterFloor
+// dBFS (empty) to 0 dBFS (full).
+const (
+	meterSpan  = 400 * time.Millisecond
+	meterCells = 8
+	meterFloor = -48.0
+	// clipLevel is within 0.1dB of full scale, where samples are clipped
+	clipLevel = 0.989
+	// silenceLevel is -60dBFS
+	silenceLevel = 0.001
+)
+
+// buildLevelMeter draws a momentary peak meter for the audio at pos, red
+// when it clips and labelled when it is silent.
+func (t *Timeline) buildLevelMeter(pos time.Duration) string {
+	if t.peaks == nil {
+		return ""
+	}
+	level := t.peaks.Level(pos, meterSpan)
+	if level < 0 {
+		return ""
+	}
+
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	if level < silenceLevel {
+		return dimStyle.Render(repeat("▯", meterCells) + " silence")
+	}
+	db := 20 * math.Log10(float64(level))
+	filled := int(math.Round((db - meterFloor) / -meterFloor * meterCells))
+	filled = max(0, min(meterCells, filled))
+
+	barStyle := lipgloss.NewStyle().Foreground(theme.Good)
+	label := fmt.Sprintf(" %3.0fdB", db)
+	if level >= clipLevel {
+		barStyle = lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+		label = " CLIP"
+	}
+	return barStyle.Render(repeat("▮", filled)) + dimStyle.Render(repeat("▯", meterCells-filled)) +
+		barStyle.Render(label)
+}
+
+// clipLines joins lines, each truncated to width so an overlong status
+// (a long or wide-character filename) cannot wrap and push the timeline
+// out of its fixed height.
+func clipLines(width int, lines ...string) string {
+	for i, line := range lines {
+		lines[i] = Truncate(line, width)
+	}
+	return strings.Join(lines, "\n")
 }
 
 func formatDuration(d time.Duration) string {
@@ -183,10 +714,10 @@ func (t *Timeline) buildFooterHelp(width int) string {
 	trim := &t.player.Trim
 
 	// Modern, minimal styling - subtle grays with one accent
-	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)
-	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-	accentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75")).Bold(true)
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Text).Bold(true)
+	descStyle := lipgloss.NewStyle().Foreground(theme.Muted)
+	accentStyle := lipgloss.NewStyle().Foreground(theme.Accent).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	// Helper to format key-desc pairs
 	kd := func(key, desc string, accent bool) string {
@@ -226,5 +757,9 @@ func (t *Timeline) buildFooterHelp(width int) string {
 			kd("?", "help", false)
 	}
 
+	if t.jobStatus != "" {
+		// Exports running behind the editor stay in view
+		result = " " + accentStyle.Render(t.jobStatus) + sep + strings.TrimPrefix(result, " ")
+	}
 	return result
 }
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"lazycut/video"
+	"time"
+)
+
+// togglePoster marks the frame under the playhead as the thumbnail of the
+// exports, or clears the mark when it is already there.
+func (m *Model) togglePoster() {
+	pos := m.player.Position()
+	if m.poster != nil && *m.poster == pos {
+		m.setPoster(nil)
+		m.exportStatus = "Poster frame cleared"
+		return
+	}
+	m.setPoster(&pos)
+	m.exportStatus = "Poster frame: " + video.FormatTimecode(pos) + " (embedded in MP4/MOV exports, .jpg otherwise)"
+}
+
+func (m *Model) setPoster(poster *time.Duration) {
+	m.poster = poster
+	m.properties.SetPoster(poster)
+	m.timeline.SetPoster(poster)
+}
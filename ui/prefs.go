@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"encoding/json"
+	"lazycut/video"
+	"os"
+	"path/filepath"
+)
+
+// filePrefs is the preview quality and mute state remembered for a file.
+type filePrefs struct {
+	Quality video.QualityPreset `json:"quality"`
+	Muted   bool                `json:"muted"`
+}
+
+// playerPrefs is the prefs file: the settings of each file opened before,
+// and the last choice, which files opened for the first time start with.
+type playerPrefs struct {
+	Last  filePrefs            `json:"last"`
+	Files map[string]filePrefs `json:"files,omitempty"`
+}
+
+func prefsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lazycut", "prefs.json"), nil
+}
+
+func loadPrefs() playerPrefs {
+	prefs := playerPrefs{Last: filePrefs{Quality: video.DefaultPlayerPrefs().Quality}}
+	path, err := prefsPath()
+	if err != nil {
+		return prefs
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return prefs
+	}
+	// A damaged file falls back to the defaults rather than failing startup
+	_ = json.Unmarshal(data, &prefs)
+	return prefs
+}
+
+// LoadPlayerPrefs returns the quality and mute state videoPath was last
+// viewed with. A file not opened before starts with the config's quality
+// and mute, or else the most recent choice.
+func LoadPlayerPrefs(videoPath string, cfg Config) video.PlayerPrefs {
+	prefs := loadPrefs()
+	p := prefs.Last
+	if cfg.Quality != nil {
+		p.Quality = *cfg.Quality
+	}
+	if cfg.Muted != nil {
+		p.Muted = *cfg.Muted
+	}
+	if abs, err := filepath.Abs(videoPath); err == nil {
+		if fp, ok := prefs.Files[abs]; ok {
+			p = fp
+		}
+	}
+	return video.PlayerPrefs{Quality: p.Quality, Muted: p.Muted}
+}
+
+// savePlayerPrefs remembers the current quality and mute state for this
+// file and as the choice for new ones. Entries of files that are gone are
+// dropped on the way.
+func (m Model) savePlayerPrefs() error {
+	path, err := prefsPath()
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(m.player.Path())
+	if err != nil {
+		return err
+	}
+	prefs := loadPrefs()
+	current := filePrefs{Quality: m.player.Quality(), Muted: m.player.IsMuted()}
+	files := map[string]filePrefs{abs: current}
+	for f, p := range prefs.Files {
+		if _, err := os.Stat(f); err == nil && f != abs {
+			files[f] = p
+		}
+	}
+	prefs.Last, prefs.Files = current, files
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(prefs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"fmt"
+	"hash/fnv"
+	"lazycut/video"
+	"time"
+)
+
+// PreviewEnd selects what happens when selection preview reaches the
+// out-point.
+type PreviewEnd int
+
+const (
+	PreviewStop     PreviewEnd = iota // pause and return to the in-point
+	PreviewLoop                       // jump back to the in-point and keep playing
+	PreviewContinue                   // keep playing past the out-point
+)
+
+func (e PreviewEnd) String() string {
+	switch e {
+	case PreviewStop:
+		return "stop"
+	case PreviewLoop:
+		return "loop"
+	case PreviewContinue:
+		return "continue"
+	}
+	return "unknown"
+}
+
+func (e PreviewEnd) Next() PreviewEnd {
+	return (e + 1) % 3
+}
+
+// ParsePreviewEnd parses the --preview-end flag value.
+func ParsePreviewEnd(s string) (PreviewEnd, error) {
+	for e := PreviewStop; e <= PreviewContinue; e++ {
+		if e.String() == s {
+			return e, nil
+		}
+	}
+	return PreviewStop, fmt.Errorf("invalid preview end %q (want stop, loop or continue)", s)
+}
+
+// warmKey identifies the trim points and quality last warmed.
+type warmKey struct {
+	in, out *time.Duration
+	quality video.QualityPreset
+}
+
+func (k warmKey) same(o warmKey) bool {
+	same := func(a, b *time.Duration) bool {
+		return (a == nil) == (b == nil) && (a == nil || *a == *b)
+	}
+	return same(k.in, o.in) && same(k.out, o.out) && k.quality == o.quality
+}
+
+// warmTrimFrames renders the frames around the in- and out-points ahead
+// whenever they or the quality change, so checking the cut points by
+// jumping between them does not wait for ffmpeg.
+func (m *Model) warmTrimFrames() {
+	trim := m.player.Trim
+	key := warmKey{in: trim.InPoint, out: trim.OutPoint, quality: m.player.Quality()}
+	if key.same(m.warmed) {
+		return
+	}
+	var points []time.Duration
+	for _, p := range []*time.Duration{trim.InPoint, trim.OutPoint} {
+		if p != nil {
+			points = append(points, *p)
+		}
+	}
+	if m.player.WarmCache(points...) {
+		m.warmed = key
+	}
+}
+
+// overlaySixel draws a sixel preview frame over the cells the preview
+// panel left blank for it. It goes at the end of the last line, so it is
+// written after the rows it covers; a fingerprint of the rest of the view
+// changes that line, and so redraws the image, whenever bubbletea
+// rewrites any row under it.
+func (m Model) overlaySixel(view string, dims PanelDimensions) string {
+	frame, x, y, ok := m.preview.Sixel(dims.PreviewContentWidth, dims.PreviewContentHeight)
+	if !ok {
+		return view
+	}
+	sum := fnv.New32a()
+	sum.Write([]byte(view))
+	// Content starts inside the top border and the left border and padding
+	row, col := 2+y, 3+x
+	return view + fmt.Sprintf("\x1b_lazycut %08x\x1b\\\x1b7\x1b[%d;%dH%s\x1b8", sum.Sum32(), row, col, frame)
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"lazycut/ui/theme"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ExportResult records one successful export of the session.
+type ExportResult struct {
+	Output   string
+	In       time.Duration
+	Out      time.Duration
+	Duration time.Duration // of the output, after speed changes and freezes
+}
+
+// Exports returns the session's successful exports, oldest first.
+func (m Model) Exports() []ExportResult {
+	return m.exports
+}
+
+// QuitAfterExport reports whether the program ended because the user
+// asked to quit once the running export finished.
+func (m Model) QuitAfterExport() bool {
+	return m.quitAfterExport
+}
+
+// requestQuit quits right away unless that would kill a running export or
+// drop a selection that was never exported, in which case it asks first.
+func (m Model) requestQuit() (tea.Model, tea.Cmd) {
+	if m.exporting || m.hasUnexportedSelection() {
+		m.showQuitConfirm = true
+		return m, nil
+	}
+	return m.quit()
+}
+
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.autosave(true)
+	m.cancelExports()
+	m.player.Close()
+	return m, tea.Quit
+}
+
+func (m Model) handleQuitConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "q", "ctrl+c":
+		return m.quit()
+	case "w", "enter":
+		m.showQuitConfirm = false
+		// Nothing running: "wait" means export the selection first
+		if !m.exporting && m.player.Trim.IsComplete() {
+			m.openExportModal()
+		}
+	case "f":
+		if m.exporting {
+			m.showQuitConfirm = false
+			m.quitAfterExport = true
+		}
+	case "esc", "n":
+		m.showQuitConfirm = false
+	}
+	return m, nil
+}
+
+func (m Model) renderQuitConfirm() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	reason := "The selection has not been exported."
+	wait := "export first"
+	if m.exporting {
+		reason = "An export is still running and will be cancelled."
+		wait = "keep exporting"
+	}
+
+	choices := keyStyle.Render("y") + labelStyle.Render(" quit anyway   ") +
+		keyStyle.Render("w") + labelStyle.Render(" "+wait+"   ")
+	if m.exporting {
+		choices += keyStyle.Render("f") + labelStyle.Render(" quit when finished   ")
+	}
+	choices += keyStyle.Render("Esc") + labelStyle.Render(" cancel")
+
+	content := titleStyle.Render("Quit lazycut?") + "\n\n" +
+		labelStyle.Render(reason) + "\n\n" +
+		choices
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/video"
+	"math"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cropOffsetStep is how far one ←/→ press slides the crop window.
+const cropOffsetStep = 0.1
+
+// ReframeMsg delivers a suggested crop offset from a background analysis.
+type ReframeMsg struct {
+	Offset float64
+	Err    error
+}
+
+func suggestReframe(ctx context.Context, opts video.ExportOptions) tea.Cmd {
+	return func() tea.Msg {
+		offset, err := video.SuggestCropOffset(ctx, opts)
+		return ReframeMsg{Offset: offset, Err: err}
+	}
+}
+
+// cropsFrame reports whether the selected aspect crops, i.e. whether a
+// crop offset means anything.
+func (m Model) cropsFrame() bool {
+	return cropsAspect(m.exportAspectRatio)
+}
+
+// startReframe analyzes the selection for where to place the crop.
+func (m *Model) startReframe() tea.Cmd {
+	if !m.cropsFrame() {
+		m.exportStatus = "Reframe needs a cropping aspect ratio"
+		return nil
+	}
+	if !m.player.Trim.IsComplete() {
+		m.exportStatus = "Reframe analyzes the selection: set in and out points"
+		return nil
+	}
+	if m.reframing {
+		return nil
+	}
+	m.reframing = true
+	m.exportStatus = "Analyzing motion..."
+	return suggestReframe(m.ctx, m.exportOptions())
+}
+
+func (m *Model) adjustCropOffset(delta float64) {
+	// Round so stepping back and forth lands exactly on centered again
+	m.exportCropOffset = math.Round(max(-1, min(1, m.exportCropOffset+delta))*100) / 100
+	m.exportPreview, m.exportPreviewErr = "", ""
+}
+
+// formatCropOffset describes the crop position along the axis it slides
+// on: landscape-to-portrait crops move sideways, the reverse up and down.
+func (m Model) formatCropOffset(offset float64) string {
+	opt := video.AspectRatioOptions[m.exportAspectRatio]
+	props := m.player.Properties()
+	before, after := "left", "right"
+	if opt.W > 0 && props.Height > 0 && float64(props.Width)/float64(props.Height) <= float64(opt.W)/float64(opt.H) {
+		before, after = "up", "down"
+	}
+	switch {
+	case offset == 0:
+		return "centered"
+	case offset < 0:
+		return fmt.Sprintf("%.0f%% %s", -offset*100, before)
+	default:
+		return fmt.Sprintf("%.0f%% %s", offset*100, after)
+	}
+}
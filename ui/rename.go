@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"fmt"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// defaultRenameTemplate numbers the outputs after the input file.
+const defaultRenameTemplate = "{name}_{n}"
+
+// renameForm edits the names of the last segment batch's outputs (R),
+// one by one or all at once from a template.
+type renameForm struct {
+	names    []string // new base names, without directory or extension
+	cursor   int
+	template string
+	editing  bool // typing goes to the template, not the names
+	err      string
+}
+
+func (m *Model) openRenameForm() {
+	if len(m.lastBatch) == 0 {
+		m.exportStatus = "Nothing to rename: export segments with E first"
+		return
+	}
+	f := renameForm{template: defaultRenameTemplate}
+	for _, i := range m.lastBatch {
+		out := m.exports[i].Output
+		f.names = append(f.names, strings.TrimSuffix(filepath.Base(out), filepath.Ext(out)))
+	}
+	m.renameForm = f
+	m.showRenameForm = true
+}
+
+// applyTemplate names every output from the template. {name} is the
+// input's name, {n} the output's number in the batch and {in}/{out} its
+// range as file-safe timecodes.
+func (m *Model) applyTemplate() {
+	f := &m.renameForm
+	input := filepath.Base(m.player.Path())
+	input = strings.TrimSuffix(input, filepath.Ext(input))
+	digits := len(strconv.Itoa(len(f.names)))
+	for row, i := range m.lastBatch {
+		r := m.exports[i]
+		f.names[row] = strings.NewReplacer(
+			"{name}", input,
+			"{n}", fmt.Sprintf("%0*d", digits, row+1),
+			"{in}", video.FileTimecode(r.In),
+			"{out}", video.FileTimecode(r.Out),
+		).Replace(f.template)
+	}
+}
+
+// renameOutputs moves each output whose name changed. After a failure
+// the ones already renamed stay renamed, and the form stays open to
+// show why.
+func (m *Model) renameOutputs() tea.Cmd {
+	f := &m.renameForm
+	seen := map[string]bool{}
+	for _, name := range f.names {
+		switch {
+		case strings.TrimSpace(name) == "":
+			f.err = "names cannot be empty"
+			return nil
+		case strings.ContainsAny(name, `/\`):
+			f.err = fmt.Sprintf("%q: names cannot contain / or \\", name)
+			return nil
+		case seen[name]:
+			f.err = fmt.Sprintf("%q is used twice", name)
+			return nil
+		}
+		seen[name] = true
+	}
+
+	renamed := 0
+	for row, i := range m.lastBatch {
+		old := m.exports[i].Output
+		output := filepath.Join(filepath.Dir(old), f.names[row]+filepath.Ext(old))
+		if output == old {
+			continue
+		}
+		if err := video.RenameExport(old, output); err != nil {
+			f.err = err.Error()
+			if renamed > 0 {
+				f.err = fmt.Sprintf("renamed %d, then: %s", renamed, err)
+			}
+			return nil
+		}
+		m.exports[i].Output = output
+		renamed++
+	}
+	m.showRenameForm = false
+	m.exportStatus = fmt.Sprintf("Renamed %d of %d exports", renamed, len(m.lastBatch))
+	if renamed == 0 || m.manifestFormat == "" {
+		return nil
+	}
+	// The manifest lists the old names; write it again
+	if m.lastManifest != "" {
+		os.Remove(m.lastManifest)
+	}
+	batch := make([]ExportResult, len(m.lastBatch))
+	for row, i := range m.lastBatch {
+		batch[row] = m.exports[i]
+	}
+	return writeManifest(m.manifestFormat, m.player.Path(), batch)
+}
+
+func (m Model) handleRenameFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.renameForm
+	field := &f.names[f.cursor]
+	if f.editing {
+		field = &f.template
+	}
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.showRenameForm = false
+	case tea.KeyTab:
+		f.editing = !f.editing
+	case tea.KeyUp:
+		f.editing = false
+		f.cursor = max(0, f.cursor-1)
+	case tea.KeyDown:
+		f.editing = false
+		f.cursor = min(len(f.names)-1, f.cursor+1)
+	case tea.KeyEnter:
+		f.err = ""
+		if f.editing {
+			m.applyTemplate()
+			f.editing = false
+			return m, nil
+		}
+		return m, m.renameOutputs()
+	case tea.KeyBackspace:
+		if len(*field) > 0 {
+			*field = panels.DropLastRune(*field)
+		}
+	case tea.KeyCtrlU:
+		*field = ""
+	default:
+		*field += string(msg.Runes)
+	}
+	return m, nil
+}
+
+func (m Model) renderRenameForm() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(theme.Text)
+	accentStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	warnStyle := lipgloss.NewStyle().
+		Foreground(theme.Warn)
+
+	f := m.renameForm
+	listHeight := max(3, m.height-16)
+	top := max(0, f.cursor-listHeight+1)
+
+	var rows []string
+	for row := top; row < len(f.names) && row < top+listHeight; row++ {
+		ext := filepath.Ext(m.exports[m.lastBatch[row]].Output)
+		name := panels.TruncateStart(f.names[row], 55-len(ext))
+		if row == f.cursor && !f.editing {
+			rows = append(rows, accentStyle.Render("> ")+valueStyle.Render(name)+dimStyle.Render("_"+ext))
+		} else {
+			rows = append(rows, "  "+labelStyle.Render(name)+dimStyle.Render(ext))
+		}
+	}
+
+	template := labelStyle.Render("Template ") + valueStyle.Render(panels.TruncateStart(f.template, 44))
+	if f.editing {
+		template = accentStyle.Render("Template ") + valueStyle.Render(panels.TruncateStart(f.template, 44)) + dimStyle.Render("_")
+	}
+
+	footer := keyStyle.Render("↑↓") + labelStyle.Render(" choose  ") +
+		keyStyle.Render("Tab") + labelStyle.Render(" template  ") +
+		keyStyle.Render("Enter") + labelStyle.Render(" rename  ") +
+		keyStyle.Render("Esc") + labelStyle.Render(" keep names")
+
+	lines := []string{
+		titleStyle.Render(fmt.Sprintf("Rename %d exports", len(f.names))),
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		template,
+		dimStyle.Render("{name} {n} {in} {out}; Enter in the template fills every name"),
+	}
+	if f.err != "" {
+		lines = append(lines, "", warnStyle.Render(panels.Wrap(f.err, 60)))
+	}
+	lines = append(lines, "", footer)
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Width(67).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
@@ -0,0 +1,387 @@
+package ui
+
+import (
+	"fmt"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Segment is a saved selection with its own export settings, so one range
+// can go out as 16:9 for one platform and 9:16 for another.
+type Segment struct {
+	In         time.Duration `json:"in"`
+	Out        time.Duration `json:"out"`
+	Aspect     int           `json:"aspect"` // index into video.AspectRatioOptions
+	CropOffset float64       `json:"crop_offset,omitempty"`
+	Preset     string        `json:"preset,omitempty"` // video.Presets name, if made from one
+	Note       string        `json:"note,omitempty"`
+	Speed      float64       `json:"speed,omitempty"` // 0 follows the export modal's Speed
+}
+
+// segmentSpeed is the speed seg exports at.
+func (m Model) segmentSpeed(seg Segment) float64 {
+	if seg.Speed > 0 {
+		return seg.Speed
+	}
+	return speedSteps[m.exportSpeed]
+}
+
+// stepSegmentSpeed moves the highlighted segment to the next slower or
+// faster of speedSteps.
+func (m *Model) stepSegmentSpeed(delta int) {
+	seg := &m.segments[m.segmentCursor]
+	i := normalSpeed
+	for j, speed := range speedSteps {
+		if speed == m.segmentSpeed(*seg) {
+			i = j
+		}
+	}
+	seg.Speed = speedSteps[max(0, min(len(speedSteps)-1, i+delta))]
+}
+
+// addSegment stores the current selection with the current export aspect.
+func (m *Model) addSegment() {
+	if err := m.player.Trim.Validate(m.player.MinSelection()); err != nil {
+		m.exportStatus = "Cannot add segment: " + err.Error()
+		return
+	}
+	aspect := m.exportAspectRatio
+	if !m.aspectChosen {
+		aspect = m.defaultAspectIndex()
+	}
+	m.segments = append(m.segments, Segment{
+		In:         *m.player.Trim.InPoint,
+		Out:        *m.player.Trim.OutPoint,
+		Aspect:     aspect,
+		CropOffset: m.exportCropOffset,
+		Note:       m.note,
+	})
+	m.segmentCursor = len(m.segments) - 1
+	m.exportStatus = fmt.Sprintf("Added segment %d (S to edit, E to export all)", len(m.segments))
+}
+
+// addCuts stores imported cuts as segments, trimmed to the video; cuts
+// starting past its end or shorter than a frame are left out.
+func (m *Model) addCuts(cuts []video.Cut) {
+	if len(cuts) == 0 {
+		return
+	}
+	added := 0
+	for _, cut := range cuts {
+		out := min(cut.Out, m.player.Duration())
+		if out-cut.In < m.player.MinSelection() {
+			continue
+		}
+		m.segments = append(m.segments, Segment{In: cut.In, Out: out, Aspect: m.defaultAspectIndex(), Note: cut.Note})
+		added++
+	}
+	m.exportStatus = fmt.Sprintf("Added %d segments from the cut list (S to edit, E to export all)", added)
+	if skipped := len(cuts) - added; skipped > 0 {
+		m.exportStatus += fmt.Sprintf(", %d outside the video left out", skipped)
+	}
+}
+
+// duplicateWithNextPreset queues in-out again with the platform preset
+// after the last one used for that range, so repeated presses produce
+// YouTube, Shorts, Instagram... versions of one clip.
+func (m *Model) duplicateWithNextPreset(in, out time.Duration) {
+	next := 0
+	note := m.note
+	for _, seg := range m.segments {
+		if seg.In != in || seg.Out != out {
+			continue
+		}
+		note = seg.Note
+		for i, p := range video.Presets {
+			if p.Name == seg.Preset {
+				next = (i + 1) % len(video.Presets)
+			}
+		}
+	}
+	preset := video.Presets[next]
+	aspect := video.AspectRatioIndex(preset.Aspect)
+	if !m.canCrop {
+		aspect = 0
+	}
+	m.segments = append(m.segments, Segment{In: in, Out: out, Aspect: aspect, Preset: preset.Name, Note: note})
+	m.segmentCursor = len(m.segments) - 1
+	m.exportStatus = fmt.Sprintf("Queued %s version as segment %d (E to export all)", preset.Name, len(m.segments))
+}
+
+// duplicateSelection is duplicateWithNextPreset for the current selection.
+func (m *Model) duplicateSelection() {
+	if err := m.player.Trim.Validate(m.player.MinSelection()); err != nil {
+		m.exportStatus = "Cannot duplicate: " + err.Error()
+		return
+	}
+	m.duplicateWithNextPreset(*m.player.Trim.InPoint, *m.player.Trim.OutPoint)
+}
+
+// segmentOptions builds the export options for one segment.
+func (m Model) segmentOptions(seg Segment) video.ExportOptions {
+	opts := video.DefaultExportOptions(m.player.Path(), m.player.Properties())
+	opts.OutputDir = m.outputDir
+	opts.SafeNames = m.safeNames
+	opts.TrashReplaced = m.trash
+	opts.InPoint = seg.In
+	opts.OutPoint = seg.Out
+	opts.AspectRatio = video.AspectRatioOptions[seg.Aspect].Ratio
+	opts.FastStart = m.exportFastStart
+	opts.SmartCut = m.exportSmartCut
+	opts.Container = video.ContainerOptions[m.exportContainer].Container
+	opts.Stabilize = m.exportStabilize
+	opts.Speed = m.segmentSpeed(seg)
+	opts.Note = seg.Note
+	opts.NoteSidecar = m.exportNoteSidecar
+	opts.LowPriority = m.exportLowPriority
+	opts.Encoder = m.exportEncoder
+	opts.CRF = crfSteps[m.exportCRF]
+	opts.Bitrate = bitrateSteps[m.exportBitrate]
+	opts.Animation = animationSteps[m.exportAnimation]
+	if m.exportCaptions {
+		opts.Captions = m.transcript
+	}
+	opts.Music = m.musicBed()
+	opts.Intro, opts.Outro = m.bumpers()
+	opts.TitleCard = m.titleCard(seg.Note)
+	// One poster serves the segment it falls in
+	if m.poster != nil && *m.poster >= seg.In && *m.poster <= seg.Out {
+		opts.Poster = m.poster
+		opts.PosterSidecar = m.exportPosterSidecar
+	}
+	if cropsAspect(seg.Aspect) {
+		opts.CropOffset = seg.CropOffset
+	}
+	return opts
+}
+
+// queueSegments exports every segment, up to exportWorkers at a time.
+func (m *Model) queueSegments() tea.Cmd {
+	if len(m.segments) == 0 {
+		m.exportStatus = "No segments: press a to add the selection as one"
+		return nil
+	}
+	if m.exporting {
+		m.exportStatus = "An export is already running"
+		return nil
+	}
+	m.exportQueue = m.exportQueue[:0]
+	for _, seg := range m.segments {
+		m.exportQueue = append(m.exportQueue, m.segmentOptions(seg))
+	}
+	m.queueTotal = len(m.exportQueue)
+	m.queueStart, m.queueFailed, m.queueErr = len(m.exports), 0, ""
+	m.queueStats, _ = video.LoadStats()
+	m.exportFallback = ""
+	m.showSegments = false
+	m.showExportModal = true
+	return m.startNextQueued()
+}
+
+// crossfadeSteps are the selectable crossfades between reel segments;
+// index 0 is a hard cut.
+var crossfadeSteps = []time.Duration{0, 250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2 * time.Second}
+
+// exportReel joins every segment, in order and each at its own speed, into
+// one video framed like the first segment.
+func (m *Model) exportReel() tea.Cmd {
+	if len(m.segments) < 2 {
+		m.exportStatus = "A reel needs at least two segments: press a to add the selection as one"
+		return nil
+	}
+	if m.exporting {
+		m.exportStatus = "An export is already running"
+		return nil
+	}
+	m.exportFallback = ""
+	m.showSegments = false
+	m.showExportModal = true
+	stats, _ := video.LoadStats()
+	return m.startExport(m.reelOptions(), stats)
+}
+
+// reelOptions are the export options of the reel of all segments; there
+// must be at least one.
+func (m Model) reelOptions() video.ExportOptions {
+	first := m.segments[0]
+	opts := m.segmentOptions(first)
+	// Stabilization analyzes one range, and the poster and captions
+	// belong to the source timeline
+	opts.Stabilize = false
+	opts.Poster, opts.Captions = nil, nil
+	opts.Note = m.note
+	opts.TitleCard = m.titleCard(m.note)
+	opts.FreezeStart = freezeSteps[m.exportFreezeIn]
+	opts.FreezeEnd = freezeSteps[m.exportFreezeOut]
+	opts.Speed = 0
+	for _, seg := range m.segments {
+		opts.Parts = append(opts.Parts, video.ReelPart{In: seg.In, Out: seg.Out, Speed: m.segmentSpeed(seg)})
+	}
+	opts.Crossfade = crossfadeSteps[m.reelCrossfade]
+	opts.InPoint = first.In
+	opts.OutPoint = m.segments[len(m.segments)-1].Out
+	return opts
+}
+
+func (m Model) handleSegmentsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(m.segments)
+	switch msg.String() {
+	case "esc", "S", "q":
+		m.showSegments = false
+	case "j", "down":
+		if m.segmentCursor < n-1 {
+			m.segmentCursor++
+		}
+	case "k", "up":
+		if m.segmentCursor > 0 {
+			m.segmentCursor--
+		}
+	case "h", "left", "l", "right":
+		if n == 0 {
+			return m, nil
+		}
+		delta := 1
+		if s := msg.String(); s == "h" || s == "left" {
+			delta = -1
+		}
+		seg := &m.segments[m.segmentCursor]
+		seg.Aspect = m.nextAspect(seg.Aspect, delta)
+		seg.Preset = ""
+	case "enter":
+		// Load the segment back into the selection for editing
+		if n == 0 {
+			return m, nil
+		}
+		seg := m.segments[m.segmentCursor]
+		in, out := seg.In, seg.Out
+		if m.acceptTrim(video.TrimState{InPoint: &in, OutPoint: &out}) {
+			m.player.Seek(in)
+			m.showSegments = false
+		}
+	case "x", "d", "delete":
+		if n == 0 {
+			return m, nil
+		}
+		m.segments = append(m.segments[:m.segmentCursor], m.segments[m.segmentCursor+1:]...)
+		m.segmentCursor = max(0, min(m.segmentCursor, len(m.segments)-1))
+	case "n":
+		if n > 0 {
+			m.openNoteForm(m.segmentCursor)
+		}
+	case "D":
+		if n > 0 {
+			seg := m.segments[m.segmentCursor]
+			m.duplicateWithNextPreset(seg.In, seg.Out)
+		}
+	case "-", "+", "=":
+		if n > 0 {
+			delta := 1
+			if msg.String() == "-" {
+				delta = -1
+			}
+			m.stepSegmentSpeed(delta)
+		}
+	case "E":
+		return m, m.queueSegments()
+	case "c":
+		m.reelCrossfade = (m.reelCrossfade + 1) % len(crossfadeSteps)
+	case "J":
+		return m, m.exportReel()
+	}
+	return m, nil
+}
+
+func (m Model) renderSegments() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(theme.Text)
+	accentStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	var rows []string
+	for i, seg := range m.segments {
+		aspect := video.AspectRatioOptions[seg.Aspect].Label
+		if seg.Preset != "" {
+			aspect = seg.Preset + " " + aspect
+		}
+		if cropsAspect(seg.Aspect) && seg.CropOffset != 0 {
+			aspect += fmt.Sprintf(" %+.0f%%", seg.CropOffset*100)
+		}
+		if speed := m.segmentSpeed(seg); speed != 1 {
+			aspect += " " + video.FormatSpeed(speed)
+		}
+		line := fmt.Sprintf("%2d  %s - %s  %7s  ", i+1,
+			video.FormatTimecode(seg.In), video.FormatTimecode(seg.Out),
+			formatDuration(seg.Out-seg.In))
+		if i == m.segmentCursor {
+			rows = append(rows, accentStyle.Render("> ")+valueStyle.Render(line)+accentStyle.Render("["+aspect+"]"))
+		} else {
+			rows = append(rows, "  "+labelStyle.Render(line)+dimStyle.Render(aspect))
+		}
+		if seg.Note != "" {
+			rows = append(rows, "      "+dimStyle.Render(panels.Truncate(seg.Note, 63)))
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, dimStyle.Render("  no segments yet: select a range and press a"))
+	}
+
+	footer := keyStyle.Render("←→") + labelStyle.Render(" aspect  ") +
+		keyStyle.Render("Enter") + labelStyle.Render(" edit  ") +
+		keyStyle.Render("n") + labelStyle.Render(" note  ") +
+		keyStyle.Render("-+") + labelStyle.Render(" speed  ") +
+		keyStyle.Render("D") + labelStyle.Render(" next preset  ") +
+		keyStyle.Render("x") + labelStyle.Render(" delete") + "\n" +
+		keyStyle.Render("E") + labelStyle.Render(" export all  ") +
+		keyStyle.Render("J") + labelStyle.Render(" join into one reel  ") +
+		keyStyle.Render("c") + labelStyle.Render(" crossfade  ") +
+		keyStyle.Render("Esc") + labelStyle.Render(" close")
+
+	reel := labelStyle.Render("Reel  ") + dimStyle.Render("J with two or more segments")
+	if len(m.segments) >= 2 {
+		opts := m.reelOptions()
+		reel = labelStyle.Render("Reel  ") + valueStyle.Render(formatDuration(opts.OutputDuration())) +
+			dimStyle.Render(fmt.Sprintf(" from %d segments, ", len(m.segments)))
+		if crossfade := crossfadeSteps[m.reelCrossfade]; crossfade > 0 {
+			reel += valueStyle.Render(formatFreeze(crossfade)+" crossfade") + dimStyle.Render(", re-encodes")
+		} else {
+			reel += dimStyle.Render("hard cuts, re-encodes")
+		}
+	}
+
+	content := titleStyle.Render("Segments") + "\n\n" +
+		strings.Join(rows, "\n") + "\n\n" +
+		reel + "\n\n" +
+		footer
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Width(75).
+		Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
+
+// formatDuration formats a segment length as seconds with millisecond
+// precision, e.g. "12.500s".
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"lazycut/video"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// session is the marking work on one video that survives a crash or an
+// accidental quit. It is saved as JSON in the user cache directory.
+type session struct {
+	Video      string         `json:"video"`
+	Saved      time.Time      `json:"saved"`
+	In         *time.Duration `json:"in,omitempty"`
+	Out        *time.Duration `json:"out,omitempty"`
+	Position   time.Duration  `json:"position"`
+	Aspect     string         `json:"aspect,omitempty"`
+	CropOffset float64        `json:"crop_offset,omitempty"`
+	Segments   []Segment      `json:"segments,omitempty"`
+	Note       string         `json:"note,omitempty"`
+	Poster     *time.Duration `json:"poster,omitempty"`
+}
+
+// AutosaveMsg fires the periodic session save.
+type AutosaveMsg struct{}
+
+func autosaveCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return AutosaveMsg{}
+	})
+}
+
+// sessionPath returns where the session for video is kept; the name is a
+// hash of the absolute path so renames of other files do not collide.
+func sessionPath(videoPath string) (string, error) {
+	abs, err := filepath.Abs(videoPath)
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(abs))
+	return filepath.Join(dir, "lazycut", "sessions", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+func loadSession(videoPath string) (session, bool) {
+	path, err := sessionPath(videoPath)
+	if err != nil {
+		return session{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return session{}, false
+	}
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return session{}, false
+	}
+	return s, true
+}
+
+// save writes the session atomically so a crash mid-write keeps the
+// previous copy.
+func (s session) save() error {
+	path, err := sessionPath(s.Video)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	s.Saved = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// sameWork reports whether two sessions hold the same marks, ignoring the
+// playhead and save time.
+func (s session) sameWork(o session) bool {
+	eq := func(a, b *time.Duration) bool {
+		return (a == nil) == (b == nil) && (a == nil || *a == *b)
+	}
+	return eq(s.In, o.In) && eq(s.Out, o.Out) &&
+		s.Aspect == o.Aspect && s.CropOffset == o.CropOffset && s.Note == o.Note && eq(s.Poster, o.Poster) &&
+		slices.Equal(s.Segments, o.Segments)
+}
+
+// currentSession snapshots the model's session state.
+func (m Model) currentSession() session {
+	s := session{Video: m.player.Path(), Position: m.player.Position()}
+	if in := m.player.Trim.InPoint; in != nil {
+		v := *in
+		s.In = &v
+	}
+	if out := m.player.Trim.OutPoint; out != nil {
+		v := *out
+		s.Out = &v
+	}
+	s.Segments = slices.Clone(m.segments)
+	s.Note = m.note
+	if m.poster != nil {
+		v := *m.poster
+		s.Poster = &v
+	}
+	if m.aspectChosen {
+		s.Aspect = video.AspectRatioOptions[m.exportAspectRatio].Label
+		s.CropOffset = m.exportCropOffset
+	}
+	return s
+}
+
+// trim returns the session's selection as a TrimState.
+func (s session) trim() video.TrimState {
+	return video.TrimState{InPoint: s.In, OutPoint: s.Out}
+}
+
+// restoreSession applies a saved session from an earlier run.
+func (m *Model) restoreSession(s session) {
+	if s.In == nil && s.Out == nil && s.Aspect == "" && len(s.Segments) == 0 && s.Note == "" && s.Poster == nil {
+		return
+	}
+	m.setNote(s.Note)
+	if s.Poster != nil && *s.Poster <= m.player.Duration() {
+		m.setPoster(s.Poster)
+	}
+	m.player.Trim = s.trim()
+	if s.Position > 0 && s.Position <= m.player.Duration() {
+		m.player.Seek(s.Position)
+	}
+	for i, opt := range video.AspectRatioOptions {
+		if opt.Label == s.Aspect {
+			m.exportAspectRatio = i
+			m.exportCropOffset = s.CropOffset
+			m.aspectChosen = true
+		}
+	}
+	for _, seg := range s.Segments {
+		if seg.Aspect >= 0 && seg.Aspect < len(video.AspectRatioOptions) && seg.Out > seg.In {
+			m.segments = append(m.segments, seg)
+		}
+	}
+	m.savedSession = s
+	m.exportStatus = "Restored session from " + s.Saved.Format("Jan 2 15:04") + " (u to undo)"
+	m.undoStack = append(m.undoStack, trimSnapshot{})
+}
+
+// autosave writes the session if it changed since the last save. Marks
+// are saved right away; a moved playhead waits for the timer (force).
+func (m *Model) autosave(force bool) {
+	if m.autosaveInterval <= 0 {
+		return
+	}
+	s := m.currentSession()
+	if s.sameWork(m.savedSession) && (!force || s.Position == m.savedSession.Position) {
+		return
+	}
+	if err := s.save(); err == nil {
+		m.savedSession = s
+	}
+}
+
+// hasUnexportedSelection reports whether quitting would lose a selection
+// that was never exported.
+func (m Model) hasUnexportedSelection() bool {
+	t := m.player.Trim
+	if !t.IsComplete() {
+		return false
+	}
+	e := m.exportedTrim
+	return e.InPoint == nil || *e.InPoint != *t.InPoint || *e.OutPoint != *t.OutPoint
+}
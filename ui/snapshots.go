@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Thumbnail size in the snapshots gallery, in cells: about 16:9 with
+// cells twice as tall as wide.
+const (
+	snapshotWidth  = 24
+	snapshotHeight = 7
+)
+
+// snapshot is a frame bookmarked with s, to hold candidate poster or
+// start frames side by side.
+type snapshot struct {
+	at    time.Duration
+	thumb string // "" while rendering
+	err   string // why the thumbnail could not be rendered
+}
+
+// SnapshotRenderedMsg delivers the thumbnail of the snapshot at At.
+type SnapshotRenderedMsg struct {
+	At    time.Duration
+	Thumb string
+	Err   error
+}
+
+// SnapshotSavedMsg reports a snapshot written out as PNG.
+type SnapshotSavedMsg struct {
+	Path string
+	Err  error
+}
+
+func renderSnapshot(ctx context.Context, player *video.Player, at time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		thumb, err := player.RenderThumbnail(ctx, at, snapshotWidth, snapshotHeight)
+		return SnapshotRenderedMsg{At: at, Thumb: thumb, Err: err}
+	}
+}
+
+func saveSnapshot(ctx context.Context, path string, at time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		png, err := video.SaveSnapshot(ctx, path, at)
+		return SnapshotSavedMsg{Path: png, Err: err}
+	}
+}
+
+// takeSnapshot bookmarks the frame under the playhead and renders its
+// thumbnail in the background.
+func (m *Model) takeSnapshot() tea.Cmd {
+	pos := m.player.Position()
+	for i, s := range m.snapshots {
+		if s.at == pos {
+			m.exportStatus = fmt.Sprintf("Already snapshot %d (g to open)", i+1)
+			return nil
+		}
+	}
+	m.snapshots = append(m.snapshots, snapshot{at: pos})
+	m.snapshotCursor = len(m.snapshots) - 1
+	m.exportStatus = fmt.Sprintf("Snapshot %d at %s (g to compare)", len(m.snapshots), video.FormatTimecode(pos))
+	return renderSnapshot(m.ctx, m.player, pos)
+}
+
+func (m *Model) openSnapshots() {
+	if len(m.snapshots) == 0 {
+		m.exportStatus = "No snapshots: press s on a frame to keep it"
+		return
+	}
+	m.showSnapshots = true
+}
+
+// setSnapshotThumb stores a rendered thumbnail; the snapshot may have
+// been deleted while it rendered.
+func (m *Model) setSnapshotThumb(msg SnapshotRenderedMsg) {
+	for i := range m.snapshots {
+		if m.snapshots[i].at != msg.At {
+			continue
+		}
+		if msg.Err != nil {
+			m.snapshots[i].err = "no preview: " + msg.Err.Error()
+		} else {
+			m.snapshots[i].thumb = msg.Thumb
+		}
+	}
+}
+
+func (m Model) handleSnapshotsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(m.snapshots)
+	if n == 0 {
+		m.showSnapshots = false
+		return m, nil
+	}
+	snap := m.snapshots[m.snapshotCursor]
+	switch msg.String() {
+	case "esc", "g", "q":
+		m.showSnapshots = false
+	case "h", "left":
+		if m.snapshotCursor > 0 {
+			m.snapshotCursor--
+		}
+	case "l", "right":
+		if m.snapshotCursor < n-1 {
+			m.snapshotCursor++
+		}
+	case "enter":
+		m.player.Seek(snap.at)
+		m.showSnapshots = false
+	case "i":
+		candidate := m.player.Trim
+		candidate.SetIn(snap.at)
+		if m.acceptTrim(candidate) {
+			m.announce("In-point set to %s", formatTimestamp(snap.at))
+		}
+	case "F":
+		at := snap.at
+		m.setPoster(&at)
+		m.exportStatus = "Poster frame: " + video.FormatTimecode(at)
+	case "p":
+		m.exportStatus = "Saving " + video.FormatTimecode(snap.at) + " as PNG..."
+		return m, saveSnapshot(m.ctx, m.player.Path(), snap.at)
+	case "x", "d", "delete":
+		m.snapshots = append(m.snapshots[:m.snapshotCursor], m.snapshots[m.snapshotCursor+1:]...)
+		m.snapshotCursor = max(0, min(m.snapshotCursor, len(m.snapshots)-1))
+		if len(m.snapshots) == 0 {
+			m.showSnapshots = false
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderSnapshots() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	accentStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	// As many cards as fit, scrolled so the cursor stays visible
+	visible := max(1, (m.width-12)/(snapshotWidth+2))
+	first := max(0, m.snapshotCursor-visible+1)
+	last := min(len(m.snapshots), first+visible)
+
+	var cards []string
+	for i := first; i < last; i++ {
+		snap := m.snapshots[i]
+		var thumb string
+		switch {
+		case snap.err != "":
+			thumb = dimStyle.Render(panels.Wrap(snap.err, snapshotWidth))
+		case snap.thumb == "":
+			thumb = dimStyle.Render("rendering...")
+		default:
+			thumb = panels.ClipFrame(snap.thumb, snapshotWidth, snapshotHeight)
+		}
+		thumb = lipgloss.Place(snapshotWidth, snapshotHeight, lipgloss.Center, lipgloss.Center, thumb)
+
+		label := fmt.Sprintf("%d  %s", i+1, video.FormatTimecode(snap.at))
+		if m.poster != nil && *m.poster == snap.at {
+			label += " ◆"
+		}
+		border := theme.Border
+		if i == m.snapshotCursor {
+			border = theme.Accent
+			label = accentStyle.Render(label)
+		} else {
+			label = labelStyle.Render(label)
+		}
+		cards = append(cards, lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(border).
+			Render(thumb+"\n"+label))
+	}
+
+	position := dimStyle.Render(fmt.Sprintf("%d/%d", m.snapshotCursor+1, len(m.snapshots)))
+
+	footer := keyStyle.Render("←→") + labelStyle.Render(" choose  ") +
+		keyStyle.Render("Enter") + labelStyle.Render(" jump  ") +
+		keyStyle.Render("i") + labelStyle.Render(" in-point  ") +
+		keyStyle.Render("F") + labelStyle.Render(" poster  ") +
+		keyStyle.Render("p") + labelStyle.Render(" save PNG  ") +
+		keyStyle.Render("x") + labelStyle.Render(" delete  ") +
+		keyStyle.Render("Esc") + labelStyle.Render(" close")
+
+	content := titleStyle.Render("Snapshots") + "  " + position + "\n\n" +
+		lipgloss.JoinHorizontal(lipgloss.Top, cards...) + "\n\n" +
+		footer
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
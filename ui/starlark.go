@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"lazycut/video"
+)
+
+// maxScriptSteps stops a hook that loops forever instead of hanging the
+// editor.
+const maxScriptSteps = 1_000_000
+
+// scriptRun is the model a running Starlark hook acts on, kept in the
+// thread's locals.
+type scriptRun struct {
+	m     *Model
+	batch []tea.Cmd
+}
+
+const scriptRunKey = "lazycut.run"
+
+// scriptBuiltin is one function of the hooks API: fn acts on the model of
+// the hook that called it.
+type scriptBuiltin struct {
+	name string
+	fn   func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error)
+}
+
+// scriptAPI is what a Starlark hooks file can call from a bound function.
+// Times are seconds (int or float) or the strings ParseTimecode takes;
+// times returned are float seconds. Steps go through the same handlers
+// as the --cmd commands, so a refused in/out point fails the call.
+var scriptAPI = []scriptBuiltin{
+	{"position", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackArgs("position", args, kwargs); err != nil {
+			return nil, err
+		}
+		return seconds(run.m.player.Position()), nil
+	}},
+	{"duration", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackArgs("duration", args, kwargs); err != nil {
+			return nil, err
+		}
+		return seconds(run.m.player.Duration()), nil
+	}},
+	{"in_point", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackArgs("in_point", args, kwargs); err != nil {
+			return nil, err
+		}
+		return optionalSeconds(run.m.player.Trim.InPoint), nil
+	}},
+	{"out_point", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackArgs("out_point", args, kwargs); err != nil {
+			return nil, err
+		}
+		return optionalSeconds(run.m.player.Trim.OutPoint), nil
+	}},
+	{"segments", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackArgs("segments", args, kwargs); err != nil {
+			return nil, err
+		}
+		list := make([]starlark.Value, len(run.m.segments))
+		for i, seg := range run.m.segments {
+			list[i] = starlark.Tuple{seconds(seg.In), seconds(seg.Out)}
+		}
+		return starlark.NewList(list), nil
+	}},
+	{"seek", timeStep("seek", "seek", true)},
+	{"set_in", timeStep("set_in", "in", false)},
+	{"set_out", timeStep("set_out", "out", false)},
+	{"clear", plainStep("clear", "clear")},
+	{"play", plainStep("play", "play")},
+	{"pause", plainStep("pause", "pause")},
+	{"preview", plainStep("preview", "preview")},
+	{"mute", plainStep("mute", "mute")},
+	{"add_segment", plainStep("add_segment", "segment")},
+	{"export", plainStep("export", "export")},
+	{"preset", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var name string
+		if err := starlark.UnpackArgs("preset", args, kwargs, "name", &name); err != nil {
+			return nil, err
+		}
+		if _, ok := lookupAspect(name); !ok {
+			return nil, fmt.Errorf("preset: unknown preset %q (want %s or an aspect ratio)", name, presetNames())
+		}
+		return starlark.None, run.step("preset", Command{Name: "preset", Arg: name})
+	}},
+	{"run", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var script string
+		if err := starlark.UnpackArgs("run", args, kwargs, "script", &script); err != nil {
+			return nil, err
+		}
+		cmds, err := ParseCommands(script)
+		if err != nil {
+			return nil, fmt.Errorf("run: %w", err)
+		}
+		for _, c := range cmds {
+			if err := run.step("run: "+c.Name, c); err != nil {
+				return nil, err
+			}
+		}
+		return starlark.None, nil
+	}},
+	{"status", func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var text string
+		if err := starlark.UnpackArgs("status", args, kwargs, "text", &text); err != nil {
+			return nil, err
+		}
+		run.m.exportStatus = text
+		return starlark.None, nil
+	}},
+}
+
+// step runs c on the hook's model, failing with the reason it was refused.
+func (run *scriptRun) step(name string, c Command) error {
+	m, cmd, refused := run.m.runCommand(c)
+	*run.m = m
+	run.batch = append(run.batch, cmd)
+	if refused != "" {
+		return fmt.Errorf("%s: %s", name, refused)
+	}
+	return nil
+}
+
+// plainStep is an API function running the command without arguments.
+func plainStep(name, command string) func(*scriptRun, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackArgs(name, args, kwargs); err != nil {
+			return nil, err
+		}
+		return starlark.None, run.step(name, Command{Name: command})
+	}
+}
+
+// timeStep is an API function running a command that takes a time, which
+// is the playhead when left out unless required.
+func timeStep(name, command string, required bool) func(*scriptRun, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(run *scriptRun, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var t starlark.Value
+		spec := "t?"
+		if required {
+			spec = "t"
+		}
+		if err := starlark.UnpackArgs(name, args, kwargs, spec, &t); err != nil {
+			return nil, err
+		}
+		c := Command{Name: command}
+		if t != nil && t != starlark.None {
+			at, err := scriptTime(t)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			c.At = &at
+		}
+		return starlark.None, run.step(name, c)
+	}
+}
+
+// scriptTime reads a time argument: seconds, or a timecode string.
+func scriptTime(v starlark.Value) (time.Duration, error) {
+	switch v := v.(type) {
+	case starlark.Int, starlark.Float:
+		f, _ := starlark.AsFloat(v)
+		if f < 0 {
+			return 0, fmt.Errorf("negative time %s", v)
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	case starlark.String:
+		return video.ParseTimecode(string(v))
+	}
+	return 0, fmt.Errorf("want seconds or a timecode, got %s", v.Type())
+}
+
+func seconds(d time.Duration) starlark.Value {
+	return starlark.Float(d.Seconds())
+}
+
+func optionalSeconds(d *time.Duration) starlark.Value {
+	if d == nil {
+		return starlark.None
+	}
+	return seconds(*d)
+}
+
+// loadStarlarkHooks runs a Starlark hooks file, collecting its bind(key,
+// fn) calls. The API functions only work inside bound functions, when a
+// key runs them.
+func loadStarlarkHooks(path string, keys keymap) ([]Hook, error) {
+	var hooks []Hook
+	seen := map[string]bool{}
+	bind := starlark.NewBuiltin("bind", func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var key string
+		var fn starlark.Callable
+		if err := starlark.UnpackArgs("bind", args, kwargs, "key", &key, "fn", &fn); err != nil {
+			return nil, err
+		}
+		if err := checkHookKey(key, keys); err != nil {
+			return nil, err
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%s is bound twice", key)
+		}
+		seen[key] = true
+		hooks = append(hooks, Hook{Key: key, Script: fn.Name() + "()", fn: fn})
+		return starlark.None, nil
+	})
+
+	predeclared := starlark.StringDict{"bind": bind}
+	for _, api := range scriptAPI {
+		predeclared[api.name] = starlark.NewBuiltin(api.name, func(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			run, _ := thread.Local(scriptRunKey).(*scriptRun)
+			if run == nil {
+				return nil, fmt.Errorf("%s can only be called from a bound function", b.Name())
+			}
+			return api.fn(run, args, kwargs)
+		})
+	}
+
+	thread := &starlark.Thread{Name: "load " + path, Print: func(*starlark.Thread, string) {}}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	if _, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, path, nil, predeclared); err != nil {
+		return nil, scriptError(err)
+	}
+	return hooks, nil
+}
+
+// runStarlark calls a Starlark hook's function on m. An error stops the
+// hook where it happened, and the status line says why.
+func (m Model) runStarlark(origin string, fn starlark.Callable) (Model, tea.Cmd) {
+	m.inScript = true
+	run := &scriptRun{m: &m}
+	thread := &starlark.Thread{Name: origin, Print: func(_ *starlark.Thread, msg string) {
+		m.exportStatus = msg
+	}}
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+	thread.SetLocal(scriptRunKey, run)
+	if _, err := starlark.Call(thread, fn, nil, nil); err != nil {
+		m.exportStatus = origin + " stopped: " + scriptError(err).Error()
+	}
+	m.inScript = false
+	return m, tea.Batch(run.batch...)
+}
+
+// scriptError is err without Starlark's traceback, which does not fit a
+// status line, but with where in the hooks file it failed.
+func scriptError(err error) error {
+	evalErr, ok := err.(*starlark.EvalError)
+	if !ok {
+		return err
+	}
+	for i := range len(evalErr.CallStack) {
+		// The innermost frame is the builtin that failed, if one did
+		if pos := evalErr.CallStack.At(i).Pos; pos.Filename() != "<builtin>" {
+			return fmt.Errorf("%s: %s", filepath.Base(pos.Filename())+":"+strconv.Itoa(int(pos.Line)), evalErr.Msg)
+		}
+	}
+	return errors.New(evalErr.Msg)
+}
@@ -0,0 +1,107 @@
+package ui_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/ui"
+)
+
+// writeHooks writes a Starlark hooks file and loads it with the default
+// keys.
+func writeHooks(t *testing.T, src string) ([]ui.Hook, error) {
+	t.Helper()
+	return writeHooksConfig(t, src, ui.Config{})
+}
+
+func writeHooksConfig(t *testing.T, src string, cfg ui.Config) ([]ui.Hook, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hooks.star")
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return ui.LoadHooks(path, cfg)
+}
+
+func TestStarlarkHooks(t *testing.T) {
+	hooks, err := writeHooks(t, `
+def short():
+    set_in(2)
+    set_out(in_point() + 3.5)
+    status("selected %s-%s of %d" % (in_point(), out_point(), duration()))
+
+def refused():
+    set_in(8)
+    set_out("0:08.010")
+    status("not reached")
+
+bind("x", short)
+bind("ctrl+y", refused)
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 2 || hooks[0].Key != "x" || hooks[0].Script != "short()" {
+		t.Fatalf("hooks = %+v", hooks)
+	}
+
+	term, _ := openFake(t, ui.Options{Hooks: hooks}, 120, 40)
+	term.Press("x")
+	if err := term.WaitFor("selected 2.0-5.5 of 10", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := term.WaitFor("Out         00:05", time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	term.Press("ctrl+y")
+	if err := term.WaitFor("hook ctrl+y stopped: hooks.star:9: set_out:", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(term.View(), "not reached") {
+		t.Error("the hook went on after a refused out-point")
+	}
+}
+
+func TestStarlarkHooksErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"load time call", "seek(1)\n", "seek can only be called from a bound function"},
+		{"digit", "def f():\n    pass\nbind(\"5\", f)\n", "cannot bind 5"},
+		{"quit", "def f():\n    pass\nbind(\"q\", f)\n", "cannot bind q: it quits"},
+		{"ctrl+c", "def f():\n    pass\nbind(\"ctrl+c\", f)\n", "cannot bind ctrl+c"},
+		{"twice", "def f():\n    pass\nbind(\"x\", f)\nbind(\"x\", f)\n", "x is bound twice"},
+		{"not callable", "bind(\"x\", 1)\n", "bind: for parameter fn"},
+		{"syntax", "def f(:\n", "hooks.star:1:"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := writeHooks(t, tc.src)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %v, want it to contain %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestStarlarkHooksRemappedQuit(t *testing.T) {
+	cfg, err := loadConfig(t, "[keys]\nquit = \"x\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeHooksConfig(t, "def f():\n    pass\nbind(\"x\", f)\n", cfg); err == nil ||
+		!strings.Contains(err.Error(), "cannot bind x: it quits") {
+		t.Errorf("binding the remapped quit key: error %v", err)
+	}
+	// q is free once quit has moved
+	hooks, err := writeHooksConfig(t, "def f():\n    pass\nbind(\"q\", f)\n", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || hooks[0].Key != "q" {
+		t.Errorf("hooks = %+v, want one on q", hooks)
+	}
+}
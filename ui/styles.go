@@ -1,16 +1,22 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"lazycut/ui/theme"
 
-var (
-	// Panel border style
-	BorderStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("240")).
-			Padding(0, 1)
-
-	// Panel title style
-	TitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
+	"github.com/charmbracelet/lipgloss"
 )
 
+// BorderStyle is the panel border style. It is built per render, like
+// the other styles, so it follows the theme.
+func BorderStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(0, 1)
+}
+
+// TitleStyle is the panel title style.
+func TitleStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(theme.Dim)
+}
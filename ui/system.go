@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"context"
+	"lazycut/video"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SystemInfoMsg delivers the helper versions for the help modal's SYSTEM
+// section.
+type SystemInfoMsg struct {
+	Info video.SystemInfo
+}
+
+func loadSystemInfo(ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return SystemInfoMsg{Info: video.DetectSystemInfo(ctx)}
+	}
+}
@@ -0,0 +1,126 @@
+// Package theme holds the UI color palette shared by the ui and panels
+// packages.
+package theme
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// The palette is the dark theme until Set picks another. Each color
+// carries explicit fallbacks so 256- and 16-color terminals get a
+// deliberate choice instead of lipgloss' nearest-match guess, which tends to
+// collapse the dark grays into the background. With NO_COLOR set lipgloss
+// strips all of them.
+var (
+	Border    = lipgloss.CompleteColor{TrueColor: "#585858", ANSI256: "240", ANSI: "8"}
+	Text      = lipgloss.CompleteColor{TrueColor: "#d0d0d0", ANSI256: "252", ANSI: "15"}
+	Muted     = lipgloss.CompleteColor{TrueColor: "#8a8a8a", ANSI256: "245", ANSI: "7"}
+	Dim       = lipgloss.CompleteColor{TrueColor: "#585858", ANSI256: "240", ANSI: "8"}
+	Faint     = lipgloss.CompleteColor{TrueColor: "#767676", ANSI256: "243", ANSI: "8"}
+	Accent    = lipgloss.CompleteColor{TrueColor: "#5fafff", ANSI256: "75", ANSI: "12"}
+	Good      = lipgloss.CompleteColor{TrueColor: "#00ff00", ANSI256: "46", ANSI: "10"}
+	InMarker  = lipgloss.CompleteColor{TrueColor: "#00af00", ANSI256: "34", ANSI: "2"}
+	OutMarker = lipgloss.CompleteColor{TrueColor: "#d75f00", ANSI256: "166", ANSI: "3"}
+	Warn      = lipgloss.CompleteColor{TrueColor: "#ff5f5f", ANSI256: "203", ANSI: "9"}
+)
+
+// MarkerPalette is a choice of in/out marker colors.
+type MarkerPalette struct {
+	Name      string
+	InMarker  lipgloss.CompleteColor
+	OutMarker lipgloss.CompleteColor
+}
+
+// MarkerPalettes are the --palette choices. Blue and orange (from the
+// Okabe-Ito set) stay apart with red-green color blindness, magenta and
+// teal with blue-yellow; mono leaves telling in from out to the glyphs and
+// draws both in the theme's Text.
+var MarkerPalettes = []MarkerPalette{
+	{"default", InMarker, OutMarker},
+	{"okabe-ito", lipgloss.CompleteColor{TrueColor: "#0072b2", ANSI256: "25", ANSI: "4"},
+		lipgloss.CompleteColor{TrueColor: "#e69f00", ANSI256: "214", ANSI: "11"}},
+	{"tritan", lipgloss.CompleteColor{TrueColor: "#cc79a7", ANSI256: "175", ANSI: "13"},
+		lipgloss.CompleteColor{TrueColor: "#009e73", ANSI256: "36", ANSI: "6"}},
+	{"mono", lipgloss.CompleteColor{}, lipgloss.CompleteColor{}},
+}
+
+// MarkerGlyphs is how the in and out points are drawn on the marker line.
+type MarkerGlyphs struct {
+	Name    string
+	In, Out string
+}
+
+// MarkerGlyphSets are the --markers choices; all but arrows differ in
+// shape, not just color.
+var MarkerGlyphSets = []MarkerGlyphs{
+	{"arrows", "▼", "▼"},
+	{"letters", "I", "O"},
+	{"brackets", "[", "]"},
+	{"shapes", "▶", "◀"},
+}
+
+// InGlyph and OutGlyph mark the in and out points, see SetMarkers.
+var InGlyph, OutGlyph = "▼", "▼"
+
+// SetMarkers switches the in/out marker colors to the named palette and
+// the glyphs to the named set. An empty glyphs picks arrows, or letters
+// for the mono palette, whose arrows would look the same.
+func SetMarkers(palette, glyphs string) error {
+	var p *MarkerPalette
+	for i := range MarkerPalettes {
+		if MarkerPalettes[i].Name == palette {
+			p = &MarkerPalettes[i]
+		}
+	}
+	if p == nil {
+		var names []string
+		for _, p := range MarkerPalettes {
+			names = append(names, p.Name)
+		}
+		return fmt.Errorf("unknown palette %q (want %s)", palette, strings.Join(names, ", "))
+	}
+	if glyphs == "" {
+		glyphs = "arrows"
+		if p.Name == "mono" {
+			glyphs = "letters"
+		}
+	}
+	var g *MarkerGlyphs
+	for i := range MarkerGlyphSets {
+		if MarkerGlyphSets[i].Name == glyphs {
+			g = &MarkerGlyphSets[i]
+		}
+	}
+	if g == nil {
+		var names []string
+		for _, s := range MarkerGlyphSets {
+			names = append(names, s.Name)
+		}
+		return fmt.Errorf("unknown markers %q (want %s)", glyphs, strings.Join(names, ", "))
+	}
+	InMarker, OutMarker = p.InMarker, p.OutMarker
+	if p.Name == "mono" {
+		InMarker, OutMarker = Text, Text
+	}
+	InGlyph, OutGlyph = g.In, g.Out
+	return nil
+}
+
+// ChafaColors maps the detected terminal color profile to a chafa --colors
+// value, so frames never use more colors than the terminal can show.
+func ChafaColors() string {
+	switch lipgloss.ColorProfile() {
+	case termenv.TrueColor:
+		return "full"
+	case termenv.ANSI256:
+		return "256"
+	case termenv.ANSI:
+		return "16"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,118 @@
+package theme
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is a set of UI colors, see the palette variables for what each
+// one paints. The in/out markers are chosen apart with --palette.
+type Theme struct {
+	Name   string
+	Border lipgloss.CompleteColor
+	Text   lipgloss.CompleteColor
+	Muted  lipgloss.CompleteColor
+	Dim    lipgloss.CompleteColor
+	Faint  lipgloss.CompleteColor
+	Accent lipgloss.CompleteColor
+	Good   lipgloss.CompleteColor
+	Warn   lipgloss.CompleteColor
+}
+
+// Themes are the built-in themes; the first is the default. Light is for
+// terminals with a light background, where the default grays wash out;
+// contrast raises the dim grays for low-contrast screens and projectors.
+var Themes = []Theme{
+	{"dark", Border, Text, Muted, Dim, Faint, Accent, Good, Warn},
+	{"light",
+		lipgloss.CompleteColor{TrueColor: "#a8a8a8", ANSI256: "248", ANSI: "7"},
+		lipgloss.CompleteColor{TrueColor: "#262626", ANSI256: "235", ANSI: "0"},
+		lipgloss.CompleteColor{TrueColor: "#585858", ANSI256: "240", ANSI: "8"},
+		lipgloss.CompleteColor{TrueColor: "#8a8a8a", ANSI256: "245", ANSI: "8"},
+		lipgloss.CompleteColor{TrueColor: "#767676", ANSI256: "243", ANSI: "8"},
+		lipgloss.CompleteColor{TrueColor: "#005fd7", ANSI256: "26", ANSI: "4"},
+		lipgloss.CompleteColor{TrueColor: "#008700", ANSI256: "28", ANSI: "2"},
+		lipgloss.CompleteColor{TrueColor: "#d70000", ANSI256: "160", ANSI: "1"}},
+	{"contrast",
+		lipgloss.CompleteColor{TrueColor: "#bcbcbc", ANSI256: "250", ANSI: "7"},
+		lipgloss.CompleteColor{TrueColor: "#ffffff", ANSI256: "231", ANSI: "15"},
+		lipgloss.CompleteColor{TrueColor: "#d0d0d0", ANSI256: "252", ANSI: "15"},
+		lipgloss.CompleteColor{TrueColor: "#a8a8a8", ANSI256: "248", ANSI: "7"},
+		lipgloss.CompleteColor{TrueColor: "#b2b2b2", ANSI256: "249", ANSI: "7"},
+		lipgloss.CompleteColor{TrueColor: "#5fd7ff", ANSI256: "81", ANSI: "14"},
+		lipgloss.CompleteColor{TrueColor: "#00ff00", ANSI256: "46", ANSI: "10"},
+		lipgloss.CompleteColor{TrueColor: "#ff5f5f", ANSI256: "203", ANSI: "9"}},
+}
+
+// Lookup returns the built-in theme called name.
+func Lookup(name string) (Theme, error) {
+	var names []string
+	for _, t := range Themes {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(names, ", "))
+}
+
+// SetColor replaces the color called key, a lowercase field name such as
+// "accent", with value as read by ParseColor.
+func (t *Theme) SetColor(key, value string) error {
+	c, err := ParseColor(value)
+	if err != nil {
+		return err
+	}
+	switch key {
+	case "border":
+		t.Border = c
+	case "text":
+		t.Text = c
+	case "muted":
+		t.Muted = c
+	case "dim":
+		t.Dim = c
+	case "faint":
+		t.Faint = c
+	case "accent":
+		t.Accent = c
+	case "good":
+		t.Good = c
+	case "warn":
+		t.Warn = c
+	default:
+		return fmt.Errorf("unknown theme color %q (want border, text, muted, dim, faint, accent, good, warn)", key)
+	}
+	return nil
+}
+
+// ParseColor reads "#rrggbb" or a 256-color palette number, filling in the
+// fallbacks for terminals with fewer colors.
+func ParseColor(s string) (lipgloss.CompleteColor, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 255 {
+			return lipgloss.CompleteColor{}, fmt.Errorf("invalid color %s (want 0-255)", s)
+		}
+		c := termenv.ANSI256Color(n)
+		ansi := termenv.ANSI.Convert(c).(termenv.ANSIColor)
+		return lipgloss.CompleteColor{TrueColor: c.String(), ANSI256: s, ANSI: strconv.Itoa(int(ansi))}, nil
+	}
+	if len(s) != 7 || termenv.TrueColor.Color(s) == nil {
+		return lipgloss.CompleteColor{}, fmt.Errorf("invalid color %q (want #rrggbb or 0-255)", s)
+	}
+	c256 := termenv.ANSI256.Color(s).(termenv.ANSI256Color)
+	ansi := termenv.ANSI.Color(s).(termenv.ANSIColor)
+	return lipgloss.CompleteColor{TrueColor: s, ANSI256: strconv.Itoa(int(c256)), ANSI: strconv.Itoa(int(ansi))}, nil
+}
+
+// Set switches the UI to t. Styles are built from the palette as they
+// render, so it takes effect on the next frame; call it before SetMarkers,
+// whose mono palette follows the theme's text.
+func Set(t Theme) {
+	Border, Text, Muted, Dim, Faint = t.Border, t.Text, t.Muted, t.Dim, t.Faint
+	Accent, Good, Warn = t.Accent, t.Good, t.Warn
+}
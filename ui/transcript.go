@@ -0,0 +1,279 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TranscriptLoadedMsg delivers cues loaded in the background at startup.
+type TranscriptLoadedMsg struct {
+	Cues []video.Cue
+	Err  error
+}
+
+// transcriptView is the state of the searchable transcript panel.
+type transcriptView struct {
+	query     string
+	searching bool
+	cursor    int // index into the filtered cue list
+}
+
+// TranscribeDoneMsg delivers word-level cues from a background whisper run.
+type TranscribeDoneMsg struct {
+	Words []video.Cue
+	Err   error
+}
+
+func transcribe(ctx context.Context, path, model string) tea.Cmd {
+	return func() tea.Msg {
+		tool, err := video.FindWhisper()
+		if err != nil {
+			return TranscribeDoneMsg{Err: err}
+		}
+		words, err := video.Transcribe(ctx, tool, path, video.WhisperOptions{Model: model})
+		return TranscribeDoneMsg{Words: words, Err: err}
+	}
+}
+
+// startTranscription runs whisper in the background unless a run is
+// already in flight.
+func (m *Model) startTranscription() tea.Cmd {
+	if m.transcribing {
+		m.exportStatus = "Transcription already running"
+		return nil
+	}
+	m.transcribing = true
+	m.exportStatus = "Transcribing audio in the background..."
+	return transcribe(m.ctx, m.player.Path(), m.whisperModel)
+}
+
+func loadTranscript(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		cues, err := video.LoadTranscript(ctx, path)
+		return TranscriptLoadedMsg{Cues: cues, Err: err}
+	}
+}
+
+// filteredCues returns the indices of cues matching the search query.
+func (m Model) filteredCues() []int {
+	query := strings.ToLower(m.transcriptView.query)
+	var idx []int
+	for i, cue := range m.transcript {
+		if query == "" || strings.Contains(strings.ToLower(cue.Text), query) {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func (m *Model) openTranscript() {
+	if len(m.transcript) == 0 {
+		if m.transcribing {
+			m.exportStatus = "Transcription still running..."
+		} else {
+			m.exportStatus = "No transcript: add an .srt/.vtt next to the video or press T to transcribe"
+		}
+		return
+	}
+	// Start on the cue under (or right after) the playhead
+	pos := m.player.Position()
+	m.transcriptView = transcriptView{}
+	for i, cue := range m.transcript {
+		if cue.End >= pos {
+			m.transcriptView.cursor = i
+			break
+		}
+	}
+	m.showTranscript = true
+}
+
+// selectedCue returns the cue under the cursor, if any.
+func (m Model) selectedCue() (video.Cue, bool) {
+	idx := m.filteredCues()
+	if m.transcriptView.cursor < 0 || m.transcriptView.cursor >= len(idx) {
+		return video.Cue{}, false
+	}
+	return m.transcript[idx[m.transcriptView.cursor]], true
+}
+
+func (m Model) handleTranscriptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.transcriptView
+
+	if v.searching {
+		switch msg.Type {
+		case tea.KeyEsc:
+			v.searching = false
+			v.query = ""
+			v.cursor = 0
+		case tea.KeyEnter:
+			v.searching = false
+		case tea.KeyBackspace:
+			if len(v.query) > 0 {
+				v.query = panels.DropLastRune(v.query)
+				v.cursor = 0
+			}
+		default:
+			if len(msg.Runes) > 0 {
+				v.query += string(msg.Runes)
+				v.cursor = 0
+			}
+		}
+		return m, nil
+	}
+
+	count := len(m.filteredCues())
+	switch msg.String() {
+	case "esc", "t", "q":
+		m.showTranscript = false
+	case "j", "down":
+		if v.cursor < count-1 {
+			v.cursor++
+		}
+	case "k", "up":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+	case "g", "home":
+		v.cursor = 0
+	case "G", "end":
+		v.cursor = max(0, count-1)
+	case "/":
+		v.searching = true
+	case "enter":
+		if cue, ok := m.selectedCue(); ok {
+			m.player.Seek(cue.Start)
+			m.showTranscript = false
+		}
+	case "i":
+		if cue, ok := m.selectedCue(); ok {
+			candidate := m.player.Trim
+			candidate.SetIn(cue.Start)
+			if m.acceptTrim(candidate) {
+				m.announce("In-point set to %s", formatTimestamp(cue.Start))
+			}
+		}
+	case "o":
+		if cue, ok := m.selectedCue(); ok {
+			candidate := m.player.Trim
+			candidate.SetOut(cue.End)
+			if m.acceptTrim(candidate) {
+				m.announce("Out-point set to %s", formatTimestamp(cue.End))
+			}
+		}
+	case "w":
+		// Word-level trim: select exactly the searched phrase
+		if len(m.words) == 0 {
+			m.exportStatus = "Quote trimming needs a whisper transcript (T)"
+			return m, nil
+		}
+		from := time.Duration(0)
+		if cue, ok := m.selectedCue(); ok {
+			from = cue.Start
+		}
+		start, end, ok := video.FindQuote(m.words, v.query, from)
+		if !ok {
+			m.exportStatus = fmt.Sprintf("Quote %q not found", v.query)
+			return m, nil
+		}
+		if m.acceptTrim(video.TrimState{InPoint: &start, OutPoint: &end}) {
+			m.player.Seek(start)
+			m.showTranscript = false
+			m.exportStatus = fmt.Sprintf("Selected quote %s - %s", video.FormatTimecode(start), video.FormatTimecode(end))
+		}
+	case "c":
+		if cue, ok := m.selectedCue(); ok {
+			start, end := cue.Start, cue.End
+			if m.acceptTrim(video.TrimState{InPoint: &start, OutPoint: &end}) {
+				m.player.Seek(start)
+				m.showTranscript = false
+				m.announce("Selected cue %s - %s", formatTimestamp(start), formatTimestamp(end))
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderTranscript() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(theme.Text)
+	accentStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	modalWidth := min(90, m.width-4)
+	textWidth := max(10, modalWidth-6-12)
+	listHeight := max(3, m.height-14)
+
+	v := m.transcriptView
+	idx := m.filteredCues()
+
+	// Scroll so the cursor stays visible
+	top := 0
+	if v.cursor >= listHeight {
+		top = v.cursor - listHeight + 1
+	}
+
+	var rows []string
+	for row := top; row < len(idx) && row < top+listHeight; row++ {
+		cue := m.transcript[idx[row]]
+		text := panels.SanitizeLine(cue.Text)
+		text = panels.Truncate(text, textWidth)
+		stamp := formatTimestamp(cue.Start)
+		if row == v.cursor {
+			rows = append(rows, accentStyle.Render("> "+stamp)+"  "+valueStyle.Render(text))
+		} else {
+			rows = append(rows, dimStyle.Render("  "+stamp)+"  "+labelStyle.Render(text))
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, dimStyle.Render("  no matching lines"))
+	}
+
+	search := dimStyle.Render("/ to search")
+	if v.searching || v.query != "" {
+		cursor := ""
+		if v.searching {
+			cursor = dimStyle.Render("_")
+		}
+		search = labelStyle.Render("Search ") + valueStyle.Render(v.query) + cursor +
+			dimStyle.Render(fmt.Sprintf("  %d/%d", len(idx), len(m.transcript)))
+	}
+
+	footer := keyStyle.Render("Enter") + labelStyle.Render(" seek  ") +
+		keyStyle.Render("i/o") + labelStyle.Render(" in/out  ") +
+		keyStyle.Render("c") + labelStyle.Render(" select cue  ") +
+		keyStyle.Render("w") + labelStyle.Render(" select quote  ") +
+		keyStyle.Render("Esc") + labelStyle.Render(" close")
+
+	content := titleStyle.Render("Transcript") + "\n\n" +
+		search + "\n\n" +
+		strings.Join(rows, "\n") + "\n\n" +
+		footer
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Width(modalWidth).
+		Render(content)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
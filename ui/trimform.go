@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"fmt"
+	"lazycut/ui/panels"
+	"lazycut/ui/theme"
+	"lazycut/video"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// trimForm is the exact in/out entry modal opened with `e`.
+type trimForm struct {
+	inText  string
+	outText string
+	focus   int // 0: in, 1: out
+	err     string
+}
+
+func (m *Model) openTrimForm() {
+	form := trimForm{}
+	if m.player.Trim.InPoint != nil {
+		form.inText = video.FormatTimecode(*m.player.Trim.InPoint)
+	}
+	if m.player.Trim.OutPoint != nil {
+		form.outText = video.FormatTimecode(*m.player.Trim.OutPoint)
+	}
+	m.trimForm = form
+	m.showTrimForm = true
+}
+
+func (m Model) handleTrimFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := &m.trimForm
+	field := &f.inText
+	if f.focus == 1 {
+		field = &f.outText
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.showTrimForm = false
+		return m, nil
+
+	case tea.KeyEnter:
+		if err := m.applyTrimForm(); err != nil {
+			f.err = err.Error()
+			return m, nil
+		}
+		m.showTrimForm = false
+		return m, nil
+
+	case tea.KeyUp, tea.KeyDown, tea.KeyTab, tea.KeyShiftTab:
+		f.focus = 1 - f.focus
+		return m, nil
+
+	case tea.KeyBackspace:
+		if len(*field) > 0 {
+			*field = panels.DropLastRune(*field)
+		}
+		f.err = ""
+		return m, nil
+
+	case tea.KeyCtrlU:
+		*field = ""
+		f.err = ""
+		return m, nil
+	}
+
+	for _, r := range msg.Runes {
+		if (r >= '0' && r <= '9') || strings.ContainsRune(":.,hms ", r) {
+			*field += string(r)
+			f.err = ""
+		}
+	}
+	return m, nil
+}
+
+// applyTrimForm validates both fields against the video and, if they form a
+// valid selection, replaces the current trim (undoable like any other edit).
+func (m *Model) applyTrimForm() error {
+	in, err := video.ParseTimecode(m.trimForm.inText)
+	if err != nil {
+		return fmt.Errorf("in: %w", err)
+	}
+	out, err := video.ParseTimecode(m.trimForm.outText)
+	if err != nil {
+		return fmt.Errorf("out: %w", err)
+	}
+	dur := m.player.Duration()
+	if in > dur {
+		return fmt.Errorf("in: %s is past the end (%s)", video.FormatTimecode(in), video.FormatTimecode(dur))
+	}
+	if out > dur {
+		return fmt.Errorf("out: %s is past the end (%s)", video.FormatTimecode(out), video.FormatTimecode(dur))
+	}
+	if out <= in {
+		return fmt.Errorf("out-point must be after in-point")
+	}
+
+	candidate := video.TrimState{InPoint: &in, OutPoint: &out}
+	if err := candidate.Validate(m.player.MinSelection()); err != nil {
+		return err
+	}
+	m.saveTrimState()
+	m.player.Trim = candidate
+	m.announce("Selection set to %s - %s", video.FormatTimecode(in), video.FormatTimecode(out))
+	return nil
+}
+
+func (m Model) renderTrimForm() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(theme.Muted)
+	valueStyle := lipgloss.NewStyle().
+		Foreground(theme.Text)
+	accentStyle := lipgloss.NewStyle().
+		Foreground(theme.Accent).
+		Bold(true)
+	dimStyle := lipgloss.NewStyle().
+		Foreground(theme.Dim)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Bold(true)
+
+	field := func(label, value string, focused bool) string {
+		indicator := "  "
+		if focused {
+			indicator = accentStyle.Render("> ")
+			value += dimStyle.Render("_")
+		}
+		if value == "" && !focused {
+			value = dimStyle.Render("HH:MM:SS.mmm")
+		}
+		return indicator + labelStyle.Render(label) + valueStyle.Render(value)
+	}
+
+	errLine := ""
+	if m.trimForm.err != "" {
+		errLine = accentStyle.Render(m.trimForm.err)
+	}
+
+	footer := keyStyle.Render("↑↓") + labelStyle.Render(" field  ") +
+		keyStyle.Render("Enter") + labelStyle.Render(" apply  ") +
+		keyStyle.Render("Esc") + labelStyle.Render(" cancel")
+
+	lines := []string{
+		titleStyle.Render("Edit Selection"),
+		"",
+		field("In    ", m.trimForm.inText, m.trimForm.focus == 0),
+		field("Out   ", m.trimForm.outText, m.trimForm.focus == 1),
+		"",
+		dimStyle.Render("Duration " + video.FormatTimecode(m.player.Duration())),
+		errLine,
+		footer,
+	}
+
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Border).
+		Padding(1, 3).
+		Width(50).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
+}
@@ -0,0 +1,271 @@
+// Package uitest drives the lazycut UI the way a terminal would, for
+// integration tests of whole key sequences: keys go through Update, the
+// commands they return run in the background and their messages are fed
+// back, and assertions read View() with the colors stripped.
+//
+//	term, err := uitest.Open(ctx, t.TempDir(), videotest.SmallFixture, ui.Options{}, 120, 40)
+//	if err != nil {
+//		t.Skip(err)
+//	}
+//	defer term.Close()
+//	term.Press("i", "l", "o", "enter")
+//	if err := term.WaitFor("Filename", time.Second); err != nil {
+//		t.Fatal(err)
+//	}
+//
+// Exports run ffmpeg through the video package's runner, so a
+// videotest.FakeRunner set with video.SetRunner records them instead.
+package uitest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"lazycut/ui"
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+// Terminal is a bubbletea model behind a scripted terminal of a fixed
+// size. It is not safe for concurrent use.
+type Terminal struct {
+	model  tea.Model
+	msgs   chan tea.Msg
+	quit   bool
+	player *video.Player
+}
+
+// New starts m in a width x height terminal: Init runs, then the size
+// arrives, as in a real one.
+func New(m tea.Model, width, height int) *Terminal {
+	t := &Terminal{model: m, msgs: make(chan tea.Msg, 256)}
+	t.run(m.Init())
+	t.Send(tea.WindowSizeMsg{Width: width, Height: height})
+	return t
+}
+
+// Open generates the fixture video in dir and starts the UI on it with
+// opts, with the default viewing settings rather than the user's saved
+// ones. It needs ffmpeg, ffprobe and chafa, like lazycut itself.
+func Open(ctx context.Context, dir string, f videotest.Fixture, opts ui.Options, width, height int) (*Terminal, error) {
+	path, err := f.Generate(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	player, err := video.NewPlayer(ctx, path, video.DefaultPlayerPrefs())
+	if err != nil {
+		return nil, err
+	}
+	t := New(ui.NewModel(ctx, player, opts), width, height)
+	t.player = player
+	return t, nil
+}
+
+// Close stops the player Open started.
+func (t *Terminal) Close() {
+	if t.player != nil {
+		t.player.Close()
+	}
+}
+
+// Model returns the model as the last update left it.
+func (t *Terminal) Model() tea.Model {
+	return t.model
+}
+
+// Quit reports whether the model asked to quit.
+func (t *Terminal) Quit() bool {
+	return t.quit
+}
+
+// View is the screen as plain text, without colors or other escapes.
+func (t *Terminal) View() string {
+	return ansi.Strip(t.model.View())
+}
+
+// Send updates the model with msg and starts the command it returns,
+// after taking in the messages already waiting.
+func (t *Terminal) Send(msg tea.Msg) {
+	t.drain()
+	t.update(msg)
+}
+
+// Press sends keys one after another, by the names the help screen uses:
+// "enter", "esc", "tab", "space", "up", "ctrl+p", "alt+x", or a single
+// character.
+func (t *Terminal) Press(keys ...string) {
+	for _, key := range keys {
+		t.Send(Key(key))
+	}
+}
+
+// Type sends text as typed characters.
+func (t *Terminal) Type(text string) {
+	for _, r := range text {
+		t.Send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+// Settle takes in the messages commands deliver for d, for the effects of
+// background work that no text on screen announces.
+func (t *Terminal) Settle(d time.Duration) {
+	deadline := time.After(d)
+	for {
+		select {
+		case msg := <-t.msgs:
+			t.update(msg)
+		case <-deadline:
+			return
+		}
+	}
+}
+
+// WaitFor takes in messages until the screen shows text, failing after
+// timeout with the screen as it was.
+func (t *Terminal) WaitFor(text string, timeout time.Duration) error {
+	return t.WaitUntil(func(view string) bool { return strings.Contains(view, text) }, timeout,
+		fmt.Sprintf("%q", text))
+}
+
+// WaitUntil takes in messages until ok accepts the screen (see View),
+// failing after timeout; what names the condition in the error.
+func (t *Terminal) WaitUntil(ok func(view string) bool, timeout time.Duration, what string) error {
+	deadline := time.After(timeout)
+	for !ok(t.View()) {
+		select {
+		case msg := <-t.msgs:
+			t.update(msg)
+		case <-deadline:
+			return fmt.Errorf("no %s on screen after %s:\n%s", what, timeout, t.View())
+		}
+	}
+	return nil
+}
+
+// update runs one message through the model.
+func (t *Terminal) update(msg tea.Msg) {
+	if _, ok := msg.(tea.QuitMsg); ok {
+		t.quit = true
+		return
+	}
+	if _, ok := commands(msg); ok {
+		// Sent by the test rather than returned by a command
+		go t.deliver(msg)
+		return
+	}
+	var cmd tea.Cmd
+	t.model, cmd = t.model.Update(msg)
+	t.run(cmd)
+}
+
+// drain takes in the messages waiting, without blocking.
+func (t *Terminal) drain() {
+	for {
+		select {
+		case msg := <-t.msgs:
+			t.update(msg)
+		default:
+			return
+		}
+	}
+}
+
+// run starts cmd in the background, as bubbletea does. Commands that
+// wait on something that never comes, such as the next preview frame,
+// are left blocked.
+func (t *Terminal) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() { t.deliver(cmd()) }()
+}
+
+// deliver queues what a command returned for the model. The messages of
+// tea.Batch commands arrive as each finishes and those of tea.Sequence
+// in order, one command after the other, as bubbletea runs them.
+func (t *Terminal) deliver(msg tea.Msg) {
+	if msg == nil {
+		return
+	}
+	cmds, ok := commands(msg)
+	if !ok {
+		t.msgs <- msg
+		return
+	}
+	if _, batch := msg.(tea.BatchMsg); batch {
+		var wg sync.WaitGroup
+		for _, cmd := range cmds {
+			if cmd != nil {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					t.deliver(cmd())
+				}()
+			}
+		}
+		// A batch inside a sequence holds up the commands after it
+		wg.Wait()
+		return
+	}
+	for _, cmd := range cmds {
+		if cmd != nil {
+			t.deliver(cmd())
+		}
+	}
+}
+
+var cmdType = reflect.TypeFor[tea.Cmd]()
+
+// commands unpacks the message tea.Batch and tea.Sequence commands
+// return; bubbletea does not export the type of the sequence one, a
+// []tea.Cmd like tea.BatchMsg.
+func commands(msg tea.Msg) ([]tea.Cmd, bool) {
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		return batch, true
+	}
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Slice || v.Type().Elem() != cmdType {
+		return nil, false
+	}
+	cmds := make([]tea.Cmd, v.Len())
+	for i := range cmds {
+		cmds[i] = v.Index(i).Interface().(tea.Cmd)
+	}
+	return cmds, true
+}
+
+// keyTypes maps key names to their bubbletea key types.
+var keyTypes = map[string]tea.KeyType{}
+
+func init() {
+	for k := tea.KeyType(-200); k < 200; k++ {
+		if name := k.String(); name != "" && k != tea.KeyRunes {
+			if _, taken := keyTypes[name]; !taken {
+				keyTypes[name] = k
+			}
+		}
+	}
+}
+
+// Key is the key message for a key name, see Press.
+func Key(name string) tea.KeyMsg {
+	if name == "space" || name == " " {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	if k, ok := keyTypes[name]; ok {
+		return tea.KeyMsg{Type: k}
+	}
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		msg := Key(rest)
+		msg.Alt = true
+		return msg
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
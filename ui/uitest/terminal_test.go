@@ -0,0 +1,74 @@
+package uitest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type step string
+
+// recorder shows the steps it was sent, in order, and answers "go" with
+// cmd.
+type recorder struct {
+	cmd   tea.Cmd
+	steps []string
+}
+
+func (r recorder) Init() tea.Cmd { return nil }
+
+func (r recorder) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case step:
+		r.steps = append(r.steps, string(msg))
+	case tea.KeyMsg:
+		if msg.String() == "g" {
+			return r, r.cmd
+		}
+	}
+	return r, nil
+}
+
+func (r recorder) View() string { return strings.Join(r.steps, " ") }
+
+func send(s string, delay time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(delay)
+		return step(s)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cmd  tea.Cmd
+		want string
+	}{
+		{"batch", tea.Batch(send("slow", 20*time.Millisecond), send("fast", 0)), "fast slow"},
+		{"sequence", tea.Sequence(send("slow", 20*time.Millisecond), send("fast", 0)), "slow fast"},
+		{"nested", tea.Sequence(
+			tea.Batch(send("b1", 20*time.Millisecond), send("b0", 0)),
+			send("after", 0),
+			tea.Sequence(send("s1", 10*time.Millisecond), send("s2", 0)),
+		), "b0 b1 after s1 s2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			term := New(recorder{cmd: tc.cmd}, 80, 24)
+			term.Press("g")
+			if err := term.WaitFor(tc.want, time.Second); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSettleSequence(t *testing.T) {
+	term := New(recorder{cmd: tea.Sequence(send("one", 0), send("two", 0))}, 80, 24)
+	term.Press("g")
+	term.Settle(50 * time.Millisecond)
+	if got := term.View(); got != "one two" {
+		t.Errorf("after Settle the screen is %q, want %q", got, "one two")
+	}
+}
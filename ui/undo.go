@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"lazycut/video"
+)
+
+// undo restores the trim state from n steps back (n < 1 means one) and
+// says what changed, plus what the next u would undo.
+func (m *Model) undo(n int) {
+	if len(m.undoStack) == 0 {
+		m.exportStatus = "Nothing to undo"
+		return
+	}
+	n = min(max(n, 1), len(m.undoStack))
+	target := m.undoStack[len(m.undoStack)-n]
+	m.undoStack = m.undoStack[:len(m.undoStack)-n]
+
+	change := describeTrimChange(snapshotOf(m.player.Trim), target)
+	m.player.Trim.InPoint = target.inPoint
+	m.player.Trim.OutPoint = target.outPoint
+
+	status := "Undone: " + change
+	if n > 1 {
+		status = fmt.Sprintf("Undone %d steps: %s", n, change)
+	}
+	if next := m.nextUndo(); next != "" {
+		status += " · next u: " + next
+	}
+	m.exportStatus = status
+}
+
+// nextUndo describes what u would undo now, or "" with nothing to undo.
+func (m Model) nextUndo() string {
+	if len(m.undoStack) == 0 {
+		return ""
+	}
+	return describeTrimChange(snapshotOf(m.player.Trim), m.undoStack[len(m.undoStack)-1])
+}
+
+func snapshotOf(t video.TrimState) trimSnapshot {
+	return trimSnapshot{inPoint: t.InPoint, outPoint: t.OutPoint}
+}
+
+// describeTrimChange words the move from one trim state to another, e.g.
+// "out-point 01:25 → 01:23".
+func describeTrimChange(from, to trimSnapshot) string {
+	var parts []string
+	if !samePoint(from.inPoint, to.inPoint) {
+		parts = append(parts, "in-point "+pointChange(from.inPoint, to.inPoint))
+	}
+	if !samePoint(from.outPoint, to.outPoint) {
+		parts = append(parts, "out-point "+pointChange(from.outPoint, to.outPoint))
+	}
+	if len(parts) == 0 {
+		return "no change"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func samePoint(a, b *time.Duration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// pointChange formats "old → new" to the second, or to the millisecond
+// when both round to the same second (a frame step).
+func pointChange(from, to *time.Duration) string {
+	format := formatTimestamp
+	if from != nil && to != nil && formatTimestamp(*from) == formatTimestamp(*to) {
+		format = video.FormatTimecode
+	}
+	text := func(p *time.Duration) string {
+		if p == nil {
+			return "unset"
+		}
+		return format(*p)
+	}
+	return text(from) + " → " + text(to)
+}
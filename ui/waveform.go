@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"context"
+	"lazycut/video"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PeaksLoadedMsg delivers the audio envelope decoded at startup.
+type PeaksLoadedMsg struct {
+	Peaks *video.Peaks
+	Err   error
+}
+
+func loadPeaks(ctx context.Context, path string) tea.Cmd {
+	return func() tea.Msg {
+		peaks, err := video.LoadPeaks(ctx, path)
+		return PeaksLoadedMsg{Peaks: peaks, Err: err}
+	}
+}
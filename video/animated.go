@@ -0,0 +1,90 @@
+package video
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Animation shapes animated WebP and APNG output, short silent loops for
+// the web that are far smaller than a GIF of the same clip.
+type Animation struct {
+	FPS     float64 // frame rate, 0 for DefaultAnimationFPS; never above the source
+	Loops   int     // times the animation plays, 0 for forever
+	Quality int     // WebP quality 1-100, 0 for 75; APNG is lossless
+}
+
+// DefaultAnimationFPS is the frame rate of animations that set none.
+const DefaultAnimationFPS = 15
+
+// isAnimation reports whether path (by extension) is an animated image.
+func isAnimation(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".webp", ".apng":
+		return true
+	}
+	return false
+}
+
+// Animated reports whether the export is an animated WebP or APNG, by the
+// chosen container or else the typed name.
+func (opts ExportOptions) Animated() bool {
+	if ext := containerExt(opts.Container); ext != "" {
+		return isAnimation(ext)
+	}
+	return isAnimation(opts.Output)
+}
+
+// CanWebP reports whether ffmpeg can write animated WebP. APNG needs
+// nothing beyond ffmpeg itself.
+func (c *Capabilities) CanWebP() bool {
+	return webpEncoder(c) != ""
+}
+
+// webpEncoder prefers libwebp_anim, which stores only what changes
+// between frames, over plain libwebp.
+func webpEncoder(c *Capabilities) string {
+	for _, enc := range []string{"libwebp_anim", "libwebp"} {
+		if c.HasEncoder(enc) {
+			return enc
+		}
+	}
+	return ""
+}
+
+// animationFPS is the frame rate of the animation, capped at the source's.
+func (opts ExportOptions) animationFPS() float64 {
+	fps := opts.Animation.FPS
+	if fps <= 0 {
+		fps = DefaultAnimationFPS
+	}
+	if opts.FPS > 0 {
+		fps = min(fps, opts.FPS)
+	}
+	return fps
+}
+
+// animationArgs replaces encoderArgs for animations: the frame rate, the
+// image encoder and no audio. CRF and Bitrate do not apply.
+func animationArgs(opts ExportOptions, output string) []string {
+	args := []string{"-an", "-r", formatFactor(opts.animationFPS())}
+	if strings.EqualFold(filepath.Ext(output), ".apng") {
+		return append(args, "-c:v", "apng", "-pix_fmt", "rgb24")
+	}
+	quality := opts.Animation.Quality
+	if quality <= 0 {
+		quality = 75
+	}
+	return append(args, "-c:v", videoEncoder(opts, output), "-lossless", "0",
+		"-quality", strconv.Itoa(min(quality, 100)), "-pix_fmt", "yuv420p")
+}
+
+// animationMuxerArgs sets how many times the animation plays: -loop for
+// WebP and -plays for APNG, where 0 is forever in both.
+func animationMuxerArgs(opts ExportOptions, output string) []string {
+	loops := strconv.Itoa(max(0, opts.Animation.Loops))
+	if strings.EqualFold(filepath.Ext(output), ".apng") {
+		return []string{"-plays", loops, "-f", "apng"}
+	}
+	return []string{"-loop", loops}
+}
@@ -1,6 +1,7 @@
 package video
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"sync"
@@ -8,15 +9,19 @@ import (
 
 // AudioPlayer manages audio playback via ffplay subprocess
 type AudioPlayer struct {
+	ctx      context.Context
 	filePath string
 	cmd      *exec.Cmd
+	done     chan struct{} // closed once cmd has exited and been waited for
 	muted    bool
 	mu       sync.Mutex
 }
 
-// NewAudioPlayer creates a new AudioPlayer for the given video file
-func NewAudioPlayer(filePath string) *AudioPlayer {
+// NewAudioPlayer creates a new AudioPlayer for the given video file.
+// ffplay processes it starts are killed when ctx is cancelled.
+func NewAudioPlayer(ctx context.Context, filePath string) *AudioPlayer {
 	return &AudioPlayer{
+		ctx:      ctx,
 		filePath: filePath,
 		muted:    false,
 	}
@@ -34,7 +39,7 @@ func (a *AudioPlayer) Start(position float64) {
 	// Stop any existing playback
 	a.stopLocked()
 
-	a.cmd = exec.Command("ffplay",
+	cmd := command(a.ctx, "ffplay",
 		"-nodisp",
 		"-autoexit",
 		"-vn",
@@ -43,8 +48,18 @@ func (a *AudioPlayer) Start(position float64) {
 		a.filePath,
 	)
 
-	// Start ffplay in background
-	_ = a.cmd.Start()
+	// Start ffplay in background. It exits by itself at the end of the
+	// file, so it is waited for right away instead of staying a zombie
+	// until the next Stop
+	if err := startTracked(cmd); err != nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = waitTracked(cmd)
+		close(done)
+	}()
+	a.cmd, a.done = cmd, done
 }
 
 // Stop kills the ffplay process if running
@@ -56,11 +71,17 @@ func (a *AudioPlayer) Stop() {
 
 // stopLocked stops playback (must be called with lock held)
 func (a *AudioPlayer) stopLocked() {
-	if a.cmd != nil && a.cmd.Process != nil {
-		_ = a.cmd.Process.Kill()
-		_ = a.cmd.Wait()
-		a.cmd = nil
+	if a.cmd == nil {
+		return
 	}
+	select {
+	case <-a.done:
+		// Exited on its own; its PID may belong to another process now
+	default:
+		_ = killProcessGroup(a.cmd.Process)
+		<-a.done
+	}
+	a.cmd, a.done = nil, nil
 }
 
 // ToggleMute toggles the muted state and stops audio if muting
@@ -79,12 +100,15 @@ func (a *AudioPlayer) IsRunning() bool {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	if a.cmd == nil || a.cmd.Process == nil {
+	if a.done == nil {
+		return false
+	}
+	select {
+	case <-a.done:
 		return false
+	default:
+		return true
 	}
-	// Check if process is still running by checking if Wait would block
-	// A nil from ProcessState means it's still running
-	return a.cmd.ProcessState == nil
 }
 
 // IsMuted returns the current mute state
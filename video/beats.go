@@ -0,0 +1,122 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minBeatGap is the closest two detected beats may be; 100ms is faster
+// than any tempo a cut would follow.
+const minBeatGap = 100 * time.Millisecond
+
+// FindBeats returns the onset times in path, oldest first. It uses the
+// aubio CLI when installed and otherwise picks energy peaks from peaks,
+// which may be nil when aubio is expected to do the work.
+func FindBeats(ctx context.Context, path string, peaks *Peaks) ([]time.Duration, error) {
+	if _, err := lookPath("aubio"); err == nil {
+		return aubioBeats(ctx, path)
+	}
+	if peaks == nil {
+		return nil, fmt.Errorf("no audio to detect beats in")
+	}
+	return peaks.Onsets(), nil
+}
+
+// aubioBeats runs `aubio beat`, which prints one beat time in seconds per
+// line.
+func aubioBeats(ctx context.Context, path string) ([]time.Duration, error) {
+	cmd := command(ctx, "aubio", "beat", path)
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("aubio failed: %w", err)
+	}
+
+	var beats []time.Duration
+	sc := bufio.NewScanner(strings.NewReader(string(out)))
+	for sc.Scan() {
+		secs, err := strconv.ParseFloat(strings.TrimSpace(sc.Text()), 64)
+		if err != nil {
+			continue
+		}
+		beats = append(beats, time.Duration(secs*float64(time.Second)))
+	}
+	if len(beats) == 0 {
+		return nil, fmt.Errorf("aubio found no beats")
+	}
+	return beats, nil
+}
+
+// Onsets picks the moments where the envelope rises sharply above its
+// recent level: the rise from each slice to the next, kept when it beats
+// a running mean plus 1.5 standard deviations over the surrounding second
+// and is the largest rise within minBeatGap.
+func (p *Peaks) Onsets() []time.Duration {
+	n := len(p.Values)
+	if n < 2 || p.Loudest <= 0 {
+		return nil
+	}
+	// Rises are relative to the loudest peak so quiet files work too
+	rise := make([]float64, n)
+	for i := 1; i < n; i++ {
+		rise[i] = math.Max(0, float64((p.Values[i]-p.Values[i-1])/p.Loudest))
+	}
+
+	const window = PeaksPerSecond / 2
+	gap := int(minBeatGap * PeaksPerSecond / time.Second)
+	var onsets []time.Duration
+	last := -gap
+	for i := 1; i < n; i++ {
+		if rise[i] == 0 || i-last < gap {
+			continue
+		}
+		lo, hi := max(0, i-window), min(n, i+window+1)
+		var sum, sq float64
+		for _, r := range rise[lo:hi] {
+			sum += r
+			sq += r * r
+		}
+		count := float64(hi - lo)
+		mean := sum / count
+		std := math.Sqrt(math.Max(0, sq/count-mean*mean))
+		if rise[i] < mean+1.5*std || rise[i] < 0.05 {
+			continue
+		}
+		if rise[i] < slices.Max(rise[i:min(n, i+gap)]) {
+			continue
+		}
+		onsets = append(onsets, time.Duration(i)*time.Second/PeaksPerSecond)
+		last = i
+	}
+	return onsets
+}
+
+// NearestBeat returns the beat closest to t if one lies within window.
+func NearestBeat(beats []time.Duration, t, window time.Duration) (time.Duration, bool) {
+	i, _ := slices.BinarySearch(beats, t)
+	best, found := time.Duration(0), false
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(beats) {
+			continue
+		}
+		if d := absDuration(beats[j] - t); d <= window && (!found || d < absDuration(best-t)) {
+			best, found = beats[j], true
+		}
+	}
+	return best, found
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,131 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bitrate is the video bitrate of a file second by second, measured from
+// its packets, and where its keyframes fall. Stream-copy exports can only
+// start cleanly on a keyframe, so long GOPs make copied cuts imprecise.
+type Bitrate struct {
+	PerSecond []int64 // bits of video in each second
+	Peak      int64   // largest PerSecond value
+	Keyframes []time.Duration
+}
+
+// LoadBitrate reads the packet sizes and keyframe flags of the first video
+// stream of path with ffprobe. It does not decode, but long files still
+// take a few seconds, so callers run it in the background.
+func LoadBitrate(ctx context.Context, path string) (*Bitrate, error) {
+	cmd := command(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,dts_time,size,flags",
+		"-of", "csv=p=0",
+		path,
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffprobe: %w", err)
+	}
+
+	b, readErr := readBitrate(stdout)
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	if len(b.PerSecond) == 0 {
+		return nil, fmt.Errorf("no video packets")
+	}
+	return b, nil
+}
+
+// readBitrate sums "pts_time,dts_time,size,flags" packet lines into
+// seconds. Packets without a pts (B-frames in some muxers) use their dts.
+func readBitrate(r io.Reader) (*Bitrate, error) {
+	b := &Bitrate{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ",")
+		if len(fields) < 4 {
+			continue
+		}
+		secs, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			if secs, err = strconv.ParseFloat(fields[1], 64); err != nil {
+				continue
+			}
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil || secs < 0 {
+			continue
+		}
+		sec := int(secs)
+		for len(b.PerSecond) <= sec {
+			b.PerSecond = append(b.PerSecond, 0)
+		}
+		b.PerSecond[sec] += size * 8
+		if strings.HasPrefix(fields[3], "K") {
+			b.Keyframes = append(b.Keyframes, time.Duration(secs*float64(time.Second)))
+		}
+	}
+	for _, bits := range b.PerSecond {
+		b.Peak = max(b.Peak, bits)
+	}
+	return b, scanner.Err()
+}
+
+// Window returns the bitrate of columns equal slices of the first dur of
+// the file as fractions of the peak, for drawing it along the timeline.
+func (b *Bitrate) Window(dur time.Duration, columns int) []float64 {
+	out := make([]float64, columns)
+	if b.Peak <= 0 || dur <= 0 {
+		return out
+	}
+	secs := dur.Seconds()
+	for i := range out {
+		from := int(secs * float64(i) / float64(columns))
+		to := max(from+1, int(secs*float64(i+1)/float64(columns)))
+		var sum int64
+		n := 0
+		for s := from; s < to && s < len(b.PerSecond); s++ {
+			sum += b.PerSecond[s]
+			n++
+		}
+		if n > 0 {
+			out[i] = float64(sum) / float64(n) / float64(b.Peak)
+		}
+	}
+	return out
+}
+
+// GOP is the average distance between keyframes, 0 with fewer than two.
+func (b *Bitrate) GOP() time.Duration {
+	if len(b.Keyframes) < 2 {
+		return 0
+	}
+	return (b.Keyframes[len(b.Keyframes)-1] - b.Keyframes[0]) / time.Duration(len(b.Keyframes)-1)
+}
+
+// NearestKeyframe is the keyframe closest to t, false before any are known.
+func (b *Bitrate) NearestKeyframe(t time.Duration) (time.Duration, bool) {
+	if b == nil {
+		return 0, false
+	}
+	return NearestBeat(b.Keyframes, t, time.Duration(math.MaxInt64))
+}
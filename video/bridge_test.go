@@ -0,0 +1,19 @@
+package video
+
+import "context"
+
+// Unexported helpers the video_test package tests directly.
+var BuildCropFilter = buildCropFilter
+
+// RenderFrame renders with the current frame renderer, as playback does.
+func RenderFrame(ctx context.Context, frame []byte, width, height int, quality QualityPreset) (string, error) {
+	return currentFrameRenderer().RenderFrame(ctx, frame, width, height, quality)
+}
+
+// SetCapabilities makes c the detected capabilities until the returned
+// func restores the old ones.
+func SetCapabilities(c *Capabilities) (restore func()) {
+	old := capsCached
+	capsCached = c
+	return func() { capsCached = old }
+}
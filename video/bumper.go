@@ -0,0 +1,167 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Bumper is a clip joined to the start or end of every export, such as
+// the intro and end card of a recurring series.
+type Bumper struct {
+	Path     string
+	Duration time.Duration
+	HasAudio bool
+}
+
+// ProbeBumper reads what joining the clip at path takes.
+func ProbeBumper(ctx context.Context, path string) (Bumper, error) {
+	props, err := GetVideoProperties(ctx, path)
+	if err != nil {
+		return Bumper{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if props.Width == 0 || props.Height == 0 {
+		return Bumper{}, fmt.Errorf("%s: no video stream", path)
+	}
+	return Bumper{Path: path, Duration: props.Duration, HasAudio: props.AudioCodec != ""}, nil
+}
+
+// bumpers returns the intro and outro that are set, in playing order.
+func (opts ExportOptions) bumpers() []Bumper {
+	var bumpers []Bumper
+	for _, b := range []Bumper{opts.Intro, opts.Outro} {
+		if b.Path != "" {
+			bumpers = append(bumpers, b)
+		}
+	}
+	return bumpers
+}
+
+// hasBumpers reports whether anything is joined to the export: an intro,
+// an outro or a title card.
+func (opts ExportOptions) hasBumpers() bool {
+	return opts.Intro.Path != "" || opts.Outro.Path != "" || opts.hasTitleCard()
+}
+
+// leadIn is how far the selection starts into the output because of the
+// intro and title card before it.
+func (opts ExportOptions) leadIn() time.Duration {
+	d := opts.Intro.Duration
+	if opts.hasTitleCard() {
+		d += opts.TitleCard.Duration
+	}
+	return d
+}
+
+// frameSize is the picture size of the export after cropping or filling,
+// which intros, outros and title cards are fitted to.
+func (opts ExportOptions) frameSize() (int, int) {
+	for _, opt := range AspectRatioOptions {
+		if opt.Ratio == opts.AspectRatio && opt.Fill && opts.AspectRatio != AspectOriginal {
+			if w, h := fillSize(opts.Width, opts.Height, opt.W, opt.H); w > 0 && h > 0 {
+				return w, h
+			}
+		}
+	}
+	if w, h := cropSize(opts.Width, opts.Height, opts.AspectRatio); w > 0 && h > 0 {
+		return w, h
+	}
+	return opts.Width, opts.Height
+}
+
+// bumperInputArgs adds the intro and outro as whole-clip inputs, then the
+// title card's still from the source at input.
+func bumperInputArgs(opts ExportOptions, input string) []string {
+	var args []string
+	for _, b := range opts.bumpers() {
+		args = append(args, "-i", b.Path)
+	}
+	return append(args, titleInputArgs(opts, input)...)
+}
+
+// bumperGraph joins the intro, title card and outro (ffmpeg inputs from
+// input on) around the export's picture v and sound a, "" when it has
+// none, ending in [vout] and [aout]. Intro and outro are scaled into the
+// export's frame and padded with black; a bumper without sound and the
+// title card get silence, and their sound is left out of an export
+// without any.
+func bumperGraph(opts ExportOptions, v, a string, input int) []string {
+	w, h := opts.frameSize()
+	titleInput := input + len(opts.bumpers())
+	fit := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2", w, h, w, h)
+	// concat takes one frame rate, pixel format and sound layout throughout
+	same := "setsar=1,format=yuv420p"
+	if opts.FPS > 0 {
+		same = "fps=" + formatFactor(opts.FPS) + "," + same
+	}
+	const layout = "aformat=sample_rates=48000:channel_layouts=stereo"
+
+	var graph, concat []string
+	join := func(name, video, audio string) {
+		graph = append(graph, fmt.Sprintf("%s,%s[%sv]", video, same, name))
+		concat = append(concat, "["+name+"v]")
+		if a != "" {
+			graph = append(graph, fmt.Sprintf("%s,%s[%sa]", audio, layout, name))
+			concat[len(concat)-1] += "[" + name + "a]"
+		}
+	}
+	silence := func(d time.Duration) string {
+		return fmt.Sprintf("anullsrc=r=48000:cl=stereo,atrim=duration=%.3f", d.Seconds())
+	}
+	bumper := func(name string, b Bumper) {
+		audio := silence(b.Duration)
+		if b.HasAudio {
+			audio = fmt.Sprintf("[%d:a]anull", input)
+		}
+		join(name, fmt.Sprintf("[%d:v]%s", input, fit), audio)
+		input++
+	}
+
+	if opts.Intro.Path != "" {
+		bumper("intro", opts.Intro)
+	}
+	if opts.hasTitleCard() {
+		join("title", titleCardVideo(opts, w, h, titleInput), silence(opts.TitleCard.Duration))
+	}
+	join("main", v+"null", a+"anull")
+	if opts.Outro.Path != "" {
+		bumper("outro", opts.Outro)
+	}
+	if a == "" {
+		return append(graph, fmt.Sprintf("%sconcat=n=%d:v=1:a=0[vout]", strings.Join(concat, ""), len(concat)))
+	}
+	return append(graph, fmt.Sprintf("%sconcat=n=%d:v=1:a=1[vout][aout]", strings.Join(concat, ""), len(concat)))
+}
+
+// bumperArgs builds the rest of a single export's arguments, after the
+// source input, when an intro, outro or title card joins it: picture and
+// sound go through one filter_complex, with the music bed under the
+// selection only, and the export always re-encodes.
+func bumperArgs(opts ExportOptions, vf, af []string, input, output string) []string {
+	var args []string
+	next := 1
+	if opts.hasMusic() {
+		args = append(args, musicInputArgs(opts)...)
+		next++
+	}
+	args = append(args, bumperInputArgs(opts, input)...)
+
+	graph := []string{"[0:v]" + strings.Join(append([]string{"null"}, vf...), ",") + "[main]"}
+	audio := ""
+	if opts.AudioCodec != "" && !opts.DropsAudio() {
+		graph = append(graph, "[0:a]"+strings.Join(append([]string{"anull"}, af...), ",")+"[src]")
+		audio = "[src]"
+	}
+	if opts.hasMusic() {
+		graph = append(graph, musicGraph(opts, audio, 1, "[mainaudio]")...)
+		audio = "[mainaudio]"
+	}
+	graph = append(graph, bumperGraph(opts, "[main]", audio, next)...)
+	args = append(args, "-filter_complex", strings.Join(graph, ";"), "-map", "[vout]")
+	if audio != "" {
+		args = append(args, "-map", "[aout]")
+	}
+	args = append(args, encoderArgs(opts, output)...)
+	return append(args, outputArgs(opts, output)...)
+}
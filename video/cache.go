@@ -18,6 +18,7 @@ type CacheKey struct {
 type cacheEntry struct {
 	key   CacheKey
 	frame string
+	shown time.Duration // source time of the frame, see PutShown
 }
 
 type FrameCache struct {
@@ -51,6 +52,13 @@ func (c *FrameCache) quantizePosition(position time.Duration) time.Duration {
 }
 
 func (c *FrameCache) Get(position time.Duration, width, height int, quality QualityPreset) (string, bool) {
+	frame, _, ok := c.GetShown(position, width, height, quality)
+	return frame, ok
+}
+
+// GetShown is Get with the source time of the frame, which for a still
+// may be off position by up to a frame (or a GOP with fast seeking).
+func (c *FrameCache) GetShown(position time.Duration, width, height int, quality QualityPreset) (string, time.Duration, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -63,15 +71,32 @@ func (c *FrameCache) Get(position time.Duration, width, height int, quality Qual
 
 	if elem, ok := c.items[key]; ok {
 		c.order.MoveToFront(elem)
-		return elem.Value.(*cacheEntry).frame, true
+		entry := elem.Value.(*cacheEntry)
+		return entry.frame, entry.shown, true
 	}
-	return "", false
+	return "", 0, false
 }
 
+// Put stores the frame at position.
 func (c *FrameCache) Put(position time.Duration, width, height int, quality QualityPreset, frame string) {
+	c.PutShown(position, position, width, height, quality, frame)
+}
+
+// PutShown stores a frame decoded for position that is really at shown:
+// under both, so asking for position again hits, and so does playback or
+// frame stepping reaching the frame's own time.
+func (c *FrameCache) PutShown(position, shown time.Duration, width, height int, quality QualityPreset, frame string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.put(position, shown, width, height, quality, frame)
+	if c.quantizePosition(shown) != c.quantizePosition(position) {
+		c.put(shown, shown, width, height, quality, frame)
+	}
+}
+
+// put stores one entry (must be called with lock held).
+func (c *FrameCache) put(position, shown time.Duration, width, height int, quality QualityPreset, frame string) {
 	key := CacheKey{
 		Position: c.quantizePosition(position),
 		Width:    width,
@@ -81,7 +106,8 @@ func (c *FrameCache) Put(position time.Duration, width, height int, quality Qual
 
 	if elem, ok := c.items[key]; ok {
 		c.order.MoveToFront(elem)
-		elem.Value.(*cacheEntry).frame = frame
+		entry := elem.Value.(*cacheEntry)
+		entry.frame, entry.shown = frame, shown
 		return
 	}
 
@@ -93,7 +119,7 @@ func (c *FrameCache) Put(position time.Duration, width, height int, quality Qual
 		}
 	}
 
-	entry := &cacheEntry{key: key, frame: frame}
+	entry := &cacheEntry{key: key, frame: frame, shown: shown}
 	elem := c.order.PushFront(entry)
 	c.items[key] = elem
 }
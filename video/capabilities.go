@@ -0,0 +1,195 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MinFFmpegMajor is the oldest ffmpeg release lazycut is tested against.
+const MinFFmpegMajor = 4
+
+// Capabilities describes what the local ffmpeg build can do.
+type Capabilities struct {
+	Version  string // as reported by `ffmpeg -version`, e.g. "6.1.1"
+	Major    int    // 0 when the version could not be parsed (git builds)
+	Minor    int
+	Encoders map[string]bool
+	Filters  map[string]bool
+	Demuxers map[string]bool
+}
+
+// requiredEncoders and requiredFilters back the preview pipeline and basic
+// exports; lazycut cannot run without them.
+var (
+	requiredEncoders = []string{"bmp"}
+	requiredFilters  = []string{"scale", "fps", "crop"}
+)
+
+// h264Encoders are the encoders that can serve a cropped (re-encoded) export,
+// in order of preference.
+var h264Encoders = []string{
+	"libx264", "h264_videotoolbox", "h264_nvenc", "h264_qsv",
+	"h264_vaapi", "h264_amf", "h264_mf",
+}
+
+var (
+	capsOnce   sync.Once
+	capsCached *Capabilities
+	capsErr    error
+)
+
+// DetectCapabilities probes ffmpeg once per process and caches the result.
+func DetectCapabilities(ctx context.Context) (*Capabilities, error) {
+	capsOnce.Do(func() {
+		capsCached, capsErr = probeCapabilities(ctx)
+	})
+	return capsCached, capsErr
+}
+
+// FFmpegCapabilities returns the cached capability set, or nil if
+// DetectCapabilities has not run successfully.
+func FFmpegCapabilities() *Capabilities {
+	return capsCached
+}
+
+func probeCapabilities(ctx context.Context) (*Capabilities, error) {
+	caps := &Capabilities{}
+
+	versionOut, err := ffmpegOutput(ctx, "-version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ffmpeg -version: %w", err)
+	}
+	caps.Version, caps.Major, caps.Minor = parseFFmpegVersion(versionOut)
+
+	if caps.Encoders, err = ffmpegList(ctx, "-encoders"); err != nil {
+		return nil, err
+	}
+	if caps.Filters, err = ffmpegList(ctx, "-filters"); err != nil {
+		return nil, err
+	}
+	if caps.Demuxers, err = ffmpegList(ctx, "-demuxers"); err != nil {
+		return nil, err
+	}
+	return caps, nil
+}
+
+func ffmpegOutput(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := command(ctx, "ffmpeg", append([]string{"-hide_banner"}, args...)...)
+	return cmd.Output()
+}
+
+var versionPattern = regexp.MustCompile(`ffmpeg version n?(\d+)\.(\d+)(\.\d+)?`)
+
+func parseFFmpegVersion(out []byte) (string, int, int) {
+	firstLine, _, _ := strings.Cut(string(out), "\n")
+	m := versionPattern.FindStringSubmatch(firstLine)
+	if m == nil {
+		// Git snapshots report e.g. "N-113000-g..."; treat as recent
+		if fields := strings.Fields(firstLine); len(fields) >= 3 {
+			return fields[2], 0, 0
+		}
+		return "unknown", 0, 0
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return m[1] + "." + m[2] + m[3], major, minor
+}
+
+var flagColumn = regexp.MustCompile(`^[A-Z.|]+$`)
+
+// ffmpegList parses the tabular output of -encoders/-filters/-demuxers.
+// Entries look like " V....D libx264  ..." or " D  mov,mp4,m4a  ...";
+// legend lines ("V..... = Video") are skipped.
+func ffmpegList(ctx context.Context, flag string) (map[string]bool, error) {
+	out, err := ffmpegOutput(ctx, flag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ffmpeg %s: %w", flag, err)
+	}
+	names := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] == "=" || !flagColumn.MatchString(fields[0]) {
+			continue
+		}
+		for _, name := range strings.Split(fields[1], ",") {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+func (c *Capabilities) HasEncoder(name string) bool { return c != nil && c.Encoders[name] }
+func (c *Capabilities) HasFilter(name string) bool  { return c != nil && c.Filters[name] }
+func (c *Capabilities) HasDemuxer(name string) bool { return c != nil && c.Demuxers[name] }
+
+// H264Encoder returns the preferred available H.264 encoder, or "" if the
+// build has none.
+func (c *Capabilities) H264Encoder() string {
+	for _, name := range h264Encoders {
+		if c.HasEncoder(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// IsH264Encoder reports whether name is one of the H.264 encoders lazycut
+// knows, software or hardware.
+func IsH264Encoder(name string) bool {
+	return slices.Contains(h264Encoders, name)
+}
+
+// SoftwareFallback returns the encoder to retry with when encoder fails:
+// libx264 where this ffmpeg has it, otherwise "" for the container's
+// default. ok is false when encoder already is that fallback.
+func SoftwareFallback(encoder string) (fallback string, ok bool) {
+	if FFmpegCapabilities().HasEncoder("libx264") {
+		return "libx264", encoder != "libx264"
+	}
+	return "", encoder != ""
+}
+
+// describeEncoder names encoder for messages, "" being ffmpeg's default.
+func describeEncoder(encoder string) string {
+	if encoder == "" {
+		return "ffmpeg's default encoder"
+	}
+	return encoder
+}
+
+// CanCrop reports whether cropped exports (which must re-encode) are possible.
+func (c *Capabilities) CanCrop() bool {
+	return c.H264Encoder() != ""
+}
+
+// CanBlurFill reports whether blurred-background exports are possible.
+func (c *Capabilities) CanBlurFill() bool {
+	return c.CanCrop() && c.HasFilter("split") && c.HasFilter("boxblur") && c.HasFilter("overlay")
+}
+
+// Check returns an error describing the first missing hard requirement.
+func (c *Capabilities) Check() error {
+	if c.Major != 0 && c.Major < MinFFmpegMajor {
+		return fmt.Errorf("ffmpeg %s is too old (need %d.0 or newer). Install: %s",
+			c.Version, MinFFmpegMajor, getInstallCommand("ffmpeg"))
+	}
+	for _, name := range requiredEncoders {
+		if !c.HasEncoder(name) {
+			return fmt.Errorf("ffmpeg build is missing the %q encoder required for preview", name)
+		}
+	}
+	for _, name := range requiredFilters {
+		if !c.HasFilter(name) {
+			return fmt.Errorf("ffmpeg build is missing the %q filter", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package video
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CaptionsPath is the subtitle file written beside the export to output:
+// the same name with .srt, which players pick up automatically.
+func CaptionsPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".srt"
+}
+
+// ClipCaptions returns the cues of opts.Captions that show during the
+// selection, cut to it and re-timed to the output: starting at zero, after
+// any intro, title card and freeze-in, and stretched or squeezed by the speed.
+func (opts ExportOptions) ClipCaptions() []Cue {
+	speed := opts.speed()
+	at := func(t time.Duration) time.Duration {
+		return opts.leadIn() + opts.FreezeStart + time.Duration(float64(t-opts.InPoint)/speed)
+	}
+	var clipped []Cue
+	for _, c := range opts.Captions {
+		if c.End <= opts.InPoint || c.Start >= opts.OutPoint {
+			continue
+		}
+		clipped = append(clipped, Cue{
+			Start: at(max(c.Start, opts.InPoint)),
+			End:   at(min(c.End, opts.OutPoint)),
+			Text:  c.Text,
+		})
+	}
+	return clipped
+}
+
+// WriteSRT writes cues as SubRip.
+func WriteSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, c := range cues {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n\n", i+1, srtTime(c.Start), srtTime(c.End), c.Text)
+	}
+	return bw.Flush()
+}
+
+// srtTime formats d as SubRip's HH:MM:SS,mmm.
+func srtTime(d time.Duration) string {
+	return strings.Replace(FormatTimecode(d), ".", ",", 1)
+}
+
+// writeCaptions saves the selection's captions beside output. A selection
+// without any speech writes nothing.
+func writeCaptions(opts ExportOptions, output string) error {
+	cues := opts.ClipCaptions()
+	if len(cues) == 0 {
+		return nil
+	}
+	f, err := os.Create(CaptionsPath(output))
+	if err != nil {
+		return err
+	}
+	err = WriteSRT(f, cues)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -2,7 +2,9 @@ package video
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 type QualityPreset int
@@ -26,7 +28,23 @@ func (q QualityPreset) Next() QualityPreset {
 	return (q + 1) % 2
 }
 
+// MarshalText writes the preset as "low" or "high" for settings files.
+func (q QualityPreset) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(q.String())), nil
+}
+
+func (q *QualityPreset) UnmarshalText(text []byte) error {
+	for _, p := range []QualityPreset{QualityLow, QualityHigh} {
+		if strings.EqualFold(string(text), p.String()) {
+			*q = p
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown quality %q (want low or high)", text)
+}
+
 type ChafaConfig struct {
+	Format         string // --format, "" for symbols; see SetGraphics
 	Colors         string
 	Optimize       int
 	Work           int
@@ -46,11 +64,39 @@ var ChafaPresets = map[QualityPreset]ChafaConfig{
 	},
 }
 
+// chafaColorRank orders chafa --colors values from fewest to most colors.
+var chafaColorRank = map[string]int{
+	"none": 0, "2": 1, "8": 2, "16/8": 3, "16": 4, "240": 5, "256": 6, "full": 7,
+}
+
+// maxColors caps every preset's color mode; "full" leaves presets untouched.
+var maxColors = "full"
+
+// SetMaxColors limits chafa output to the given --colors value (for example
+// "256" on terminals without truecolor, "none" under NO_COLOR).
+func SetMaxColors(colors string) {
+	if _, ok := chafaColorRank[colors]; ok {
+		maxColors = colors
+	}
+}
+
+// colors returns the preset's color mode clamped to maxColors.
+func (c ChafaConfig) colors() string {
+	if chafaColorRank[c.Colors] > chafaColorRank[maxColors] {
+		return maxColors
+	}
+	return c.Colors
+}
+
 func (c ChafaConfig) BuildArgs(width, height int) []string {
+	format := c.Format
+	if format == "" {
+		format = "symbols"
+	}
 	return []string{
-		"--format=symbols",
+		"--format=" + format,
 		"--size", fmt.Sprintf("%dx%d", width, height),
-		"--colors", c.Colors,
+		"--colors", c.colors(),
 		"-O", strconv.Itoa(c.Optimize),
 		"--work", strconv.Itoa(c.Work),
 		"--color-space", c.ColorSpace,
@@ -59,3 +105,42 @@ func (c ChafaConfig) BuildArgs(width, height int) []string {
 		"-",
 	}
 }
+
+// Set changes one option of the preset by its config file name: colors,
+// optimize (0-9), work (1-9), color_space, dither or color_extractor.
+func (c *ChafaConfig) Set(key, value string) error {
+	oneOf := func(field *string, choices ...string) error {
+		if !slices.Contains(choices, value) {
+			return fmt.Errorf("invalid %s %q (want %s)", key, value, strings.Join(choices, ", "))
+		}
+		*field = value
+		return nil
+	}
+	level := func(field *int, lo, hi int) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < lo || n > hi {
+			return fmt.Errorf("invalid %s %q (want %d-%d)", key, value, lo, hi)
+		}
+		*field = n
+		return nil
+	}
+	switch key {
+	case "colors":
+		if _, ok := chafaColorRank[value]; !ok {
+			return fmt.Errorf("invalid colors %q (want none, 2, 8, 16/8, 16, 240, 256 or full)", value)
+		}
+		c.Colors = value
+		return nil
+	case "optimize":
+		return level(&c.Optimize, 0, 9)
+	case "work":
+		return level(&c.Work, 1, 9)
+	case "color_space":
+		return oneOf(&c.ColorSpace, "rgb", "din99d")
+	case "dither":
+		return oneOf(&c.Dither, "none", "ordered", "diffusion", "noise")
+	case "color_extractor":
+		return oneOf(&c.ColorExtractor, "average", "median")
+	}
+	return fmt.Errorf("unknown chafa option %q", key)
+}
@@ -0,0 +1,74 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ClipboardTool is the program that puts files on the clipboard here:
+// osascript on macOS, wl-copy under Wayland and xclip under X11, or ""
+// when none is available.
+func ClipboardTool() string {
+	var candidates []string
+	switch {
+	case runtime.GOOS == "darwin":
+		candidates = []string{"osascript"}
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		candidates = []string{"wl-copy", "xclip"}
+	case os.Getenv("DISPLAY") != "":
+		candidates = []string{"xclip"}
+	}
+	for _, tool := range candidates {
+		if _, err := lookPath(tool); err == nil {
+			return tool
+		}
+	}
+	return ""
+}
+
+var errNoClipboard = errors.New("no clipboard tool: install wl-copy (wl-clipboard) or xclip")
+
+// CopyFileToClipboard puts path on the clipboard as a file, not its name,
+// so that pasting into a chat app or file manager attaches the file.
+func CopyFileToClipboard(ctx context.Context, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	uri := (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String() + "\n"
+
+	var cmd *exec.Cmd
+	switch tool := ClipboardTool(); tool {
+	case "osascript":
+		// The path goes in as an argument, so no quoting is needed
+		cmd = command(ctx, tool,
+			"-e", "on run argv",
+			"-e", "set the clipboard to (POSIX file (item 1 of argv))",
+			"-e", "end run",
+			abs)
+	case "wl-copy":
+		cmd = command(ctx, tool, "--type", "text/uri-list")
+		cmd.Stdin = strings.NewReader(uri)
+	case "xclip":
+		cmd = command(ctx, tool, "-selection", "clipboard", "-t", "text/uri-list")
+		cmd.Stdin = strings.NewReader(uri)
+	default:
+		return errNoClipboard
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		detail, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+		return fmt.Errorf("%w: %s", err, detail)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package video
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Container is the output file format chosen in the export modal.
+type Container int
+
+const (
+	ContainerSource        Container = iota // keep the input's (or the typed name's) format
+	ContainerMP4                            // MP4
+	ContainerFragmentedMP4                  // MP4 in fragments, playable while it is still arriving
+	ContainerWebM                           // WebM with its cues (seek index) at the front
+	ContainerWebP                           // animated WebP, see Animation
+	ContainerAPNG                           // animated PNG, see Animation
+)
+
+var ContainerOptions = []struct {
+	Container Container
+	Label     string
+	Ext       string // "" keeps the extension
+}{
+	{ContainerSource, "Source", ""},
+	{ContainerMP4, "MP4", ".mp4"},
+	{ContainerFragmentedMP4, "fMP4", ".mp4"},
+	{ContainerWebM, "WebM", ".webm"},
+	{ContainerWebP, "WebP", ".webp"},
+	{ContainerAPNG, "APNG", ".apng"},
+}
+
+// containerExt returns the extension c forces on the output, if any.
+func containerExt(c Container) string {
+	for _, opt := range ContainerOptions {
+		if opt.Container == c {
+			return opt.Ext
+		}
+	}
+	return ""
+}
+
+// copyCodecs lists the codecs each extension can take by stream copy.
+// Extensions missing here (MKV, MOV, TS...) take practically anything.
+var copyCodecs = map[string]struct{ video, audio []string }{
+	".mp4": {
+		video: []string{"h264", "hevc", "av1", "vp9", "mpeg4"},
+		audio: []string{"aac", "mp3", "opus", "flac", "alac", "ac3", "eac3"},
+	},
+	".webm": {
+		video: []string{"vp8", "vp9", "av1"},
+		audio: []string{"opus", "vorbis"},
+	},
+	// Animations are always encoded frame by frame
+	".webp": {},
+	".apng": {},
+}
+
+func init() {
+	copyCodecs[".m4v"] = copyCodecs[".mp4"]
+}
+
+// CopyCompatible reports whether streams in the given codecs can be
+// stream copied into a file named output. Unknown (empty) codecs are
+// assumed to fit; the mux fails loudly if they do not.
+func CopyCompatible(output, videoCodec, audioCodec string) (videoOK, audioOK bool) {
+	allowed, ok := copyCodecs[strings.ToLower(filepath.Ext(output))]
+	if !ok {
+		return true, true
+	}
+	fits := func(codec string, list []string) bool {
+		return codec == "" || slices.Contains(list, codec)
+	}
+	return fits(videoCodec, allowed.video), fits(audioCodec, allowed.audio)
+}
+
+// CopyProblem describes which source streams must be re-encoded because
+// they cannot be copied into output, or "" when both can.
+func (opts ExportOptions) CopyProblem(output string) string {
+	if opts.Animated() {
+		return "encoded frame by frame, without audio"
+	}
+	videoOK, audioOK := CopyCompatible(output, opts.VideoCodec, opts.AudioCodec)
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(output)), ".")
+	switch {
+	case !videoOK && !audioOK:
+		return opts.VideoCodec + " video and " + opts.AudioCodec + " audio re-encode for " + ext
+	case !videoOK:
+		return opts.VideoCodec + " video re-encodes for " + ext
+	case !audioOK:
+		return opts.AudioCodec + " audio re-encodes for " + ext
+	}
+	return ""
+}
+
+// muxerArgs returns the container flags for output.
+func muxerArgs(opts ExportOptions, output string) []string {
+	switch {
+	case opts.Animated():
+		return animationMuxerArgs(opts, output)
+	case opts.Container == ContainerFragmentedMP4:
+		// Fragments carry their own index, which replaces faststart
+		return []string{"-movflags", "+frag_keyframe+empty_moov+default_base_moof"}
+	case strings.EqualFold(filepath.Ext(output), ".webm"):
+		return []string{"-cues_to_front", "1"}
+	case opts.FastStart && SupportsFastStart(output):
+		return []string{"-movflags", "+faststart"}
+	}
+	return nil
+}
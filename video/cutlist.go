@@ -0,0 +1,211 @@
+package video
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cut is one range of a cut list.
+type Cut struct {
+	In   time.Duration
+	Out  time.Duration
+	Note string
+}
+
+// CutListFormats are the cut list file formats, by extension.
+var CutListFormats = []string{"edl", "csv", "json"}
+
+// WriteCutList writes cuts of source (at fps) to path as format: a CMX
+// 3600 EDL that DaVinci Resolve and Premiere import, or CSV or JSON for
+// scripts.
+func WriteCutList(path, format, source string, fps float64, cuts []Cut) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "edl":
+		err = writeEDL(f, source, fps, cuts)
+	case "csv":
+		err = writeCutCSV(f, cuts)
+	case "json":
+		err = writeCutJSON(f, source, fps, cuts)
+	default:
+		err = fmt.Errorf("unknown cut list format %q", format)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// edlTimecode formats d as HH:MM:SS:FF, non-drop-frame at the nearest
+// whole frame rate, as EDLs count frames.
+func edlTimecode(d time.Duration, fps float64) string {
+	rate := max(1, int(math.Round(fps)))
+	frames := int(math.Round(d.Seconds() * float64(rate)))
+	return fmt.Sprintf("%02d:%02d:%02d:%02d",
+		frames/(3600*rate), frames/(60*rate)%60, frames/rate%60, frames%rate)
+}
+
+func writeEDL(w io.Writer, source string, fps float64, cuts []Cut) error {
+	name := filepath.Base(source)
+	title := strings.TrimSuffix(name, filepath.Ext(name))
+	fmt.Fprintf(w, "TITLE: %s\nFCM: NON-DROP FRAME\n\n", title)
+	// The record side lays the cuts end to end, as a sequence
+	var record time.Duration
+	for i, cut := range cuts {
+		length := cut.Out - cut.In
+		fmt.Fprintf(w, "%03d  AX       AA/V  C        %s %s %s %s\n", i+1,
+			edlTimecode(cut.In, fps), edlTimecode(cut.Out, fps),
+			edlTimecode(record, fps), edlTimecode(record+length, fps))
+		fmt.Fprintf(w, "* FROM CLIP NAME: %s\n", name)
+		if cut.Note != "" {
+			fmt.Fprintf(w, "* COMMENT: %s\n", strings.Join(strings.Fields(cut.Note), " "))
+		}
+		fmt.Fprintln(w)
+		record += length
+	}
+	return nil
+}
+
+// LoadEDL reads the events of a CMX 3600 EDL at path as cuts of a source
+// at fps: their source in- and out-points, with a "* COMMENT:" line below
+// an event as its note. Every event is read, whichever reel or clip it
+// names.
+func LoadEDL(path string, fps float64) ([]Cut, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cuts, err := readEDL(f, fps)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return cuts, nil
+}
+
+func readEDL(r io.Reader, fps float64) ([]Cut, error) {
+	var cuts []Cut
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if note, ok := strings.CutPrefix(line, "* COMMENT:"); ok && len(cuts) > 0 {
+			cuts[len(cuts)-1].Note = strings.TrimSpace(note)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 8 || !isDigits(fields[0]) {
+			// TITLE, FCM, clip names and other comments
+			continue
+		}
+		// The source in and out come before the record in and out, last
+		tc := fields[len(fields)-4:]
+		in, err := parseEDLTimecode(tc[0], fps)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		out, err := parseEDLTimecode(tc[1], fps)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		// The outgoing side of a dissolve has no length
+		if out > in {
+			cuts = append(cuts, Cut{In: in, Out: out})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(cuts) == 0 {
+		return nil, fmt.Errorf("no events")
+	}
+	return cuts, nil
+}
+
+// parseEDLTimecode reads HH:MM:SS:FF at the nearest whole frame rate, as
+// edlTimecode writes it, or drop-frame HH:MM:SS;FF at 29.97 or 59.94.
+func parseEDLTimecode(s string, fps float64) (time.Duration, error) {
+	cut := strings.LastIndexAny(s, ":;")
+	if cut < 0 || strings.Count(s, ":")+strings.Count(s, ";") != 3 {
+		return 0, fmt.Errorf("invalid timecode %q: want HH:MM:SS:FF", s)
+	}
+	clock, err := ParseTimecode(s[:cut])
+	if err != nil {
+		return 0, fmt.Errorf("invalid timecode %q: %w", s, err)
+	}
+	rate := max(1, int(math.Round(fps)))
+	frames, err := strconv.Atoi(s[cut+1:])
+	if err != nil || frames < 0 || frames >= rate || !isDigits(s[cut+1:]) {
+		return 0, fmt.Errorf("invalid timecode %q: frame %s at %d fps", s, s[cut+1:], rate)
+	}
+	if s[cut] == ';' && rate%30 == 0 {
+		// Drop-frame numbering skips the first rate/15 frame numbers of
+		// every minute but each tenth, to keep up with the clock
+		seconds := int(clock / time.Second)
+		minutes := seconds / 60
+		number := seconds*rate + frames - rate/15*(minutes-minutes/10)
+		return time.Duration(number) * 1001 * time.Second / time.Duration(rate*1000), nil
+	}
+	return clock + time.Duration(frames)*time.Second/time.Duration(rate), nil
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+func writeCutCSV(w io.Writer, cuts []Cut) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"index", "in", "out", "duration", "in_timecode", "out_timecode", "note"})
+	for i, cut := range cuts {
+		cw.Write([]string{
+			strconv.Itoa(i + 1),
+			strconv.FormatFloat(cut.In.Seconds(), 'f', 3, 64),
+			strconv.FormatFloat(cut.Out.Seconds(), 'f', 3, 64),
+			strconv.FormatFloat((cut.Out - cut.In).Seconds(), 'f', 3, 64),
+			FormatTimecode(cut.In),
+			FormatTimecode(cut.Out),
+			cut.Note,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCutJSON(w io.Writer, source string, fps float64, cuts []Cut) error {
+	type jsonCut struct {
+		In       float64 `json:"in"`
+		Out      float64 `json:"out"`
+		Duration float64 `json:"duration"`
+		Note     string  `json:"note,omitempty"`
+	}
+	list := struct {
+		Source string    `json:"source"`
+		FPS    float64   `json:"fps"`
+		Cuts   []jsonCut `json:"cuts"`
+	}{Source: source, FPS: fps}
+	for _, cut := range cuts {
+		list.Cuts = append(list.Cuts, jsonCut{
+			In:       cut.In.Seconds(),
+			Out:      cut.Out.Seconds(),
+			Duration: (cut.Out - cut.In).Seconds(),
+			Note:     cut.Note,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
@@ -0,0 +1,99 @@
+package video_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/video"
+)
+
+func TestEDLRoundTrip(t *testing.T) {
+	cuts := []video.Cut{
+		{In: time.Second, Out: 2500 * time.Millisecond, Note: "first  goal"},
+		{In: time.Minute, Out: time.Minute + 10*time.Second},
+		{In: time.Hour + 100*time.Millisecond, Out: time.Hour + 5*time.Second, Note: "replay"},
+	}
+	path := filepath.Join(t.TempDir(), "cuts.edl")
+	if err := video.WriteCutList(path, "edl", "match.mp4", 30, cuts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := video.LoadEDL(path, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Notes are written on one line, with runs of spaces collapsed
+	cuts[0].Note = "first goal"
+	if !reflect.DeepEqual(got, cuts) {
+		t.Errorf("read back %+v, want %+v", got, cuts)
+	}
+}
+
+func TestLoadEDL(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fps  float64
+		edl  string
+		want []video.Cut
+	}{
+		{"resolve", 25, `TITLE: Timeline 1
+FCM: NON-DROP FRAME
+
+001  AX       V     C        00:00:10:00 00:00:12:12 01:00:00:00 01:00:02:12
+* FROM CLIP NAME: match.mp4
+
+002  AX       V     C        00:00:20:24 00:00:21:00 01:00:02:12 01:00:02:13
+`, []video.Cut{
+			{In: 10 * time.Second, Out: 12*time.Second + 480*time.Millisecond},
+			{In: 20*time.Second + 960*time.Millisecond, Out: 21 * time.Second},
+		}},
+		{"dissolve", 30, `002  AX       V     C        00:00:05:00 00:00:05:00 01:00:00:00 01:00:00:00
+002  BX       V     D    015 00:00:08:00 00:00:09:00 01:00:00:00 01:00:01:00
+* COMMENT: cross
+`, []video.Cut{{In: 8 * time.Second, Out: 9 * time.Second, Note: "cross"}}},
+		{"drop frame", 29.97, `FCM: DROP FRAME
+001  AX       V     C        00:00:59;28 00:01:00;02 01:00:00;00 01:00:00;03
+`, []video.Cut{{
+			// Frames 1798 and 1800: 00:01:00;00 and ;01 do not exist
+			In:  1798 * 1001 * time.Second / 30000,
+			Out: 1800 * 1001 * time.Second / 30000,
+		}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "in.edl")
+			if err := os.WriteFile(path, []byte(tc.edl), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := video.LoadEDL(path, tc.fps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadEDLErrors(t *testing.T) {
+	for _, tc := range []struct {
+		edl, want string
+	}{
+		{"TITLE: empty\n", "no events"},
+		{"001  AX  V  C  00:00:01:30 00:00:02:00 01:00:00:00 01:00:01:00\n", "line 1: invalid timecode \"00:00:01:30\": frame 30 at 30 fps"},
+		{"\n001  AX  V  C  00:00:61:00 00:01:02:00 01:00:00:00 01:00:01:00\n", "line 2: invalid timecode \"00:00:61:00\""},
+		{"001  AX  V  C  00:01:00 00:01:02:00 01:00:00:00 01:00:01:00\n", "want HH:MM:SS:FF"},
+	} {
+		path := filepath.Join(t.TempDir(), "bad.edl")
+		if err := os.WriteFile(path, []byte(tc.edl), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := video.LoadEDL(path, 30)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: error %v, want %q", tc.edl, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// demoMode is set once at startup, like graphicsMode.
+var demoMode bool
+
+// SetDemo replaces every preview frame with a synthetic one drawn from its
+// position alone, and paces playback by frame count instead of the wall
+// clock, so that recordings of the UI come out the same every time and on
+// any machine. Exports still encode the real file.
+func SetDemo(on bool) {
+	demoMode = on
+}
+
+// DemoClip returns a generated test pattern clip with a tone, written to
+// the user cache directory the first time, to run --demo without a
+// sample video.
+func DemoClip(ctx context.Context) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	dir = filepath.Join(dir, "lazycut")
+	path := filepath.Join(dir, "demo_640x360_30s.mp4")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	// mpeg4 ships with every ffmpeg build, unlike libx264
+	tmp := path + ".part.mp4"
+	err = runFFmpeg(ctx, []string{"-y", "-loglevel", "error",
+		"-f", "lavfi", "-i", "testsrc2=size=640x360:rate=30:duration=30",
+		"-f", "lavfi", "-i", "sine=frequency=440:duration=30",
+		"-c:v", "mpeg4", "-q:v", "4", "-pix_fmt", "yuv420p", "-g", "30",
+		"-c:a", "aac", "-shortest", tmp,
+	})
+	if err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("generating the demo clip: %w", err)
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// demoBars are the colors of the synthetic frame's bars, left to right.
+var demoBars = []lipgloss.Color{"#c0c0c0", "#c0c000", "#00c0c0", "#00c000", "#c000c0", "#c00000", "#0000c0"}
+
+// demoFrame is the synthetic frame at position: color bars that scroll
+// one cell every 1/8s, with the timecode in the middle.
+func demoFrame(position time.Duration, width, height int) string {
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	shift := int(position * 8 / time.Second)
+	var bars strings.Builder
+	for x := 0; x < width; {
+		bar := (x + shift) * len(demoBars) / width % len(demoBars)
+		run := 1
+		for x+run < width && (x+run+shift)*len(demoBars)/width%len(demoBars) == bar {
+			run++
+		}
+		bars.WriteString(lipgloss.NewStyle().Background(demoBars[bar]).Render(strings.Repeat(" ", run)))
+		x += run
+	}
+
+	label := " " + FormatTimecode(position) + " "
+	labelLine := lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#ffffff")).Background(lipgloss.Color("#000000")).Render(label))
+	lines := make([]string, height)
+	for y := range lines {
+		lines[y] = bars.String()
+	}
+	if len(label) <= width {
+		lines[height/2] = labelLine
+	}
+	return strings.Join(lines, "\n")
+}
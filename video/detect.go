@@ -0,0 +1,114 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval is a span of the input, such as a run of black frames.
+type Interval struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Black frame detection thresholds: a frame is black when 98% of its
+// pixels are below 10% luma, and a run counts from 0.1s.
+const (
+	blackMinDuration = 0.1
+	blackPixelLevel  = 0.10
+)
+
+// DetectBlack runs ffmpeg blackdetect over the whole input and returns
+// the black runs in order. It decodes every frame, so callers run it in
+// the background.
+func DetectBlack(ctx context.Context, path string, duration time.Duration) ([]Interval, error) {
+	filter := fmt.Sprintf("blackdetect=d=%g:pix_th=%.2f", blackMinDuration, blackPixelLevel)
+	return detectIntervals(ctx, duration, "black",
+		"-i", path, "-map", "0:v:0", "-vf", filter, "-an", "-f", "null", "-")
+}
+
+// Silence detection thresholds: quieter than -40 dBFS (room tone, not
+// quiet speech) for at least half a second.
+const (
+	silenceNoiseDB     = -40
+	SilenceMinDuration = 500 * time.Millisecond
+)
+
+// DetectSilence runs ffmpeg silencedetect over the audio and returns the
+// silent runs in order.
+func DetectSilence(ctx context.Context, path string, duration time.Duration) ([]Interval, error) {
+	filter := fmt.Sprintf("silencedetect=noise=%ddB:d=%g", silenceNoiseDB, SilenceMinDuration.Seconds())
+	return detectIntervals(ctx, duration, "silence",
+		"-i", path, "-map", "0:a:0", "-af", filter, "-vn", "-f", "null", "-")
+}
+
+// detectIntervals runs ffmpeg with a *detect filter and collects the
+// "<kind>_start" / "<kind>_end" pairs it logs. A run still open when the
+// input ends is closed at duration.
+func detectIntervals(ctx context.Context, duration time.Duration, kind string, args ...string) ([]Interval, error) {
+	cmd := command(ctx, "ffmpeg", append([]string{"-hide_banner", "-nostats"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		return nil, fmt.Errorf("%sdetect failed: %w: %s", kind, err, lines[len(lines)-1])
+	}
+	return parseIntervals(stderr.Bytes(), kind, duration), nil
+}
+
+var detectField = regexp.MustCompile(`(\w+)_(start|end): ?(-?[0-9.]+)`)
+
+func parseIntervals(log []byte, kind string, duration time.Duration) []Interval {
+	var intervals []Interval
+	open := false
+	for _, m := range detectField.FindAllSubmatch(log, -1) {
+		if string(m[1]) != kind {
+			continue
+		}
+		secs, err := strconv.ParseFloat(string(m[3]), 64)
+		if err != nil {
+			continue
+		}
+		t := max(0, time.Duration(math.Round(secs*1000))*time.Millisecond)
+		switch string(m[2]) {
+		case "start":
+			intervals = append(intervals, Interval{Start: t, End: duration})
+			open = true
+		case "end":
+			if open {
+				intervals[len(intervals)-1].End = t
+				open = false
+			}
+		}
+	}
+	return intervals
+}
+
+// TrimEdges returns the selection left after dropping the intervals that
+// touch the start or end of a duration-long input, with tolerance for
+// detectors that stop a frame or two short of either edge. ok is false
+// when neither edge is covered or nothing would be left.
+func TrimEdges(intervals []Interval, duration, tolerance time.Duration) (in, out time.Duration, ok bool) {
+	in, out = 0, duration
+	for _, iv := range intervals {
+		if iv.Start <= tolerance && iv.End > in {
+			in = iv.End
+		}
+		if iv.End >= duration-tolerance && iv.Start < out {
+			out = iv.Start
+		}
+	}
+	if (in == 0 && out == duration) || out <= in {
+		return 0, 0, false
+	}
+	return in, out, true
+}
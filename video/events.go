@@ -0,0 +1,234 @@
+package video
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MarkImporter reads the marks of one kind of event file: a game
+// recorder's match log, a CI failure report, an exported chat. It returns
+// marks as stamped in the file; LoadEventFile applies the start offset.
+type MarkImporter interface {
+	// Name is what --events-format calls the format.
+	Name() string
+	// Match reports whether path looks like this format, by its name.
+	Match(path string) bool
+	Import(r io.Reader) ([]Mark, error)
+}
+
+var (
+	importersMu   sync.RWMutex
+	markImporters = []MarkImporter{jsonImporter{}, csvImporter{comma: ','}, csvImporter{comma: '\t'}}
+)
+
+// RegisterMarkImporter adds an importer, tried before the built-in ones.
+func RegisterMarkImporter(imp MarkImporter) {
+	importersMu.Lock()
+	defer importersMu.Unlock()
+	markImporters = append([]MarkImporter{imp}, markImporters...)
+}
+
+// FindMarkImporter returns the importer called name, or for "" the first
+// one matching path; anything unmatched is read as log lines.
+func FindMarkImporter(name, path string) (MarkImporter, error) {
+	importersMu.RLock()
+	defer importersMu.RUnlock()
+	for _, imp := range append(markImporters, logImporter{}) {
+		if name == imp.Name() || name == "" && imp.Match(path) {
+			return imp, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown event format %q (want %s)", name, strings.Join(MarkFormats(), ", "))
+}
+
+// MarkFormats lists the importer names.
+func MarkFormats() []string {
+	importersMu.RLock()
+	defer importersMu.RUnlock()
+	var names []string
+	for _, imp := range append(markImporters, logImporter{}) {
+		names = append(names, imp.Name())
+	}
+	return names
+}
+
+// LoadEventFile reads the marks of the event file at path with the
+// importer called format ("" picks one by the file name), shifted back by
+// start like ReadMarks.
+func LoadEventFile(path, format string, start time.Duration) ([]Mark, error) {
+	imp, err := FindMarkImporter(format, path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	marks, err := imp.Import(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return shiftMarks(marks, start), nil
+}
+
+// Field names recognized in JSON and CSV event files, in order of
+// preference.
+var (
+	timeFields  = []string{"time", "timestamp", "ts", "t", "at", "start", "offset", "seconds"}
+	labelFields = []string{"label", "name", "title", "event", "type", "message", "msg", "text", "description"}
+)
+
+// fieldTime reads a time value of an event file: seconds as a number, or
+// a string with a timestamp in it as in a log line.
+func fieldTime(s string) (time.Duration, bool) {
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(f * float64(time.Second)), f >= 0
+	}
+	at, _, ok := splitMarkLine(s)
+	return at, ok
+}
+
+// jsonImporter reads an array of event objects, an object holding one
+// under "events", "markers" or "marks", or one object per line (JSON
+// Lines).
+type jsonImporter struct{}
+
+func (jsonImporter) Name() string { return "json" }
+
+func (jsonImporter) Match(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+func (jsonImporter) Import(r io.Reader) ([]Mark, error) {
+	var marks []Mark
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		var v any
+		if err := dec.Decode(&v); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		marks = append(marks, jsonMarks(v)...)
+	}
+	return marks, nil
+}
+
+func jsonMarks(v any) []Mark {
+	switch v := v.(type) {
+	case []any:
+		var marks []Mark
+		for _, e := range v {
+			marks = append(marks, jsonMarks(e)...)
+		}
+		return marks
+	case map[string]any:
+		for _, key := range []string{"events", "markers", "marks"} {
+			if list, ok := v[key].([]any); ok {
+				return jsonMarks(list)
+			}
+		}
+		at, ok := fieldTime(jsonField(v, timeFields))
+		if !ok {
+			return nil
+		}
+		return []Mark{{At: at, Label: strings.Join(strings.Fields(jsonField(v, labelFields)), " ")}}
+	}
+	return nil
+}
+
+// jsonField returns the first of names present in v as a string.
+func jsonField(v map[string]any, names []string) string {
+	for _, name := range names {
+		switch value := v[name].(type) {
+		case string:
+			return value
+		case json.Number:
+			return value.String()
+		}
+	}
+	return ""
+}
+
+// csvImporter reads comma- or tab-separated events with a header naming
+// the time and label columns; without a header, the first column is the
+// time and the second the label.
+type csvImporter struct {
+	comma rune
+}
+
+func (c csvImporter) Name() string {
+	if c.comma == '\t' {
+		return "tsv"
+	}
+	return "csv"
+}
+
+func (c csvImporter) Match(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), "."+c.Name())
+}
+
+func (c csvImporter) Import(r io.Reader) ([]Mark, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = c.comma
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	timeCol, labelCol := 0, 1
+	if _, ok := fieldTime(rows[0][0]); !ok {
+		header := rows[0]
+		rows = rows[1:]
+		column := func(names []string) int {
+			for _, name := range names {
+				if i := slices.IndexFunc(header, func(h string) bool {
+					return strings.EqualFold(strings.TrimSpace(h), name)
+				}); i >= 0 {
+					return i
+				}
+			}
+			return -1
+		}
+		if timeCol = column(timeFields); timeCol < 0 {
+			return nil, fmt.Errorf("no time column (want one of %s)", strings.Join(timeFields, ", "))
+		}
+		labelCol = column(labelFields)
+	}
+
+	var marks []Mark
+	for _, row := range rows {
+		if timeCol >= len(row) {
+			continue
+		}
+		at, ok := fieldTime(row[timeCol])
+		if !ok {
+			continue
+		}
+		var label string
+		if labelCol >= 0 && labelCol < len(row) {
+			label = strings.Join(strings.Fields(row[labelCol]), " ")
+		}
+		marks = append(marks, Mark{At: at, Label: label})
+	}
+	return marks, nil
+}
@@ -2,12 +2,15 @@ package video
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,130 +22,627 @@ const (
 	Aspect9x16                 // Portrait/Mobile
 	Aspect1x1                  // Square
 	Aspect4x5                  // Instagram Portrait
+	AspectBlur9x16             // Portrait over a blurred copy of itself
 )
 
 var AspectRatioOptions = []struct {
 	Ratio AspectRatio
 	Label string
-	W, H  int // ratio components (0,0 means original)
+	W, H  int  // ratio components (0,0 means original)
+	Fill  bool // fit the whole frame over a blurred background instead of cropping
 }{
-	{AspectOriginal, "Original", 0, 0},
-	{Aspect16x9, "16:9", 16, 9},
-	{Aspect9x16, "9:16", 9, 16},
-	{Aspect1x1, "1:1", 1, 1},
-	{Aspect4x5, "4:5", 4, 5},
+	{AspectOriginal, "Original", 0, 0, false},
+	{Aspect16x9, "16:9", 16, 9, false},
+	{Aspect9x16, "9:16", 9, 16, false},
+	{Aspect1x1, "1:1", 1, 1, false},
+	{Aspect4x5, "4:5", 4, 5, false},
+	{AspectBlur9x16, "9:16 blur", 9, 16, true},
+}
+
+// Preset is a platform's preferred framing for the same clip.
+type Preset struct {
+	Name   string
+	Aspect AspectRatio
+}
+
+// Presets are cycled by "duplicate segment with the next preset".
+var Presets = []Preset{
+	{"YouTube", Aspect16x9},
+	{"Shorts", Aspect9x16},
+	{"Instagram", Aspect4x5},
+	{"Square", Aspect1x1},
 }
 
 type ExportOptions struct {
-	Input       string
-	Output      string
+	Input     string
+	Output    string
+	OutputDir string // for automatic and relative names; "" is beside the input
+	// SafeNames passes the output's name, typed or automatic, through
+	// SafeName, for upload pipelines that choke on spaces or unicode.
+	SafeNames   bool
 	InPoint     time.Duration
 	OutPoint    time.Duration
 	AspectRatio AspectRatio
 	Width       int
 	Height      int
+	// FreezeStart/FreezeEnd hold the first/last frame for this long
+	// (thumbnail intros, end cards). Either one forces a re-encode.
+	FreezeStart time.Duration
+	FreezeEnd   time.Duration
+	// CropOffset slides the crop window from centered (0) to the left or
+	// top edge (-1) or the right or bottom edge (1).
+	CropOffset float64
+	// FrameExact re-encodes even without filters so the cut lands on the
+	// exact in/out frames rather than the nearest keyframes (loops).
+	FrameExact bool
+	// FastStart moves the MP4/MOV index to the front of the file so web
+	// players can start before the download finishes. Other containers
+	// ignore it.
+	FastStart bool
+	Container Container
+	// VideoCodec/AudioCodec are the source codecs, to tell whether the
+	// streams can be copied into Container.
+	VideoCodec string
+	AudioCodec string
+	// Stabilize runs vid.stab over the selection: an analysis pass, then
+	// the export with the smoothed camera path applied.
+	Stabilize bool
+
+	// Speed plays the selection faster (>1) or slower (<1); 0 means 1.
+	// Slow motion interpolates new frames at FPS, the source frame rate.
+	Speed float64
+	FPS   float64
+
+	// Note is written as the output's comment metadata and, with
+	// NoteSidecar, to a .txt file beside it.
+	Note        string
+	NoteSidecar bool
+
+	// Poster is the source frame used as the thumbnail: embedded as cover
+	// art where the container holds one (see EmbedsPoster), otherwise or
+	// with PosterSidecar written as a .jpg beside the output. nil skips it.
+	Poster        *time.Duration
+	PosterSidecar bool
+
+	// Captions is the source's transcript; the part in the selection is
+	// written re-timed as an .srt beside the output (see ClipCaptions).
+	Captions []Cue
+
+	// Threads caps the threads ffmpeg uses for this export, so parallel
+	// exports share the CPU; 0 lets ffmpeg decide.
+	Threads int
+
+	// Encoder is the H.264 encoder for re-encoded video, e.g. h264_nvenc;
+	// "" leaves the choice to ffmpeg. See ExportWithProgress for what
+	// happens when it fails.
+	Encoder string
+
+	// TrashReplaced moves a file the export replaces to the trash (see
+	// MoveToTrash) instead of writing over it. Without a trash (see
+	// HasTrash) the file is written over, and the fallback says so.
+	TrashReplaced bool
+
+	// CRF is the constant quality of re-encoded video, lower being better,
+	// in the encoder's own scale (0 keeps its default); Bitrate, in kbit/s,
+	// is the average to encode at, or with CRF the most to spend. Copied
+	// streams keep theirs. See rateArgs.
+	CRF     int
+	Bitrate int
+
+	// Animation sets the frame rate, loops and quality of WebP and APNG
+	// exports, which have no audio: music is left out with it.
+	Animation Animation
+
+	// LowPriority runs ffmpeg at reduced CPU and disk priority, so a long
+	// encode leaves the machine usable.
+	LowPriority bool
+
+	// Parts makes the export a highlight reel of these ranges joined in
+	// order, each at its own speed, instead of InPoint-OutPoint at Speed.
+	// Crossfade blends each part into the next instead of a hard cut.
+	Parts     []ReelPart
+	Crossfade time.Duration
+
+	// Music is laid under the audio when its Path and Volume are set.
+	Music MusicBed
+
+	// SmartCut makes a stream copy frame accurate at near copy speed: the
+	// frames before the first keyframe of the selection and from the last
+	// one on are re-encoded, the whole GOPs between copied. See
+	// SmartCutProblem for when it applies.
+	SmartCut bool
+
+	// Intro and Outro are joined before and after the export when their
+	// Path is set, fitted to its frame. TitleCard, with Text and Duration
+	// set, comes between the intro and the selection. Any of them forces
+	// a re-encode.
+	Intro     Bumper
+	Outro     Bumper
+	TitleCard TitleCard
+
+	stabTransforms string // analysis result file, set between the passes
+}
+
+// DefaultExportOptions returns the export settings a new export of input
+// starts from: the source dimensions, an aspect ratio matching the source
+// orientation, and faststart on.
+func DefaultExportOptions(input string, props *VideoProperties) ExportOptions {
+	opts := ExportOptions{Input: input, FastStart: true}
+	if props != nil {
+		opts.Width, opts.Height = props.Width, props.Height
+		opts.VideoCodec, opts.AudioCodec = props.Codec, props.AudioCodec
+		opts.FPS = props.FPS
+		opts.AspectRatio = DefaultAspectRatio(props.Width, props.Height)
+	}
+	return opts
+}
+
+// DefaultAspectRatio keeps landscape and square sources as they are and
+// crops portrait ones to 9:16, unless they already are (near enough) 9:16.
+func DefaultAspectRatio(w, h int) AspectRatio {
+	if w <= 0 || h <= 0 || h <= w {
+		return AspectOriginal
+	}
+	if math.Abs(float64(w)/float64(h)-9.0/16.0) < 0.01 {
+		return AspectOriginal
+	}
+	return Aspect9x16
+}
+
+// AspectRatioIndex returns the position of ratio in AspectRatioOptions.
+func AspectRatioIndex(ratio AspectRatio) int {
+	for i, opt := range AspectRatioOptions {
+		if opt.Ratio == ratio {
+			return i
+		}
+	}
+	return 0
+}
+
+// DescribeAspect labels source dimensions, e.g. "1080x1920 (9:16 portrait)".
+func DescribeAspect(w, h int) string {
+	if w <= 0 || h <= 0 {
+		return "unknown"
+	}
+	orientation := "landscape"
+	switch {
+	case h > w:
+		orientation = "portrait"
+	case h == w:
+		orientation = "square"
+	}
+	ratio := fmt.Sprintf("%.2f:1", float64(w)/float64(h))
+	for _, opt := range AspectRatioOptions {
+		if opt.W > 0 && math.Abs(float64(w)/float64(h)-float64(opt.W)/float64(opt.H)) < 0.01 {
+			ratio = opt.Label
+			break
+		}
+	}
+	return fmt.Sprintf("%dx%d (%s %s)", w, h, ratio, orientation)
 }
 
 func BuildFFmpegCommand(opts ExportOptions) string {
+	input := filepath.Base(opts.Input)
+	args := append([]string{"ffmpeg"}, exportArgs(opts, input, filepath.Base(opts.OutputPath()), false)...)
+	cmd := strings.Join(args, " ")
+	if opts.Stabilize {
+		pass1 := append([]string{"ffmpeg"}, shakeArgs(opts, input, stabPlaceholder, false)...)
+		cmd = strings.Join(pass1, " ") + " && " + cmd
+	}
+	if opts.smartCuts(opts.OutputPath()) {
+		// The pieces depend on where the keyframes are, found at export time
+		cmd = "# smart cut: the ends re-encoded with " + opts.smartEncoder() + ", the rest as\n" + cmd
+	}
+	return cmd
+}
+
+// OutputPath is where the export will be written: the typed name next to
+// the input (or a free "_trimmed" name), with the extension of the chosen
+// container.
+func (opts ExportOptions) OutputPath() string {
+	ext := containerExt(opts.Container)
 	output := opts.Output
 	if output == "" {
-		output = generateOutputName(opts.Input)
+		if ext == "" {
+			ext = filepath.Ext(opts.Input)
+		}
+		if len(opts.Parts) > 0 {
+			return generateOutputName(opts.outputDir(), opts.Input, "_reel", ext, opts.SafeNames)
+		}
+		return generateOutputName(opts.outputDir(), opts.Input, "_trimmed", ext, opts.SafeNames)
+	}
+	switch {
+	case ext != "":
+		output = strings.TrimSuffix(output, filepath.Ext(output)) + ext
+	case filepath.Ext(output) == "":
+		output += filepath.Ext(opts.Input)
+	}
+	ext = filepath.Ext(output)
+	output = filepath.Join(filepath.Dir(output), outputStem(output, opts.SafeNames, len(partSuffix)+len(ext))+ext)
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(opts.outputDir(), output)
+	}
+	return output
+}
+
+func (opts ExportOptions) outputDir() string {
+	if opts.OutputDir != "" {
+		return opts.OutputDir
+	}
+	return filepath.Dir(opts.Input)
+}
+
+// exportArgs builds the ffmpeg arguments shared by the displayed command
+// and the real export. -t is an input option so that padding filters can
+// extend the output past the selection length.
+func exportArgs(opts ExportOptions, input, output string, withProgress bool) []string {
+	if opts.Animated() {
+		// There is no audio track to copy, encode or mix music into
+		opts.AudioCodec, opts.Music = "", MusicBed{}
+	}
+	if len(opts.Parts) > 0 {
+		return reelArgs(opts, input, output, withProgress)
 	}
 	duration := opts.OutPoint - opts.InPoint
 
-	args := []string{"ffmpeg", "-y",
-		"-ss", fmt.Sprintf("%.3f", opts.InPoint.Seconds()),
-		"-i", filepath.Base(opts.Input),
-		"-t", fmt.Sprintf("%.3f", duration.Seconds()),
+	args := append([]string{"-y"}, seekInput(seekRange, opts.InPoint, input,
+		"-t", fmt.Sprintf("%.3f", duration.Seconds()))...)
+	if withProgress {
+		args = append(args, "-progress", "pipe:2")
 	}
 
-	if opts.AspectRatio != AspectOriginal && opts.Width > 0 && opts.Height > 0 {
-		cropFilter := buildCropFilter(opts.Width, opts.Height, opts.AspectRatio)
-		if cropFilter != "" {
-			args = append(args, "-vf", cropFilter)
+	vf, af := exportFilters(opts)
+	if opts.hasBumpers() {
+		return append(args, bumperArgs(opts, vf, af, input, output)...)
+	}
+	if opts.hasMusic() {
+		return append(args, musicArgs(opts, vf, af, output)...)
+	}
+	if opts.DropsAudio() {
+		args = append(args, "-an")
+	}
+	if len(vf) == 0 && len(af) == 0 && !opts.FrameExact && !opts.Animated() {
+		// Streams the container cannot hold are re-encoded with its
+		// default encoder instead of failing the mux
+		switch videoOK, audioOK := CopyCompatible(output, opts.VideoCodec, opts.AudioCodec); {
+		case videoOK && audioOK:
+			args = append(args, "-c", "copy")
+		case videoOK:
+			args = append(args, "-c:v", "copy")
+		case audioOK:
+			args = append(args, "-c:a", "copy")
+			args = append(args, encoderArgs(opts, output)...)
+		default:
+			args = append(args, encoderArgs(opts, output)...)
 		}
 	} else {
-		args = append(args, "-c", "copy")
+		args = append(args, encoderArgs(opts, output)...)
+		if len(vf) > 0 {
+			args = append(args, "-vf", strings.Join(vf, ","))
+		}
+		if len(af) > 0 {
+			args = append(args, "-af", strings.Join(af, ","))
+		}
 	}
+	return append(args, outputArgs(opts, output)...)
+}
 
-	args = append(args, filepath.Base(output))
-	return strings.Join(args, " ")
+// outputArgs ends every export command: metadata, threads and container
+// flags, then the output itself.
+func outputArgs(opts ExportOptions, output string) []string {
+	var args []string
+	if opts.Note != "" {
+		args = append(args, "-metadata", "comment="+opts.Note)
+	}
+	args = append(args, threadArgs(opts)...)
+	args = append(args, muxerArgs(opts, output)...)
+	return append(args, output)
 }
 
-func ExportWithProgress(opts ExportOptions, progress chan<- float64) (string, error) {
-	defer close(progress)
+// encoderArgs sets up the encoder of re-encoded video and its quality, see
+// rateArgs.
+func encoderArgs(opts ExportOptions, output string) []string {
+	if opts.Animated() {
+		return animationArgs(opts, output)
+	}
+	return append(codecArgs(opts, output), rateArgs(opts, output)...)
+}
 
-	output := opts.Output
-	if output == "" {
-		output = generateOutputName(opts.Input)
-	} else {
-		dir := filepath.Dir(opts.Input)
-		ext := filepath.Ext(opts.Input)
-		if filepath.Ext(output) == "" {
-			output = output + ext
+// codecArgs selects opts.Encoder for the video, where output's container
+// takes H.264 at all; elsewhere the container default is the only choice.
+func codecArgs(opts ExportOptions, output string) []string {
+	if opts.Encoder == "" {
+		return nil
+	}
+	if ok, _ := CopyCompatible(output, "h264", ""); !ok {
+		return nil
+	}
+	return []string{"-c:v", opts.Encoder}
+}
+
+// threadArgs limits the encoder and filter threads to opts.Threads.
+func threadArgs(opts ExportOptions) []string {
+	if opts.Threads <= 0 {
+		return nil
+	}
+	n := strconv.Itoa(opts.Threads)
+	return []string{"-threads", n, "-filter_threads", n}
+}
+
+// NotePath is the sidecar file for the note of the export written to
+// output: the same name with a .txt extension.
+func NotePath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".txt"
+}
+
+// SupportsFastStart reports whether the container of path (by extension)
+// has an index that -movflags +faststart can move.
+func SupportsFastStart(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".mov", ".m4v", ".m4a":
+		return true
+	}
+	return false
+}
+
+// exportFilters returns the video and audio filter chains; both empty
+// means the streams can be copied unless FrameExact is set.
+func exportFilters(opts ExportOptions) (vf, af []string) {
+	// Stabilize the full frame first so a crop cannot cut into the
+	// moving frame edge
+	if opts.Stabilize {
+		vf = append(vf, stabilizeFilters(opts)...)
+	}
+	vf = append(vf, composeFilters(opts)...)
+	vf = append(vf, speedVideoFilters(opts)...)
+
+	freezeV, freezeA := freezeFilters(opts)
+	vf = append(vf, freezeV...)
+	if opts.DropsAudio() || opts.Animated() {
+		// exportArgs drops the audio, so there is nothing to retime or pad
+		return vf, nil
+	}
+	return vf, append(speedAudioFilters(opts.speed()), freezeA...)
+}
+
+// freezeFilters clone the first/last frame; audio gets matching silence.
+func freezeFilters(opts ExportOptions) (vf, af []string) {
+	if opts.FreezeStart <= 0 && opts.FreezeEnd <= 0 {
+		return nil, nil
+	}
+	var pad []string
+	if opts.FreezeStart > 0 {
+		pad = append(pad, fmt.Sprintf("start_mode=clone:start_duration=%.3f", opts.FreezeStart.Seconds()))
+		af = append(af, fmt.Sprintf("adelay=delays=%d:all=1", opts.FreezeStart.Milliseconds()))
+	}
+	if opts.FreezeEnd > 0 {
+		pad = append(pad, fmt.Sprintf("stop_mode=clone:stop_duration=%.3f", opts.FreezeEnd.Seconds()))
+		af = append(af, fmt.Sprintf("apad=pad_dur=%.3f", opts.FreezeEnd.Seconds()))
+	}
+	return []string{"tpad=" + strings.Join(pad, ":")}, af
+}
+
+// composeFilters returns the filters that shape the picture (crop or
+// blurred fill); preview stills use them to show the export framing.
+func composeFilters(opts ExportOptions) []string {
+	if opts.AspectRatio == AspectOriginal || opts.Width <= 0 || opts.Height <= 0 {
+		return nil
+	}
+	f := buildCropFilter(opts.Width, opts.Height, opts.AspectRatio, opts.CropOffset)
+	for _, opt := range AspectRatioOptions {
+		if opt.Ratio == opts.AspectRatio && opt.Fill {
+			f = buildBlurFillFilter(opts.Width, opts.Height, opt.W, opt.H)
 		}
-		if !filepath.IsAbs(output) {
-			output = filepath.Join(dir, output)
+	}
+	if f == "" {
+		return nil
+	}
+	return []string{f}
+}
+
+// OutputDuration is the length of the exported file, including speed
+// changes, freezes, the intro and outro and the title card.
+func (opts ExportOptions) OutputDuration() time.Duration {
+	return opts.leadIn() + opts.clipDuration() + opts.Outro.Duration
+}
+
+// clipDuration is the length of the selection or reel as exported,
+// without what is joined to it.
+func (opts ExportOptions) clipDuration() time.Duration {
+	played := time.Duration(float64(opts.OutPoint-opts.InPoint) / opts.speed())
+	if len(opts.Parts) > 0 {
+		played = opts.reelDuration()
+	}
+	return played + opts.FreezeStart + opts.FreezeEnd
+}
+
+// encoderFailWindow is how soon an encoder has to fail for the export to
+// be retried in software: hardware encoders without a usable device, or
+// refusing the size or pixel format, give up right after opening.
+const encoderFailWindow = 3 * time.Second
+
+// ExportWithProgress runs the export, reporting progress in [0,1] on progress
+// and closing it on return. Cancelling ctx kills ffmpeg. With Stabilize the
+// first half of the progress is the motion analysis pass.
+//
+// When opts.Encoder fails within encoderFailWindow the export is redone
+// once with the software fallback (see SoftwareFallback); fallback then
+// describes the substitution for the user, and is "" otherwise. So it
+// does for a smart cut whose pieces cannot match the source's format,
+// which is re-encoded whole instead.
+func ExportWithProgress(ctx context.Context, opts ExportOptions, progress chan<- float64) (output, fallback string, err error) {
+	defer close(progress)
+
+	report := func(p float64) {
+		select {
+		case progress <- min(p, 1.0):
+		default:
 		}
 	}
-	duration := opts.OutPoint - opts.InPoint
-	totalMicros := float64(duration.Microseconds())
 
-	args := []string{"-y",
-		"-ss", fmt.Sprintf("%.3f", opts.InPoint.Seconds()),
-		"-i", opts.Input,
-		"-t", fmt.Sprintf("%.3f", duration.Seconds()),
-		"-progress", "pipe:2",
+	output, release := claimOutput(opts)
+	defer release()
+	begun := time.Now()
+	offset, share := 0.0, 1.0
+	if opts.Stabilize {
+		transforms, err := analyzeShake(ctx, opts, func(p float64) { report(p / 2) })
+		if err != nil {
+			return "", "", err
+		}
+		defer os.Remove(transforms)
+		opts.stabTransforms = transforms
+		offset, share = 0.5, 0.5
 	}
 
-	if opts.AspectRatio != AspectOriginal && opts.Width > 0 && opts.Height > 0 {
-		cropFilter := buildCropFilter(opts.Width, opts.Height, opts.AspectRatio)
-		if cropFilter != "" {
-			args = append(args, "-vf", cropFilter)
+	// ffmpeg writes to a .part file that only gets the real name once it
+	// is complete, so an interrupted export never looks like a finished one
+	part := PartPath(output)
+	if opts.TrashReplaced && !HasTrash() && fileExists(output) {
+		// Found out before encoding, rather than leaving the export as a
+		// .part file when the move fails
+		fallback = "no trash on this system, the old file was replaced"
+		opts.TrashReplaced = false
+	}
+	var format smartFormat
+	if opts.smartCuts(part) {
+		format, err = smartCutFormat(ctx, opts)
+		if errors.Is(err, errFormatMismatch) {
+			// A frame exact re-encode cuts at the same frames, only slower
+			fallback = err.Error() + ", re-encoded the whole selection"
+			opts.SmartCut, opts.FrameExact = false, true
+		} else if err != nil {
+			return "", "", err
 		}
-	} else {
-		args = append(args, "-c", "copy")
 	}
+	encode := func() error {
+		var err error
+		if opts.smartCuts(part) {
+			err = smartCut(ctx, opts, format, part, func(p float64) { report(offset + p*share) })
+		} else {
+			args := exportArgs(opts, opts.Input, part, true)
+			err = runWithProgress(ctx, args, opts.OutputDuration(), opts.LowPriority, func(p float64) { report(offset + p*share) })
+		}
+		if err != nil {
+			os.Remove(part)
+		}
+		return err
+	}
+	started := time.Now()
+	err = encode()
+	if err != nil && ctx.Err() == nil && time.Since(started) < encoderFailWindow && len(codecArgs(opts, part)) > 0 && !opts.smartCuts(part) {
+		if soft, ok := SoftwareFallback(opts.Encoder); ok {
+			fallback = fmt.Sprintf("%s failed, encoded with %s", opts.Encoder, describeEncoder(soft))
+			opts.Encoder = soft
+			report(offset)
+			err = encode()
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if opts.TrashReplaced && fileExists(output) {
+		if err := MoveToTrash(output); err != nil {
+			// The old file stays, and so does the new one under its .part name
+			return "", "", fmt.Errorf("could not move the old %s to the trash, the export is in %s: %w",
+				filepath.Base(output), filepath.Base(part), err)
+		}
+	}
+	if err := os.Rename(part, output); err != nil {
+		os.Remove(part)
+		return "", "", fmt.Errorf("failed to move the finished export into place: %w", err)
+	}
+	recordExport(opts, output, time.Since(begun))
+	if opts.NoteSidecar && opts.Note != "" {
+		if err := os.WriteFile(NotePath(output), []byte(opts.Note+"\n"), 0o644); err != nil {
+			return output, fallback, fmt.Errorf("exported, but writing the note failed: %w", err)
+		}
+	}
+	if err := writeCaptions(opts, output); err != nil {
+		return output, fallback, fmt.Errorf("exported, but writing the captions failed: %w", err)
+	}
+	if opts.Poster != nil {
+		if err := writePoster(ctx, opts, output); err != nil {
+			return output, fallback, fmt.Errorf("exported, but the poster frame failed: %w", err)
+		}
+	}
+
+	progress <- 1.0
+	return output, fallback, nil
+}
 
-	args = append(args, output)
+// runWithProgress runs ffmpeg with -progress pipe:2 in args, reporting the
+// fraction of total encoded so far, at background priority when low. A
+// failure carries the last line ffmpeg logged besides the progress.
+func runWithProgress(ctx context.Context, args []string, total time.Duration, low bool, report func(float64)) error {
+	totalMicros := float64(total.Microseconds())
 
-	cmd := exec.Command("ffmpeg", args...)
+	cmd := command(ctx, "ffmpeg", args...)
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return "", fmt.Errorf("failed to get stderr pipe: %w", err)
+		return fmt.Errorf("failed to get stderr pipe: %w", err)
 	}
 
-	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start ffmpeg: %w", err)
+	if err := startTracked(cmd); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	if low {
+		// Best effort: an export at normal priority beats no export
+		_ = lowerPriority(cmd.Process)
 	}
 
+	var detail string
 	scanner := bufio.NewScanner(stderr)
 	for scanner.Scan() {
 		line := scanner.Text()
+		if !isProgressLine(line) && strings.TrimSpace(line) != "" && line != "Conversion failed!" {
+			detail = strings.TrimSpace(line)
+		}
 		if strings.HasPrefix(line, "out_time_us=") {
 			timeStr := strings.TrimPrefix(line, "out_time_us=")
 			if micros, err := strconv.ParseFloat(timeStr, 64); err == nil && totalMicros > 0 {
-				p := micros / totalMicros
-				if p > 1.0 {
-					p = 1.0
-				}
-				select {
-				case progress <- p:
-				default:
-				}
+				report(micros / totalMicros)
 			}
 		}
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return "", fmt.Errorf("ffmpeg failed: %w", err)
+	if err := waitTracked(cmd); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("export cancelled: %w", ctx.Err())
+		}
+		if detail != "" {
+			return fmt.Errorf("ffmpeg failed: %w: %s", err, detail)
+		}
+		return fmt.Errorf("ffmpeg failed: %w", err)
 	}
+	return nil
+}
 
-	progress <- 1.0
-	return output, nil
+// isProgressLine reports whether line is one of the key=value lines of
+// -progress rather than a log message.
+func isProgressLine(line string) bool {
+	key, _, ok := strings.Cut(line, "=")
+	return ok && key != "" && !strings.ContainsAny(line, " \t")
 }
 
-func buildCropFilter(srcW, srcH int, ratio AspectRatio) string {
+func buildCropFilter(srcW, srcH int, ratio AspectRatio, offset float64) string {
+	cropW, cropH := cropSize(srcW, srcH, ratio)
+	if cropW == 0 || cropH == 0 {
+		return ""
+	}
+	if offset == 0 {
+		return fmt.Sprintf("crop=%d:%d", cropW, cropH)
+	}
+
+	// Slide the window along whichever axis has slack
+	pos := (max(-1, min(1, offset)) + 1) / 2
+	x := int(float64(srcW-cropW) * pos)
+	y := int(float64(srcH-cropH) * pos)
+	return fmt.Sprintf("crop=%d:%d:%d:%d", cropW, cropH, x, y)
+}
+
+// cropSize returns the largest ratio-shaped window inside the source, or
+// zeros when ratio does not crop.
+func cropSize(srcW, srcH int, ratio AspectRatio) (int, int) {
 	var targetW, targetH int
 	for _, opt := range AspectRatioOptions {
 		if opt.Ratio == ratio {
@@ -150,8 +650,8 @@ func buildCropFilter(srcW, srcH int, ratio AspectRatio) string {
 			break
 		}
 	}
-	if targetW == 0 || targetH == 0 {
-		return ""
+	if targetW == 0 || targetH == 0 || srcW <= 0 || srcH <= 0 {
+		return 0, 0
 	}
 
 	srcRatio := float64(srcW) / float64(srcH)
@@ -167,30 +667,124 @@ func buildCropFilter(srcW, srcH int, ratio AspectRatio) string {
 	}
 
 	// H.264 requires even dimensions
-	cropW = cropW &^ 1
-	cropH = cropH &^ 1
+	return cropW &^ 1, cropH &^ 1
+}
 
-	return fmt.Sprintf("crop=%d:%d", cropW, cropH)
+// buildBlurFillFilter fits the whole source inside a ratioW:ratioH frame
+// and fills the bars with a blurred, zoomed copy of the same picture. The
+// output is as wide as the source's shorter side (1080x1920 from 1080p).
+func buildBlurFillFilter(srcW, srcH, ratioW, ratioH int) string {
+	outW, outH := fillSize(srcW, srcH, ratioW, ratioH)
+	if outW == 0 || outH == 0 {
+		return ""
+	}
+	return fmt.Sprintf("split[bg][fg];"+
+		"[bg]scale=%[1]d:%[2]d:force_original_aspect_ratio=increase,crop=%[1]d:%[2]d,boxblur=20:2[bg];"+
+		"[fg]scale=%[1]d:%[2]d:force_original_aspect_ratio=decrease[fg];"+
+		"[bg][fg]overlay=(W-w)/2:(H-h)/2,setsar=1", outW, outH)
 }
 
-func generateOutputName(input string) string {
-	dir := filepath.Dir(input)
-	ext := filepath.Ext(input)
-	base := strings.TrimSuffix(filepath.Base(input), ext)
+// fillSize is the frame buildBlurFillFilter renders.
+func fillSize(srcW, srcH, ratioW, ratioH int) (int, int) {
+	outW := min(srcW, srcH) &^ 1
+	return outW, (outW * ratioH / ratioW) &^ 1
+}
 
-	trimmedPath := filepath.Join(dir, base+"_trimmed"+ext)
-	if !fileExists(trimmedPath) {
+// generateOutputName picks a free name in dir from the input's, with
+// suffix or else a number, shortened to leave room for the longest of
+// them and the .part file (see fitName), and SafeName'd with safe.
+func generateOutputName(dir, input, suffix, ext string, safe bool) string {
+	base := outputStem(input, safe, len(suffix)+len("_new")+len(partSuffix)+len(ext))
+
+	trimmedPath := filepath.Join(dir, base+suffix+ext)
+	if !outputTaken(trimmedPath) {
 		return trimmedPath
 	}
 
 	for i := 1; i <= 999; i++ {
 		numberedPath := filepath.Join(dir, fmt.Sprintf("%s_%03d%s", base, i, ext))
-		if !fileExists(numberedPath) {
+		if !outputTaken(numberedPath) {
 			return numberedPath
 		}
 	}
 
-	return filepath.Join(dir, base+"_trimmed_new"+ext)
+	return filepath.Join(dir, base+suffix+"_new"+ext)
+}
+
+// claimed holds the outputs of running exports. Parallel exports would
+// otherwise pick the same free name before either has created its file.
+var claimed = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: map[string]bool{}}
+
+// claimMu makes choosing and claiming an output name one step.
+var claimMu sync.Mutex
+
+// outputTaken reports whether path exists, is being written by a running
+// export, or has a .part file from another export (or one that was killed)
+// waiting to become it.
+func outputTaken(path string) bool {
+	claimed.Lock()
+	defer claimed.Unlock()
+	return claimed.paths[path] || fileExists(path) || fileExists(PartPath(path))
+}
+
+// PartPath is where the export to output is written until it completes:
+// "clip.part.mp4" for "clip.mp4". The real extension stays last so ffmpeg
+// still picks the container from it.
+func PartPath(output string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + partSuffix + ext
+}
+
+const partSuffix = ".part"
+
+// IsPartPath reports whether path is an export still being written.
+func IsPartPath(path string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(path, filepath.Ext(path)), partSuffix)
+}
+
+// claimOutput picks the output path for opts and holds it until the
+// returned release is called.
+func claimOutput(opts ExportOptions) (string, func()) {
+	claimMu.Lock()
+	defer claimMu.Unlock()
+	output := opts.OutputPath()
+	claimed.Lock()
+	claimed.paths[output] = true
+	claimed.Unlock()
+	return output, func() {
+		claimed.Lock()
+		delete(claimed.paths, output)
+		claimed.Unlock()
+	}
+}
+
+// RenameExport moves the export at old, and the poster, note and captions
+// written beside it, to output. It refuses to replace an existing file.
+func RenameExport(old, output string) error {
+	sidecars := []func(string) string{PosterPath, NotePath, CaptionsPath}
+	if outputTaken(output) {
+		return fmt.Errorf("%s already exists", filepath.Base(output))
+	}
+	for _, sidecar := range sidecars {
+		if fileExists(sidecar(old)) && fileExists(sidecar(output)) {
+			return fmt.Errorf("%s already exists", filepath.Base(sidecar(output)))
+		}
+	}
+	if err := os.Rename(old, output); err != nil {
+		return err
+	}
+	for _, sidecar := range sidecars {
+		if !fileExists(sidecar(old)) {
+			continue
+		}
+		if err := os.Rename(sidecar(old), sidecar(output)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func fileExists(path string) bool {
@@ -0,0 +1,185 @@
+package video_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+// fakeRunner swaps in a FakeRunner for the rest of the test.
+func fakeRunner(t *testing.T) *videotest.FakeRunner {
+	t.Helper()
+	fake := videotest.NewFakeRunner(t.TempDir())
+	t.Cleanup(video.SetRunner(fake))
+	return fake
+}
+
+// copyExport is a stream-copied export of 1s-3s of in.mp4 into dir.
+func copyExport(dir string) video.ExportOptions {
+	return video.ExportOptions{
+		Input:      filepath.Join(dir, "in.mp4"),
+		Output:     filepath.Join(dir, "out.mp4"),
+		InPoint:    time.Second,
+		OutPoint:   3 * time.Second,
+		Width:      1920,
+		Height:     1080,
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+	}
+}
+
+// runExport runs ExportWithProgress, collecting what it reports. The fake
+// ffmpeg writes nothing, so the .part file it would have is made up front.
+func runExport(t *testing.T, opts video.ExportOptions) (string, []float64, error) {
+	t.Helper()
+	if err := os.WriteFile(video.PartPath(opts.OutputPath()), []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	progress := make(chan float64, 64)
+	var reports []float64
+	done := make(chan struct{})
+	go func() {
+		for p := range progress {
+			reports = append(reports, p)
+		}
+		close(done)
+	}()
+	output, _, err := video.ExportWithProgress(context.Background(), opts, progress)
+	<-done
+	return output, reports, err
+}
+
+// exportCall is the ffmpeg invocation that did the export, the one
+// reporting progress.
+func exportCall(t *testing.T, fake *videotest.FakeRunner) []string {
+	t.Helper()
+	for _, call := range fake.CallsTo("ffmpeg") {
+		if slices.Contains(call.Args, "-progress") {
+			return call.Args
+		}
+	}
+	t.Fatalf("no export ran; calls: %v", fake.Calls())
+	return nil
+}
+
+func TestExportArgs(t *testing.T) {
+	fake := fakeRunner(t)
+	dir := t.TempDir()
+	opts := copyExport(dir)
+
+	output, _, err := runExport(t, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != opts.Output {
+		t.Errorf("output = %q, want %q", output, opts.Output)
+	}
+	if _, err := os.Stat(opts.Output); err != nil {
+		t.Errorf("export not moved into place: %v", err)
+	}
+	if _, err := os.Stat(video.PartPath(opts.Output)); !os.IsNotExist(err) {
+		t.Errorf(".part file left behind: %v", err)
+	}
+
+	got := strings.Join(exportCall(t, fake), " ")
+	for _, want := range []string{
+		"-ss 1.000 -t 2.000 -i " + opts.Input,
+		"-progress pipe:2",
+		"-c copy",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("args %q lack %q", got, want)
+		}
+	}
+	if part := video.PartPath(opts.Output); !strings.HasSuffix(got, " "+part) {
+		t.Errorf("args %q do not write to %s", got, part)
+	}
+}
+
+func TestExportArgsReencode(t *testing.T) {
+	fake := fakeRunner(t)
+	opts := copyExport(t.TempDir())
+	opts.AspectRatio = video.Aspect9x16
+
+	if _, _, err := runExport(t, opts); err != nil {
+		t.Fatal(err)
+	}
+	args := exportCall(t, fake)
+	if slices.Contains(args, "copy") {
+		t.Errorf("cropped export copies a stream: %q", args)
+	}
+	i := slices.Index(args, "-vf")
+	if i < 0 || !strings.HasPrefix(args[i+1], "crop=") {
+		t.Errorf("cropped export has no crop filter: %q", args)
+	}
+}
+
+func TestExportProgress(t *testing.T) {
+	fake := fakeRunner(t)
+	fake.Respond("ffmpeg", videotest.Response{Stderr: []byte(
+		"frame=1\nout_time_us=500000\nprogress=continue\n" +
+			"out_time_us=1000000\nprogress=continue\n" +
+			"out_time_us=2000000\nprogress=end\n")})
+
+	_, reports, err := runExport(t, copyExport(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{0.25, 0.5, 1, 1}
+	if !slices.Equal(reports, want) {
+		t.Errorf("progress = %v, want %v", reports, want)
+	}
+}
+
+func TestExportFailure(t *testing.T) {
+	fake := fakeRunner(t)
+	fake.Respond("ffmpeg", videotest.Response{
+		Stderr:   []byte("out_time_us=500000\n[mp4 @ 0x1] Could not write header\nConversion failed!\n"),
+		ExitCode: 1,
+	})
+	opts := copyExport(t.TempDir())
+
+	_, _, err := runExport(t, opts)
+	if err == nil {
+		t.Fatal("export succeeded")
+	}
+	if !strings.Contains(err.Error(), "Could not write header") {
+		t.Errorf("error %q lacks ffmpeg's message", err)
+	}
+	if _, err := os.Stat(opts.Output); !os.IsNotExist(err) {
+		t.Errorf("failed export left %s: %v", opts.Output, err)
+	}
+}
+
+func TestSmartCutFormatFallback(t *testing.T) {
+	fake := fakeRunner(t)
+	t.Cleanup(video.SetCapabilities(&video.Capabilities{Encoders: map[string]bool{"libx264": true}}))
+	fake.Respond("ffprobe", videotest.Response{Stdout: []byte(
+		`{"streams": [{"pix_fmt": "yuv420p12le", "profile": "High 4:4:4 Predictive", "level": 51, "time_base": "1/15360"}]}`)})
+	opts := copyExport(t.TempDir())
+	opts.SmartCut = true
+
+	if err := os.WriteFile(video.PartPath(opts.Output), []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, fallback, err := video.ExportWithProgress(context.Background(), opts, make(chan float64, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(fallback, "yuv420p12le") {
+		t.Errorf("fallback = %q, want the pixel format that did not match", fallback)
+	}
+	if len(fake.CallsTo("ffprobe")) != 1 {
+		t.Errorf("ffprobe ran %d times, want once for the format", len(fake.CallsTo("ffprobe")))
+	}
+	if args := exportCall(t, fake); slices.Contains(args, "copy") || slices.Contains(args, "concat") {
+		t.Errorf("export still smart cuts: %q", args)
+	}
+}
@@ -0,0 +1,116 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PreviewFrame is one frame of an export preview, at Pos in the output.
+type PreviewFrame struct {
+	Frame string
+	Pos   time.Duration
+}
+
+// previewFilters is the export video chain minus stabilization, which
+// needs its analysis pass first.
+func previewFilters(opts ExportOptions) []string {
+	filters := composeFilters(opts)
+	filters = append(filters, speedVideoFilters(opts)...)
+	freeze, _ := freezeFilters(opts)
+	return append(filters, freeze...)
+}
+
+// PreviewExport plays the selection through the export filter chain (crop,
+// fill, speed, freezes) in real time, sending each frame rendered at
+// width x height cells on frames and closing it when done. Frames the
+// filters cannot keep up with are skipped. Stabilization is not applied
+// and there is no sound.
+func (p *Player) PreviewExport(ctx context.Context, opts ExportOptions, width, height int, frames chan<- PreviewFrame) error {
+	defer close(frames)
+
+	p.mu.Lock()
+	quality := p.quality
+	p.mu.Unlock()
+	fps := p.properties.PreviewFPS()
+	if fps <= 0 {
+		fps = 24
+	}
+	interval := time.Second / time.Duration(fps)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	filters := append(previewFilters(opts),
+		"scale=w=640:h=640:force_original_aspect_ratio=decrease:flags=fast_bilinear",
+		fmt.Sprintf("fps=%d", fps))
+	// Seeks like the export, whatever --seek says, to show what it writes
+	args := append(seekInput(seekRange, opts.InPoint, opts.Input,
+		"-t", fmt.Sprintf("%.3f", (opts.OutPoint-opts.InPoint).Seconds())),
+		"-an",
+		"-vf", strings.Join(filters, ","),
+	)
+	args = append(args, streamCodecArgs[FrameBMP]...)
+	args = append(args, "-loglevel", "error", "-")
+
+	cmd := command(ctx, "ffmpeg", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := startTracked(cmd); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	stream := &FrameStream{
+		cmd:       cmd,
+		stdout:    stdout,
+		reader:    bufio.NewReaderSize(stdout, 1<<20),
+		cancel:    cancel,
+		format:    FrameBMP,
+		width:     width,
+		height:    height,
+		targetFPS: fps,
+	}
+	defer stream.Close()
+
+	start := time.Now()
+	for i := 0; ; i++ {
+		frameBytes, err := stream.NextFrame()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if i == 0 {
+				return fmt.Errorf("ffmpeg produced no frames")
+			}
+			return nil
+		}
+		pts := time.Duration(i) * interval
+		wall := time.Since(start)
+		if pts+interval < wall {
+			stream.Release(frameBytes)
+			continue
+		}
+		if wait := pts - wall; wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				stream.Release(frameBytes)
+				return ctx.Err()
+			}
+		}
+
+		frame, err := p.renderTextFrame(ctx, frameBytes, width, height, quality)
+		stream.Release(frameBytes)
+		if err != nil {
+			continue
+		}
+		select {
+		case frames <- PreviewFrame{Frame: frame, Pos: pts}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package video
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxNameBytes is the longest file name most filesystems take (ext4,
+// APFS and NTFS count 255 bytes or UTF-16 units), so names are kept under
+// it in bytes, which covers all of them.
+const maxNameBytes = 255
+
+// fitName shortens base so that reserve more bytes still fit in a file
+// name, cutting on a character boundary and dropping what would be left
+// dangling at the cut.
+func fitName(base string, reserve int) string {
+	limit := maxNameBytes - reserve
+	if len(base) <= limit {
+		return base
+	}
+	cut := max(0, limit)
+	for cut > 0 && !utf8.RuneStart(base[cut]) {
+		cut--
+	}
+	return strings.TrimRight(base[:cut], " .-_")
+}
+
+// transliterations are the letters NFD does not take apart into an ASCII
+// letter and marks.
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th",
+	'Þ': "Th", 'ı': "i",
+}
+
+// SafeName turns a file name stem into one that upload pipelines and
+// URLs take as is: accents dropped ("Día" becomes "dia"), lower case,
+// and everything but ASCII letters, digits, "_" and "." (spaces, other
+// scripts, emoji) squeezed into single dashes. A name with nothing left
+// becomes "clip".
+func SafeName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(name) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		s := string(r)
+		if t, ok := transliterations[r]; ok {
+			s = t
+		}
+		for _, c := range strings.ToLower(s) {
+			switch {
+			case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_', c == '.':
+				if dash && b.Len() > 0 {
+					b.WriteByte('-')
+				}
+				dash = false
+				b.WriteRune(c)
+			default:
+				dash = true
+			}
+		}
+	}
+	safe := strings.Trim(b.String(), "._-")
+	if safe == "" {
+		return "clip"
+	}
+	return safe
+}
+
+// outputStem is the file name stem of path for an output: SafeName'd with
+// safe, and short enough for reserve more bytes of suffix and extension.
+func outputStem(path string, safe bool, reserve int) string {
+	stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if safe {
+		stem = SafeName(stem)
+	}
+	return fitName(stem, reserve)
+}
@@ -0,0 +1,22 @@
+package video
+
+import (
+	"context"
+	"time"
+)
+
+// RenderFilmstrip renders count thumbnails of width x height cells, one
+// from the middle of each of count equal parts of the file, for an
+// overview of where the scenes are.
+func (p *Player) RenderFilmstrip(ctx context.Context, count, width, height int) ([]string, error) {
+	frames := make([]string, count)
+	for i := range frames {
+		at := time.Duration((float64(i) + 0.5) / float64(count) * float64(p.Duration()))
+		frame, err := p.renderThumbnail(ctx, seekOverview, at, width, height)
+		if err != nil {
+			return nil, err
+		}
+		frames[i] = frame
+	}
+	return frames, nil
+}
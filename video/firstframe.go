@@ -0,0 +1,100 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// firstFrame is the frame at the opening position, decoded while the
+// terminal size is still unknown so only the chafa conversion is left once
+// the preview has a size.
+type firstFrame struct {
+	position time.Duration
+	shown    time.Duration // where the decoded frame really is
+	done     chan struct{}
+	data     []byte
+	err      error
+}
+
+// Preload starts decoding the frame under the playhead. Call it once the
+// opening position is known, after a resumed session has seeked; the
+// first SetSize then shows it as soon as it is converted.
+func (p *Player) Preload() {
+	if demoMode {
+		// Synthetic frames are drawn at once, the first SetSize does it
+		return
+	}
+	p.mu.Lock()
+	f := &firstFrame{position: p.position, done: make(chan struct{})}
+	p.first = f
+	p.mu.Unlock()
+
+	go func() {
+		defer close(f.done)
+		f.data, f.shown, f.err = decodeStill(p.ctx, seekStill, p.path, f.position, p.previewFilters())
+	}()
+}
+
+// Loading reports whether the opening frame is still on its way.
+func (p *Player) Loading() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.first != nil && p.currentFrame == ""
+}
+
+// takeFirst returns the preloaded frame if it is for position (must be
+// called with lock held).
+func (p *Player) takeFirst(position time.Duration) *firstFrame {
+	if p.first == nil || p.first.position != position {
+		return nil
+	}
+	return p.first
+}
+
+// renderFirst converts the preloaded frame at width x height, in the
+// background so the rest of the UI draws meanwhile. Nothing is shown if
+// the playhead, size or play state changed first.
+func (p *Player) renderFirst(f *firstFrame, width, height int, quality QualityPreset) {
+	<-f.done
+	var frame string
+	err := f.err
+	if err == nil {
+		frame, err = currentFrameRenderer().RenderFrame(p.ctx, f.data, width, height, quality)
+	}
+	if err == nil {
+		p.cache.PutShown(f.position, f.shown, width, height, quality, frame)
+	}
+
+	p.mu.Lock()
+	if p.first == f {
+		p.first = nil
+	}
+	current := p.position == f.position && p.width == width && p.height == height && !p.playing
+	p.mu.Unlock()
+
+	switch {
+	case !current:
+	case err != nil:
+		// Decoding without the size failed; try the usual way once
+		p.renderFrameCached(f.position, width, height, quality)
+	default:
+		p.mu.Lock()
+		p.setFrame(frame, f.position, f.shown)
+		p.notify()
+		p.mu.Unlock()
+	}
+}
+
+// decodeStill decodes one frame at position through filters as a BMP,
+// returning where the frame really is, see shownPosition.
+func decodeStill(ctx context.Context, op seekOp, path string, position time.Duration, filters []string) ([]byte, time.Duration, error) {
+	cmd := command(ctx, "ffmpeg", stillArgs(op, path, position, filters)...)
+	var log bytes.Buffer
+	cmd.Stderr = &log
+	frame, err := cmd.Output()
+	if err != nil {
+		return nil, 0, err
+	}
+	return frame, shownPosition(op, position, frameTimes(log.Bytes())), nil
+}
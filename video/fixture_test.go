@@ -0,0 +1,107 @@
+package video_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+// fixture generates f with the real ffmpeg, skipping the test without it.
+func fixture(t *testing.T, f videotest.Fixture) string {
+	t.Helper()
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("needs %s", tool)
+		}
+	}
+	path, err := f.Generate(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// near reports whether got is within tolerance of want.
+func near(got, want, tolerance time.Duration) bool {
+	return got > want-tolerance && got < want+tolerance
+}
+
+func TestProbeFixture(t *testing.T) {
+	f := videotest.SmallFixture
+	path := fixture(t, f)
+
+	props, err := video.GetVideoProperties(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props.Width != f.Width || props.Height != f.Height {
+		t.Errorf("size = %dx%d, want %dx%d", props.Width, props.Height, f.Width, f.Height)
+	}
+	if props.FPS != float64(f.FPS) {
+		t.Errorf("fps = %g, want %d", props.FPS, f.FPS)
+	}
+	if !near(props.Duration, f.Duration, 100*time.Millisecond) {
+		t.Errorf("duration = %v, want %v", props.Duration, f.Duration)
+	}
+	if props.Codec != "mpeg4" {
+		t.Errorf("codec = %s, want mpeg4", props.Codec)
+	}
+}
+
+func TestExportFixture(t *testing.T) {
+	path := fixture(t, videotest.SmallFixture)
+	ctx := context.Background()
+	props, err := video.GetVideoProperties(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name  string
+		ratio video.AspectRatio
+		exact bool
+		w, h  int
+	}{
+		{"copy", video.AspectOriginal, false, 320, 180},
+		{"exact", video.AspectOriginal, true, 320, 180},
+		{"crop", video.Aspect9x16, false, 100, 180},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			opts := video.DefaultExportOptions(path, props)
+			opts.Output = filepath.Join(t.TempDir(), c.name+".mp4")
+			opts.InPoint, opts.OutPoint = 500*time.Millisecond, 1500*time.Millisecond
+			opts.AspectRatio, opts.FrameExact = c.ratio, c.exact
+
+			progress := make(chan float64, 64)
+			go func() {
+				for range progress {
+				}
+			}()
+			output, _, err := video.ExportWithProgress(ctx, opts, progress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := video.GetVideoProperties(ctx, output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Width != c.w || got.Height != c.h {
+				t.Errorf("size = %dx%d, want %dx%d", got.Width, got.Height, c.w, c.h)
+			}
+			// A copy starts on the keyframe before the in-point, up to a GOP
+			// early; re-encodes cut where asked
+			tolerance := 100 * time.Millisecond
+			if !c.exact && c.ratio == video.AspectOriginal {
+				tolerance = time.Second
+			}
+			if !near(got.Duration, time.Second, tolerance) {
+				t.Errorf("duration = %v, want about 1s", got.Duration)
+			}
+		})
+	}
+}
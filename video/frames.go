@@ -0,0 +1,184 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// FrameFormat is the image format ffmpeg pipes into a FrameStream.
+type FrameFormat int
+
+const (
+	FrameBMP FrameFormat = iota // image2pipe + bmp, self-describing
+	FramePPM                    // image2pipe + ppm (P6), self-describing
+	FrameRaw                    // rawvideo rgb24 with dimensions known up front
+)
+
+const (
+	bmpHeaderSize = 54
+	// maxFrameBytes rejects absurd sizes from a corrupted header before
+	// allocating; 8K RGB is ~100MB, preview frames are capped at 1920 wide.
+	maxFrameBytes = 64 << 20
+	// maxResyncBytes bounds how far we scan for the next frame signature
+	// before declaring the stream broken.
+	maxResyncBytes = 16 << 20
+)
+
+// ErrResyncFailed is returned when no frame signature is found within
+// maxResyncBytes of a corrupt frame.
+var ErrResyncFailed = fmt.Errorf("frame stream: could not resynchronize")
+
+// readFrame reads one self-describing (BMP or PPM) frame, skipping corrupt
+// data. skipped reports how many corrupt frames were discarded on the way.
+func readFrame(r *bufio.Reader, format FrameFormat) (frame []byte, skipped int, err error) {
+	for {
+		switch format {
+		case FramePPM:
+			frame, err = readPPM(r)
+		default:
+			frame, err = readBMP(r)
+		}
+		if err == nil {
+			return frame, skipped, nil
+		}
+		if err != errCorruptFrame {
+			return nil, skipped, err
+		}
+		skipped++
+		if err := resync(r, format); err != nil {
+			return nil, skipped, err
+		}
+	}
+}
+
+var errCorruptFrame = fmt.Errorf("corrupt frame")
+
+// readBMP reads a BMP file. Headers are validated beyond the "BM" magic
+// (reserved fields, DIB size) so pixel data that happens to contain "BM"
+// is not mistaken for a frame after a resync.
+func readBMP(r *bufio.Reader) ([]byte, error) {
+	header, err := r.Peek(bmpHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	if header[0] != 'B' || header[1] != 'M' {
+		return nil, errCorruptFrame
+	}
+	frameSize := binary.LittleEndian.Uint32(header[2:6])
+	reserved := binary.LittleEndian.Uint32(header[6:10])
+	dataOffset := binary.LittleEndian.Uint32(header[10:14])
+	dibSize := binary.LittleEndian.Uint32(header[14:18])
+	if reserved != 0 || frameSize < bmpHeaderSize || frameSize > maxFrameBytes ||
+		dataOffset > frameSize || dibSize < 40 {
+		return nil, errCorruptFrame
+	}
+
+	frame := getFrameBuf(int(frameSize))
+	if _, err := io.ReadFull(r, frame); err != nil {
+		putFrameBuf(frame)
+		return nil, err
+	}
+	return frame, nil
+}
+
+// readPPM reads a binary (P6) PPM with 8-bit samples.
+func readPPM(r *bufio.Reader) ([]byte, error) {
+	magic, err := r.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if magic[0] != 'P' || magic[1] != '6' {
+		return nil, errCorruptFrame
+	}
+
+	// Header is "P6 <w> <h> <maxval>" followed by one whitespace byte;
+	// it is short, so parse it from a peeked window.
+	window, err := r.Peek(64)
+	if err != nil && len(window) == 0 {
+		return nil, err
+	}
+	var fields []int
+	i := 2
+	for len(fields) < 3 && i < len(window) {
+		c := window[i]
+		switch {
+		case c == '#':
+			for i < len(window) && window[i] != '\n' {
+				i++
+			}
+		case isSpace(c):
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(window) && window[i] >= '0' && window[i] <= '9' {
+				i++
+			}
+			n, _ := strconv.Atoi(string(window[start:i]))
+			fields = append(fields, n)
+		default:
+			return nil, errCorruptFrame
+		}
+	}
+	if len(fields) < 3 || i >= len(window) || !isSpace(window[i]) {
+		return nil, errCorruptFrame
+	}
+	headerLen := i + 1
+	w, h, maxval := fields[0], fields[1], fields[2]
+	if w <= 0 || h <= 0 || maxval <= 0 || maxval > 255 {
+		return nil, errCorruptFrame
+	}
+	frameSize := headerLen + w*h*3
+	if frameSize > maxFrameBytes {
+		return nil, errCorruptFrame
+	}
+
+	frame := make([]byte, frameSize)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// ppmHeader returns the P6 header for a w x h rgb24 frame. Prefixing raw
+// rgb24 pixels with it yields an image chafa can read without conversion.
+func ppmHeader(w, h int) []byte {
+	return []byte(fmt.Sprintf("P6\n%d %d\n255\n", w, h))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// resync discards bytes until the next frame signature is at the head of r.
+func resync(r *bufio.Reader, format FrameFormat) error {
+	sig := []byte("BM")
+	if format == FramePPM {
+		sig = []byte("P6")
+	}
+
+	// Always drop at least one byte so a bad header is not re-read forever
+	if _, err := r.Discard(1); err != nil {
+		return err
+	}
+	for scanned := 0; scanned < maxResyncBytes; {
+		if _, err := r.Peek(len(sig)); err != nil {
+			return err
+		}
+		buf, _ := r.Peek(r.Buffered())
+		if idx := bytes.Index(buf, sig); idx >= 0 {
+			_, err := r.Discard(idx)
+			return err
+		}
+		// Keep the tail in case the signature straddles the boundary
+		n := len(buf) - (len(sig) - 1)
+		if _, err := r.Discard(n); err != nil {
+			return err
+		}
+		scanned += n
+	}
+	return ErrResyncFailed
+}
@@ -0,0 +1,66 @@
+package video
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// showinfoPTS matches the presentation time showinfo logs for a frame.
+var showinfoPTS = regexp.MustCompile(`pts_time:\s*(-?[0-9.]+)`)
+
+// frameTimes reads the pts_time of every frame showinfo logged, in order.
+func frameTimes(log []byte) []time.Duration {
+	var times []time.Duration
+	for _, line := range bytes.Split(log, []byte("\n")) {
+		if !bytes.Contains(line, []byte("showinfo")) {
+			continue
+		}
+		m := showinfoPTS.FindSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if secs, err := strconv.ParseFloat(string(m[1]), 64); err == nil {
+			times = append(times, time.Duration(secs*float64(time.Second)))
+		}
+	}
+	return times
+}
+
+// shownPosition is where the frame decoded for position really is, going
+// by the times showinfo logged at the head of the filter chain: relative
+// to position for fast and accurate seeks, whose output starts at 0 (a
+// fast seek lands on the keyframe before, so it is negative), and source
+// times for exact seeks, which pass every frame before position through
+// the filters. It is position itself when nothing was logged.
+func shownPosition(op seekOp, position time.Duration, times []time.Duration) time.Duration {
+	if len(times) == 0 {
+		return position
+	}
+	if op.mode() != SeekExact {
+		return max(0, position+times[0])
+	}
+	for _, t := range times {
+		if t >= position-time.Millisecond {
+			return t
+		}
+	}
+	return position
+}
+
+// stillArgs decodes the frame at position through filters to a BMP on
+// stdout, with showinfo logging its time to stderr for shownPosition.
+func stillArgs(op seekOp, path string, position time.Duration, filters []string) []string {
+	chain := append([]string{"showinfo"}, filters...)
+	return append(seekInput(op, position, path),
+		"-vf", strings.Join(chain, ","),
+		"-vframes", "1",
+		"-f", "image2pipe",
+		"-vcodec", "bmp",
+		"-hide_banner", "-nostats",
+		"-loglevel", "info",
+		"-",
+	)
+}
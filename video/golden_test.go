@@ -0,0 +1,41 @@
+package video_test
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// golden checks each case's line of got against testdata/name.golden.
+func golden(t *testing.T, name string, got []string) {
+	t.Helper()
+	data := []byte(strings.Join(got, "\n") + "\n")
+	if err := videotest.Golden(filepath.Join("testdata", name+".golden"), data, *update); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuildFFmpegCommandGolden(t *testing.T) {
+	var got []string
+	for _, c := range videotest.ExportCases("in.mp4") {
+		got = append(got, c.Name+": "+video.BuildFFmpegCommand(c.Opts))
+	}
+	golden(t, "ffmpeg_commands", got)
+}
+
+func TestBuildCropFilterGolden(t *testing.T) {
+	var got []string
+	for _, c := range videotest.ExportCases("in.mp4") {
+		o := c.Opts
+		filter := video.BuildCropFilter(o.Width, o.Height, o.AspectRatio, o.CropOffset)
+		got = append(got, fmt.Sprintf("%s: %q", c.Name, filter))
+	}
+	golden(t, "crop_filters", got)
+}
@@ -0,0 +1,95 @@
+package video
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GraphicsMode is how preview frames are drawn in the terminal.
+type GraphicsMode int
+
+const (
+	GraphicsSymbols GraphicsMode = iota // chafa character art, any terminal
+	GraphicsKitty                       // kitty graphics protocol, real pixels
+	GraphicsSixel                       // chafa sixels, real pixels
+)
+
+func (g GraphicsMode) String() string {
+	switch g {
+	case GraphicsKitty:
+		return "kitty"
+	case GraphicsSixel:
+		return "sixel"
+	}
+	return "symbols"
+}
+
+// ParseGraphicsMode reads a --graphics value; "auto" asks DetectGraphics.
+func ParseGraphicsMode(s string) (GraphicsMode, error) {
+	switch s {
+	case "", "auto":
+		return DetectGraphics(), nil
+	case "kitty":
+		return GraphicsKitty, nil
+	case "sixel":
+		return GraphicsSixel, nil
+	case "symbols":
+		return GraphicsSymbols, nil
+	}
+	return GraphicsSymbols, fmt.Errorf("unknown graphics %q (want auto, kitty, sixel or symbols)", s)
+}
+
+// DetectGraphics picks kitty graphics in terminals known to draw them
+// through Unicode placeholders (kitty, ghostty) and sixels in terminals
+// known to draw those (foot, mlterm, contour, WezTerm). xterm only does
+// with -ti vt340, which the environment does not tell, so it needs
+// --graphics sixel. Inside tmux or screen the escapes would need
+// passthrough, so those get symbols.
+func DetectGraphics() GraphicsMode {
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" {
+		return GraphicsSymbols
+	}
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case term == "xterm-kitty" || term == "xterm-ghostty" ||
+		os.Getenv("KITTY_WINDOW_ID") != "" || program == "ghostty":
+		return GraphicsKitty
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") ||
+		strings.HasPrefix(term, "contour") || program == "WezTerm":
+		return GraphicsSixel
+	}
+	return GraphicsSymbols
+}
+
+// graphicsMode is set once at startup, like maxColors.
+var graphicsMode = GraphicsSymbols
+
+// SetGraphics selects the preview backend. Frames kitty cannot carry (a
+// preview taller than the placeholder rows) still come out as symbols,
+// and so do frames shown inside other text, where a sixel image cannot
+// go (see IsSixel).
+func SetGraphics(mode GraphicsMode) {
+	graphicsMode = mode
+}
+
+// IsSixel reports whether frame is a sixel image rather than text. It
+// cannot be laid out with lipgloss: the caller reserves the cells and
+// draws it at their position.
+func IsSixel(frame string) bool {
+	return strings.Contains(frame, "\x1bP")
+}
+
+// chafaCellPixels is the cell size chafa assumes when it cannot ask the
+// terminal, which it cannot through the pipe lazycut reads it from.
+var chafaCellPixels = [2]int{10, 20}
+
+// sixelSize converts a width x height cell area into the --size that
+// makes chafa's sixel image fit it in this terminal's cells.
+func sixelSize(width, height int) (int, int) {
+	cw, ch := cellPixels()
+	if cw == 0 || ch == 0 {
+		return width, height
+	}
+	return max(1, width*cw/chafaCellPixels[0]), max(1, height*ch/chafaCellPixels[1])
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package video
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// cellPixels returns the size of a terminal cell in pixels, or zeros when
+// the terminal does not report its pixel size.
+func cellPixels() (w, h int) {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.Col == 0 || ws.Row == 0 {
+		return 0, 0
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
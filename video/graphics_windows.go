@@ -0,0 +1,8 @@
+//go:build windows
+
+package video
+
+// cellPixels returns zeros: the console API has no pixel size for cells.
+func cellPixels() (w, h int) {
+	return 0, 0
+}
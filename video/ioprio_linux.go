@@ -0,0 +1,22 @@
+package video
+
+import "syscall"
+
+// ioprio_set(2) constants, as used by ionice(1)
+const (
+	ioprioWhoPgrp    = 2
+	ioprioClassBE    = 2
+	ioprioClassShift = 13
+	ioprioLowest     = 7
+)
+
+// lowerIOPriority puts the process group pgid in the lowest best-effort
+// I/O class, like ionice -c2 -n7. The idle class would be gentler still,
+// but it can stall an export completely while anything else reads the disk.
+func lowerIOPriority(pgid int) error {
+	prio := ioprioClassBE<<ioprioClassShift | ioprioLowest
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoPgrp, uintptr(pgid), uintptr(prio)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !windows
+
+package video
+
+// lowerIOPriority is a no-op: only Linux has per-process I/O priorities,
+// elsewhere the nice value is all there is.
+func lowerIOPriority(pgid int) error {
+	return nil
+}
@@ -0,0 +1,237 @@
+package video
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// kittyCellPixels is the image resolution sent per cell: the terminal
+// scales it to the font, so this only trades detail for bytes written.
+var kittyCellPixels = map[QualityPreset][2]int{
+	QualityLow:  {4, 8},
+	QualityHigh: {8, 16},
+}
+
+// kittyImageIDs alternate between frames, so the previous image can be
+// deleted by the frame replacing it. They are sent as the 24-bit
+// foreground color of the placeholder cells, which is how the terminal
+// knows which image a cell shows.
+var (
+	kittyImageIDs   = [2]uint32{0x4c4301, 0x4c4302}
+	kittyNextImage  atomic.Uint32
+	errKittyTooTall = errors.New("kitty: preview taller than the placeholder rows")
+)
+
+// kittyPlaceholder is the cell character the terminal replaces with the
+// image; the diacritics after it number the row and column.
+const kittyPlaceholder = '\U0010EEEE'
+
+// renderKitty converts a BMP or PPM frame into a kitty graphics
+// transmission followed by width x height (at most) placeholder cells.
+// The image goes out as a virtual placement (U=1) so the frame stays text
+// that lipgloss can lay out and bubbletea can redraw line by line.
+func renderKitty(frame []byte, width, height int, quality QualityPreset) (string, error) {
+	w, h, rgb, err := framePixels(frame)
+	if err != nil {
+		return "", err
+	}
+	cols, rows := FitCells(w, h, width, height)
+	if rows > len(kittyDiacritics) {
+		return "", errKittyTooTall
+	}
+	cell := kittyCellPixels[quality]
+	tw, th := min(w, cols*cell[0]), min(h, rows*cell[1])
+	rgb = scalePixels(rgb, w, h, tw, th)
+
+	var packed bytes.Buffer
+	zw, _ := zlib.NewWriterLevel(&packed, zlib.BestSpeed)
+	zw.Write(rgb)
+	zw.Close()
+	payload := base64.StdEncoding.EncodeToString(packed.Bytes())
+
+	n := kittyNextImage.Add(1)
+	id, prev := kittyImageIDs[n%2], kittyImageIDs[(n+1)%2]
+
+	var b strings.Builder
+	b.Grow(len(payload) + len(payload)/4096*16 + rows*(cols*4+32))
+	fmt.Fprintf(&b, "\x1b_Ga=d,d=I,i=%d,q=2\x1b\\", prev)
+	// Payloads go out in chunks of at most 4096 bytes
+	for i := 0; i < len(payload); i += 4096 {
+		chunk := payload[i:min(i+4096, len(payload))]
+		more := 0
+		if i+4096 < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,U=1,q=2,f=24,o=z,s=%d,v=%d,i=%d,c=%d,r=%d,m=%d;%s\x1b\\",
+				tw, th, id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm", id>>16&0xff, id>>8&0xff, id&0xff)
+		// The first cell names its row and column; the rest of the row
+		// is inferred from it
+		b.WriteRune(kittyPlaceholder)
+		b.WriteRune(kittyDiacritics[r])
+		b.WriteRune(kittyDiacritics[0])
+		for c := 1; c < cols; c++ {
+			b.WriteRune(kittyPlaceholder)
+		}
+		b.WriteString("\x1b[39m")
+	}
+	return b.String(), nil
+}
+
+// FitCells is the largest cols x rows within width x height showing a
+// w x h image undistorted, taking a cell to be twice as tall as wide.
+func FitCells(w, h, width, height int) (cols, rows int) {
+	cols = width
+	rows = (cols*h + w) / (2 * w)
+	if rows > height {
+		rows = height
+		cols = (rows*2*w + h/2) / h
+	}
+	return max(1, min(cols, width)), max(1, rows)
+}
+
+// framePixels returns the rgb24 pixels of a BMP or PPM frame, top row
+// first.
+func framePixels(frame []byte) (w, h int, rgb []byte, err error) {
+	switch {
+	case len(frame) >= bmpHeaderSize && frame[0] == 'B' && frame[1] == 'M':
+		return bmpPixels(frame)
+	case len(frame) >= 2 && frame[0] == 'P' && frame[1] == '6':
+		return ppmPixels(frame)
+	}
+	return 0, 0, nil, errCorruptFrame
+}
+
+func bmpPixels(frame []byte) (w, h int, rgb []byte, err error) {
+	offset := int(binary.LittleEndian.Uint32(frame[10:14]))
+	w = int(int32(binary.LittleEndian.Uint32(frame[18:22])))
+	h = int(int32(binary.LittleEndian.Uint32(frame[22:26])))
+	bpp := int(binary.LittleEndian.Uint16(frame[28:30]))
+	// Positive heights are stored bottom row first
+	bottomUp := h > 0
+	if h < 0 {
+		h = -h
+	}
+	if w <= 0 || h == 0 || (bpp != 24 && bpp != 32) {
+		return 0, 0, nil, errCorruptFrame
+	}
+	px := bpp / 8
+	stride := (w*px + 3) &^ 3
+	if offset+stride*h > len(frame) {
+		return 0, 0, nil, errCorruptFrame
+	}
+	rgb = make([]byte, w*h*3)
+	for y := 0; y < h; y++ {
+		src := y
+		if bottomUp {
+			src = h - 1 - y
+		}
+		row := frame[offset+src*stride:]
+		out := rgb[y*w*3:]
+		for x := 0; x < w; x++ {
+			out[x*3], out[x*3+1], out[x*3+2] = row[x*px+2], row[x*px+1], row[x*px]
+		}
+	}
+	return w, h, rgb, nil
+}
+
+func ppmPixels(frame []byte) (w, h int, rgb []byte, err error) {
+	r := bytes.NewReader(frame)
+	var magic string
+	var maxval int
+	if _, err := fmt.Fscan(r, &magic, &w, &h, &maxval); err != nil || maxval != 255 {
+		return 0, 0, nil, errCorruptFrame
+	}
+	// One whitespace byte ends the header
+	r.ReadByte()
+	start := len(frame) - r.Len()
+	if w <= 0 || h <= 0 || start+w*h*3 > len(frame) {
+		return 0, 0, nil, errCorruptFrame
+	}
+	return w, h, frame[start : start+w*h*3], nil
+}
+
+// scalePixels box-filters w x h rgb24 pixels down to tw x th.
+func scalePixels(rgb []byte, w, h, tw, th int) []byte {
+	if tw == w && th == h {
+		return rgb
+	}
+	out := make([]byte, tw*th*3)
+	for ty := 0; ty < th; ty++ {
+		y0, y1 := ty*h/th, max(ty*h/th+1, (ty+1)*h/th)
+		for tx := 0; tx < tw; tx++ {
+			x0, x1 := tx*w/tw, max(tx*w/tw+1, (tx+1)*w/tw)
+			var sr, sg, sb, n int
+			for y := y0; y < y1; y++ {
+				row := rgb[(y*w+x0)*3 : (y*w+x1)*3]
+				for i := 0; i < len(row); i += 3 {
+					sr += int(row[i])
+					sg += int(row[i+1])
+					sb += int(row[i+2])
+				}
+				n += x1 - x0
+			}
+			o := (ty*tw + tx) * 3
+			out[o], out[o+1], out[o+2] = byte(sr/n), byte(sg/n), byte(sb/n)
+		}
+	}
+	return out
+}
+
+// kittyDiacritics number placeholder rows and columns, in the order of
+// kitty's rowcolumn-diacritics.txt.
+var kittyDiacritics = []rune{
+	'\u0305', '\u030D', '\u030E', '\u0310', '\u0312', '\u033D', '\u033E', '\u033F',
+	'\u0346', '\u034A', '\u034B', '\u034C', '\u0350', '\u0351', '\u0352', '\u0357',
+	'\u035B', '\u0363', '\u0364', '\u0365', '\u0366', '\u0367', '\u0368', '\u0369',
+	'\u036A', '\u036B', '\u036C', '\u036D', '\u036E', '\u036F', '\u0483', '\u0484',
+	'\u0485', '\u0486', '\u0487', '\u0592', '\u0593', '\u0594', '\u0595', '\u0597',
+	'\u0598', '\u0599', '\u059C', '\u059D', '\u059E', '\u059F', '\u05A0', '\u05A1',
+	'\u05A8', '\u05A9', '\u05AB', '\u05AC', '\u05AF', '\u05C4', '\u0610', '\u0611',
+	'\u0612', '\u0613', '\u0614', '\u0615', '\u0616', '\u0617', '\u0657', '\u0658',
+	'\u0659', '\u065A', '\u065B', '\u065D', '\u065E', '\u06D6', '\u06D7', '\u06D8',
+	'\u06D9', '\u06DA', '\u06DB', '\u06DC', '\u06DF', '\u06E0', '\u06E1', '\u06E2',
+	'\u06E4', '\u06E7', '\u06E8', '\u06EB', '\u06EC', '\u0730', '\u0732', '\u0733',
+	'\u0735', '\u0736', '\u073A', '\u073D', '\u073F', '\u0740', '\u0741', '\u0743',
+	'\u0745', '\u0747', '\u0749', '\u074A', '\u07EB', '\u07EC', '\u07ED', '\u07EE',
+	'\u07EF', '\u07F0', '\u07F1', '\u07F3', '\u0816', '\u0817', '\u0818', '\u0819',
+	'\u081B', '\u081C', '\u081D', '\u081E', '\u081F', '\u0820', '\u0821', '\u0822',
+	'\u0823', '\u0825', '\u0826', '\u0827', '\u0829', '\u082A', '\u082B', '\u082C',
+	'\u082D', '\u0951', '\u0953', '\u0954', '\u0F82', '\u0F83', '\u0F86', '\u0F87',
+	'\u135D', '\u135E', '\u135F', '\u17DD', '\u193A', '\u1A17', '\u1A75', '\u1A76',
+	'\u1A77', '\u1A78', '\u1A79', '\u1A7A', '\u1A7B', '\u1A7C', '\u1B6B', '\u1B6D',
+	'\u1B6E', '\u1B6F', '\u1B70', '\u1B71', '\u1B72', '\u1B73', '\u1CD0', '\u1CD1',
+	'\u1CD2', '\u1CDA', '\u1CDB', '\u1CE0', '\u1DC0', '\u1DC1', '\u1DC3', '\u1DC4',
+	'\u1DC5', '\u1DC6', '\u1DC7', '\u1DC8', '\u1DC9', '\u1DCB', '\u1DCC', '\u1DD1',
+	'\u1DD2', '\u1DD3', '\u1DD4', '\u1DD5', '\u1DD6', '\u1DD7', '\u1DD8', '\u1DD9',
+	'\u1DDA', '\u1DDB', '\u1DDC', '\u1DDD', '\u1DDE', '\u1DDF', '\u1DE0', '\u1DE1',
+	'\u1DE2', '\u1DE3', '\u1DE4', '\u1DE5', '\u1DE6', '\u1DFE', '\u20D0', '\u20D1',
+	'\u20D4', '\u20D5', '\u20D6', '\u20D7', '\u20DB', '\u20DC', '\u20E1', '\u20E7',
+	'\u20E9', '\u20F0', '\u2CEF', '\u2CF0', '\u2CF1', '\u2DE0', '\u2DE1', '\u2DE2',
+	'\u2DE3', '\u2DE4', '\u2DE5', '\u2DE6', '\u2DE7', '\u2DE8', '\u2DE9', '\u2DEA',
+	'\u2DEB', '\u2DEC', '\u2DED', '\u2DEE', '\u2DEF', '\u2DF0', '\u2DF1', '\u2DF2',
+	'\u2DF3', '\u2DF4', '\u2DF5', '\u2DF6', '\u2DF7', '\u2DF8', '\u2DF9', '\u2DFA',
+	'\u2DFB', '\u2DFC', '\u2DFD', '\u2DFE', '\u2DFF', '\uA66F', '\uA67C', '\uA67D',
+	'\uA6F0', '\uA6F1', '\uA8E0', '\uA8E1', '\uA8E2', '\uA8E3', '\uA8E4', '\uA8E5',
+	'\uA8E6', '\uA8E7', '\uA8E8', '\uA8E9', '\uA8EA', '\uA8EB', '\uA8EC', '\uA8ED',
+	'\uA8EE', '\uA8EF', '\uA8F0', '\uA8F1', '\uAAB0', '\uAAB2', '\uAAB3', '\uAAB7',
+	'\uAAB8', '\uAABE', '\uAABF', '\uAAC1', '\uFE20', '\uFE21', '\uFE22', '\uFE23',
+	'\uFE24', '\uFE25', '\uFE26', '\U00010A0F', '\U00010A38', '\U0001D185', '\U0001D186', '\U0001D187',
+	'\U0001D188', '\U0001D189', '\U0001D1AA', '\U0001D1AB', '\U0001D1AC', '\U0001D1AD', '\U0001D242', '\U0001D243',
+	'\U0001D244',
+}
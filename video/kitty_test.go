@@ -0,0 +1,254 @@
+package video
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testBMP is a w x h BMP of bpp bits per pixel, stored bottom row first
+// unless topDown. Pixel (x, y) has the color {x, y, x+y}.
+func testBMP(w, h, bpp int, topDown bool) []byte {
+	px := bpp / 8
+	stride := (w*px + 3) &^ 3
+	frame := make([]byte, bmpHeaderSize+stride*h)
+	frame[0], frame[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(frame[2:6], uint32(len(frame)))
+	binary.LittleEndian.PutUint32(frame[10:14], bmpHeaderSize)
+	binary.LittleEndian.PutUint32(frame[18:22], uint32(int32(w)))
+	stored := int32(h)
+	if topDown {
+		stored = -stored
+	}
+	binary.LittleEndian.PutUint32(frame[22:26], uint32(stored))
+	binary.LittleEndian.PutUint16(frame[28:30], uint16(bpp))
+	for y := 0; y < h; y++ {
+		row := h - 1 - y
+		if topDown {
+			row = y
+		}
+		for x := 0; x < w; x++ {
+			o := bmpHeaderSize + row*stride + x*px
+			// BMP stores blue, green, red
+			frame[o], frame[o+1], frame[o+2] = byte(x+y), byte(y), byte(x)
+		}
+	}
+	return frame
+}
+
+// testRGB is the rgb24 pixels testBMP and testPPM encode.
+func testRGB(w, h int) []byte {
+	rgb := make([]byte, 0, w*h*3)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			rgb = append(rgb, byte(x), byte(y), byte(x+y))
+		}
+	}
+	return rgb
+}
+
+func testPPM(w, h int) []byte {
+	return append(fmt.Appendf(nil, "P6\n%d %d\n255\n", w, h), testRGB(w, h)...)
+}
+
+func TestFramePixels(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame []byte
+		w, h  int
+	}{
+		{"bmp bottom-up", testBMP(4, 3, 24, false), 4, 3},
+		{"bmp top-down", testBMP(4, 3, 24, true), 4, 3},
+		{"bmp row padding", testBMP(3, 2, 24, false), 3, 2},
+		{"bmp 32 bits", testBMP(3, 2, 32, false), 3, 2},
+		{"ppm", testPPM(5, 2), 5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, h, rgb, err := framePixels(tt.frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w != tt.w || h != tt.h {
+				t.Errorf("size %dx%d, want %dx%d", w, h, tt.w, tt.h)
+			}
+			if want := testRGB(tt.w, tt.h); !bytes.Equal(rgb, want) {
+				t.Errorf("pixels\n%v\nwant\n%v", rgb, want)
+			}
+		})
+	}
+}
+
+func TestFramePixelsCorrupt(t *testing.T) {
+	bmp16 := testBMP(2, 2, 24, false)
+	binary.LittleEndian.PutUint16(bmp16[28:30], 16)
+	zeroWidth := testBMP(2, 2, 24, false)
+	binary.LittleEndian.PutUint32(zeroWidth[18:22], 0)
+	ppm16 := append([]byte("P6\n1 1\n65535\n"), 0, 0, 0, 0, 0, 0)
+
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{"empty", nil},
+		{"not an image", []byte("GIF89a")},
+		{"bmp 16 bits", bmp16},
+		{"bmp zero width", zeroWidth},
+		{"bmp truncated", testBMP(4, 4, 24, false)[:bmpHeaderSize+20]},
+		{"ppm 16 bits", ppm16},
+		{"ppm bad header", []byte("P6\nwide tall\n255\n")},
+		{"ppm truncated", testPPM(4, 4)[:20]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := framePixels(tt.frame); !errors.Is(err, errCorruptFrame) {
+				t.Errorf("err = %v, want errCorruptFrame", err)
+			}
+		})
+	}
+}
+
+func TestFitCells(t *testing.T) {
+	tests := []struct {
+		name                string
+		w, h, width, height int
+		cols, rows          int
+	}{
+		{"16:9 in a wide panel", 1920, 1080, 80, 40, 80, 23},
+		{"4:3 filling the panel", 640, 480, 80, 30, 80, 30},
+		{"portrait takes the height", 1080, 1920, 80, 40, 45, 40},
+		{"never wider than the panel", 1000, 10, 20, 5, 20, 1},
+		{"at least one cell", 1, 1000, 10, 1, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cols, rows := FitCells(tt.w, tt.h, tt.width, tt.height)
+			if cols != tt.cols || rows != tt.rows {
+				t.Errorf("FitCells(%d, %d, %d, %d) = %d x %d, want %d x %d",
+					tt.w, tt.h, tt.width, tt.height, cols, rows, tt.cols, tt.rows)
+			}
+		})
+	}
+}
+
+var (
+	kittyTransmit = regexp.MustCompile(`\x1b_Ga=T,U=1,q=2,f=24,o=z,s=(\d+),v=(\d+),i=(\d+),c=(\d+),r=(\d+),m=\d;`)
+	kittyPayload  = regexp.MustCompile(`\x1b_G[^;\x1b]*;([^\x1b]*)\x1b\\`)
+)
+
+func TestRenderKitty(t *testing.T) {
+	// 4x2 pixels fit 10 x 3 cells, well under the 8x16 pixels per cell
+	// of high quality, so the image goes out unscaled
+	out, err := renderKitty(testPPM(4, 2), 10, 10, QualityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := kittyTransmit.FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("no transmission in %q", out)
+	}
+	if got := strings.Join(m[1:3], "x") + " " + strings.Join(m[4:6], "x"); got != "4x2 10x3" {
+		t.Errorf("sent pixels and cells %s, want 4x2 10x3", got)
+	}
+	var payload string
+	for _, p := range kittyPayload.FindAllStringSubmatch(out, -1) {
+		payload += p[1]
+	}
+	packed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(packed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rgb, _ := io.ReadAll(zr); !bytes.Equal(rgb, testRGB(4, 2)) {
+		t.Errorf("payload decodes to %v, want the frame's pixels", rgb)
+	}
+
+	// Placeholder cells after the last escape of the transmission
+	rows := strings.Split(out[strings.LastIndex(out, "\x1b\\")+2:], "\n")
+	if len(rows) != 3 {
+		t.Fatalf("%d placeholder rows, want 3", len(rows))
+	}
+	for r, row := range rows {
+		if n := strings.Count(row, string(kittyPlaceholder)); n != 10 {
+			t.Errorf("row %d has %d placeholders, want 10", r, n)
+		}
+		if i := strings.IndexRune(row, kittyPlaceholder); i < 0 || []rune(row[i:])[1] != kittyDiacritics[r] {
+			t.Errorf("row %d is not numbered %U: %q", r, kittyDiacritics[r], row)
+		}
+	}
+}
+
+func TestRenderKittyAlternatesImages(t *testing.T) {
+	id := func(out string) string {
+		m := kittyTransmit.FindStringSubmatch(out)
+		if m == nil {
+			t.Fatalf("no transmission in %q", out)
+		}
+		return m[3]
+	}
+	first, err := renderKitty(testPPM(2, 2), 4, 2, QualityLow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := renderKitty(testPPM(2, 2), 4, 2, QualityLow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id(first) == id(second) {
+		t.Fatalf("both frames use image %s", id(first))
+	}
+	// Each frame deletes the image the one before it showed
+	if want := "\x1b_Ga=d,d=I,i=" + id(first) + ",q=2\x1b\\"; !strings.HasPrefix(second, want) {
+		t.Errorf("second frame does not start by deleting image %s: %q", id(first), second[:min(len(second), 40)])
+	}
+}
+
+func TestRenderKittyChunks(t *testing.T) {
+	// Noise does not compress, so the payload needs several chunks
+	frame := testPPM(64, 64)
+	x := uint32(1)
+	for i := len(frame) - 64*64*3; i < len(frame); i++ {
+		x ^= x << 13
+		x ^= x >> 17
+		x ^= x << 5
+		frame[i] = byte(x)
+	}
+	out, err := renderKitty(frame, 40, 20, QualityHigh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := kittyPayload.FindAllStringSubmatch(out, -1)
+	if len(chunks) < 2 {
+		t.Fatalf("%d chunks, want the payload split", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c[1]) > 4096 {
+			t.Errorf("chunk %d is %d bytes, over 4096", i, len(c[1]))
+		}
+		last := i == len(chunks)-1
+		if more := strings.Contains(c[0], "m=1"); more == last {
+			t.Errorf("chunk %d: m=1 is %v, want %v", i, more, !last)
+		}
+	}
+}
+
+func TestRenderKittyErrors(t *testing.T) {
+	if _, err := renderKitty([]byte("not a frame"), 10, 10, QualityHigh); !errors.Is(err, errCorruptFrame) {
+		t.Errorf("corrupt frame: err = %v", err)
+	}
+	// A tall narrow image needs more rows than there are diacritics
+	if _, err := renderKitty(testPPM(1, 1000), 1, 1000, QualityHigh); !errors.Is(err, errKittyTooTall) {
+		t.Errorf("tall frame: err = %v, want errKittyTooTall", err)
+	}
+}
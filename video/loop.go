@@ -0,0 +1,136 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Loop search works on tiny grayscale thumbnails: enough to tell frames
+// apart, cheap enough to compare every start candidate with every end one.
+const (
+	loopThumbW = 32
+	loopThumbH = 18
+)
+
+// LoopMatch is a frame-aligned selection whose last frame flows into its
+// first. End is exclusive: the frame at End is the one most similar to the
+// frame at Start, so [Start, End) repeats without a visible jump.
+type LoopMatch struct {
+	Start time.Duration
+	End   time.Duration
+	// Diff is the mean absolute luma difference (0-255) between the
+	// frames at Start and End.
+	Diff float64
+}
+
+// FindLoop searches window either side of in and out for the start/end
+// frame pair that differ least, keeping at least minLen between them.
+func FindLoop(ctx context.Context, path string, in, out time.Duration, fps float64, window, minLen time.Duration) (LoopMatch, error) {
+	if fps <= 0 {
+		return LoopMatch{}, fmt.Errorf("unknown frame rate")
+	}
+
+	starts, startAt, err := loopThumbs(ctx, path, in-window, 2*window, fps)
+	if err != nil {
+		return LoopMatch{}, err
+	}
+	ends, endAt, err := loopThumbs(ctx, path, out-window, 2*window, fps)
+	if err != nil {
+		return LoopMatch{}, err
+	}
+
+	frameTime := func(base time.Duration, i int) time.Duration {
+		frame := math.Round(base.Seconds()*fps) + float64(i)
+		return time.Duration(frame / fps * float64(time.Second))
+	}
+
+	best := LoopMatch{Diff: math.Inf(1)}
+	for i, s := range starts {
+		start := frameTime(startAt, i)
+		for j, e := range ends {
+			end := frameTime(endAt, j)
+			if end-start < minLen {
+				continue
+			}
+			if d := thumbDiff(s, e); d < best.Diff {
+				best = LoopMatch{Start: start, End: end, Diff: d}
+			}
+		}
+	}
+	if math.IsInf(best.Diff, 1) {
+		return LoopMatch{}, fmt.Errorf("no loop candidates between %s and %s", FormatTimecode(in), FormatTimecode(out))
+	}
+	return best, nil
+}
+
+// loopThumbs decodes every frame of [from, from+length) as a thumbnail.
+// It returns the thumbnails and the (clamped) time of the first one.
+func loopThumbs(ctx context.Context, path string, from, length time.Duration, fps float64) ([][]byte, time.Duration, error) {
+	if from < 0 {
+		length += from
+		from = 0
+	}
+	from = snapToFrame(from, fps)
+	thumbs, err := grayThumbs(ctx, path, from, length, fps, loopThumbW, loopThumbH)
+	return thumbs, from, err
+}
+
+// grayThumbs decodes [from, from+length) at fps as w x h grayscale frames.
+func grayThumbs(ctx context.Context, path string, from, length time.Duration, fps float64, w, h int) ([][]byte, error) {
+	// -ss before -i is frame accurate when decoding; fps= pins output
+	// frames to the source grid so index i is exactly from + i/fps
+	cmd := command(ctx, "ffmpeg",
+		"-ss", fmt.Sprintf("%.6f", from.Seconds()),
+		"-t", fmt.Sprintf("%.6f", length.Seconds()),
+		"-i", path,
+		"-an",
+		"-vf", fmt.Sprintf("fps=%g,scale=%d:%d,format=gray", fps, w, h),
+		"-f", "rawvideo",
+		"-loglevel", "error",
+		"pipe:1",
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	var thumbs [][]byte
+	for {
+		thumb := make([]byte, w*h)
+		if _, err := io.ReadFull(stdout, thumb); err != nil {
+			break
+		}
+		thumbs = append(thumbs, thumb)
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffmpeg failed: %w", err)
+	}
+	return thumbs, nil
+}
+
+// snapToFrame rounds t to the nearest frame boundary.
+func snapToFrame(t time.Duration, fps float64) time.Duration {
+	frame := math.Round(t.Seconds() * fps)
+	return time.Duration(frame / fps * float64(time.Second))
+}
+
+func thumbDiff(a, b []byte) float64 {
+	var sum int
+	for i := range a {
+		d := int(a[i]) - int(b[i])
+		if d < 0 {
+			d = -d
+		}
+		sum += d
+	}
+	return float64(sum) / float64(len(a))
+}
@@ -0,0 +1,14 @@
+package video_test
+
+import (
+	"os"
+	"testing"
+
+	"lazycut/video/videotest"
+)
+
+func TestMain(m *testing.M) {
+	// Faked commands re-run this binary; they stop here
+	videotest.RunHelper()
+	os.Exit(m.Run())
+}
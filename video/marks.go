@@ -0,0 +1,92 @@
+package video
+
+import (
+	"bufio"
+	"cmp"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Mark is a labeled point of interest on the timeline, such as an event
+// from a log of the recorded session.
+type Mark struct {
+	At    time.Duration
+	Label string
+}
+
+// markTime finds a timestamp in a log line: a clock time ("1:02:03",
+// "02:03.5", "12:03:04,250") or seconds with a unit ("83.2s").
+var markTime = regexp.MustCompile(`\b(?:\d+:)?\d{1,2}:\d{2}(?:[.,]\d{1,3})?|\b\d+(?:\.\d+)?s\b`)
+
+// markDate is the date before a wall-clock time: "2024-05-01", "2024-05-01T".
+var markDate = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}T?`)
+
+// ReadMarks makes a mark of every line of r with a timestamp in it,
+// labeled with the rest of the line; lines without one are skipped. start
+// is subtracted from every timestamp, for logs stamped with the wall-clock
+// time when the recording began at start. Marks come out sorted.
+func ReadMarks(r io.Reader, start time.Duration) ([]Mark, error) {
+	marks, err := logImporter{}.Import(r)
+	return shiftMarks(marks, start), err
+}
+
+// shiftMarks moves marks back by start, drops the ones before it and
+// sorts the rest.
+func shiftMarks(marks []Mark, start time.Duration) []Mark {
+	var out []Mark
+	for _, mark := range marks {
+		if mark.At >= start {
+			out = append(out, Mark{At: mark.At - start, Label: mark.Label})
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Mark) int {
+		return cmp.Compare(a.At, b.At)
+	})
+	return out
+}
+
+// logImporter reads plain log lines, see ReadMarks.
+type logImporter struct{}
+
+func (logImporter) Name() string { return "log" }
+
+func (logImporter) Match(path string) bool { return true }
+
+func (logImporter) Import(r io.Reader) ([]Mark, error) {
+	var marks []Mark
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if at, label, ok := splitMarkLine(scanner.Text()); ok {
+			marks = append(marks, Mark{At: at, Label: label})
+		}
+	}
+	return marks, scanner.Err()
+}
+
+// splitMarkLine finds the first timestamp in line and returns it with the
+// rest of the line as the label.
+func splitMarkLine(line string) (time.Duration, string, bool) {
+	// A date would hide the time glued to it ("2024-05-01T12:03:04")
+	line = markDate.ReplaceAllString(line, " ")
+	loc := markTime.FindStringIndex(line)
+	if loc == nil {
+		return 0, "", false
+	}
+	at, ok := parseMarkTime(line[loc[0]:loc[1]])
+	if !ok {
+		return 0, "", false
+	}
+	// Drop the brackets or separators around the time
+	before := strings.TrimRight(line[:loc[0]], " [(<=:|,;-")
+	after := strings.TrimLeft(line[loc[1]:], " ])>:|,;-")
+	return at, strings.Join(strings.Fields(before+" "+after), " "), true
+}
+
+func parseMarkTime(s string) (time.Duration, bool) {
+	at, err := ParseTimecode(s)
+	return at, err == nil
+}
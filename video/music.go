@@ -0,0 +1,82 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MusicBed is a background track laid under an export's audio.
+type MusicBed struct {
+	Path string
+	// Volume is the track's level, 0-1; 0 leaves the music out.
+	Volume float64
+	// Duck pushes the track down further while the original audio is
+	// loud, sidechain style: 0 mixes at a fixed Volume, 1 ducks hardest.
+	Duck float64
+}
+
+// hasMusic reports whether the export gets a music bed.
+func (opts ExportOptions) hasMusic() bool {
+	return opts.Music.Path != "" && opts.Music.Volume > 0
+}
+
+// bedFade is the longest fade-out at the end of the music, so it does not
+// stop mid-note where the clip ends.
+const bedFade = 2.0
+
+// musicGraph returns the filter chains that lay the music (ffmpeg input
+// number input, looped) under the audio labeled orig, or make it the only
+// audio when orig is "", ending in the label out.
+func musicGraph(opts ExportOptions, orig string, input int, out string) []string {
+	length := opts.clipDuration().Seconds()
+	fade := min(bedFade, length/4)
+	bed := fmt.Sprintf("[%d:a]atrim=duration=%.3f,asetpts=PTS-STARTPTS,volume=%s,afade=t=out:st=%.3f:d=%.3f",
+		input, length, formatFactor(opts.Music.Volume), length-fade, fade)
+	if orig == "" {
+		return []string{bed + out}
+	}
+	if opts.Music.Duck <= 0 {
+		return []string{bed + "[bed]", orig + "[bed]amix=inputs=2:duration=first:normalize=0" + out}
+	}
+	// The original audio is the key: the louder it gets, the more the
+	// music is compressed, up to sidechaincompress's 20:1
+	ratio := 1 + opts.Music.Duck*19
+	return []string{
+		bed + "[bed]",
+		orig + "asplit[orig][key]",
+		fmt.Sprintf("[bed][key]sidechaincompress=threshold=0.02:ratio=%s:attack=20:release=500[ducked]", formatFactor(ratio)),
+		"[orig][ducked]amix=inputs=2:duration=first:normalize=0" + out,
+	}
+}
+
+// musicInputArgs adds the music as a looped input, so a short track
+// covers a long clip.
+func musicInputArgs(opts ExportOptions) []string {
+	return []string{"-stream_loop", "-1", "-i", opts.Music.Path}
+}
+
+// musicArgs builds the rest of a single export's arguments, after the
+// source input, when it has a music bed: the audio goes through a
+// filter_complex with the music while the picture keeps its -vf chain, or
+// is copied when there is none.
+func musicArgs(opts ExportOptions, vf, af []string, output string) []string {
+	args := musicInputArgs(opts)
+	var graph []string
+	orig := ""
+	if opts.AudioCodec != "" && !opts.DropsAudio() {
+		graph = append(graph, "[0:a]"+strings.Join(append([]string{"anull"}, af...), ",")+"[src]")
+		orig = "[src]"
+	}
+	graph = append(graph, musicGraph(opts, orig, 1, "[aout]")...)
+	args = append(args, "-filter_complex", strings.Join(graph, ";"), "-map", "0:v:0", "-map", "[aout]")
+
+	if videoOK, _ := CopyCompatible(output, opts.VideoCodec, ""); len(vf) == 0 && !opts.FrameExact && videoOK {
+		args = append(args, "-c:v", "copy")
+	} else {
+		args = append(args, encoderArgs(opts, output)...)
+		if len(vf) > 0 {
+			args = append(args, "-vf", strings.Join(vf, ","))
+		}
+	}
+	return append(args, outputArgs(opts, output)...)
+}
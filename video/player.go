@@ -2,11 +2,11 @@ package video
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"runtime"
-	"strings"
 	"sync"
 	"time"
 )
@@ -46,6 +46,23 @@ func (t *TrimState) Duration() time.Duration {
 	return *t.OutPoint - *t.InPoint
 }
 
+var (
+	ErrSelectionIncomplete = errors.New("set both in- and out-points first")
+	ErrSelectionTooShort   = errors.New("selection is shorter than one frame")
+)
+
+// Validate checks that the selection is complete and at least min long.
+// Shorter selections make ffmpeg produce empty or broken files.
+func (t *TrimState) Validate(min time.Duration) error {
+	if !t.IsComplete() {
+		return ErrSelectionIncomplete
+	}
+	if t.Duration() < min {
+		return ErrSelectionTooShort
+	}
+	return nil
+}
+
 type Player struct {
 	path       string
 	duration   time.Duration
@@ -61,23 +78,48 @@ type Player struct {
 	currentFrame  string
 	stopChan      chan struct{}
 	stream        *FrameStream
-	frameInterval time.Duration
+	framesLeft    int           // frames to play before auto-pausing; 0 plays on
+	seekGen       int           // bumped by Seek so the playback loop can resync
+	framePosition time.Duration // position currentFrame was decoded for
+	frameShown    time.Duration // and where it really is, see FrameTime
+	updates       chan struct{}
 
 	// Optimization: Frame cache
-	cache *FrameCache
+	cache      *FrameCache
+	warm       *FrameCache        // frames around the trim points, see WarmCache
+	warmCancel context.CancelFunc // stops the running WarmCache
+	first      *firstFrame        // opening frame, see Preload
 
 	// Audio playback
 	audioPlayer *AudioPlayer
 
+	// Lifetime of every external process spawned by the player
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	Trim TrimState
 }
 
-func NewPlayer(path string) (*Player, error) {
-	props, err := GetVideoProperties(path)
+// PlayerPrefs are the viewing settings a player starts with.
+type PlayerPrefs struct {
+	Quality QualityPreset
+	Muted   bool
+}
+
+// DefaultPlayerPrefs is full quality with sound.
+func DefaultPlayerPrefs() PlayerPrefs {
+	return PlayerPrefs{Quality: QualityHigh}
+}
+
+func NewPlayer(ctx context.Context, path string, prefs PlayerPrefs) (*Player, error) {
+	props, err := GetVideoProperties(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	audio := NewAudioPlayer(ctx, path)
+	audio.muted = prefs.Muted
 	return &Player{
 		path:        path,
 		duration:    props.Duration,
@@ -85,10 +127,14 @@ func NewPlayer(path string) (*Player, error) {
 		playing:     false,
 		fps:         int(props.FPS),
 		properties:  props,
-		quality:     QualityHigh,
+		quality:     prefs.Quality,
 		stopChan:    make(chan struct{}),
+		updates:     make(chan struct{}, 1),
 		cache:       NewFrameCache(DefaultCacheCapacity, props.FPS),
-		audioPlayer: NewAudioPlayer(path),
+		warm:        NewFrameCache(warmCapacity, props.FPS),
+		audioPlayer: audio,
+		ctx:         ctx,
+		cancel:      cancel,
 	}, nil
 }
 
@@ -103,6 +149,13 @@ func (p *Player) SetSize(width, height int) {
 	p.mu.Unlock()
 
 	if !playing && width > 0 && height > 0 && (width != oldWidth || height != oldHeight) {
+		p.mu.Lock()
+		first := p.takeFirst(pos)
+		p.mu.Unlock()
+		if first != nil {
+			go p.renderFirst(first, width, height, quality)
+			return
+		}
 		p.renderFrameCached(pos, width, height, quality)
 	}
 }
@@ -115,11 +168,6 @@ func (p *Player) Play() error {
 	}
 	p.playing = true
 	p.stopChan = make(chan struct{})
-	if p.fps > 0 {
-		p.frameInterval = time.Second / time.Duration(p.fps)
-	} else {
-		p.frameInterval = time.Second / 24
-	}
 	pos := p.position
 	p.mu.Unlock()
 
@@ -130,6 +178,45 @@ func (p *Player) Play() error {
 	return nil
 }
 
+// PlayFrames plays n frames at normal speed starting with the frame after
+// the one on screen, then pauses with the last of them displayed.
+func (p *Player) PlayFrames(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	if p.playing {
+		p.mu.Unlock()
+		return nil
+	}
+	interval := p.frameDuration()
+	if p.position+interval > p.duration {
+		p.mu.Unlock()
+		return nil
+	}
+	p.position += interval
+	p.framesLeft = n
+	p.mu.Unlock()
+	return p.Play()
+}
+
+// MinSelection returns the shortest selection that can be exported: one
+// source frame.
+func (p *Player) MinSelection() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.frameDuration()
+}
+
+// frameDuration returns the length of one source frame (must be called with
+// lock held).
+func (p *Player) frameDuration() time.Duration {
+	if p.fps > 0 {
+		return time.Second / time.Duration(p.fps)
+	}
+	return time.Second / 24
+}
+
 func (p *Player) Pause() {
 	p.mu.Lock()
 	if !p.playing {
@@ -137,6 +224,7 @@ func (p *Player) Pause() {
 		return
 	}
 	p.playing = false
+	p.framesLeft = 0
 	close(p.stopChan)
 	stream := p.stream
 	p.stream = nil
@@ -190,6 +278,7 @@ func (p *Player) Seek(position time.Duration) {
 		position = p.duration
 	}
 	p.position = position
+	p.seekGen++
 	width, height := p.width, p.height
 	quality := p.quality
 	playing := p.playing
@@ -257,9 +346,17 @@ func (p *Player) CycleQuality() QualityPreset {
 	return newQuality
 }
 
+// Close stops playback and cancels the player's context, terminating any
+// ffmpeg, chafa or ffplay process still running on its behalf.
 func (p *Player) Close() {
 	p.Pause()
+	p.mu.Lock()
+	if p.warmCancel != nil {
+		p.warmCancel()
+	}
+	p.mu.Unlock()
 	p.audioPlayer.Stop()
+	p.cancel()
 }
 
 func (p *Player) ToggleMute() {
@@ -270,6 +367,9 @@ func (p *Player) IsMuted() bool {
 	return p.audioPlayer.IsMuted()
 }
 
+// playbackLoop decodes and renders frames on a wall clock: the position is
+// the stream start position plus real elapsed time, so slow renders drop
+// frames instead of making video time run slow against audio.
 func (p *Player) playbackLoop() {
 	var currentStream *FrameStream
 	defer func() {
@@ -278,10 +378,20 @@ func (p *Player) playbackLoop() {
 		}
 	}()
 
+	// Clock state, reset whenever a stream (re)starts
+	var (
+		clockStart time.Time
+		clockPos   time.Duration
+		frameIdx   int
+		streamGen  int
+	)
+
 	for {
 		select {
 		case <-p.stopChan:
 			return
+		case <-p.ctx.Done():
+			return
 		default:
 		}
 
@@ -294,8 +404,8 @@ func (p *Player) playbackLoop() {
 		height := p.height
 		quality := p.quality
 		pos := p.position
-		frameInterval := p.frameInterval
-		fps := p.fps
+		gen := p.seekGen
+		stepping := p.framesLeft > 0
 		p.mu.Unlock()
 
 		if width <= 0 || height <= 0 {
@@ -303,24 +413,26 @@ func (p *Player) playbackLoop() {
 			continue
 		}
 
-		if fps <= 0 {
-			fps = 24
-		}
-
 		// Use preview parameters for smooth playback
 		previewFPS := p.properties.PreviewFPS()
+		if previewFPS <= 0 {
+			previewFPS = 24
+		}
+		streamInterval := time.Second / time.Duration(previewFPS)
 		videoWidth := p.properties.Width
+		videoHeight := p.properties.Height
 
-		if currentStream == nil || currentStream.NeedsRestart(width, height, previewFPS, videoWidth) {
+		if currentStream == nil || gen != streamGen || currentStream.NeedsRestart(width, height, previewFPS, videoWidth) {
 			if currentStream != nil {
 				currentStream.Close()
 			}
-			stream, err := NewFrameStream(p.path, pos, width, height, previewFPS, videoWidth)
+			stream, err := NewFrameStream(p.ctx, p.path, pos, width, height, previewFPS, videoWidth, videoHeight)
 			if err != nil {
 				time.Sleep(20 * time.Millisecond)
 				continue
 			}
 			currentStream = stream
+			clockStart, clockPos, frameIdx, streamGen = time.Now(), pos, 0, gen
 			p.mu.Lock()
 			p.stream = stream
 			p.mu.Unlock()
@@ -333,22 +445,69 @@ func (p *Player) playbackLoop() {
 			continue
 		}
 
-		frame, err := p.renderFrameFromBytes(frameBytes, width, height, quality)
+		framePTS := clockPos + time.Duration(frameIdx)*streamInterval
+		frameIdx++
+		wallPos := clockPos + time.Since(clockStart)
+
+		// Behind schedule: skip rendering this frame to catch up. Frame
+		// stepping shows every frame, however late.
+		if !stepping && !demoMode && framePTS+streamInterval < wallPos {
+			currentStream.Release(frameBytes)
+			continue
+		}
+		// Ahead of schedule: wait until the frame is due
+		if wait := framePTS - wallPos; wait > 0 && !p.sleep(wait) {
+			currentStream.Release(frameBytes)
+			return
+		}
+
+		var frame string
+		if demoMode {
+			frame = demoFrame(framePTS, width, height)
+		} else {
+			frame, err = p.renderFrameFromBytes(p.ctx, frameBytes, width, height, quality)
+		}
+		currentStream.Release(frameBytes)
 		if err != nil {
 			continue
 		}
 
-		p.cache.Put(pos, width, height, quality, frame)
+		// A fast-seeking stream starts on the keyframe before clockPos, so
+		// its frames are not where framePTS says
+		if seekStream.mode() != SeekFast {
+			p.cache.Put(framePTS, width, height, quality, frame)
+		}
 		p.mu.Lock()
 		if !p.playing {
 			p.mu.Unlock()
 			return
 		}
-		p.currentFrame = frame
-		p.position += frameInterval
+		if p.seekGen != streamGen {
+			// A seek landed while this frame rendered; it is stale
+			p.mu.Unlock()
+			continue
+		}
+		p.setFrame(frame, framePTS, framePTS)
+		p.notify()
+		if p.framesLeft > 0 {
+			p.framesLeft--
+			if p.framesLeft == 0 {
+				// Stay on the frame just shown rather than the next one
+				p.position = framePTS
+				p.mu.Unlock()
+				p.Pause()
+				return
+			}
+		}
+		p.position = clockPos + time.Since(clockStart)
+		if demoMode {
+			// Where the frames are, not the clock, so a demo plays alike anywhere
+			p.position = framePTS
+		}
 		if p.position >= p.duration {
 			p.position = p.duration
 			p.playing = false
+			p.notify()
 			if currentStream != nil {
 				currentStream.Close()
 				currentStream = nil
@@ -363,91 +522,177 @@ func (p *Player) playbackLoop() {
 	}
 }
 
+// Updates delivers a signal whenever the current frame changes or playback
+// stops on its own. Signals coalesce: one pending signal covers any number
+// of updates, so a slow reader always catches up to the latest frame.
+func (p *Player) Updates() <-chan struct{} {
+	return p.updates
+}
+
+func (p *Player) notify() {
+	select {
+	case p.updates <- struct{}{}:
+	default:
+	}
+}
+
+// sleep waits for d, returning false if playback stopped in the meantime.
+func (p *Player) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-p.stopChan:
+		return false
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
 // renderFrameCached renders a frame using cache
 func (p *Player) renderFrameCached(position time.Duration, width, height int, quality QualityPreset) {
 	// Check cache first
-	if frame, ok := p.cache.Get(position, width, height, quality); ok {
+	frame, shown, ok := p.cache.GetShown(position, width, height, quality)
+	if !ok {
+		frame, shown, ok = p.warm.GetShown(position, width, height, quality)
+	}
+	if ok {
 		p.mu.Lock()
-		p.currentFrame = frame
+		p.setFrame(frame, position, shown)
+		p.notify()
 		p.mu.Unlock()
 		return
 	}
 
 	// Cache miss - render
-	frame, err := p.renderFrame(position, width, height)
+	frame, shown, err := p.renderFrame(p.ctx, position, width, height)
 	if err != nil {
 		return
 	}
-	p.cache.Put(position, width, height, quality, frame)
+	p.cache.PutShown(position, shown, width, height, quality, frame)
 	p.mu.Lock()
-	p.currentFrame = frame
+	p.setFrame(frame, position, shown)
+	p.notify()
 	p.mu.Unlock()
 }
 
-func (p *Player) renderFrame(position time.Duration, width, height int) (string, error) {
+func (p *Player) renderFrame(ctx context.Context, position time.Duration, width, height int) (string, time.Duration, error) {
 	p.mu.Lock()
-	config := ChafaPresets[p.quality]
+	quality := p.quality
 	p.mu.Unlock()
 
-	// Build filter chain with preview parameters
-	previewFPS := p.properties.PreviewFPS()
+	return terminalRenderer{}.renderStillAt(ctx, seekStill, p.path, position, p.previewFilters(), width, height, quality)
+}
+
+// setFrame shows frame, decoded for position and really at shown (must be
+// called with lock held).
+func (p *Player) setFrame(frame string, position, shown time.Duration) {
+	p.currentFrame = frame
+	p.framePosition, p.frameShown = position, shown
+}
+
+// FrameTime returns where the paused frame on screen really is in the
+// source: a still decoded for the playhead can land up to a frame after
+// it, or on the keyframe before with fast seeking. ok is false while
+// playing and until the frame for the playhead has arrived.
+func (p *Player) FrameTime() (shown time.Duration, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.playing || p.currentFrame == "" || p.framePosition != p.position {
+		return 0, false
+	}
+	return p.frameShown, true
+}
+
+// previewFilters is the filter chain of paused preview frames.
+func (p *Player) previewFilters() []string {
 	var filters []string
 	if p.properties.NeedsScaling() {
 		filters = append(filters, "scale=1920:-1:flags=fast_bilinear")
 	}
-	filters = append(filters, fmt.Sprintf("fps=%d", previewFPS))
-
-	ffmpegCmd := exec.Command("ffmpeg",
-		"-ss", fmt.Sprintf("%.3f", position.Seconds()),
-		"-i", p.path,
-		"-vf", strings.Join(filters, ","),
-		"-vframes", "1",
-		"-f", "image2pipe",
-		"-vcodec", "bmp",
-		"-loglevel", "error",
-		"-",
-	)
+	return append(filters, fmt.Sprintf("fps=%d", p.properties.PreviewFPS()))
+}
+
+// RenderExportPreview renders the frame under the playhead as opts would
+// export it (crop, blurred fill), sized to width x height cells.
+func (p *Player) RenderExportPreview(ctx context.Context, opts ExportOptions, width, height int) (string, error) {
+	p.mu.Lock()
+	quality := p.quality
+	position := p.position
+	p.mu.Unlock()
+
+	filters := composeFilters(opts)
+	if len(filters) == 0 {
+		filters = []string{"null"}
+	}
+	return terminalRenderer{text: true}.renderStill(ctx, seekStill, p.path, position, filters, width, height, quality)
+}
+
+// renderStill decodes one frame at position through filters and converts
+// it with chafa.
+func (r terminalRenderer) renderStill(ctx context.Context, op seekOp, path string, position time.Duration, filters []string, width, height int, quality QualityPreset) (string, error) {
+	frame, _, err := r.renderStillAt(ctx, op, path, position, filters, width, height, quality)
+	return frame, err
+}
 
-	chafaArgs := config.BuildArgs(width, height)
-	chafaCmd := exec.Command("chafa", chafaArgs...)
+// renderStillAt is renderStill that also returns where the frame really
+// is, see shownPosition. Kitty graphics need the decoded pixels, so for
+// those the frame is read first and drawn like a playback frame.
+func (r terminalRenderer) renderStillAt(ctx context.Context, op seekOp, path string, position time.Duration, filters []string, width, height int, quality QualityPreset) (string, time.Duration, error) {
+	if demoMode {
+		return demoFrame(position, width, height), position, nil
+	}
+	if r.mode() == GraphicsKitty {
+		frame, shown, err := decodeStill(ctx, op, path, position, filters)
+		if err != nil {
+			return "", 0, err
+		}
+		rendered, err := r.RenderFrame(ctx, frame, width, height, quality)
+		return rendered, shown, err
+	}
+
+	ffmpegCmd := command(ctx, "ffmpeg", stillArgs(op, path, position, filters)...)
+	var log bytes.Buffer
+	ffmpegCmd.Stderr = &log
+
+	chafaCmd := command(ctx, "chafa", r.chafaArgs(width, height, quality)...)
 
 	pipe, err := ffmpegCmd.StdoutPipe()
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	chafaCmd.Stdin = pipe
 
-	var chafaOut bytes.Buffer
-	chafaCmd.Stdout = &chafaOut
+	chafaOut := getOutputBuf()
+	defer putOutputBuf(chafaOut)
+	chafaCmd.Stdout = chafaOut
 
 	if err := chafaCmd.Start(); err != nil {
-		return "", err
+		return "", 0, err
 	}
 	if err := ffmpegCmd.Run(); err != nil {
-		return "", err
+		return "", 0, err
 	}
 	if err := chafaCmd.Wait(); err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	return chafaOut.String(), nil
+	return chafaOut.String(), shownPosition(op, position, frameTimes(log.Bytes())), nil
 }
 
-func (p *Player) renderFrameFromBytes(frame []byte, width, height int, quality QualityPreset) (string, error) {
-	config := ChafaPresets[quality]
-	chafaArgs := config.BuildArgs(width, height)
-	chafaCmd := exec.Command("chafa", chafaArgs...)
-
-	chafaCmd.Stdin = bytes.NewReader(frame)
-
-	var chafaOut bytes.Buffer
-	chafaCmd.Stdout = &chafaOut
+func (p *Player) renderFrameFromBytes(ctx context.Context, frame []byte, width, height int, quality QualityPreset) (string, error) {
+	return currentFrameRenderer().RenderFrame(ctx, frame, width, height, quality)
+}
 
-	if err := chafaCmd.Run(); err != nil {
-		return "", err
+// renderTextFrame is renderFrameFromBytes for frames laid out as text, see
+// terminalRenderer.
+func (p *Player) renderTextFrame(ctx context.Context, frame []byte, width, height int, quality QualityPreset) (string, error) {
+	r := currentFrameRenderer()
+	if _, ok := r.(terminalRenderer); ok {
+		r = terminalRenderer{text: true}
 	}
-
-	return chafaOut.String(), nil
+	return r.RenderFrame(ctx, frame, width, height, quality)
 }
 
 func getInstallCommand(packageName string) string {
@@ -481,16 +726,16 @@ func getInstallCommand(packageName string) string {
 }
 
 func CheckDependencies() error {
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
+	if _, err := lookPath("ffmpeg"); err != nil {
 		return fmt.Errorf("ffmpeg not found. Install: %s", getInstallCommand("ffmpeg"))
 	}
-	if _, err := exec.LookPath("ffprobe"); err != nil {
+	if _, err := lookPath("ffprobe"); err != nil {
 		return fmt.Errorf("ffprobe not found. Install: %s", getInstallCommand("ffmpeg"))
 	}
-	if _, err := exec.LookPath("ffplay"); err != nil {
+	if _, err := lookPath("ffplay"); err != nil {
 		return fmt.Errorf("ffplay not found. Install: %s", getInstallCommand("ffmpeg"))
 	}
-	if _, err := exec.LookPath("chafa"); err != nil {
+	if _, err := lookPath("chafa"); err != nil {
 		return fmt.Errorf("chafa not found. Install: %s", getInstallCommand("chafa"))
 	}
 	return nil
@@ -0,0 +1,27 @@
+package video_test
+
+import (
+	"strings"
+	"testing"
+
+	"lazycut/video"
+)
+
+func TestCheckDependencies(t *testing.T) {
+	fake := fakeRunner(t)
+	if err := video.CheckDependencies(); err != nil {
+		t.Fatalf("all installed: %v", err)
+	}
+	for _, name := range []string{"ffmpeg", "ffprobe", "ffplay", "chafa"} {
+		t.Run(name, func(t *testing.T) {
+			fake.Missing = map[string]bool{name: true}
+			err := video.CheckDependencies()
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.HasPrefix(err.Error(), name+" not found") {
+				t.Errorf("error %q does not name %s", err, name)
+			}
+		})
+	}
+}
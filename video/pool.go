@@ -0,0 +1,51 @@
+package video
+
+import (
+	"bytes"
+	"sync"
+)
+
+// framePool recycles decoded frame buffers for self-describing formats
+// (BMP/PPM), whose size is only known per frame. Pointers to slices are
+// pooled to avoid an allocation on every Put.
+var framePool = sync.Pool{
+	New: func() any { return new([]byte) },
+}
+
+// getFrameBuf returns a buffer of length n, reusing pooled capacity.
+func getFrameBuf(n int) []byte {
+	bp := framePool.Get().(*[]byte)
+	if cap(*bp) < n {
+		*bp = make([]byte, n)
+	}
+	return (*bp)[:n]
+}
+
+// putFrameBuf returns a buffer obtained from getFrameBuf to the pool.
+func putFrameBuf(buf []byte) {
+	if cap(buf) == 0 || cap(buf) > maxFrameBytes {
+		return
+	}
+	framePool.Put(&buf)
+}
+
+// outputPool recycles the buffers chafa writes rendered frames into. A
+// truecolor frame is a few hundred KB of escape codes; reusing the buffer
+// saves the repeated grow-and-copy of a fresh bytes.Buffer each frame.
+var outputPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getOutputBuf() *bytes.Buffer {
+	buf := outputPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putOutputBuf(buf *bytes.Buffer) {
+	// Don't let one huge frame pin memory for the rest of the session
+	if buf.Cap() > 4<<20 {
+		return
+	}
+	outputPool.Put(buf)
+}
@@ -0,0 +1,90 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// Frame sizes of a 640x360 preview, as BMP and as chafa's truecolor output
+const (
+	benchFrameBytes  = bmpHeaderSize + 640*360*3
+	benchOutputBytes = 300 << 10
+)
+
+var (
+	sinkBytes []byte
+	sinkLen   int
+)
+
+// BenchmarkFrameBuf compares a pooled frame buffer per frame with a fresh
+// allocation per frame.
+func BenchmarkFrameBuf(b *testing.B) {
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := getFrameBuf(benchFrameBytes)
+			buf[0] = 'B'
+			putFrameBuf(buf)
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := make([]byte, benchFrameBytes)
+			buf[0] = 'B'
+			sinkBytes = buf
+		}
+	})
+}
+
+// BenchmarkOutputBuf compares writing a rendered frame into a pooled
+// buffer with growing a new bytes.Buffer for it.
+func BenchmarkOutputBuf(b *testing.B) {
+	chunk := []byte(strings.Repeat("\x1b[38;2;120;80;40m▀", 64))
+	write := func(buf *bytes.Buffer) {
+		for buf.Len() < benchOutputBytes {
+			buf.Write(chunk)
+		}
+		sinkLen = buf.Len()
+	}
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := getOutputBuf()
+			write(buf)
+			putOutputBuf(buf)
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			write(new(bytes.Buffer))
+		}
+	})
+}
+
+// BenchmarkReadBMP reads frames off a BMP stream the way FrameStream
+// does, handing each buffer back once it is rendered.
+func BenchmarkReadBMP(b *testing.B) {
+	frame := make([]byte, benchFrameBytes)
+	frame[0], frame[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(frame[2:6], benchFrameBytes)
+	binary.LittleEndian.PutUint32(frame[10:14], bmpHeaderSize)
+	binary.LittleEndian.PutUint32(frame[14:18], 40)
+	stream := bytes.Repeat(frame, 8)
+
+	b.ReportAllocs()
+	b.SetBytes(benchFrameBytes)
+	r := bufio.NewReaderSize(bytes.NewReader(stream), 1<<20)
+	for b.Loop() {
+		got, _, err := readFrame(r, FrameBMP)
+		if err != nil {
+			r.Reset(bytes.NewReader(stream))
+			continue
+		}
+		putFrameBuf(got)
+	}
+}
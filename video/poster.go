@@ -0,0 +1,87 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PosterPath is the sidecar thumbnail for the export written to output:
+// the same name with a .jpg extension.
+func PosterPath(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".jpg"
+}
+
+// EmbedsPoster reports whether the poster frame goes into output as cover
+// art (attached_pic). MP4 and MOV hold one; other containers, and
+// fragmented MP4, get the .jpg beside them instead.
+func (opts ExportOptions) EmbedsPoster(output string) bool {
+	return !opts.PosterSidecar && opts.Container != ContainerFragmentedMP4 && SupportsFastStart(output)
+}
+
+// writePoster saves the poster frame of opts for output: always as the
+// .jpg sidecar first, then embedded and the sidecar removed where the
+// container allows it.
+func writePoster(ctx context.Context, opts ExportOptions, output string) error {
+	jpg := PosterPath(output)
+	if err := extractPoster(ctx, opts, *opts.Poster, jpg); err != nil {
+		return err
+	}
+	if !opts.EmbedsPoster(output) {
+		return nil
+	}
+	if err := embedPoster(ctx, opts, output, jpg); err != nil {
+		return err
+	}
+	return os.Remove(jpg)
+}
+
+// extractPoster writes the source frame at position as a JPEG, framed
+// like the export (crop or blurred fill).
+func extractPoster(ctx context.Context, opts ExportOptions, position time.Duration, jpg string) error {
+	args := append([]string{"-y", "-loglevel", "error"}, seekInput(seekFrame, position, opts.Input)...)
+	if vf := composeFilters(opts); len(vf) > 0 {
+		args = append(args, "-vf", strings.Join(vf, ","))
+	}
+	args = append(args, "-frames:v", "1", "-q:v", "2", jpg)
+	return runFFmpeg(ctx, args)
+}
+
+// embedPoster remuxes output with jpg attached as its cover art.
+func embedPoster(ctx context.Context, opts ExportOptions, output, jpg string) error {
+	ext := filepath.Ext(output)
+	tmp := strings.TrimSuffix(output, ext) + ".poster" + ext
+	args := []string{"-y", "-loglevel", "error",
+		"-i", output,
+		"-i", jpg,
+		"-map", "0", "-map", "1",
+		"-c", "copy",
+		"-disposition:v:1", "attached_pic",
+	}
+	args = append(args, muxerArgs(opts, output)...)
+	if err := runFFmpeg(ctx, append(args, tmp)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, output)
+}
+
+// runFFmpeg runs a short ffmpeg job, with the first line of its stderr
+// in the error.
+func runFFmpeg(ctx context.Context, args []string) error {
+	var stderr bytes.Buffer
+	cmd := command(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		detail, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+		return fmt.Errorf("%w: %s", err, detail)
+	}
+	return nil
+}
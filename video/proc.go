@@ -0,0 +1,213 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// helperNames are the binaries lazycut spawns; only these are ever reaped.
+var helperNames = map[string]bool{
+	"ffmpeg":  true,
+	"ffprobe": true,
+	"ffplay":  true,
+	"chafa":   true,
+	// whisper.cpp's current and legacy names, and openai-whisper's
+	"whisper-cli": true,
+	"whisper-cpp": true,
+	"whisper":     true,
+}
+
+// command builds an exec.Cmd that runs in its own process group. Cancelling
+// ctx kills the whole group, so grandchildren spawned by the helper go too.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := currentRunner().Command(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd.Process)
+	}
+	return cmd
+}
+
+// registry records long-lived helper PIDs in a per-run file so that a later
+// run can clean them up if this one dies without running its shutdown path.
+// Each PID is recorded with when it started, so that a reused PID is not
+// mistaken for the helper.
+type registry struct {
+	mu      sync.Mutex
+	pids    map[int]trackedProc
+	flush   *time.Timer // pending persist, nil when the file is current
+	written string      // what the file holds
+}
+
+type trackedProc struct {
+	proc    *os.Process
+	started time.Time
+}
+
+var helpers = &registry{pids: make(map[int]trackedProc)}
+
+// persistDelay batches registry writes: seeking restarts the stream helper
+// many times a second, and a helper that lives shorter than this is not
+// worth reaping.
+const persistDelay = time.Second
+
+// startSlack is how far apart the recorded and the actual start time of a
+// helper may be; ps reports whole seconds.
+const startSlack = 2 * time.Second
+
+func registryDir() string {
+	return filepath.Join(os.TempDir(), "lazycut")
+}
+
+func registryFile(ownerPID int) string {
+	return filepath.Join(registryDir(), strconv.Itoa(ownerPID)+".pids")
+}
+
+// startTracked starts cmd and records it in the helper registry.
+func startTracked(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	helpers.mu.Lock()
+	helpers.pids[cmd.Process.Pid] = trackedProc{proc: cmd.Process, started: time.Now()}
+	helpers.scheduleLocked()
+	helpers.mu.Unlock()
+	return nil
+}
+
+// waitTracked waits for cmd and removes it from the helper registry.
+func waitTracked(cmd *exec.Cmd) error {
+	err := cmd.Wait()
+	if cmd.Process != nil {
+		helpers.mu.Lock()
+		delete(helpers.pids, cmd.Process.Pid)
+		helpers.scheduleLocked()
+		helpers.mu.Unlock()
+	}
+	return err
+}
+
+// scheduleLocked persists the registry after persistDelay, together with
+// whatever else changes by then (must be called with lock held).
+func (r *registry) scheduleLocked() {
+	if r.flush != nil {
+		return
+	}
+	r.flush = time.AfterFunc(persistDelay, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.flush = nil
+		r.persistLocked()
+	})
+}
+
+// persistLocked rewrites the registry file as "pid start" lines, start in
+// Unix seconds, when it has changed (must be called with lock held).
+// Failures are ignored: the file only serves the best-effort orphan reaper.
+func (r *registry) persistLocked() {
+	if r.flush != nil {
+		r.flush.Stop()
+		r.flush = nil
+	}
+	var b strings.Builder
+	for pid, p := range r.pids {
+		fmt.Fprintln(&b, pid, p.started.Unix())
+	}
+	if b.String() == r.written {
+		return
+	}
+	path := registryFile(os.Getpid())
+	if len(r.pids) == 0 {
+		if err := os.Remove(path); err == nil || os.IsNotExist(err) {
+			r.written = ""
+		}
+		return
+	}
+	if err := os.MkdirAll(registryDir(), 0o700); err != nil {
+		return
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err == nil {
+		r.written = b.String()
+	}
+}
+
+// KillHelpers kills the process groups of all tracked helpers. It is safe to
+// call from signal handlers and deferred shutdown paths.
+func KillHelpers() {
+	helpers.mu.Lock()
+	defer helpers.mu.Unlock()
+	for pid, p := range helpers.pids {
+		_ = killProcessGroup(p.proc)
+		delete(helpers.pids, pid)
+	}
+	helpers.persistLocked()
+}
+
+// ReapOrphans kills helpers left behind by previous lazycut runs that were
+// terminated abruptly (SIGKILL, power loss). A helper is only killed when
+// its owner is gone and the PID still belongs to a known helper binary
+// that started when the helper did; anything else has reused the PID.
+func ReapOrphans() {
+	entries, err := os.ReadDir(registryDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		owner, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".pids"))
+		if err != nil || owner == os.Getpid() || processAlive(owner) {
+			continue
+		}
+		path := filepath.Join(registryDir(), entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if pid, started, ok := parseRegistryLine(line); ok && isOrphan(pid, started) {
+				_ = killGroup(pid)
+			}
+		}
+		_ = os.Remove(path)
+	}
+}
+
+// parseRegistryLine reads a "pid start" line of a registry file. Lines
+// without a start time, from older versions, are skipped: there is no
+// telling their PIDs were not reused.
+func parseRegistryLine(line string) (int, time.Time, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return 0, time.Time{}, false
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return pid, time.Unix(secs, 0), true
+}
+
+// isOrphan reports whether pid is still the helper recorded as started at
+// started: alive, a helper binary, leading its own process group, and
+// started within startSlack of it.
+func isOrphan(pid int, started time.Time) bool {
+	if !processAlive(pid) || !helperNames[processName(pid)] || !groupLeader(pid) {
+		return false
+	}
+	actual, ok := processStart(pid)
+	if !ok {
+		return false
+	}
+	d := actual.Sub(started)
+	return d > -startSlack && d < startSlack
+}
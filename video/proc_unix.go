@@ -0,0 +1,78 @@
+//go:build !windows
+
+package video
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func killProcessGroup(proc *os.Process) error {
+	if proc == nil {
+		return nil
+	}
+	if err := killGroup(proc.Pid); err != nil {
+		return proc.Kill()
+	}
+	return nil
+}
+
+// killGroup kills the process group led by pgid, and nothing else.
+func killGroup(pgid int) error {
+	// Negative PID targets the group whose leader is pgid
+	return syscall.Kill(-pgid, syscall.SIGKILL)
+}
+
+// groupLeader reports whether pid leads its own process group, as every
+// helper does (see setProcessGroup).
+func groupLeader(pid int) bool {
+	pgid, err := syscall.Getpgid(pid)
+	return err == nil && pgid == pid
+}
+
+// processStart is when pid started, to the second, as ps reports it.
+func processStart(pid int) (time.Time, bool) {
+	cmd := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid))
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	out, err := cmd.Output()
+	if err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation("Mon Jan _2 15:04:05 2006", strings.Join(strings.Fields(string(out)), " "), time.Local)
+	return t, err == nil
+}
+
+func processAlive(pid int) bool {
+	return syscall.Kill(pid, 0) == nil
+}
+
+func processName(pid int) string {
+	out, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSpace(string(out)))
+}
+
+// lowNice is the nice value of background-priority helpers, as for nice(1).
+const lowNice = 10
+
+// lowerPriority renices proc's whole process group and drops its disk
+// priority where the kernel supports it. The group covers every thread
+// ffmpeg has started so far; later ones inherit the lower priority.
+func lowerPriority(proc *os.Process) error {
+	err := syscall.Setpriority(syscall.PRIO_PGRP, proc.Pid, lowNice)
+	if ioErr := lowerIOPriority(proc.Pid); err == nil {
+		err = ioErr
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+//go:build windows
+
+package video
+
+import (
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+func killProcessGroup(proc *os.Process) error {
+	if proc == nil {
+		return nil
+	}
+	if err := killGroup(proc.Pid); err != nil {
+		return proc.Kill()
+	}
+	return nil
+}
+
+// killGroup kills pid and the whole child tree under it, and nothing else.
+func killGroup(pid int) error {
+	// taskkill /T takes the whole child tree down with the helper
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).Run()
+}
+
+// groupLeader is always true: Windows has no process groups to check, the
+// start time alone tells a reused PID apart.
+func groupLeader(pid int) bool {
+	return true
+}
+
+const processQueryLimitedInformation = 0x1000
+
+// processStart is when pid was created.
+func processStart(pid int) (time.Time, bool) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer syscall.CloseHandle(h)
+	var created, exited, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(h, &created, &exited, &kernel, &user); err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, created.Nanoseconds()), true
+}
+
+func processAlive(pid int) bool {
+	return processName(pid) != ""
+}
+
+func processName(pid int) string {
+	out, err := exec.Command("tasklist", "/FI", "PID eq "+strconv.Itoa(pid), "/FO", "CSV", "/NH").Output()
+	if err != nil {
+		return ""
+	}
+	record, err := csv.NewReader(strings.NewReader(string(out))).Read()
+	if err != nil || len(record) < 2 || record[1] != strconv.Itoa(pid) {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(record[0]), ".exe")
+}
+
+const (
+	processSetInformation    = 0x0200
+	belowNormalPriorityClass = 0x4000
+)
+
+var procSetPriorityClass = syscall.NewLazyDLL("kernel32.dll").NewProc("SetPriorityClass")
+
+// lowerPriority moves proc to the below-normal priority class. Windows
+// only lets a process lower its own I/O priority, so disk access is left
+// as it is.
+func lowerPriority(proc *os.Process) error {
+	h, err := syscall.OpenProcess(processSetInformation, false, uint32(proc.Pid))
+	if err != nil {
+		return err
+	}
+	defer syscall.CloseHandle(h)
+	if ok, _, err := procSetPriorityClass.Call(uintptr(h), belowNormalPriorityClass); ok == 0 {
+		return err
+	}
+	return nil
+}
@@ -1,23 +1,25 @@
 package video
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type VideoProperties struct {
-	Width    int
-	Height   int
-	Codec    string
-	FPS      float64
-	Bitrate  int64
-	FileSize int64
-	Duration time.Duration
+	Width  int
+	Height int
+	Codec  string
+	// AudioCodec is the first audio stream's codec, "" without audio.
+	AudioCodec string
+	FPS        float64
+	Bitrate    int64
+	FileSize   int64
+	Duration   time.Duration
 }
 
 type ffprobeOutput struct {
@@ -25,6 +27,7 @@ type ffprobeOutput struct {
 		Width      int    `json:"width"`
 		Height     int    `json:"height"`
 		CodecName  string `json:"codec_name"`
+		CodecType  string `json:"codec_type"`
 		RFrameRate string `json:"r_frame_rate"`
 	} `json:"streams"`
 	Format struct {
@@ -34,11 +37,11 @@ type ffprobeOutput struct {
 	} `json:"format"`
 }
 
-func GetVideoProperties(path string) (*VideoProperties, error) {
-	cmd := exec.Command("ffprobe",
+func GetVideoProperties(ctx context.Context, path string) (*VideoProperties, error) {
+	cmd := command(ctx, "ffprobe",
 		"-v", "error",
 		"-show_entries", "format=duration,size,bit_rate",
-		"-show_entries", "stream=width,height,codec_name,r_frame_rate",
+		"-show_entries", "stream=width,height,codec_name,codec_type,r_frame_rate",
 		"-of", "json",
 		path,
 	)
@@ -55,6 +58,11 @@ func GetVideoProperties(path string) (*VideoProperties, error) {
 
 	props := &VideoProperties{}
 
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "audio" && props.AudioCodec == "" {
+			props.AudioCodec = stream.CodecName
+		}
+	}
 	for _, stream := range probe.Streams {
 		if stream.Width > 0 && stream.Height > 0 {
 			props.Width = stream.Width
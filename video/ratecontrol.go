@@ -0,0 +1,107 @@
+package video
+
+import (
+	"cmp"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// videoEncoder is the encoder re-encoded video of output goes through:
+// opts.Encoder where the container takes it, otherwise what ffmpeg picks
+// for the container, libvpx-vp9 for WebM and libx264 (or mpeg4 without
+// it) for the rest, or the image encoder of an animation.
+func videoEncoder(opts ExportOptions, output string) string {
+	if len(codecArgs(opts, output)) > 0 {
+		return opts.Encoder
+	}
+	switch {
+	case strings.EqualFold(filepath.Ext(output), ".apng"):
+		return "apng"
+	case strings.EqualFold(filepath.Ext(output), ".webp"):
+		return cmp.Or(webpEncoder(FFmpegCapabilities()), "libwebp")
+	case strings.EqualFold(filepath.Ext(output), ".webm"):
+		return "libvpx-vp9"
+	case FFmpegCapabilities().HasEncoder("libx264"):
+		return "libx264"
+	}
+	return "mpeg4"
+}
+
+// crfArgs sets constant quality crf on encoder, nil for encoders that
+// have no such mode.
+func crfArgs(encoder string, crf int) []string {
+	q := strconv.Itoa(crf)
+	switch encoder {
+	case "libx264", "libx265", "libvpx", "libvpx-vp9", "libaom-av1", "libsvtav1":
+		return []string{"-crf", q}
+	case "h264_nvenc", "hevc_nvenc":
+		return []string{"-cq", q}
+	case "h264_qsv", "hevc_qsv":
+		return []string{"-global_quality", q}
+	case "h264_vaapi", "hevc_vaapi":
+		return []string{"-qp", q}
+	case "h264_amf", "hevc_amf":
+		return []string{"-rc", "cqp", "-qp_i", q, "-qp_p", q}
+	}
+	return nil
+}
+
+// rateArgs sets the quality of re-encoded video: opts.CRF alone encodes at
+// constant quality, opts.Bitrate alone at that average bitrate, and both
+// at constant quality capped at the bitrate.
+func rateArgs(opts ExportOptions, output string) []string {
+	encoder := videoEncoder(opts, output)
+	rate := strconv.Itoa(opts.Bitrate) + "k"
+	var args []string
+	if opts.CRF > 0 {
+		args = crfArgs(encoder, opts.CRF)
+	}
+	switch {
+	case len(args) == 0 && opts.Bitrate > 0:
+		return []string{"-b:v", rate}
+	case len(args) == 0:
+		return nil
+	case strings.HasPrefix(encoder, "libvpx") && opts.Bitrate > 0:
+		// libvpx caps constant quality with -b:v; -b:v 0 lifts the cap
+		return append(args, "-b:v", rate)
+	case strings.HasPrefix(encoder, "libvpx"):
+		return append(args, "-b:v", "0")
+	case opts.Bitrate > 0:
+		return append(args, "-maxrate", rate, "-bufsize", strconv.Itoa(2*opts.Bitrate)+"k")
+	}
+	return args
+}
+
+// RateProblem says why opts.CRF does not apply to output, whose encoder
+// has no constant quality mode or which is an animation (with Bitrate
+// too, then), or "" when it does.
+func (opts ExportOptions) RateProblem(output string) string {
+	if opts.Animated() && (opts.CRF > 0 || opts.Bitrate > 0) {
+		return "animations take their own quality"
+	}
+	if opts.CRF <= 0 {
+		return ""
+	}
+	if encoder := videoEncoder(opts, output); crfArgs(encoder, opts.CRF) == nil {
+		if opts.Bitrate > 0 {
+			return encoder + " has no CRF, encodes at the bitrate"
+		}
+		return encoder + " has no CRF, set a bitrate"
+	}
+	return ""
+}
+
+// ReencodesVideo reports whether exporting to output re-encodes the
+// picture, which CRF and Bitrate apply to, rather than copying it.
+func (opts ExportOptions) ReencodesVideo(output string) bool {
+	if len(opts.Parts) > 0 || opts.hasBumpers() || opts.FrameExact || opts.Animated() {
+		return true
+	}
+	vf, af := exportFilters(opts)
+	if len(vf) > 0 || len(af) > 0 && !opts.hasMusic() {
+		return true
+	}
+	videoOK, _ := CopyCompatible(output, opts.VideoCodec, "")
+	return !videoOK
+}
@@ -0,0 +1,160 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReelPart is one range of a highlight reel, played at its own speed.
+type ReelPart struct {
+	In    time.Duration
+	Out   time.Duration
+	Speed float64 // 0 means 1
+}
+
+func (p ReelPart) speed() float64 {
+	if p.Speed <= 0 {
+		return 1
+	}
+	return p.Speed
+}
+
+func (p ReelPart) length() time.Duration {
+	return time.Duration(float64(p.Out-p.In) / p.speed())
+}
+
+// reelDuration is the length of the joined parts after their speed changes
+// and crossfades.
+func (opts ExportOptions) reelDuration() time.Duration {
+	var total time.Duration
+	for _, p := range opts.Parts {
+		total += p.length()
+	}
+	return total - time.Duration(len(opts.Parts)-1)*opts.reelFade()
+}
+
+// reelFade is the crossfade between reel parts, shortened to half the
+// shortest part so every part still shows on its own for a moment.
+func (opts ExportOptions) reelFade() time.Duration {
+	fade := opts.Crossfade
+	for _, p := range opts.Parts {
+		fade = min(fade, p.length()/2)
+	}
+	return max(0, fade)
+}
+
+// reelArgs builds the ffmpeg arguments of a highlight reel: every part is
+// a separately seeked input, retimed on its own (slow parts interpolated,
+// their audio stretched without changing pitch, timelapse parts muted so
+// the sound track stays continuous), then the parts are concatenated, or
+// crossfaded into each other with xfade and acrossfade, and the result
+// framed and padded like a single export. A reel always re-encodes.
+func reelArgs(opts ExportOptions, input, output string, withProgress bool) []string {
+	args := []string{"-y"}
+	for _, p := range opts.Parts {
+		args = append(args, seekInput(seekRange, p.In, input,
+			"-t", fmt.Sprintf("%.3f", (p.Out-p.In).Seconds()))...)
+	}
+	bumperInput := len(opts.Parts)
+	if opts.hasMusic() {
+		args = append(args, musicInputArgs(opts)...)
+		bumperInput++
+	}
+	args = append(args, bumperInputArgs(opts, input)...)
+	if withProgress {
+		args = append(args, "-progress", "pipe:2")
+	}
+
+	audio := opts.AudioCodec != ""
+	fade := opts.reelFade()
+	var graph, concat []string
+	for i, p := range opts.Parts {
+		part := opts
+		part.Speed = p.Speed
+		v := append([]string{"setpts=PTS-STARTPTS"}, speedVideoFilters(part)...)
+		if fade > 0 && opts.FPS > 0 {
+			// xfade needs both sides at the same rate and timebase
+			v = append(v, "fps="+formatFactor(opts.FPS), "settb=AVTB")
+		}
+		graph = append(graph, fmt.Sprintf("[%d:v]%s[v%d]", i, strings.Join(v, ","), i))
+		concat = append(concat, fmt.Sprintf("[v%d]", i))
+		if !audio {
+			continue
+		}
+		a := append([]string{"asetpts=PTS-STARTPTS"}, speedAudioFilters(p.speed())...)
+		if part.DropsAudio() {
+			a = append(a, "volume=0")
+		}
+		graph = append(graph, fmt.Sprintf("[%d:a]%s[a%d]", i, strings.Join(a, ","), i))
+		concat[i] += fmt.Sprintf("[a%d]", i)
+	}
+	if fade > 0 {
+		graph = append(graph, crossfadeGraph(opts.Parts, fade, audio)...)
+	} else {
+		n := 0
+		if audio {
+			n = 1
+		}
+		graph = append(graph, fmt.Sprintf("%sconcat=n=%d:v=1:a=%d[cv]", strings.Join(concat, ""), len(opts.Parts), n))
+		if audio {
+			graph[len(graph)-1] += "[ca]"
+		}
+	}
+
+	// With an intro, outro or title card the reel is joined to them before
+	// the output
+	vout, aout := "[vout]", "[aout]"
+	if opts.hasBumpers() {
+		vout, aout = "[main]", "[mainaudio]"
+	}
+	freezeV, freezeA := freezeFilters(opts)
+	vf := append(composeFilters(opts), freezeV...)
+	graph = append(graph, "[cv]"+strings.Join(append([]string{"null"}, vf...), ",")+vout)
+	switch {
+	case audio && opts.hasMusic():
+		graph = append(graph, "[ca]"+strings.Join(append([]string{"anull"}, freezeA...), ",")+"[mix]")
+		graph = append(graph, musicGraph(opts, "[mix]", len(opts.Parts), aout)...)
+	case audio:
+		graph = append(graph, "[ca]"+strings.Join(append([]string{"anull"}, freezeA...), ",")+aout)
+	case opts.hasMusic():
+		graph = append(graph, musicGraph(opts, "", len(opts.Parts), aout)...)
+	}
+	hasAudio := audio || opts.hasMusic()
+	if opts.hasBumpers() {
+		if !hasAudio {
+			aout = ""
+		}
+		graph = append(graph, bumperGraph(opts, vout, aout, bumperInput)...)
+	}
+	args = append(args, "-filter_complex", strings.Join(graph, ";"), "-map", "[vout]")
+	if hasAudio {
+		args = append(args, "-map", "[aout]")
+	}
+
+	args = append(args, encoderArgs(opts, output)...)
+	return append(args, outputArgs(opts, output)...)
+}
+
+// crossfadeGraph fades each part [vN][aN] into the next, ending in [cv]
+// and [ca]. Each xfade starts fade before the end of everything joined so
+// far, so the reel is (parts-1)*fade shorter than the parts end to end.
+func crossfadeGraph(parts []ReelPart, fade time.Duration, audio bool) []string {
+	var graph []string
+	v, a := "[v0]", "[a0]"
+	var offset time.Duration
+	for i := 1; i < len(parts); i++ {
+		offset += parts[i-1].length() - fade
+		nextV, nextA := fmt.Sprintf("[xv%d]", i), fmt.Sprintf("[xa%d]", i)
+		if i == len(parts)-1 {
+			nextV, nextA = "[cv]", "[ca]"
+		}
+		graph = append(graph, fmt.Sprintf("%s[v%d]xfade=transition=fade:duration=%.3f:offset=%.3f%s",
+			v, i, fade.Seconds(), offset.Seconds(), nextV))
+		if audio {
+			graph = append(graph, fmt.Sprintf("%s[a%d]acrossfade=d=%.3f%s", a, i, fade.Seconds(), nextA))
+		}
+		v, a = nextV, nextA
+	}
+	return graph
+}
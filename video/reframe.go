@@ -0,0 +1,81 @@
+package video
+
+import (
+	"context"
+	"fmt"
+)
+
+// Reframe analysis samples the selection at a low rate on a coarse grid;
+// the subject only needs to be located to within a few percent.
+const (
+	reframeFPS   = 4
+	reframeGridW = 64
+	reframeGridH = 36
+)
+
+// SuggestCropOffset estimates where the action is in opts' selection and
+// returns the CropOffset that centers the crop window on it. It uses a
+// motion-center heuristic: the mean position of pixel changes between
+// consecutive samples, weighted by their size. Static shots return 0.
+func SuggestCropOffset(ctx context.Context, opts ExportOptions) (float64, error) {
+	cropW, cropH := cropSize(opts.Width, opts.Height, opts.AspectRatio)
+	if cropW == 0 || cropH == 0 {
+		return 0, fmt.Errorf("aspect ratio does not crop")
+	}
+	horizontal := cropW < opts.Width
+	if !horizontal && cropH >= opts.Height {
+		return 0, nil // crop covers the whole frame; nothing to slide
+	}
+
+	thumbs, err := grayThumbs(ctx, opts.Input, opts.InPoint, opts.OutPoint-opts.InPoint,
+		reframeFPS, reframeGridW, reframeGridH)
+	if err != nil {
+		return 0, err
+	}
+	if len(thumbs) < 2 {
+		return 0, fmt.Errorf("selection too short to analyze")
+	}
+
+	// Motion energy per column (or row), summed over the selection
+	n := reframeGridH
+	if horizontal {
+		n = reframeGridW
+	}
+	energy := make([]float64, n)
+	for i := 1; i < len(thumbs); i++ {
+		prev, cur := thumbs[i-1], thumbs[i]
+		for p := range cur {
+			d := int(cur[p]) - int(prev[p])
+			if d < 0 {
+				d = -d
+			}
+			// Ignore sensor noise and compression shimmer
+			if d < 8 {
+				continue
+			}
+			if horizontal {
+				energy[p%reframeGridW] += float64(d)
+			} else {
+				energy[p/reframeGridW] += float64(d)
+			}
+		}
+	}
+
+	var total, weighted float64
+	for i, e := range energy {
+		total += e
+		weighted += e * (float64(i) + 0.5)
+	}
+	if total == 0 {
+		return 0, nil
+	}
+	center := weighted / total / float64(n) // 0..1 across the frame
+
+	// Window size as a fraction of the frame, then clamp it inside
+	frac := float64(cropH) / float64(opts.Height)
+	if horizontal {
+		frac = float64(cropW) / float64(opts.Width)
+	}
+	left := max(0, min(1-frac, center-frac/2))
+	return left/(1-frac)*2 - 1, nil
+}
@@ -0,0 +1,88 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// FrameRenderer turns one decoded frame (a BMP or PPM image) into terminal
+// text of width x height cells. The default pipes it through chafa, or
+// draws it with kitty graphics (see SetGraphics); tests and benchmarks
+// swap in a fake with SetFrameRenderer, see videotest.
+type FrameRenderer interface {
+	RenderFrame(ctx context.Context, frame []byte, width, height int, quality QualityPreset) (string, error)
+}
+
+// terminalRenderer draws frames as SetGraphics chose. text frames are
+// laid out among other text (export previews), where a sixel image
+// cannot go, so those come out as symbols instead. symbols frames always
+// do: thumbnails are kept and shown together, more of them than the
+// kitty image ids go round.
+type terminalRenderer struct {
+	text    bool
+	symbols bool
+}
+
+func (r terminalRenderer) mode() GraphicsMode {
+	if r.symbols || r.text && graphicsMode == GraphicsSixel {
+		return GraphicsSymbols
+	}
+	return graphicsMode
+}
+
+// chafaArgs is the chafa command line for a width x height frame.
+func (r terminalRenderer) chafaArgs(width, height int, quality QualityPreset) []string {
+	config := ChafaPresets[quality]
+	if r.mode() == GraphicsSixel {
+		config.Format = "sixels"
+		width, height = sixelSize(width, height)
+	}
+	return config.BuildArgs(width, height)
+}
+
+func (r terminalRenderer) RenderFrame(ctx context.Context, frame []byte, width, height int, quality QualityPreset) (string, error) {
+	if r.mode() == GraphicsKitty {
+		if out, err := renderKitty(frame, width, height, quality); err == nil {
+			return out, nil
+		}
+	}
+	chafaCmd := command(ctx, "chafa", r.chafaArgs(width, height, quality)...)
+
+	chafaCmd.Stdin = bytes.NewReader(frame)
+
+	chafaOut := getOutputBuf()
+	defer putOutputBuf(chafaOut)
+	chafaCmd.Stdout = chafaOut
+
+	if err := chafaCmd.Run(); err != nil {
+		return "", err
+	}
+
+	return chafaOut.String(), nil
+}
+
+var (
+	rendererMu    sync.RWMutex
+	frameRenderer FrameRenderer = terminalRenderer{}
+)
+
+// SetFrameRenderer replaces the playback frame renderer and returns a
+// function restoring the previous one.
+func SetFrameRenderer(r FrameRenderer) (restore func()) {
+	rendererMu.Lock()
+	prev := frameRenderer
+	frameRenderer = r
+	rendererMu.Unlock()
+	return func() {
+		rendererMu.Lock()
+		frameRenderer = prev
+		rendererMu.Unlock()
+	}
+}
+
+func currentFrameRenderer() FrameRenderer {
+	rendererMu.RLock()
+	defer rendererMu.RUnlock()
+	return frameRenderer
+}
@@ -0,0 +1,105 @@
+package video_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+// benchSizes are the decoded preview sizes playback sees.
+var benchSizes = []struct {
+	name string
+	w, h int
+}{{"360p", 640, 360}, {"720p", 1280, 720}, {"1080p", 1920, 1080}}
+
+// benchCells is the preview panel of a 120x40 terminal.
+const benchCols, benchRows = 116, 32
+
+// benchPresets are the quality presets in order.
+func benchPresets() []video.QualityPreset {
+	var presets []video.QualityPreset
+	for q := range video.ChafaPresets {
+		presets = append(presets, q)
+	}
+	slices.Sort(presets)
+	return presets
+}
+
+// benchRender reads frames off a fake ffmpeg stream of w x h and renders
+// each with the current renderer, reporting the frame rate reached.
+func benchRender(b *testing.B, fake *videotest.FakeRunner, w, h int, quality video.QualityPreset) {
+	const frames = 16
+	fake.Respond("ffmpeg", videotest.Response{Stdout: videotest.SyntheticFrames(w, h, frames)})
+	ctx := context.Background()
+	var stream *video.FrameStream
+	defer func() {
+		if stream != nil {
+			stream.Close()
+		}
+	}()
+
+	b.ReportAllocs()
+	rendered := 0
+	for b.Loop() {
+		if stream == nil {
+			b.StopTimer()
+			var err error
+			stream, err = video.NewFrameStream(ctx, "in.mp4", 0, benchCols, benchRows, 24, w, h)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+		}
+		frame, err := stream.NextFrame()
+		if err != nil {
+			stream.Close()
+			stream = nil
+			continue
+		}
+		if _, err := video.RenderFrame(ctx, frame, benchCols, benchRows, quality); err != nil {
+			b.Fatal(err)
+		}
+		stream.Release(frame)
+		rendered++
+	}
+	b.ReportMetric(float64(rendered)/b.Elapsed().Seconds(), "frames/s")
+}
+
+// BenchmarkRenderStream measures the decode side of playback: frames
+// read off the stream and handed to a renderer that costs next to nothing.
+func BenchmarkRenderStream(b *testing.B) {
+	for _, size := range benchSizes {
+		for _, quality := range benchPresets() {
+			b.Run(fmt.Sprintf("%s/%s", size.name, quality), func(b *testing.B) {
+				fake := videotest.NewFakeRunner(b.TempDir())
+				defer video.SetRunner(fake)()
+				renderer := &videotest.FakeRenderer{}
+				defer video.SetFrameRenderer(renderer)()
+				benchRender(b, fake, size.w, size.h, quality)
+			})
+		}
+	}
+}
+
+// BenchmarkRenderChafa measures the chafa side: the preset's command line
+// and a process per frame, answered by a fake chafa with a truecolor
+// frame's worth of output.
+func BenchmarkRenderChafa(b *testing.B) {
+	cell := "\x1b[38;2;120;80;40m\x1b[48;2;10;20;30m▀"
+	out := strings.Repeat(strings.Repeat(cell, benchCols)+"\x1b[0m\n", benchRows)
+	for _, size := range benchSizes {
+		for _, quality := range benchPresets() {
+			b.Run(fmt.Sprintf("%s/%s", size.name, quality), func(b *testing.B) {
+				fake := videotest.NewFakeRunner(b.TempDir())
+				defer video.SetRunner(fake)()
+				fake.Respond("chafa", videotest.Response{Stdout: []byte(out)})
+				benchRender(b, fake, size.w, size.h, quality)
+			})
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// InputProblem describes a damaged or unfinished input file, such as a
+// recording whose writer was killed before it closed the file.
+type InputProblem struct {
+	Reason string
+	// Detail is the first error ffprobe printed, if any.
+	Detail string
+	// NoIndex is set for MP4/MOV files missing their moov atom. A remux
+	// cannot rebuild that; it takes untrunc and a healthy reference
+	// recording from the same source.
+	NoIndex bool
+}
+
+func (p *InputProblem) Error() string {
+	if p.Detail == "" {
+		return p.Reason
+	}
+	return p.Reason + ": " + p.Detail
+}
+
+// CheckInput probes path for signs of damage: ffprobe errors or a missing
+// duration, either of which would show as an empty 00:00 timeline. It
+// returns nil for a healthy file.
+func CheckInput(ctx context.Context, path string) *InputProblem {
+	cmd := command(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	detail, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+	noIndex := strings.Contains(stderr.String(), "moov atom not found")
+	switch {
+	case noIndex:
+		return &InputProblem{Reason: "file is missing its index (unfinished recording?)", Detail: detail, NoIndex: true}
+	case runErr != nil:
+		return &InputProblem{Reason: "ffprobe cannot read the file", Detail: detail}
+	case detail != "":
+		return &InputProblem{Reason: "ffprobe reported errors", Detail: detail}
+	}
+
+	secs, err := strconv.ParseFloat(strings.TrimSpace(stdout.String()), 64)
+	if err != nil || secs <= 0 {
+		return &InputProblem{Reason: "duration is missing (unfinished recording?)"}
+	}
+	return nil
+}
+
+// RepairInput remuxes path into a new file next to it, which rewrites the
+// container index and duration while copying the video and audio streams
+// untouched, and returns the new file's path.
+func RepairInput(ctx context.Context, path string) (string, error) {
+	ext := filepath.Ext(path)
+	output := strings.TrimSuffix(path, ext) + "_fixed" + ext
+	for i := 1; fileExists(output); i++ {
+		output = fmt.Sprintf("%s_fixed_%d%s", strings.TrimSuffix(path, ext), i, ext)
+	}
+
+	cmd := command(ctx, "ffmpeg",
+		"-v", "error",
+		// Keep going past damaged packets instead of giving up on them
+		"-err_detect", "ignore_err",
+		"-fflags", "+genpts+discardcorrupt",
+		"-i", path,
+		// Subtitle and data streams often cannot be copied into the same
+		// container again, and the edit only needs picture and sound
+		"-map", "0:v?",
+		"-map", "0:a?",
+		"-c", "copy",
+		output,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		detail, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+		return "", fmt.Errorf("repair failed: %w: %s", err, detail)
+	}
+	if problem := CheckInput(ctx, output); problem != nil {
+		return output, fmt.Errorf("repaired file still has problems: %w", problem)
+	}
+	return output, nil
+}
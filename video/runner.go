@@ -0,0 +1,57 @@
+package video
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+)
+
+// Runner creates the external processes (ffmpeg, ffprobe, ffplay, chafa,
+// whisper) the video package runs. The default runs the real binaries;
+// tests swap in a fake with SetRunner, see package videotest.
+type Runner interface {
+	// Command returns an unstarted command for name. The video package
+	// adds process-group setup and cancellation on top.
+	Command(ctx context.Context, name string, args ...string) *exec.Cmd
+	// LookPath reports where name is installed, like exec.LookPath.
+	LookPath(name string) (string, error)
+}
+
+type execRunner struct{}
+
+func (execRunner) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, name, args...)
+}
+
+func (execRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+var (
+	runnerMu sync.RWMutex
+	runner   Runner = execRunner{}
+)
+
+// SetRunner replaces the process runner and returns a function restoring
+// the previous one.
+func SetRunner(r Runner) (restore func()) {
+	runnerMu.Lock()
+	prev := runner
+	runner = r
+	runnerMu.Unlock()
+	return func() {
+		runnerMu.Lock()
+		runner = prev
+		runnerMu.Unlock()
+	}
+}
+
+func currentRunner() Runner {
+	runnerMu.RLock()
+	defer runnerMu.RUnlock()
+	return runner
+}
+
+func lookPath(name string) (string, error) {
+	return currentRunner().LookPath(name)
+}
@@ -0,0 +1,100 @@
+package video
+
+import (
+	"fmt"
+	"time"
+)
+
+// SeekMode is how ffmpeg gets to the position it decodes from.
+type SeekMode int
+
+const (
+	SeekAuto     SeekMode = iota // per operation, see seekOp.mode
+	SeekFast                     // -noaccurate_seek: start at the keyframe before
+	SeekAccurate                 // -ss before -i: jump to the keyframe, decode up to the position
+	SeekExact                    // -ss after -i: decode from the start, for broken indexes
+)
+
+func (s SeekMode) String() string {
+	switch s {
+	case SeekFast:
+		return "fast"
+	case SeekAccurate:
+		return "accurate"
+	case SeekExact:
+		return "exact"
+	}
+	return "auto"
+}
+
+// ParseSeekMode reads a --seek value.
+func ParseSeekMode(s string) (SeekMode, error) {
+	for _, mode := range []SeekMode{SeekAuto, SeekFast, SeekAccurate, SeekExact} {
+		if s == mode.String() {
+			return mode, nil
+		}
+	}
+	return SeekAuto, fmt.Errorf("unknown seek mode %q (want auto, fast, accurate or exact)", s)
+}
+
+// seekMode is set once at startup, like graphicsMode.
+var seekMode = SeekAuto
+
+// SetSeekMode overrides the seek strategy of previews. Exports, and
+// anything else written to a file, always seek accurately (or exactly,
+// for single frames), so that they start on the frame the preview showed
+// at the in-point.
+func SetSeekMode(mode SeekMode) {
+	seekMode = mode
+}
+
+// seekOp is what ffmpeg reads from a position for.
+type seekOp int
+
+const (
+	seekOverview seekOp = iota // filmstrip thumbnails, spread over the file
+	seekStream                 // preview playback
+	seekStill                  // a preview frame, including the in/out warm-up
+	seekFrame                  // a single frame written to a file
+	seekRange                  // an export, or an analysis pass over the selection
+)
+
+// mode picks the strategy of op. Auto seeks fast only for the filmstrip,
+// where a frame a few seconds off does not matter, and accurately for
+// everything else: a fast seek starts on the keyframe before, which the
+// preview would show as the in-point while the export starts later.
+// Forcing fast covers previews only, and exact single frames only:
+// decoding from the start for every filmstrip thumbnail takes too long.
+func (op seekOp) mode() SeekMode {
+	switch seekMode {
+	case SeekAuto:
+		if op == seekOverview {
+			return SeekFast
+		}
+	case SeekFast:
+		if op == seekOverview || op == seekStream || op == seekStill {
+			return SeekFast
+		}
+	case SeekExact:
+		if op == seekStill || op == seekFrame {
+			return SeekExact
+		}
+	}
+	return SeekAccurate
+}
+
+// seekInput opens input at position for op, with extra input options such
+// as -t before it. Exact seeks go after -i, so ops that get them take no
+// extra options.
+func seekInput(op seekOp, position time.Duration, input string, extra ...string) []string {
+	ss := []string{"-ss", fmt.Sprintf("%.3f", position.Seconds())}
+	switch op.mode() {
+	case SeekFast:
+		ss = append([]string{"-noaccurate_seek"}, ss...)
+	case SeekExact:
+		args := append(append([]string{}, extra...), "-i", input)
+		return append(args, ss...)
+	}
+	args := append(ss, extra...)
+	return append(args, "-i", input)
+}
@@ -0,0 +1,360 @@
+package video
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smartEncoders re-encode the ends of a smart cut in the source's own
+// codec, so the pieces can be joined without touching the copied middle.
+var smartEncoders = map[string]string{
+	"h264": "libx264",
+	"hevc": "libx265",
+}
+
+// smartCRF is the quality of the re-encoded ends when opts.CRF sets none:
+// high enough that the join to the copied middle does not show.
+const smartCRF = 16
+
+// smartPixFmts are the pixel formats the smart cut encoders write.
+var smartPixFmts = map[string][]string{
+	"libx264": {"yuv420p", "yuvj420p", "yuv422p", "yuvj422p", "yuv444p", "yuvj444p",
+		"yuv420p10le", "yuv422p10le", "yuv444p10le", "gray"},
+	"libx265": {"yuv420p", "yuv422p", "yuv444p", "gbrp", "yuv420p10le", "yuv422p10le",
+		"yuv444p10le", "gbrp10le", "yuv420p12le", "yuv422p12le", "yuv444p12le"},
+}
+
+// smartProfiles are the -profile:v names of the smart cut encoders for
+// the profiles ffprobe reports.
+var smartProfiles = map[string]map[string]string{
+	"libx264": {
+		"Constrained Baseline":  "baseline",
+		"Baseline":              "baseline",
+		"Main":                  "main",
+		"High":                  "high",
+		"High 10":               "high10",
+		"High 4:2:2":            "high422",
+		"High 4:4:4 Predictive": "high444",
+	},
+	"libx265": {
+		"Main":               "main",
+		"Main 10":            "main10",
+		"Main Still Picture": "mainstillpicture",
+	},
+}
+
+// x265RextProfiles name the HEVC range extensions profile, which ffprobe
+// reports as just "Rext", by the pixel format it is used with.
+var x265RextProfiles = map[string]string{
+	"yuv420p12le": "main12",
+	"yuv422p10le": "main422-10",
+	"yuv422p12le": "main422-12",
+	"yuv444p":     "main444-8",
+	"yuv444p10le": "main444-10",
+	"yuv444p12le": "main444-12",
+	"gbrp":        "main444-8",
+	"gbrp10le":    "main444-10",
+}
+
+// errFormatMismatch is why a smart cut is not made: its encoder cannot
+// write pieces in the source's format, and players may reject the joins.
+var errFormatMismatch = errors.New("smart cut could not match the source")
+
+// streamFormat is the format of a video stream as far as smart cut
+// pieces must match it.
+type streamFormat struct {
+	PixFmt  string `json:"pix_fmt"`
+	Profile string `json:"profile"`
+	// Level is the level_idc: ten times the level for H.264, thirty
+	// times for HEVC, 0 or less when unknown.
+	Level    int    `json:"level"`
+	TimeBase string `json:"time_base"`
+}
+
+// smartFormat is how the pieces of a smart cut match the source.
+type smartFormat struct {
+	args      []string // encoder options of the re-encoded pieces
+	timescale int      // of the source's video track, 0 when unknown
+}
+
+// cutPiece is a stretch of the source in a smart cut: copied whole GOPs,
+// or re-encoded frames up to or from a keyframe.
+type cutPiece struct {
+	From, To time.Duration
+	Copy     bool
+}
+
+// smartEncoder is the encoder for the ends of a smart cut of opts, "" when
+// this ffmpeg cannot re-encode its codec.
+func (opts ExportOptions) smartEncoder() string {
+	enc := smartEncoders[opts.VideoCodec]
+	if enc == "" || !FFmpegCapabilities().HasEncoder(enc) {
+		return ""
+	}
+	return enc
+}
+
+// SmartCutProblem says why opts.SmartCut does not apply, or "" when it
+// does. Smart cuts replace stream copies only: anything that re-encodes
+// the picture anyway is frame accurate already.
+func (opts ExportOptions) SmartCutProblem(output string) string {
+	if len(opts.Parts) > 0 || opts.hasBumpers() || opts.hasMusic() || opts.Animated() {
+		return "the export re-encodes anyway"
+	}
+	if vf, af := exportFilters(opts); len(vf) > 0 || len(af) > 0 {
+		return "the export re-encodes anyway"
+	}
+	if videoOK, _ := CopyCompatible(output, opts.VideoCodec, ""); !videoOK {
+		return "the export re-encodes anyway"
+	}
+	if opts.smartEncoder() == "" {
+		if enc := smartEncoders[opts.VideoCodec]; enc != "" {
+			return "needs ffmpeg with " + enc
+		}
+		return "only for H.264 and HEVC sources"
+	}
+	return ""
+}
+
+// smartCuts reports whether the export of opts to output is a smart cut.
+func (opts ExportOptions) smartCuts(output string) bool {
+	return opts.SmartCut && opts.SmartCutProblem(output) == ""
+}
+
+// planSmartCut splits in-out at the keyframes inside it: the frames before
+// the first keyframe and from the last one on are re-encoded, the whole
+// GOPs between are copied. Without a keyframe inside, all of it is
+// re-encoded.
+func planSmartCut(in, out time.Duration, keyframes []time.Duration) []cutPiece {
+	const slack = time.Millisecond
+	first, last := time.Duration(-1), time.Duration(-1)
+	for _, k := range keyframes {
+		if k < in-slack || k > out+slack {
+			continue
+		}
+		if first < 0 {
+			first = k
+		}
+		last = k
+	}
+	if first < 0 || first >= out-slack {
+		return []cutPiece{{From: in, To: out}}
+	}
+	var pieces []cutPiece
+	if first > in+slack {
+		pieces = append(pieces, cutPiece{From: in, To: first})
+	}
+	if last > first {
+		pieces = append(pieces, cutPiece{From: first, To: last, Copy: true})
+	}
+	if last < out-slack {
+		pieces = append(pieces, cutPiece{From: last, To: out})
+	}
+	return pieces
+}
+
+// probeKeyframes reads the keyframe times of the first video stream of
+// path from just before from to to.
+func probeKeyframes(ctx context.Context, path string, from, to time.Duration) ([]time.Duration, error) {
+	cmd := command(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", fmt.Sprintf("%.3f%%%.3f", from.Seconds(), to.Seconds()+1),
+		"-show_entries", "packet=pts_time,dts_time,size,flags",
+		"-of", "csv=p=0",
+		path,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	b, err := readBitrate(strings.NewReader(string(out)))
+	if err != nil {
+		return nil, err
+	}
+	return b.Keyframes, nil
+}
+
+// probeStreamFormat reads the format of the first video stream of path.
+func probeStreamFormat(ctx context.Context, path string) (streamFormat, error) {
+	cmd := command(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=pix_fmt,profile,level,time_base",
+		"-of", "json",
+		path,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return streamFormat{}, ctx.Err()
+		}
+		return streamFormat{}, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	var probe struct {
+		Streams []streamFormat `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return streamFormat{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	if len(probe.Streams) == 0 {
+		return streamFormat{}, errors.New("no video stream")
+	}
+	return probe.Streams[0], nil
+}
+
+// matchFormat sets up enc to write pieces in format f: the same pixel
+// format, profile and level, and in the MP4 family the same track
+// timescale. It fails with errFormatMismatch when enc cannot.
+func matchFormat(enc string, f streamFormat) (smartFormat, error) {
+	if f.PixFmt == "" {
+		return smartFormat{}, fmt.Errorf("%w: unknown pixel format", errFormatMismatch)
+	}
+	if !slices.Contains(smartPixFmts[enc], f.PixFmt) {
+		return smartFormat{}, fmt.Errorf("%w: %s does not write %s", errFormatMismatch, enc, f.PixFmt)
+	}
+	profile := smartProfiles[enc][f.Profile]
+	if enc == "libx265" && f.Profile == "Rext" {
+		profile = x265RextProfiles[f.PixFmt]
+	}
+	if profile == "" {
+		return smartFormat{}, fmt.Errorf("%w: %s has no %q profile", errFormatMismatch, enc, f.Profile)
+	}
+	format := smartFormat{args: []string{"-pix_fmt", f.PixFmt, "-profile:v", profile}}
+	switch {
+	case f.Level <= 0:
+	case enc == "libx264" && f.Level == 9:
+		format.args = append(format.args, "-level:v", "1b")
+	case enc == "libx264":
+		format.args = append(format.args, "-level:v", fmt.Sprintf("%d.%d", f.Level/10, f.Level%10))
+	case enc == "libx265":
+		format.args = append(format.args, "-x265-params", fmt.Sprintf("level-idc=%d.%d", f.Level/30, f.Level%30/3))
+	}
+	if _, den, ok := strings.Cut(f.TimeBase, "/"); ok {
+		format.timescale, _ = strconv.Atoi(den)
+	}
+	return format, nil
+}
+
+// smartCutFormat probes how the pieces of a smart cut of opts match the
+// source.
+func smartCutFormat(ctx context.Context, opts ExportOptions) (smartFormat, error) {
+	f, err := probeStreamFormat(ctx, opts.Input)
+	if err != nil {
+		return smartFormat{}, fmt.Errorf("probing the video stream: %w", err)
+	}
+	return matchFormat(opts.smartEncoder(), f)
+}
+
+// pieceArgs writes one piece of a smart cut, video only, to an MPEG-TS
+// file, whose in-band parameter sets let pieces from different encoders
+// be joined.
+func pieceArgs(opts ExportOptions, piece cutPiece, format smartFormat, output string) []string {
+	// Stop short of the keyframe the next piece starts on, and seek copies
+	// just past theirs: a copy starts on the keyframe at or before -ss
+	const nudge = 500 * time.Microsecond
+	from, length := piece.From, piece.To-piece.From
+	if piece.To < opts.OutPoint-time.Millisecond {
+		length -= nudge
+	}
+	if piece.Copy {
+		from, length = from+nudge, length-nudge
+	}
+	args := []string{"-y", "-ss", fmt.Sprintf("%.6f", from.Seconds()),
+		"-t", fmt.Sprintf("%.6f", length.Seconds()), "-i", opts.Input}
+	args = append(args, "-progress", "pipe:2", "-map", "0:v:0", "-an", "-sn", "-dn")
+	if piece.Copy {
+		args = append(args, "-c:v", "copy")
+	} else {
+		enc := opts.smartEncoder()
+		crf := opts.CRF
+		if crf <= 0 {
+			crf = smartCRF
+		}
+		args = append(args, "-c:v", enc)
+		args = append(args, crfArgs(enc, crf)...)
+		args = append(args, format.args...)
+	}
+	args = append(args, threadArgs(opts)...)
+	return append(args, "-f", "mpegts", output)
+}
+
+// joinArgs muxes the pieces listed in list with the audio of the
+// selection, copied where output's container takes it. MP4 and MOV
+// outputs keep the source's track timescale, as a plain copy would.
+func joinArgs(opts ExportOptions, format smartFormat, list, output string) []string {
+	duration := opts.OutPoint - opts.InPoint
+	args := []string{"-y", "-f", "concat", "-safe", "0", "-i", list}
+	args = append(args, seekInput(seekRange, opts.InPoint, opts.Input,
+		"-t", fmt.Sprintf("%.3f", duration.Seconds()))...)
+	args = append(args, "-progress", "pipe:2", "-map", "0:v", "-map", "1:a?", "-c:v", "copy")
+	if _, audioOK := CopyCompatible(output, "", opts.AudioCodec); audioOK {
+		args = append(args, "-c:a", "copy")
+	}
+	args = append(args, "-t", fmt.Sprintf("%.3f", duration.Seconds()))
+	if format.timescale > 0 && SupportsFastStart(output) {
+		args = append(args, "-video_track_timescale", strconv.Itoa(format.timescale))
+	}
+	return append(args, outputArgs(opts, output)...)
+}
+
+// smartCut exports opts to output as a smart cut: each piece of
+// planSmartCut is written to a temporary file, then they are joined
+// under the audio. report gets progress in [0,1], re-encoded pieces
+// counting for more than copied ones. format is from smartCutFormat.
+func smartCut(ctx context.Context, opts ExportOptions, format smartFormat, output string, report func(float64)) error {
+	keyframes, err := probeKeyframes(ctx, opts.Input, opts.InPoint, opts.OutPoint)
+	if err != nil {
+		return fmt.Errorf("finding keyframes: %w", err)
+	}
+	pieces := planSmartCut(opts.InPoint, opts.OutPoint, keyframes)
+
+	dir, err := os.MkdirTemp("", "lazycut-smartcut-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Encoding takes far longer than copying; the join copies everything
+	weight := func(p cutPiece) float64 {
+		if p.Copy {
+			return (p.To - p.From).Seconds()
+		}
+		return 10 * (p.To - p.From).Seconds()
+	}
+	total := (opts.OutPoint - opts.InPoint).Seconds()
+	for _, p := range pieces {
+		total += weight(p)
+	}
+
+	var list strings.Builder
+	done := 0.0
+	for i, p := range pieces {
+		path := filepath.Join(dir, fmt.Sprintf("piece%d.ts", i))
+		w := weight(p)
+		err := runWithProgress(ctx, pieceArgs(opts, p, format, path), p.To-p.From, opts.LowPriority,
+			func(f float64) { report((done + f*w) / total) })
+		if err != nil {
+			return err
+		}
+		done += w
+		fmt.Fprintf(&list, "file '%s'\n", strings.ReplaceAll(path, "'", `'\''`))
+	}
+	listPath := filepath.Join(dir, "pieces.txt")
+	if err := os.WriteFile(listPath, []byte(list.String()), 0o644); err != nil {
+		return err
+	}
+	return runWithProgress(ctx, joinArgs(opts, format, listPath, output), opts.OutPoint-opts.InPoint, opts.LowPriority,
+		func(f float64) { report((done + f*(opts.OutPoint-opts.InPoint).Seconds()) / total) })
+}
@@ -0,0 +1,84 @@
+package video
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatchFormat(t *testing.T) {
+	tests := []struct {
+		enc       string
+		format    streamFormat
+		args      []string
+		timescale int
+	}{
+		{"libx264", streamFormat{"yuv420p", "High", 41, "1/15360"},
+			[]string{"-pix_fmt", "yuv420p", "-profile:v", "high", "-level:v", "4.1"}, 15360},
+		{"libx264", streamFormat{"yuvj420p", "Constrained Baseline", 31, "1/90000"},
+			[]string{"-pix_fmt", "yuvj420p", "-profile:v", "baseline", "-level:v", "3.1"}, 90000},
+		{"libx264", streamFormat{"yuv420p", "Baseline", 9, ""},
+			[]string{"-pix_fmt", "yuv420p", "-profile:v", "baseline", "-level:v", "1b"}, 0},
+		{"libx264", streamFormat{"yuv422p10le", "High 4:2:2", -99, "1/600"},
+			[]string{"-pix_fmt", "yuv422p10le", "-profile:v", "high422"}, 600},
+		{"libx265", streamFormat{"yuv420p10le", "Main 10", 153, "1/30000"},
+			[]string{"-pix_fmt", "yuv420p10le", "-profile:v", "main10", "-x265-params", "level-idc=5.1"}, 30000},
+		{"libx265", streamFormat{"yuv444p", "Rext", 120, "1/25"},
+			[]string{"-pix_fmt", "yuv444p", "-profile:v", "main444-8", "-x265-params", "level-idc=4.0"}, 25},
+	}
+	for _, tt := range tests {
+		got, err := matchFormat(tt.enc, tt.format)
+		if err != nil {
+			t.Errorf("matchFormat(%s, %+v): %v", tt.enc, tt.format, err)
+			continue
+		}
+		if !slices.Equal(got.args, tt.args) || got.timescale != tt.timescale {
+			t.Errorf("matchFormat(%s, %+v) = %q, %d, want %q, %d",
+				tt.enc, tt.format, got.args, got.timescale, tt.args, tt.timescale)
+		}
+	}
+}
+
+func TestMatchFormatMismatch(t *testing.T) {
+	tests := []struct {
+		enc    string
+		format streamFormat
+	}{
+		{"libx264", streamFormat{"yuv420p12le", "High 4:4:4 Predictive", 51, "1/15360"}},
+		{"libx264", streamFormat{"yuv420p", "High 10 Intra", 41, "1/15360"}},
+		{"libx264", streamFormat{"", "High", 41, "1/15360"}},
+		{"libx265", streamFormat{"yuv420p", "SCC", 120, "1/15360"}},
+		{"libx265", streamFormat{"gray", "Rext", 120, "1/15360"}},
+	}
+	for _, tt := range tests {
+		if _, err := matchFormat(tt.enc, tt.format); !errors.Is(err, errFormatMismatch) {
+			t.Errorf("matchFormat(%s, %+v) = %v, want a mismatch", tt.enc, tt.format, err)
+		}
+	}
+}
+
+func TestSmartCutFormatArgs(t *testing.T) {
+	opts := ExportOptions{
+		Input: "in.mp4", InPoint: time.Second, OutPoint: 5 * time.Second,
+		VideoCodec: "h264", AudioCodec: "aac",
+	}
+	format := smartFormat{args: []string{"-pix_fmt", "yuv420p", "-profile:v", "high"}, timescale: 15360}
+
+	encoded := pieceArgs(opts, cutPiece{From: time.Second, To: 2 * time.Second}, format, "piece0.ts")
+	if i := slices.Index(encoded, "-profile:v"); i < 0 || encoded[i+1] != "high" {
+		t.Errorf("re-encoded piece does not match the profile: %q", encoded)
+	}
+	copied := pieceArgs(opts, cutPiece{From: 2 * time.Second, To: 4 * time.Second, Copy: true}, format, "piece1.ts")
+	if slices.Contains(copied, "-pix_fmt") {
+		t.Errorf("copied piece sets a pixel format: %q", copied)
+	}
+
+	if args := joinArgs(opts, format, "pieces.txt", "out.mp4"); !slices.Contains(args, "-video_track_timescale") ||
+		args[slices.Index(args, "-video_track_timescale")+1] != "15360" {
+		t.Errorf("MP4 join does not keep the timescale: %q", args)
+	}
+	if args := joinArgs(opts, format, "pieces.txt", "out.mkv"); slices.Contains(args, "-video_track_timescale") {
+		t.Errorf("MKV join sets a track timescale: %q", args)
+	}
+}
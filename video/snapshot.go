@@ -0,0 +1,41 @@
+package video
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SnapshotPath is where the PNG of the frame at position is saved: beside
+// input, named after it and the timecode, e.g. "clip_00-01-02.500.png".
+func SnapshotPath(input string, position time.Duration) string {
+	base := strings.TrimSuffix(input, filepath.Ext(input))
+	return base + "_" + FileTimecode(position) + ".png"
+}
+
+// SaveSnapshot writes the source frame at position as a full-resolution
+// PNG and returns its path.
+func SaveSnapshot(ctx context.Context, input string, position time.Duration) (string, error) {
+	png := SnapshotPath(input, position)
+	args := append([]string{"-y", "-loglevel", "error"}, seekInput(seekFrame, position, input)...)
+	err := runFFmpeg(ctx, append(args, "-frames:v", "1", png))
+	return png, err
+}
+
+// RenderThumbnail renders the frame at position in width x height cells of
+// chafa symbols, whatever the preview uses, so that several can be laid
+// out side by side.
+func (p *Player) RenderThumbnail(ctx context.Context, position time.Duration, width, height int) (string, error) {
+	return p.renderThumbnail(ctx, seekStill, position, width, height)
+}
+
+// renderThumbnail is RenderThumbnail seeking for op: the filmstrip seeks
+// fast, the snapshot gallery shows the frames it saved.
+func (p *Player) renderThumbnail(ctx context.Context, op seekOp, position time.Duration, width, height int) (string, error) {
+	p.mu.Lock()
+	quality := p.quality
+	p.mu.Unlock()
+
+	return terminalRenderer{symbols: true}.renderStill(ctx, op, p.path, position, p.previewFilters(), width, height, quality)
+}
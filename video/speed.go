@@ -0,0 +1,98 @@
+package video
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// speed returns the playback speed, treating the zero value as 1x.
+func (opts ExportOptions) speed() float64 {
+	if opts.Speed <= 0 {
+		return 1
+	}
+	return opts.Speed
+}
+
+// TimelapseSpeed is the speedup from which exports drop the audio, which
+// is only noise when sped up that far.
+const TimelapseSpeed = 4
+
+// DropsAudio reports whether the export is a timelapse without sound.
+func (opts ExportOptions) DropsAudio() bool {
+	return opts.speed() >= TimelapseSpeed
+}
+
+// CanInterpolate reports whether slow motion can synthesize in-between
+// frames with motion estimation rather than blending neighbors.
+func (c *Capabilities) CanInterpolate() bool {
+	return c.HasFilter("minterpolate")
+}
+
+// speedVideoFilters retimes the picture. Slow motion stretches timestamps
+// and then fills the gaps back up to the source frame rate: minterpolate
+// estimates motion for real in-between frames, framerate blends
+// neighbors where minterpolate is missing. Speedups squeeze timestamps and
+// drop the surplus frames back down to the source rate.
+func speedVideoFilters(opts ExportOptions) []string {
+	speed := opts.speed()
+	if speed == 1 {
+		return nil
+	}
+	filters := []string{"setpts=PTS/" + formatFactor(speed)}
+	if opts.FPS <= 0 {
+		return filters
+	}
+	fps := strconv.FormatFloat(opts.FPS, 'f', -1, 64)
+	switch {
+	case speed > 1:
+		filters = append(filters, "fps="+fps)
+	case FFmpegCapabilities().CanInterpolate():
+		filters = append(filters, "minterpolate=fps="+fps+":mi_mode=mci:mc_mode=aobmc:me_mode=bidir:vsbmc=1")
+	default:
+		filters = append(filters, "framerate=fps="+fps)
+	}
+	return filters
+}
+
+// speedAudioFilters keeps the pitch while retiming the sound. Old ffmpeg
+// limits atempo to 0.5-2, so larger changes are chained.
+func speedAudioFilters(speed float64) []string {
+	if speed == 1 {
+		return nil
+	}
+	var filters []string
+	for speed < 0.5 {
+		filters = append(filters, "atempo=0.5")
+		speed /= 0.5
+	}
+	for speed > 2 {
+		filters = append(filters, "atempo=2")
+		speed /= 2
+	}
+	if speed != 1 {
+		filters = append(filters, "atempo="+formatFactor(speed))
+	}
+	return filters
+}
+
+// FormatSpeed labels a speed factor, e.g. "0.25x".
+func FormatSpeed(speed float64) string {
+	return formatFactor(speed) + "x"
+}
+
+func formatFactor(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// SlowMotionCost is a rough warning for interpolated encodes, which run
+// far slower than plain ones.
+func (opts ExportOptions) SlowMotionCost() string {
+	if opts.speed() >= 1 {
+		return ""
+	}
+	if !FFmpegCapabilities().CanInterpolate() {
+		return "frames are blended (no minterpolate in this ffmpeg)"
+	}
+	return fmt.Sprintf("motion interpolation is slow: expect minutes for %s of output",
+		FormatTimecode(opts.OutputDuration()))
+}
@@ -0,0 +1,69 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stabPlaceholder stands in for the analysis file in displayed commands.
+const stabPlaceholder = "transforms.trf"
+
+// CanStabilize reports whether this ffmpeg was built with vid.stab.
+func (c *Capabilities) CanStabilize() bool {
+	return c.HasFilter("vidstabdetect") && c.HasFilter("vidstabtransform")
+}
+
+// analyzeShake runs the vidstabdetect pass over the selection and returns
+// the file holding the measured camera motion; the caller removes it.
+func analyzeShake(ctx context.Context, opts ExportOptions, report func(float64)) (string, error) {
+	f, err := os.CreateTemp("", "lazycut-*.trf")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+
+	args := shakeArgs(opts, opts.Input, f.Name(), true)
+	if err := runWithProgress(ctx, args, opts.OutPoint-opts.InPoint, opts.LowPriority, report); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("stabilization analysis: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// shakeArgs builds the analysis pass: decode the selection, measure the
+// motion, write nothing but the transforms file.
+func shakeArgs(opts ExportOptions, input, transforms string, withProgress bool) []string {
+	args := append([]string{"-y"}, seekInput(seekRange, opts.InPoint, input,
+		"-t", fmt.Sprintf("%.3f", (opts.OutPoint-opts.InPoint).Seconds()))...)
+	if withProgress {
+		args = append(args, "-progress", "pipe:2")
+	}
+	args = append(args, "-vf", "vidstabdetect=shakiness=5:accuracy=15:result="+escapeFilterValue(transforms))
+	args = append(args, threadArgs(opts)...)
+	return append(args, "-an", "-f", "null", "-")
+}
+
+// stabilizeFilters applies the analyzed motion, smoothed over 30 frames
+// either side, and sharpens away the softening from the transform (the
+// unsharp values vid.stab recommends).
+func stabilizeFilters(opts ExportOptions) []string {
+	transforms := opts.stabTransforms
+	if transforms == "" {
+		transforms = stabPlaceholder
+	}
+	return []string{
+		"vidstabtransform=input=" + escapeFilterValue(transforms) + ":smoothing=30",
+		"unsharp=5:5:0.8:3:3:0.4",
+	}
+}
+
+// escapeFilterValue quotes a path for use as a filter option value inside
+// -vf. Values are unescaped twice, first as part of the filtergraph and
+// then as an option, so Windows paths ("C:\...") need both levels.
+func escapeFilterValue(s string) string {
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(filepath.ToSlash(s))
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
+}
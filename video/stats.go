@@ -0,0 +1,260 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsOn is set once at startup, like graphicsMode.
+var statsOn bool
+
+// SetStats turns on recording how long each finished export took, in a
+// local file (see StatsPath) that nothing is sent from. Predictions are
+// read from it whether or not recording is on.
+func SetStats(on bool) {
+	statsOn = on
+}
+
+// StatsPath is the file export durations are recorded in.
+func StatsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lazycut", "stats.json"), nil
+}
+
+// ExportSample is one finished export: what kind of encode it was, how
+// long the output is and how long it took, analysis passes included.
+type ExportSample struct {
+	Kind   string        `json:"kind"`
+	Length time.Duration `json:"length"`
+	Took   time.Duration `json:"took"`
+	When   time.Time     `json:"when"`
+}
+
+// Speed is how many seconds of output the export made per second of work.
+func (s ExportSample) Speed() float64 {
+	if s.Took <= 0 {
+		return 0
+	}
+	return s.Length.Seconds() / s.Took.Seconds()
+}
+
+// ExportStats are the recorded exports, oldest first.
+type ExportStats struct {
+	Exports []ExportSample `json:"exports"`
+}
+
+// maxSamples bounds the stats file; the oldest samples go first.
+const maxSamples = 1000
+
+// predictSamples is how many of the latest samples of a kind a prediction
+// goes by, and minSamples how many it needs at all.
+const (
+	predictSamples = 20
+	minSamples     = 3
+)
+
+// statsMu keeps exports finishing together from losing each other's
+// samples.
+var statsMu sync.Mutex
+
+// LoadStats reads the stats file; a missing one has no exports.
+func LoadStats() (ExportStats, error) {
+	var stats ExportStats
+	path, err := StatsPath()
+	if err != nil {
+		return stats, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return ExportStats{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// ClearStats removes the stats file.
+func ClearStats() error {
+	path, err := StatsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// recordExport adds a sample for the export of opts to output when stats
+// are on. Failing to is not worth failing the export over.
+func recordExport(opts ExportOptions, output string, took time.Duration) {
+	length := opts.OutputDuration()
+	if !statsOn || length <= 0 || took <= 0 {
+		return
+	}
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	stats, err := LoadStats()
+	if err != nil {
+		// A damaged file starts over rather than staying broken
+		stats = ExportStats{}
+	}
+	stats.Exports = append(stats.Exports, ExportSample{
+		Kind:   ExportKind(opts, output),
+		Length: length,
+		Took:   took,
+		When:   time.Now(),
+	})
+	if n := len(stats.Exports); n > maxSamples {
+		stats.Exports = stats.Exports[n-maxSamples:]
+	}
+	path, err := StatsPath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+	}
+}
+
+// ExportKind groups exports that take about as long per second of output:
+// the video encoder (or "copy") and the source height, with the passes
+// that cost far more than the encode marked, e.g. "libx264 1080p
+// +stabilize".
+func ExportKind(opts ExportOptions, output string) string {
+	kind := "copy"
+	if opts.ReencodesVideo(output) {
+		kind = videoEncoder(opts, output)
+	}
+	if opts.Height > 0 {
+		kind += " " + heightClass(opts.Height)
+	}
+	if opts.Stabilize {
+		kind += " +stabilize"
+	}
+	if opts.speed() < 1 {
+		kind += " +slowmo"
+	}
+	if opts.smartCuts(output) {
+		kind += " +smartcut"
+	}
+	return kind
+}
+
+// heightClass rounds a frame height up to the usual resolution names.
+func heightClass(h int) string {
+	for _, class := range []int{480, 720, 1080, 1440} {
+		if h <= class {
+			return fmt.Sprintf("%dp", class)
+		}
+	}
+	return "2160p+"
+}
+
+// Kinds lists the kinds recorded, most exports first.
+func (s ExportStats) Kinds() []string {
+	count := map[string]int{}
+	for _, sample := range s.Exports {
+		count[sample.Kind]++
+	}
+	var kinds []string
+	for kind := range count {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if count[kinds[i]] != count[kinds[j]] {
+			return count[kinds[i]] > count[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	return kinds
+}
+
+// Samples returns the recorded exports of kind, oldest first.
+func (s ExportStats) Samples(kind string) []ExportSample {
+	var samples []ExportSample
+	for _, sample := range s.Exports {
+		if sample.Kind == kind {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
+
+// MedianSpeed is the median Speed of samples, 0 without any.
+func MedianSpeed(samples []ExportSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	speeds := make([]float64, len(samples))
+	for i, sample := range samples {
+		speeds[i] = sample.Speed()
+	}
+	slices.Sort(speeds)
+	return speeds[len(speeds)/2]
+}
+
+// PredictExport is how long the export of opts to output should take on
+// this machine, going by the latest exports of its kind; false without
+// enough of them.
+func (s ExportStats) PredictExport(opts ExportOptions, output string) (time.Duration, bool) {
+	samples := s.Samples(ExportKind(opts, output))
+	if len(samples) < minSamples {
+		return 0, false
+	}
+	if len(samples) > predictSamples {
+		samples = samples[len(samples)-predictSamples:]
+	}
+	speed := MedianSpeed(samples)
+	if speed <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(opts.OutputDuration()) / speed), true
+}
+
+// Remaining estimates how much longer an export that has run for elapsed
+// and reached progress in [0,1] takes. Early on the prediction from past
+// exports, if any, counts for most; the export's own pace takes over as
+// it goes. false when there is nothing to go by yet.
+func Remaining(elapsed time.Duration, progress float64, predicted time.Duration) (time.Duration, bool) {
+	var paced time.Duration
+	if progress > 0 {
+		paced = time.Duration(float64(elapsed) / progress)
+	}
+	var total time.Duration
+	switch {
+	case predicted > 0 && paced > 0:
+		total = time.Duration(progress*float64(paced) + (1-progress)*float64(predicted))
+	case predicted > 0:
+		total = predicted
+	case progress >= 0.02:
+		total = paced
+	default:
+		return 0, false
+	}
+	return max(0, total-elapsed), true
+}
@@ -0,0 +1,103 @@
+package video_test
+
+import (
+	"testing"
+	"time"
+
+	"lazycut/video"
+)
+
+func TestRemaining(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		elapsed   time.Duration
+		progress  float64
+		predicted time.Duration
+		want      time.Duration
+		ok        bool
+	}{
+		{"nothing to go by", time.Second, 0, 0, 0, false},
+		{"too early to pace", time.Second, 0.01, 0, 0, false},
+		{"paced", 10 * time.Second, 0.5, 0, 10 * time.Second, true},
+		{"predicted before progress", 5 * time.Second, 0, time.Minute, 55 * time.Second, true},
+		// A quarter in, the export's own pace (40s) counts for a quarter
+		// and the prediction (80s) for the rest: 70s in all
+		{"early blend", 10 * time.Second, 0.25, 80 * time.Second, time.Minute, true},
+		// Nine tenths in, the pace (50s) has taken over: 55s in all
+		{"late blend", 45 * time.Second, 0.9, 100 * time.Second, 10 * time.Second, true},
+		{"slower than predicted", 20 * time.Second, 0, 10 * time.Second, 0, true},
+		{"done", 30 * time.Second, 1, time.Minute, 0, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := video.Remaining(tc.elapsed, tc.progress, tc.predicted)
+			if ok != tc.ok || got != tc.want {
+				t.Errorf("Remaining(%v, %v, %v) = %v, %v; want %v, %v",
+					tc.elapsed, tc.progress, tc.predicted, got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+// samples are n exports of kind, each of 10 s of output made at speed.
+func samples(kind string, n int, speed float64) []video.ExportSample {
+	var s []video.ExportSample
+	for range n {
+		s = append(s, video.ExportSample{
+			Kind:   kind,
+			Length: 10 * time.Second,
+			Took:   time.Duration(float64(10*time.Second) / speed),
+		})
+	}
+	return s
+}
+
+func TestPredictExport(t *testing.T) {
+	opts := video.ExportOptions{
+		Output:     "/videos/clip_trim.mp4",
+		InPoint:    time.Second,
+		OutPoint:   31 * time.Second,
+		Height:     1080,
+		VideoCodec: "h264",
+		AudioCodec: "aac",
+	}
+	kind := video.ExportKind(opts, opts.Output)
+	if kind != "copy 1080p" {
+		t.Fatalf("kind %q, want copy 1080p", kind)
+	}
+	other := "libx264 1080p"
+
+	for _, tc := range []struct {
+		name    string
+		exports []video.ExportSample
+		want    time.Duration
+		ok      bool
+	}{
+		{"none", nil, 0, false},
+		{"too few", samples(kind, 2, 2), 0, false},
+		{"other kinds only", samples(other, 10, 2), 0, false},
+		// Median of 1x, 2x and 4x: 30 s of output at 2x
+		{"median", append(append(samples(kind, 1, 1), samples(kind, 1, 4)...), samples(kind, 1, 2)...), 15 * time.Second, true},
+		{"other kinds ignored", append(samples(other, 5, 0.5), samples(kind, 3, 5)...), 6 * time.Second, true},
+		// Only the 20 latest count, though the 25 older ones at 10x
+		// would have been the median
+		{"latest only", append(samples(kind, 25, 10), samples(kind, 20, 1)...), 30 * time.Second, true},
+		{"no speed", []video.ExportSample{{Kind: kind}, {Kind: kind}, {Kind: kind}}, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stats := video.ExportStats{Exports: tc.exports}
+			got, ok := stats.PredictExport(opts, opts.Output)
+			if ok != tc.ok || got != tc.want {
+				t.Errorf("PredictExport = %v, %v; want %v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestExportStatsKinds(t *testing.T) {
+	stats := video.ExportStats{Exports: append(append(
+		samples("b", 2, 1), samples("a", 2, 1)...), samples("c", 3, 1)...)}
+	got := stats.Kinds()
+	if want := []string{"c", "a", "b"}; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("Kinds() = %q, want %q: most exports first, ties by name", got, want)
+	}
+}
@@ -1,8 +1,8 @@
 package video
 
 import (
+	"bufio"
 	"context"
-	"encoding/binary"
 	"fmt"
 	"io"
 	"os/exec"
@@ -11,62 +11,106 @@ import (
 	"time"
 )
 
-// FrameStream keeps a long-lived ffmpeg process that outputs scaled BMP frames.
+// FrameStream keeps a long-lived ffmpeg process that outputs scaled frames.
 type FrameStream struct {
 	cmd        *exec.Cmd
 	stdout     io.ReadCloser
+	reader     *bufio.Reader
 	cancel     context.CancelFunc
+	format     FrameFormat
+	frameW     int // decoded frame size, fixed for FrameRaw
+	frameH     int
+	rawBuf     []byte // PPM header + one rgb24 frame, reused every frame
+	rawOffset  int    // start of pixel data in rawBuf
 	width      int
 	height     int
 	videoWidth int
 	targetFPS  int
+	corrupt    int // frames skipped because they failed to parse
 	mu         sync.Mutex
 }
 
-func NewFrameStream(path string, start time.Duration, width, height, fps, videoWidth int) (*FrameStream, error) {
+// streamCodecArgs returns the ffmpeg output arguments for each format.
+var streamCodecArgs = map[FrameFormat][]string{
+	FrameBMP: {"-f", "image2pipe", "-vcodec", "bmp"},
+	FramePPM: {"-f", "image2pipe", "-vcodec", "ppm"},
+	FrameRaw: {"-f", "rawvideo", "-pix_fmt", "rgb24"},
+}
+
+// NewFrameStream starts ffmpeg decoding from start as raw rgb24 frames of a
+// size derived from the source dimensions. The process is killed when ctx
+// is cancelled or Close is called, whichever comes first.
+func NewFrameStream(ctx context.Context, path string, start time.Duration, width, height, fps, videoWidth, videoHeight int) (*FrameStream, error) {
+	return newFrameStream(ctx, path, start, width, height, fps, videoWidth, videoHeight, FrameRaw)
+}
+
+// previewFrameSize returns the decoded preview size for a source: capped at
+// 1920 wide, aspect preserved, even dimensions for the scaler.
+func previewFrameSize(videoWidth, videoHeight int) (int, int) {
+	w, h := videoWidth, videoHeight
+	if w > 1920 {
+		h = h * 1920 / w
+		w = 1920
+	}
+	return max(2, w&^1), max(2, h&^1)
+}
+
+func newFrameStream(ctx context.Context, path string, start time.Duration, width, height, fps, videoWidth, videoHeight int, format FrameFormat) (*FrameStream, error) {
 	if width <= 0 || height <= 0 || fps <= 0 {
 		return nil, fmt.Errorf("invalid stream configuration")
 	}
+	if format == FrameRaw && (videoWidth <= 0 || videoHeight <= 0) {
+		return nil, fmt.Errorf("raw frame stream needs the source dimensions")
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 
-	// Build filter chain: scale (if needed) -> fps
+	// Build filter chain: scale (if needed) -> fps. Raw frames always get an
+	// explicit size so the reader knows exactly how many bytes to expect.
+	frameW, frameH := previewFrameSize(videoWidth, videoHeight)
 	var filters []string
-	if videoWidth > 1920 {
+	if format == FrameRaw && (frameW != videoWidth || frameH != videoHeight) {
+		filters = append(filters, fmt.Sprintf("scale=%d:%d:flags=fast_bilinear", frameW, frameH))
+	} else if format != FrameRaw && videoWidth > 1920 {
 		filters = append(filters, "scale=1920:-1:flags=fast_bilinear")
 	}
 	filters = append(filters, fmt.Sprintf("fps=%d", fps))
 
-	args := []string{
-		"-ss", fmt.Sprintf("%.3f", start.Seconds()),
-		"-i", path,
-		"-vf", strings.Join(filters, ","),
-		"-f", "image2pipe",
-		"-vcodec", "bmp",
-		"-loglevel", "error",
-		"-",
-	}
+	args := append(seekInput(seekStream, start, path), "-vf", strings.Join(filters, ","))
+	args = append(args, streamCodecArgs[format]...)
+	args = append(args, "-loglevel", "error", "-")
 
-	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd := command(ctx, "ffmpeg", args...)
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		cancel()
 		return nil, err
 	}
-	if err := cmd.Start(); err != nil {
+	if err := startTracked(cmd); err != nil {
 		cancel()
 		return nil, err
 	}
 
-	return &FrameStream{
+	stream := &FrameStream{
 		cmd:        cmd,
 		stdout:     stdout,
+		reader:     bufio.NewReaderSize(stdout, 1<<20),
 		cancel:     cancel,
+		format:     format,
+		frameW:     frameW,
+		frameH:     frameH,
 		width:      width,
 		height:     height,
 		videoWidth: videoWidth,
 		targetFPS:  fps,
-	}, nil
+	}
+	if format == FrameRaw {
+		header := ppmHeader(frameW, frameH)
+		stream.rawOffset = len(header)
+		stream.rawBuf = make([]byte, len(header)+frameW*frameH*3)
+		copy(stream.rawBuf, header)
+	}
+	return stream, nil
 }
 
 // Close stops the ffmpeg process.
@@ -77,13 +121,14 @@ func (s *FrameStream) Close() {
 		s.cancel()
 	}
 	if s.cmd != nil {
-		_ = s.cmd.Wait()
+		_ = waitTracked(s.cmd)
 	}
 	s.cancel = nil
 	s.cmd = nil
 	if s.stdout != nil {
 		_ = s.stdout.Close()
 		s.stdout = nil
+		s.reader = nil
 	}
 }
 
@@ -96,31 +141,47 @@ func (s *FrameStream) NeedsRestart(width, height, fps, videoWidth int) bool {
 		s.targetFPS != fps || s.videoWidth != videoWidth
 }
 
-// NextFrame reads the next BMP frame from the stream.
+// NextFrame reads the next frame from the stream as an image chafa can
+// decode. Raw frames are read into a buffer that is reused by the next call,
+// so the result is only valid until then. Corrupt BMP/PPM frames are skipped
+// by scanning ahead to the next frame signature, so a single bad header no
+// longer forces a stream restart.
 func (s *FrameStream) NextFrame() ([]byte, error) {
 	s.mu.Lock()
-	stdout := s.stdout
+	reader := s.reader
 	s.mu.Unlock()
-	if stdout == nil {
+	if reader == nil {
 		return nil, io.EOF
 	}
 
-	header := make([]byte, 14)
-	if _, err := io.ReadFull(stdout, header); err != nil {
-		return nil, err
-	}
-	if header[0] != 'B' || header[1] != 'M' {
-		return nil, fmt.Errorf("invalid frame header")
+	if s.format == FrameRaw {
+		// Fixed-size frames: no header to parse, nothing to resync on
+		if _, err := io.ReadFull(reader, s.rawBuf[s.rawOffset:]); err != nil {
+			return nil, err
+		}
+		return s.rawBuf, nil
 	}
-	frameSize := binary.LittleEndian.Uint32(header[2:6])
-	if frameSize < 14 {
-		return nil, fmt.Errorf("invalid frame size")
+
+	frame, skipped, err := readFrame(reader, s.format)
+	if skipped > 0 {
+		s.mu.Lock()
+		s.corrupt += skipped
+		s.mu.Unlock()
 	}
+	return frame, err
+}
 
-	frame := make([]byte, frameSize)
-	copy(frame, header)
-	if _, err := io.ReadFull(stdout, frame[14:frameSize]); err != nil {
-		return nil, err
+// Release hands a frame returned by NextFrame back for reuse. The frame must
+// not be used afterwards.
+func (s *FrameStream) Release(frame []byte) {
+	if s.format != FrameRaw {
+		putFrameBuf(frame)
 	}
-	return frame, nil
+}
+
+// CorruptFrames returns how many frames have been skipped as unreadable.
+func (s *FrameStream) CorruptFrames() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.corrupt
 }
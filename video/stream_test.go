@@ -0,0 +1,68 @@
+package video_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"lazycut/video"
+	"lazycut/video/videotest"
+)
+
+func TestFrameStreamRestart(t *testing.T) {
+	fake := fakeRunner(t)
+	const w, h = 320, 180
+	frames := videotest.SyntheticFrames(w, h, 3)
+	fake.Respond("ffmpeg", videotest.Response{Stdout: frames})
+	ctx := context.Background()
+
+	stream, err := video.NewFrameStream(ctx, "in.mp4", 0, 80, 24, 24, w, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 3 {
+		frame, err := stream.NextFrame()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		// The frame is a PPM header and the rgb24 pixels as sent
+		size := w * h * 3
+		if !bytes.HasPrefix(frame, []byte("P6")) || !bytes.Equal(frame[len(frame)-size:], frames[i*size:(i+1)*size]) {
+			t.Errorf("frame %d does not hold the sent pixels", i)
+		}
+		stream.Release(frame)
+	}
+	if _, err := stream.NextFrame(); err == nil {
+		t.Error("no error after the last frame")
+	}
+
+	if stream.NeedsRestart(80, 24, 24, w) {
+		t.Error("restart needed with the same settings")
+	}
+	for _, c := range []struct{ width, height, fps, videoWidth int }{
+		{100, 24, 24, w}, {80, 30, 24, w}, {80, 24, 12, w}, {80, 24, 24, 640},
+	} {
+		if !stream.NeedsRestart(c.width, c.height, c.fps, c.videoWidth) {
+			t.Errorf("no restart needed for %+v", c)
+		}
+	}
+	stream.Close()
+	if _, err := stream.NextFrame(); err == nil {
+		t.Error("closed stream still reads")
+	}
+
+	again, err := video.NewFrameStream(ctx, "in.mp4", 1500*time.Millisecond, 100, 24, 24, w, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Close()
+	calls := fake.CallsTo("ffmpeg")
+	if len(calls) != 2 {
+		t.Fatalf("%d ffmpeg runs, want 2", len(calls))
+	}
+	if i := slices.Index(calls[1].Args, "-ss"); i < 0 || calls[1].Args[i+1] != "1.500" {
+		t.Errorf("restarted stream does not seek to 1.5s: %q", calls[1].Args)
+	}
+}
@@ -0,0 +1,145 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Cue is one timed line of a transcript or subtitle file.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// sidecarExts are checked in order next to the video.
+var sidecarExts = []string{".srt", ".vtt", ".en.srt", ".en.vtt"}
+
+// FindSidecarSubtitles returns the path of a subtitle file sharing the
+// video's base name, or "" if there is none.
+func FindSidecarSubtitles(videoPath string) string {
+	base := strings.TrimSuffix(videoPath, filepath.Ext(videoPath))
+	for _, ext := range sidecarExts {
+		if fileExists(base + ext) {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+// LoadTranscript loads cues from a sidecar .srt/.vtt, falling back to the
+// first subtitle stream embedded in the video. It returns (nil, nil) when
+// the video has no subtitles at all.
+func LoadTranscript(ctx context.Context, videoPath string) ([]Cue, error) {
+	if sidecar := FindSidecarSubtitles(videoPath); sidecar != "" {
+		f, err := os.Open(sidecar)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseSubtitles(f)
+	}
+
+	probe := command(ctx, "ffprobe",
+		"-v", "error",
+		"-select_streams", "s",
+		"-show_entries", "stream=index",
+		"-of", "csv=p=0",
+		videoPath,
+	)
+	streams, err := probe.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	if len(bytes.TrimSpace(streams)) == 0 {
+		return nil, nil
+	}
+
+	cmd := command(ctx, "ffmpeg",
+		"-i", videoPath,
+		"-map", "0:s:0",
+		"-f", "srt",
+		"-loglevel", "error",
+		"pipe:1",
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract subtitles: %w", err)
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+	return ParseSubtitles(bytes.NewReader(out))
+}
+
+var (
+	markupTags = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+	cueTiming  = regexp.MustCompile(`^\s*([\d:.,]+)\s*-->\s*([\d:.,]+)`)
+)
+
+// ParseSubtitles parses SubRip (.srt) or WebVTT (.vtt) text. Cue numbers,
+// VTT headers/settings and inline markup are dropped; malformed blocks are
+// skipped rather than failing the whole file.
+func ParseSubtitles(r io.Reader) ([]Cue, error) {
+	var cues []Cue
+	var current *Cue
+
+	flush := func() {
+		if current != nil && current.Text != "" {
+			cues = append(cues, *current)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		line = strings.TrimPrefix(line, "\ufeff")
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if m := cueTiming.FindStringSubmatch(line); m != nil {
+			flush()
+			start, err1 := parseCueTime(m[1])
+			end, err2 := parseCueTime(m[2])
+			if err1 == nil && err2 == nil && end >= start {
+				current = &Cue{Start: start, End: end}
+			}
+			continue
+		}
+		if current == nil {
+			// Cue numbers, WEBVTT header, NOTE blocks, cue identifiers
+			continue
+		}
+		text := strings.TrimSpace(markupTags.ReplaceAllString(line, ""))
+		if text == "" {
+			continue
+		}
+		if current.Text != "" {
+			current.Text += " "
+		}
+		current.Text += text
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+// parseCueTime accepts SRT ("00:01:02,500") and VTT ("01:02.500") stamps.
+func parseCueTime(s string) (time.Duration, error) {
+	return ParseTimecode(s)
+}
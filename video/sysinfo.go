@@ -0,0 +1,131 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"strings"
+)
+
+// SystemInfo is what a bug report needs about the helpers and terminal
+// lazycut runs with.
+type SystemInfo struct {
+	FFmpeg  string // versions; "not found" when the binary is missing
+	FFprobe string
+	Chafa   string
+
+	HWAccels   []string // decoders from `ffmpeg -hwaccels`, e.g. cuda, vaapi
+	HWEncoders []string // hardware H.264 encoders in this ffmpeg build
+
+	Terminal string // TERM and the emulator, plus tmux/screen
+	Graphics GraphicsMode
+}
+
+// DetectSystemInfo asks each helper for its version. It does not need
+// DetectCapabilities to have run, so --version works without a video.
+func DetectSystemInfo(ctx context.Context) SystemInfo {
+	info := SystemInfo{
+		FFmpeg:   toolVersion(ctx, "ffmpeg", "-version"),
+		FFprobe:  toolVersion(ctx, "ffprobe", "-version"),
+		Chafa:    toolVersion(ctx, "chafa", "--version"),
+		Terminal: describeTerminal(),
+		Graphics: graphicsMode,
+	}
+	if out, err := ffmpegOutput(ctx, "-hwaccels"); err == nil {
+		info.HWAccels = parseHWAccels(out)
+	}
+	var encoders map[string]bool
+	if caps := FFmpegCapabilities(); caps != nil {
+		encoders = caps.Encoders
+	} else {
+		encoders, _ = ffmpegList(ctx, "-encoders")
+	}
+	for _, name := range h264Encoders {
+		if name != "libx264" && encoders[name] {
+			info.HWEncoders = append(info.HWEncoders, name)
+		}
+	}
+	return info
+}
+
+// Lines formats the info as label/value pairs, in display order.
+func (s SystemInfo) Lines() [][2]string {
+	list := func(names []string) string {
+		if len(names) == 0 {
+			return "none"
+		}
+		return strings.Join(names, ", ")
+	}
+	return [][2]string{
+		{"ffmpeg", s.FFmpeg},
+		{"ffprobe", s.FFprobe},
+		{"chafa", s.Chafa},
+		{"hwaccel", list(s.HWAccels)},
+		{"hw enc", list(s.HWEncoders)},
+		{"terminal", s.Terminal},
+		{"graphics", s.Graphics.String()},
+	}
+}
+
+// toolVersion returns the word after "version" on the first line of the
+// tool's version output ("ffprobe version 6.1.1 Copyright ...", "Chafa
+// version 1.14.0").
+func toolVersion(ctx context.Context, name, flag string) string {
+	if _, err := lookPath(name); err != nil {
+		return "not found"
+	}
+	out, err := command(ctx, name, flag).Output()
+	if err != nil {
+		return "unknown"
+	}
+	firstLine, _, _ := strings.Cut(string(out), "\n")
+	fields := strings.Fields(firstLine)
+	for i, f := range fields {
+		if strings.EqualFold(f, "version") && i+1 < len(fields) {
+			return strings.TrimPrefix(fields[i+1], "n")
+		}
+	}
+	return "unknown"
+}
+
+// parseHWAccels reads `ffmpeg -hwaccels`: a heading, then one method per
+// line.
+func parseHWAccels(out []byte) []string {
+	var methods []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasSuffix(line, ":") {
+			continue
+		}
+		methods = append(methods, line)
+	}
+	return methods
+}
+
+// describeTerminal names the terminal from the environment, e.g.
+// "xterm-256color (iTerm.app 3.5.0, truecolor) in tmux".
+func describeTerminal() string {
+	term := os.Getenv("TERM")
+	if term == "" {
+		term = "unknown"
+	}
+	var details []string
+	if program := os.Getenv("TERM_PROGRAM"); program != "" {
+		details = append(details, strings.TrimSpace(program+" "+os.Getenv("TERM_PROGRAM_VERSION")))
+	}
+	if colors := os.Getenv("COLORTERM"); colors != "" {
+		details = append(details, colors)
+	}
+	if len(details) > 0 {
+		term += " (" + strings.Join(details, ", ") + ")"
+	}
+	switch {
+	case os.Getenv("TMUX") != "":
+		term += " in tmux"
+	case os.Getenv("STY") != "":
+		term += " in screen"
+	}
+	return term
+}
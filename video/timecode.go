@@ -0,0 +1,154 @@
+package video
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatTimecode formats d as HH:MM:SS.mmm.
+func FormatTimecode(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, ms%1000)
+}
+
+// FileTimecode is FormatTimecode for file names, with dashes for the
+// colons Windows does not allow: "00-01-02.500".
+func FileTimecode(d time.Duration) string {
+	return strings.ReplaceAll(FormatTimecode(d), ":", "-")
+}
+
+// ParseTimecode reads a position or length the way people type it: a
+// timecode HH:MM:SS.mmm whose leading fields may be omitted ("1:23", "83",
+// "00:01:23.500", ".5"), or units of h, m, s and ms ("1m23s", "1h 2m",
+// "1.5s", "500ms"). The decimal separator may also be a comma, as in much of
+// Europe and in SRT files ("1:23,5"). Fractions finer than nanoseconds
+// are rejected rather than rounded.
+func ParseTimecode(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty timestamp")
+	}
+	if strings.ContainsAny(strings.ToLower(s), "hms") {
+		return parseUnits(s)
+	}
+
+	whole, frac, hasFrac := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	parts := strings.Split(whole, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q: use HH:MM:SS.mmm", s)
+	}
+
+	var total time.Duration
+	for i, part := range parts {
+		if part == "" && hasFrac && len(parts) == 1 {
+			// ".5", half a second
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || part[0] == '+' {
+			return 0, fmt.Errorf("invalid timestamp %q: use HH:MM:SS.mmm", s)
+		}
+		// Minutes and seconds must stay below 60 once a larger unit is given
+		if i > 0 && n >= 60 {
+			return 0, fmt.Errorf("invalid timestamp %q: %d is out of range", s, n)
+		}
+		total = total*60 + time.Duration(n)*time.Second
+	}
+
+	if hasFrac {
+		f, err := parseFraction(frac)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q: %w", s, err)
+		}
+		total += f
+	}
+	return total, nil
+}
+
+// timeUnits are the units parseUnits takes, largest first; ms comes
+// before m so that it is tried first.
+var timeUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"h", time.Hour},
+	{"ms", time.Millisecond},
+	{"m", time.Minute},
+	{"s", time.Second},
+}
+
+// parseUnits reads "1h2m3.5s" and the like: numbers each followed by a
+// unit, from the largest down, optionally spaced.
+func parseUnits(s string) (time.Duration, error) {
+	rest := strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	var total time.Duration
+	last := time.Duration(-1)
+	for rest != "" {
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ','
+		})
+		if end <= 0 {
+			return 0, fmt.Errorf("invalid timestamp %q: use 1:23, 83 or 1m23s", s)
+		}
+		number := rest[:end]
+		rest = rest[end:]
+		unit := -1
+		for i, u := range timeUnits {
+			if strings.HasPrefix(rest, u.name) {
+				unit = i
+				break
+			}
+		}
+		if unit < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q: unknown unit in %q", s, rest)
+		}
+		u := timeUnits[unit]
+		rest = rest[len(u.name):]
+		if last >= 0 && u.size >= last {
+			return 0, fmt.Errorf("invalid timestamp %q: %s out of order", s, u.name)
+		}
+		last = u.size
+
+		whole, frac, hasFrac := strings.Cut(strings.Replace(number, ",", ".", 1), ".")
+		n := 0
+		if whole != "" {
+			var err error
+			if n, err = strconv.Atoi(whole); err != nil {
+				return 0, fmt.Errorf("invalid timestamp %q: use 1:23, 83 or 1m23s", s)
+			}
+		} else if !hasFrac {
+			return 0, fmt.Errorf("invalid timestamp %q: use 1:23, 83 or 1m23s", s)
+		}
+		total += time.Duration(n) * u.size
+		if hasFrac {
+			f, err := parseFraction(frac)
+			if err != nil {
+				return 0, fmt.Errorf("invalid timestamp %q: %w", s, err)
+			}
+			// f is the fraction of a second; scale it to the unit
+			total += time.Duration(float64(f) * float64(u.size) / float64(time.Second))
+		}
+	}
+	return total, nil
+}
+
+// parseFraction reads the digits after the decimal separator as a
+// fraction of a second.
+func parseFraction(digits string) (time.Duration, error) {
+	if len(digits) == 0 || len(digits) > 9 {
+		return 0, fmt.Errorf("use 1 to 9 fractional digits")
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 || digits[0] == '+' || digits[0] == '-' {
+		return 0, fmt.Errorf("invalid fraction %q", digits)
+	}
+	for i := len(digits); i < 9; i++ {
+		n *= 10
+	}
+	return time.Duration(n), nil
+}
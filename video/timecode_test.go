@@ -0,0 +1,102 @@
+package video_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lazycut/video"
+)
+
+func TestParseTimecode(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		// Clock forms, leading fields optional
+		{"83", 83 * time.Second},
+		{"1:23", 83 * time.Second},
+		{"01:23", 83 * time.Second},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"00:01:23.500", 83*time.Second + 500*time.Millisecond},
+		{"00:01:23.50", 83*time.Second + 500*time.Millisecond},
+		{"00:01:23.05", 83*time.Second + 50*time.Millisecond},
+		{"00:00:00.000000001", 1},
+		{"100:00:00", 100 * time.Hour},
+		{"0", 0},
+		{"  1:23  ", 83 * time.Second},
+
+		// Fractions with nothing before them and comma separators
+		{".5", 500 * time.Millisecond},
+		{",25", 250 * time.Millisecond},
+		{"1:23,5", 83*time.Second + 500*time.Millisecond},
+		{"00:00:01,250", 1250 * time.Millisecond},
+		{"59,5", 59*time.Second + 500*time.Millisecond},
+
+		// Units, largest first, optionally spaced
+		{"1m23s", 83 * time.Second},
+		{"1h 2m", time.Hour + 2*time.Minute},
+		{"1h2m3s", time.Hour + 2*time.Minute + 3*time.Second},
+		{"1.5s", 1500 * time.Millisecond},
+		{".5s", 500 * time.Millisecond},
+		{"1,5m", 90 * time.Second},
+		{"500ms", 500 * time.Millisecond},
+		{"2s500ms", 2500 * time.Millisecond},
+		{"1M30S", 90 * time.Second},
+	} {
+		got, err := video.ParseTimecode(tc.in)
+		if err != nil {
+			t.Errorf("ParseTimecode(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseTimecode(%q) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseTimecodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", "empty timestamp"},
+		{"   ", "empty timestamp"},
+		{"1:2:3:4", "use HH:MM:SS.mmm"},
+		{"1:60", "60 is out of range"},
+		{"1:00:60", "60 is out of range"},
+		{"-5", "use HH:MM:SS.mmm"},
+		{"+5", "use HH:MM:SS.mmm"},
+		{"1::2", "use HH:MM:SS.mmm"},
+		{":30", "use HH:MM:SS.mmm"},
+		{"abc", "use HH:MM:SS.mmm"},
+		{".", "use 1 to 9 fractional digits"},
+		{"1.", "use 1 to 9 fractional digits"},
+		{"1.0000000001", "use 1 to 9 fractional digits"},
+		{"1.-5", "invalid fraction"},
+		{"1.5.5", "invalid fraction"},
+		{"1x", "use HH:MM:SS.mmm"},
+		{"1m1h", "h out of order"},
+		{"1s1s", "s out of order"},
+		{"5h3x", "unknown unit"},
+		{"s", "use 1:23, 83 or 1m23s"},
+		{"1.s", "use 1 to 9 fractional digits"},
+	} {
+		_, err := video.ParseTimecode(tc.in)
+		if err == nil {
+			t.Errorf("ParseTimecode(%q) accepted", tc.in)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseTimecode(%q): %v, want %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestFormatTimecodeRoundTrip(t *testing.T) {
+	for _, d := range []time.Duration{0, 500 * time.Millisecond, 83*time.Second + 250*time.Millisecond, 25*time.Hour + time.Millisecond} {
+		got, err := video.ParseTimecode(video.FormatTimecode(d))
+		if err != nil || got != d {
+			t.Errorf("%s: formatted as %q, read back as %s (%v)", d, video.FormatTimecode(d), got, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TitleCard is generated text shown before the export, on black or on a
+// blurred still of its first frame.
+type TitleCard struct {
+	Text     string
+	Duration time.Duration
+	Blur     bool
+}
+
+// CanTitleCard reports whether this ffmpeg can draw text, which needs a
+// build with libfreetype.
+func (c *Capabilities) CanTitleCard() bool {
+	return c.HasFilter("drawtext")
+}
+
+func (opts ExportOptions) hasTitleCard() bool {
+	return opts.TitleCard.Text != "" && opts.TitleCard.Duration > 0
+}
+
+// titleInputArgs adds the source again, from the in-point, for the still
+// a blurred card is drawn on.
+func titleInputArgs(opts ExportOptions, input string) []string {
+	if !opts.hasTitleCard() || !opts.TitleCard.Blur {
+		return nil
+	}
+	return seekInput(seekRange, opts.InPoint, input, "-t", "1")
+}
+
+// titleCardVideo is the filter chain of a w x h card, reading its still
+// from ffmpeg input number input when blurred.
+func titleCardVideo(opts ExportOptions, w, h, input int) string {
+	card := opts.TitleCard
+	d := card.Duration.Seconds()
+	background := fmt.Sprintf("color=c=black:s=%dx%d:d=%.3f", w, h, d)
+	if card.Blur {
+		background = fmt.Sprintf("[%d:v]trim=end_frame=1,setpts=PTS-STARTPTS,"+
+			"scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,boxblur=20:2,"+
+			"tpad=stop_mode=clone:stop_duration=%.3f,trim=duration=%.3f", input, w, h, w, h, d, d)
+	}
+	// Fit the line across 90% of the width at about 0.6em a character
+	n := max(1, len([]rune(card.Text)))
+	size := max(12, min(min(w, h)/10, w*3/(2*n)))
+	return background + fmt.Sprintf(",drawtext=text=%s:expansion=none:fontcolor=white:fontsize=%d:"+
+		"shadowcolor=black@0.6:shadowx=2:shadowy=2:x=(w-text_w)/2:y=(h-text_h)/2", drawtextEscape(card.Text), size)
+}
+
+// drawtextEscape makes s a literal drawtext text= value inside a filter
+// graph: escaped once for the option parser, then again for the graph.
+func drawtextEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`).Replace(s)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
+}
@@ -0,0 +1,183 @@
+package video
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var errNoTrash = errors.New("no trash on this system")
+
+// HasTrash reports whether MoveToTrash has a trash to move files to on
+// this system.
+func HasTrash() bool {
+	return runtime.GOOS != "windows"
+}
+
+// MoveToTrash moves path to the trash, where the file manager can restore
+// it from: ~/.Trash on macOS, and elsewhere the freedesktop.org trash,
+// the home one or, for files on another filesystem, the one at the top of
+// theirs where it can be made there.
+func MoveToTrash(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		return trashInto(filepath.Join(home, ".Trash"), abs, nil)
+	case "windows":
+		return errNoTrash
+	}
+	return xdgTrash(abs)
+}
+
+// xdgTrash follows the freedesktop.org trash spec: the file goes into
+// files/ of the trash directory, with a .trashinfo in info/ saying where
+// it came from and when.
+func xdgTrash(abs string) error {
+	home := os.Getenv("XDG_DATA_HOME")
+	if home == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		home = filepath.Join(dir, ".local", "share")
+	}
+	if sameDevice(abs, home) {
+		return xdgTrashAt(abs, home, "")
+	}
+	return xdgTrashAt(abs, home, mountPoint(abs))
+}
+
+// xdgTrashAt trashes abs into the home trash under home or, with top set,
+// the trash at the top of abs's filesystem, so the move stays a rename.
+// The info then gives the path relative to that top. Where top has no
+// trash and cannot get one, as when it is "/" or read-only, the home
+// trash takes the file instead.
+func xdgTrashAt(abs, home, top string) error {
+	if top != "" {
+		trash := filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid()))
+		rel, err := filepath.Rel(top, abs)
+		if err != nil {
+			return err
+		}
+		err = trashInto(filepath.Join(trash, "files"), abs, func(name string) (string, error) {
+			return writeTrashInfo(filepath.Join(trash, "info"), name, rel)
+		})
+		if err == nil {
+			return nil
+		}
+	}
+	homeTrash := filepath.Join(home, "Trash")
+	return trashInto(filepath.Join(homeTrash, "files"), abs, func(name string) (string, error) {
+		return writeTrashInfo(filepath.Join(homeTrash, "info"), name, abs)
+	})
+}
+
+// trashInto moves abs into dir under a name not taken yet there. claim,
+// if set, reserves the name first and returns a file to remove should the
+// rename fail.
+func trashInto(dir, abs string, claim func(name string) (string, error)) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	ext := filepath.Ext(abs)
+	stem := strings.TrimSuffix(filepath.Base(abs), ext)
+	for i := 1; i <= 999; i++ {
+		name := stem + ext
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+		if fileExists(filepath.Join(dir, name)) {
+			continue
+		}
+		var info string
+		if claim != nil {
+			var err error
+			info, err = claim(name)
+			if os.IsExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := moveFile(abs, filepath.Join(dir, name)); err != nil {
+			if info != "" {
+				os.Remove(info)
+			}
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("no free name for %s in %s", filepath.Base(abs), dir)
+}
+
+// moveFile renames src to dst, copying it over and removing it when they
+// are on different filesystems.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst)
+		return err
+	}
+	os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
+
+// writeTrashInfo creates info/name.trashinfo for a file that was at
+// origPath, failing if another trashing took the name first.
+func writeTrashInfo(dir, name, origPath string) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name+".trashinfo")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	escaped := (&url.URL{Path: filepath.ToSlash(origPath)}).EscapedPath()
+	_, err = fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		escaped, time.Now().Format("2006-01-02T15:04:05"))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
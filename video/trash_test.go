@@ -0,0 +1,69 @@
+package video
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// trashFixture is a clip under top and an empty home for the trash.
+func trashFixture(t *testing.T) (abs, home, top string) {
+	t.Helper()
+	if !HasTrash() {
+		t.Skip("no trash on", runtime.GOOS)
+	}
+	dir := t.TempDir()
+	top = filepath.Join(dir, "top")
+	home = filepath.Join(dir, "home")
+	abs = filepath.Join(top, "clip.mp4")
+	if err := os.MkdirAll(top, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(abs, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return abs, home, top
+}
+
+// checkTrashed fails unless abs was moved into trash with info giving
+// path.
+func checkTrashed(t *testing.T, abs, trash, path string) {
+	t.Helper()
+	if _, err := os.Stat(abs); !os.IsNotExist(err) {
+		t.Errorf("%s is still there: %v", abs, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(trash, "files", "clip.mp4")); err != nil || string(data) != "video" {
+		t.Errorf("trashed file: %q, %v", data, err)
+	}
+	info, err := os.ReadFile(filepath.Join(trash, "info", "clip.mp4.trashinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(info), "\nPath="+path+"\n") {
+		t.Errorf("trash info %q does not give Path=%s", info, path)
+	}
+}
+
+func TestTrashTop(t *testing.T) {
+	abs, home, top := trashFixture(t)
+	if err := xdgTrashAt(abs, home, top); err != nil {
+		t.Fatal(err)
+	}
+	checkTrashed(t, abs, filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid())), "clip.mp4")
+}
+
+func TestTrashTopFallsBackToHome(t *testing.T) {
+	abs, home, top := trashFixture(t)
+	// A file where the trash would go stands in for a top it cannot be
+	// made in, as "/" or a read-only mount
+	if err := os.WriteFile(filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid())), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := xdgTrashAt(abs, home, top); err != nil {
+		t.Fatal(err)
+	}
+	checkTrashed(t, abs, filepath.Join(home, "Trash"), abs)
+}
@@ -0,0 +1,54 @@
+//go:build !windows
+
+package video
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// device is the filesystem path is on, going by its closest existing
+// parent when path itself is not there yet.
+func device(path string) (uint64, bool) {
+	for {
+		if fi, err := os.Stat(path); err == nil {
+			if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+				return uint64(st.Dev), true
+			}
+			return 0, false
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return 0, false
+		}
+		path = parent
+	}
+}
+
+// sameDevice reports whether a and b are on the same filesystem, so that
+// one can be renamed next to the other.
+func sameDevice(a, b string) bool {
+	da, okA := device(a)
+	db, okB := device(b)
+	return okA && okB && da == db
+}
+
+// mountPoint is the top directory of the filesystem abs is on.
+func mountPoint(abs string) string {
+	dev, ok := device(abs)
+	if !ok {
+		return filepath.Dir(abs)
+	}
+	dir := filepath.Dir(abs)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if d, ok := device(parent); !ok || d != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,13 @@
+package video
+
+import "path/filepath"
+
+// sameDevice and mountPoint are never reached: MoveToTrash has no trash
+// to offer on Windows.
+func sameDevice(a, b string) bool {
+	return filepath.VolumeName(a) == filepath.VolumeName(b)
+}
+
+func mountPoint(abs string) string {
+	return filepath.VolumeName(abs) + `\`
+}
@@ -0,0 +1,139 @@
+// Package videotest provides a fake video.Runner so code that shells out
+// to ffmpeg, ffprobe, ffplay or chafa can be exercised without them.
+//
+// Faked commands re-execute the current binary, which must hand control
+// to RunHelper before doing anything else; for tests that is TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		videotest.RunHelper()
+//		os.Exit(m.Run())
+//	}
+package videotest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// Environment protocol between FakeRunner and RunHelper.
+const (
+	envHelper = "LAZYCUT_VIDEOTEST_HELPER"
+	envStdout = "LAZYCUT_VIDEOTEST_STDOUT"
+	envStderr = "LAZYCUT_VIDEOTEST_STDERR"
+	envExit   = "LAZYCUT_VIDEOTEST_EXIT"
+)
+
+// Response is what a faked command prints and how it exits.
+type Response struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// Call is one recorded command invocation.
+type Call struct {
+	Name string
+	Args []string
+}
+
+// FakeRunner implements video.Runner. Commands answer with the Response
+// registered for their binary name (an empty, successful one by default)
+// and every invocation is recorded for inspection.
+type FakeRunner struct {
+	// Dir holds the canned output files; use a per-test temp dir.
+	Dir string
+	// Missing lists binaries LookPath should report as not installed.
+	Missing map[string]bool
+
+	mu        sync.Mutex
+	responses map[string]Response
+	calls     []Call
+	files     int
+}
+
+// NewFakeRunner returns a runner that keeps its files in dir.
+func NewFakeRunner(dir string) *FakeRunner {
+	return &FakeRunner{Dir: dir, Missing: map[string]bool{}, responses: map[string]Response{}}
+}
+
+// Respond sets the response for every later command running name.
+func (f *FakeRunner) Respond(name string, r Response) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[name] = r
+}
+
+// Calls returns the invocations so far, oldest first.
+func (f *FakeRunner) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// CallsTo returns the invocations of one binary.
+func (f *FakeRunner) CallsTo(name string) []Call {
+	var out []Call
+	for _, c := range f.Calls() {
+		if c.Name == name {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (f *FakeRunner) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Name: name, Args: append([]string(nil), args...)})
+	resp := f.responses[name]
+	f.files++
+	n := f.files
+	f.mu.Unlock()
+
+	self, err := os.Executable()
+	if err != nil {
+		self = os.Args[0]
+	}
+	cmd := exec.CommandContext(ctx, self)
+	cmd.Env = append(os.Environ(),
+		envHelper+"=1",
+		envExit+"="+strconv.Itoa(resp.ExitCode),
+		envStdout+"="+f.writeFile(fmt.Sprintf("%d.stdout", n), resp.Stdout),
+		envStderr+"="+f.writeFile(fmt.Sprintf("%d.stderr", n), resp.Stderr),
+	)
+	return cmd
+}
+
+func (f *FakeRunner) writeFile(name string, data []byte) string {
+	path := filepath.Join(f.Dir, name)
+	// A failed write surfaces as missing output in the code under test
+	_ = os.WriteFile(path, data, 0o600)
+	return path
+}
+
+func (f *FakeRunner) LookPath(name string) (string, error) {
+	if f.Missing[name] {
+		return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+	}
+	return filepath.Join(f.Dir, "bin", name), nil
+}
+
+// RunHelper turns the process into a faked command when it was started by
+// a FakeRunner, and returns immediately otherwise.
+func RunHelper() {
+	if os.Getenv(envHelper) != "1" {
+		return
+	}
+	if data, err := os.ReadFile(os.Getenv(envStdout)); err == nil {
+		os.Stdout.Write(data)
+	}
+	if data, err := os.ReadFile(os.Getenv(envStderr)); err == nil {
+		os.Stderr.Write(data)
+	}
+	code, _ := strconv.Atoi(os.Getenv(envExit))
+	os.Exit(code)
+}
@@ -0,0 +1,141 @@
+package videotest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"lazycut/video"
+)
+
+// Fixture describes a procedural test video. Generated files are tiny
+// (color bars and a sine tone), so they are built at test time instead of
+// being checked in.
+type Fixture struct {
+	Width    int
+	Height   int
+	FPS      int
+	Duration time.Duration
+	Audio    bool
+}
+
+// SmallFixture is a landscape clip small enough for every test to use.
+var SmallFixture = Fixture{Width: 320, Height: 180, FPS: 25, Duration: 2 * time.Second, Audio: true}
+
+// Name is the fixture's file name; equal specs share a file.
+func (f Fixture) Name() string {
+	audio := ""
+	if f.Audio {
+		audio = "_audio"
+	}
+	return fmt.Sprintf("bars_%dx%d_%dfps_%dms%s.mp4", f.Width, f.Height, f.FPS, f.Duration.Milliseconds(), audio)
+}
+
+// Generate writes the fixture into dir with the real ffmpeg, reusing an
+// existing file, and returns its path. It needs ffmpeg with lavfi.
+func (f Fixture) Generate(ctx context.Context, dir string) (string, error) {
+	path := filepath.Join(dir, f.Name())
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return "", fmt.Errorf("fixtures need ffmpeg: %w", err)
+	}
+
+	secs := fmt.Sprintf("%.3f", f.Duration.Seconds())
+	args := []string{"-y", "-loglevel", "error",
+		"-f", "lavfi", "-i", fmt.Sprintf("testsrc2=size=%dx%d:rate=%d:duration=%s", f.Width, f.Height, f.FPS, secs),
+	}
+	if f.Audio {
+		args = append(args, "-f", "lavfi", "-i", "sine=frequency=440:duration="+secs)
+	}
+	// mpeg4 ships with every ffmpeg build, unlike libx264
+	args = append(args, "-c:v", "mpeg4", "-pix_fmt", "yuv420p", "-g", strconv.Itoa(f.FPS))
+	if f.Audio {
+		args = append(args, "-c:a", "aac", "-shortest")
+	}
+
+	// Write next to the target and rename so parallel tests never see a
+	// half-written file
+	tmp := path + ".tmp.mp4"
+	args = append(args, tmp)
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("generating %s: %w: %s", f.Name(), err, strings.TrimSpace(stderr.String()))
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// Golden compares got with the golden file at path. With update set it
+// rewrites the file instead, for regenerating goldens after an intended
+// change (conventionally wired to a -update test flag).
+func Golden(path string, got []byte, update bool) error {
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, got, 0o644)
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading golden file (run with -update to create it): %w", err)
+	}
+	if !bytes.Equal(want, got) {
+		return fmt.Errorf("%s mismatch:\n--- want\n%s\n--- got\n%s", filepath.Base(path), want, got)
+	}
+	return nil
+}
+
+// ExportCase is one named combination of export options.
+type ExportCase struct {
+	Name string
+	Opts video.ExportOptions
+}
+
+// ExportCases crosses every aspect ratio with the options that change the
+// ffmpeg invocation (freezes, crop offsets, frame-exact cuts) for a
+// 1920x1080 and a 1080x1920 source, for golden tables of built commands.
+func ExportCases(input string) []ExportCase {
+	sources := []struct {
+		name string
+		w, h int
+	}{{"landscape", 1920, 1080}, {"portrait", 1080, 1920}}
+	variants := []struct {
+		name  string
+		apply func(*video.ExportOptions)
+	}{
+		{"plain", func(*video.ExportOptions) {}},
+		{"freeze", func(o *video.ExportOptions) { o.FreezeStart, o.FreezeEnd = time.Second, 2*time.Second }},
+		{"offset", func(o *video.ExportOptions) { o.CropOffset = -0.5 }},
+		{"exact", func(o *video.ExportOptions) { o.FrameExact = true }},
+	}
+
+	var cases []ExportCase
+	for _, src := range sources {
+		for _, ratio := range video.AspectRatioOptions {
+			for _, v := range variants {
+				opts := video.ExportOptions{
+					Input:       input,
+					Output:      "out.mp4",
+					InPoint:     1500 * time.Millisecond,
+					OutPoint:    4 * time.Second,
+					AspectRatio: ratio.Ratio,
+					Width:       src.w,
+					Height:      src.h,
+				}
+				v.apply(&opts)
+				cases = append(cases, ExportCase{Name: src.name + "/" + ratio.Label + "/" + v.name, Opts: opts})
+			}
+		}
+	}
+	return cases
+}
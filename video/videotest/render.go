@@ -0,0 +1,48 @@
+package videotest
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"lazycut/video"
+)
+
+// FakeRenderer implements video.FrameRenderer without chafa. It returns a
+// block of width x height cells so layout code sees realistic output, and
+// counts frames so benchmarks can report frames per second.
+type FakeRenderer struct {
+	frames atomic.Int64
+}
+
+func (r *FakeRenderer) RenderFrame(_ context.Context, frame []byte, width, height int, _ video.QualityPreset) (string, error) {
+	r.frames.Add(1)
+	// Touch the pixels like a real renderer would
+	var sum byte
+	for i := 0; i < len(frame); i += 64 {
+		sum += frame[i]
+	}
+	cell := string(rune('A' + sum%26))
+	line := strings.Repeat(cell, max(0, width))
+	return strings.TrimSuffix(strings.Repeat(line+"\n", max(0, height)), "\n"), nil
+}
+
+// Frames returns how many frames were rendered.
+func (r *FakeRenderer) Frames() int64 {
+	return r.frames.Load()
+}
+
+// SyntheticFrames returns n rgb24 frames of w x h as ffmpeg's rawvideo
+// output would deliver them, each a different gradient. Feed it to a
+// FakeRunner's ffmpeg response to drive a FrameStream without decoding.
+func SyntheticFrames(w, h, n int) []byte {
+	size := w * h * 3
+	out := make([]byte, size*n)
+	for f := 0; f < n; f++ {
+		frame := out[f*size : (f+1)*size]
+		for i := range frame {
+			frame[i] = byte(i/3 + f*7)
+		}
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package video
+
+import (
+	"context"
+	"time"
+)
+
+// warmFrames is how many frames on each side of a trim point WarmCache
+// renders ahead.
+const warmFrames = 3
+
+// warmCapacity holds the frames around both trim points at two sizes or
+// qualities.
+const warmCapacity = 2 * 2 * (2*warmFrames + 1)
+
+// WarmCache renders the frames around points at the current size and
+// quality, in the background, so jumping to a trim point and stepping
+// across it shows cached frames at once. They are kept apart from the
+// playback cache, which a few seconds of playing would flush. Each call
+// replaces the previous one's unfinished work. It reports false when the
+// preview has no size yet and nothing could be started.
+func (p *Player) WarmCache(points ...time.Duration) bool {
+	p.mu.Lock()
+	if p.warmCancel != nil {
+		p.warmCancel()
+	}
+	ctx, cancel := context.WithCancel(p.ctx)
+	p.warmCancel = cancel
+	width, height := p.width, p.height
+	quality := p.quality
+	step := p.frameDuration()
+	p.mu.Unlock()
+
+	if width <= 0 || height <= 0 {
+		return false
+	}
+
+	// The points themselves first, then outwards one frame at a time
+	var positions []time.Duration
+	for i := 0; i <= warmFrames; i++ {
+		for _, point := range points {
+			for _, pos := range []time.Duration{point - time.Duration(i)*step, point + time.Duration(i)*step} {
+				if pos >= 0 && pos <= p.duration {
+					positions = append(positions, pos)
+				}
+			}
+		}
+	}
+
+	filters := p.previewFilters()
+	var r terminalRenderer
+	go func() {
+		for _, pos := range positions {
+			if ctx.Err() != nil {
+				return
+			}
+			if _, ok := p.warm.Get(pos, width, height, quality); ok {
+				continue
+			}
+			frame, shown, ok := p.cache.GetShown(pos, width, height, quality)
+			if !ok {
+				var err error
+				if frame, shown, err = r.renderStillAt(ctx, seekStill, p.path, pos, filters, width, height, quality); err != nil {
+					continue
+				}
+			}
+			p.warm.PutShown(pos, shown, width, height, quality, frame)
+		}
+	}()
+	return true
+}
@@ -0,0 +1,130 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// PeaksPerSecond is the resolution of Peaks: one value per 10ms is finer
+// than any terminal column needs, even zoomed to a few seconds.
+const PeaksPerSecond = 100
+
+// peakSampleRate is the rate audio is decoded at for peaks. Peaks only
+// need the envelope, so a low rate keeps decoding fast on long files.
+const peakSampleRate = 8000
+
+// Peaks is the audio envelope of a file: the loudest sample of each
+// 1/PeaksPerSecond slice as a fraction of full scale.
+type Peaks struct {
+	Values  []float32
+	Loudest float32 // largest value, for drawing quiet files at full height
+}
+
+// Duration is how much audio the peaks cover.
+func (p *Peaks) Duration() time.Duration {
+	return time.Duration(len(p.Values)) * time.Second / PeaksPerSecond
+}
+
+// LoadPeaks decodes the first audio stream of path into Peaks.
+func LoadPeaks(ctx context.Context, path string) (*Peaks, error) {
+	cmd := command(ctx, "ffmpeg",
+		"-i", path,
+		"-vn",
+		"-ac", "1",
+		"-ar", fmt.Sprint(peakSampleRate),
+		"-f", "s16le",
+		"-loglevel", "error",
+		"pipe:1",
+	)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	peaks, readErr := readPeaks(bufio.NewReader(stdout), peakSampleRate/PeaksPerSecond)
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("ffmpeg failed: %w", err)
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	if len(peaks.Values) == 0 {
+		return nil, fmt.Errorf("no audio")
+	}
+	return peaks, nil
+}
+
+// readPeaks folds 16-bit little-endian mono samples into one peak per
+// perSlice samples.
+func readPeaks(r io.Reader, perSlice int) (*Peaks, error) {
+	var values []float32
+	var loudest float32
+	buf := make([]byte, perSlice*2)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n >= 2 {
+			var peak int
+			for i := 0; i+1 < n; i += 2 {
+				s := int(int16(binary.LittleEndian.Uint16(buf[i:])))
+				if s < 0 {
+					s = -s
+				}
+				peak = max(peak, s)
+			}
+			v := float32(peak) / math.MaxInt16
+			loudest = max(loudest, v)
+			values = append(values, v)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Peaks{Values: values, Loudest: loudest}, nil
+}
+
+// Window resamples [from, to) into columns peaks. Columns outside the
+// audio are -1 so callers can draw them differently from silence.
+func (p *Peaks) Window(from, to time.Duration, columns int) []float32 {
+	out := make([]float32, columns)
+	if columns <= 0 || to <= from {
+		return out
+	}
+	span := float64(to-from) / float64(columns)
+	for c := range out {
+		start := int((float64(from) + span*float64(c)) * PeaksPerSecond / float64(time.Second))
+		end := int((float64(from) + span*float64(c+1)) * PeaksPerSecond / float64(time.Second))
+		if end <= start {
+			end = start + 1
+		}
+		if end <= 0 || start >= len(p.Values) {
+			out[c] = -1
+			continue
+		}
+		var peak float32
+		for i := max(start, 0); i < min(end, len(p.Values)); i++ {
+			peak = max(peak, p.Values[i])
+		}
+		out[c] = peak
+	}
+	return out
+}
+
+// Level is the peak over the span of audio ending at t, the window a
+// momentary meter shows, or -1 outside the audio.
+func (p *Peaks) Level(t, span time.Duration) float32 {
+	return p.Window(t-span, t, 1)[0]
+}
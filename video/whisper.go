@@ -0,0 +1,175 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// WhisperTool is a locally installed speech-to-text CLI.
+type WhisperTool struct {
+	Name string
+	Path string
+	cpp  bool // whisper.cpp (needs a ggml model file) vs. openai-whisper
+}
+
+// whisperCandidates are tried in order: whisper.cpp's current and legacy
+// binary names, then the Python openai-whisper CLI.
+var whisperCandidates = []struct {
+	name string
+	cpp  bool
+}{
+	{"whisper-cli", true},
+	{"whisper-cpp", true},
+	{"whisper", false},
+}
+
+// FindWhisper locates a whisper CLI on PATH.
+func FindWhisper() (WhisperTool, error) {
+	for _, c := range whisperCandidates {
+		if path, err := lookPath(c.name); err == nil {
+			return WhisperTool{Name: c.name, Path: path, cpp: c.cpp}, nil
+		}
+	}
+	return WhisperTool{}, fmt.Errorf("whisper not found. Install whisper.cpp (%s) or `pip install openai-whisper`",
+		getInstallCommand("whisper-cpp"))
+}
+
+// WhisperOptions configures a transcription run.
+type WhisperOptions struct {
+	// Model is a ggml model file for whisper.cpp, or a model name such
+	// as "base" for openai-whisper. Empty uses $LAZYCUT_WHISPER_MODEL,
+	// then "base" for openai-whisper.
+	Model string
+}
+
+// Transcribe extracts the audio track and runs whisper over it, returning
+// word-level cues.
+func Transcribe(ctx context.Context, tool WhisperTool, videoPath string, opts WhisperOptions) ([]Cue, error) {
+	model := opts.Model
+	if model == "" {
+		model = os.Getenv("LAZYCUT_WHISPER_MODEL")
+	}
+	if tool.cpp && model == "" {
+		return nil, fmt.Errorf("%s needs a model: set --whisper-model or LAZYCUT_WHISPER_MODEL to a ggml .bin file", tool.Name)
+	}
+	if model == "" {
+		model = "base"
+	}
+
+	dir, err := os.MkdirTemp("", "lazycut-whisper-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// Both CLIs want 16 kHz mono PCM
+	wav := filepath.Join(dir, "audio.wav")
+	extract := command(ctx, "ffmpeg", "-y",
+		"-i", videoPath,
+		"-vn", "-ac", "1", "-ar", "16000", "-c:a", "pcm_s16le",
+		"-loglevel", "error",
+		wav,
+	)
+	if out, err := extract.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to extract audio: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+
+	var args []string
+	srt := filepath.Join(dir, "audio.srt")
+	if tool.cpp {
+		// -ml 1 -sow: one word per segment, split on word boundaries
+		args = []string{"-m", model, "-f", wav, "-osrt", "-of", strings.TrimSuffix(srt, ".srt"), "-ml", "1", "-sow", "-np"}
+	} else {
+		args = []string{wav, "--model", model, "--output_format", "srt", "--output_dir", dir,
+			"--word_timestamps", "True", "--max_words_per_line", "1", "--verbose", "False"}
+	}
+	cmd := command(ctx, tool.Path, args...)
+	if err := startTracked(cmd); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", tool.Name, err)
+	}
+	if err := waitTracked(cmd); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s failed: %w", tool.Name, err)
+	}
+
+	f, err := os.Open(srt)
+	if err != nil {
+		return nil, fmt.Errorf("%s produced no transcript: %w", tool.Name, err)
+	}
+	defer f.Close()
+	return ParseSubtitles(f)
+}
+
+// GroupWords joins word cues into readable lines, breaking at sentence
+// punctuation, pauses longer than a second, or every 14 words.
+func GroupWords(words []Cue) []Cue {
+	const maxWords = 14
+	const maxGap = time.Second
+
+	var lines []Cue
+	var current *Cue
+	n := 0
+	for _, w := range words {
+		if current != nil && (w.Start-current.End > maxGap || n >= maxWords) {
+			lines = append(lines, *current)
+			current = nil
+		}
+		if current == nil {
+			current = &Cue{Start: w.Start, End: w.End, Text: w.Text}
+			n = 1
+		} else {
+			current.End = w.End
+			current.Text += " " + w.Text
+			n++
+		}
+		if strings.ContainsAny(w.Text[len(w.Text)-1:], ".?!") {
+			lines = append(lines, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		lines = append(lines, *current)
+	}
+	return lines
+}
+
+// FindQuote finds the first run of consecutive words at or after from that
+// spells quote (case and punctuation insensitive) and returns its bounds.
+func FindQuote(words []Cue, quote string, from time.Duration) (start, end time.Duration, ok bool) {
+	want := strings.Fields(normalizeWord(quote))
+	if len(want) == 0 {
+		return 0, 0, false
+	}
+	for i := range words {
+		if words[i].Start < from || i+len(want) > len(words) {
+			continue
+		}
+		match := true
+		for j, token := range want {
+			if normalizeWord(words[i+j].Text) != token {
+				match = false
+				break
+			}
+		}
+		if match {
+			return words[i].Start, words[i+len(want)-1].End, true
+		}
+	}
+	return 0, 0, false
+}
+
+func normalizeWord(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
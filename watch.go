@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"lazycut/ui"
+	"lazycut/video"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const watchUsage = `Usage: lazycut watch [flags] <dir>
+
+Waits for new recordings in dir and opens each one in the editor when it
+has finished writing, or with --profile exports it without opening it.
+
+Flags:
+      --profile NAME  Export each new file headlessly (discord, trim)
+      --interval D    How often to look for new files (default 2s)
+      --accessible    As for the editor`
+
+// watchProfile is a headless cut applied to every new recording.
+type watchProfile struct {
+	Name        string
+	Description string
+	TrimStart   time.Duration // cut from the start
+	TrimEnd     time.Duration // cut from the end
+	Container   video.Container
+}
+
+var watchProfiles = []watchProfile{
+	{"discord", "first/last 2s removed, MP4 with faststart for inline playback", 2 * time.Second, 2 * time.Second, video.ContainerMP4},
+	{"trim", "first/last 2s removed, source format", 2 * time.Second, 2 * time.Second, video.ContainerSource},
+}
+
+// watchExts are the extensions watch treats as recordings.
+var watchExts = []string{".mp4", ".mkv", ".mov", ".webm", ".m4v", ".flv", ".ts"}
+
+type watchOptions struct {
+	dir        string
+	profile    *watchProfile
+	interval   time.Duration
+	accessible bool
+}
+
+func parseWatchArgs(args []string) (watchOptions, error) {
+	var opts watchOptions
+	var profile string
+	fs := flag.NewFlagSet("lazycut watch", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&profile, "profile", "", "")
+	opts.interval = 2 * time.Second
+	fs.Var(durationFlag{&opts.interval}, "interval", "")
+	fs.BoolVar(&opts.accessible, "accessible", false, "")
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return opts, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != 1 {
+		return opts, errUsage
+	}
+	opts.dir = positional[0]
+	if opts.interval <= 0 {
+		return opts, fmt.Errorf("invalid --interval %s", opts.interval)
+	}
+	if profile != "" {
+		i := slices.IndexFunc(watchProfiles, func(p watchProfile) bool { return p.Name == profile })
+		if i < 0 {
+			var names []string
+			for _, p := range watchProfiles {
+				names = append(names, p.Name)
+			}
+			return opts, fmt.Errorf("unknown profile %q (want %s)", profile, strings.Join(names, ", "))
+		}
+		opts.profile = &watchProfiles[i]
+	}
+	return opts, nil
+}
+
+func runWatch(args []string) int {
+	opts, err := parseWatchArgs(args)
+	if err != nil {
+		if err != errUsage {
+			fmt.Println(err)
+		}
+		fmt.Println(watchUsage)
+		return 1
+	}
+	if info, err := os.Stat(opts.dir); err != nil || !info.IsDir() {
+		fmt.Printf("Not a directory: %s\n", opts.dir)
+		return 1
+	}
+
+	hooks, err := ui.LoadHooks("", ui.Config{})
+	if err != nil {
+		fmt.Println("hooks:", err)
+		return 1
+	}
+
+	ctx, cancel, err := startup(opts.accessible, video.DetectGraphics())
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer cancel()
+	defer video.KillHelpers()
+
+	w := newWatcher(opts.dir)
+	if opts.profile != nil {
+		fmt.Printf("Watching %s, exporting new recordings with %s (%s). Ctrl+C stops.\n",
+			opts.dir, opts.profile.Name, opts.profile.Description)
+	} else {
+		fmt.Printf("Watching %s, opening new recordings. Ctrl+C stops.\n", opts.dir)
+	}
+
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		}
+		for _, path := range w.ready(time.Now(), 2*opts.interval) {
+			if opts.profile == nil {
+				_, written := edit(ctx, path, options{accessible: opts.accessible}, ui.Options{
+					Accessible: opts.accessible,
+					Hooks:      hooks,
+				})
+				for _, f := range written {
+					w.ignore(f)
+				}
+				fmt.Printf("Watching %s...\n", opts.dir)
+				continue
+			}
+			output, err := exportWithProfile(ctx, path, *opts.profile)
+			if err != nil {
+				if ctx.Err() != nil {
+					return 0
+				}
+				fmt.Printf("%s: %v\n", filepath.Base(path), err)
+				continue
+			}
+			// The export lands in the watched folder; it is not a recording
+			w.ignore(output)
+			fmt.Printf("%s -> %s\n", filepath.Base(path), filepath.Base(output))
+		}
+	}
+}
+
+// watcher finds files that appear in a directory and reports them once
+// their size and modification time stop changing, so a recording still
+// being written is not picked up.
+type watcher struct {
+	dir     string
+	seen    map[string]bool
+	pending map[string]watchedFile
+}
+
+type watchedFile struct {
+	size   int64
+	mod    time.Time
+	stable time.Time // when size and mod were last seen changing
+}
+
+// newWatcher starts watching dir; files already there are ignored.
+func newWatcher(dir string) *watcher {
+	w := &watcher{dir: dir, seen: map[string]bool{}, pending: map[string]watchedFile{}}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		w.seen[filepath.Join(dir, e.Name())] = true
+	}
+	return w
+}
+
+func (w *watcher) ignore(path string) {
+	w.seen[path] = true
+	delete(w.pending, path)
+}
+
+// ready returns the new recordings unchanged for at least settle, oldest
+// first, and stops tracking them.
+func (w *watcher) ready(now time.Time, settle time.Duration) []string {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil
+	}
+	var ready []string
+	for _, e := range entries {
+		path := filepath.Join(w.dir, e.Name())
+		if w.seen[path] || e.IsDir() || video.IsPartPath(path) || !slices.Contains(watchExts, strings.ToLower(filepath.Ext(path))) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		prev, ok := w.pending[path]
+		if !ok || prev.size != info.Size() || !prev.mod.Equal(info.ModTime()) {
+			w.pending[path] = watchedFile{size: info.Size(), mod: info.ModTime(), stable: now}
+			continue
+		}
+		if info.Size() > 0 && now.Sub(prev.stable) >= settle {
+			ready = append(ready, path)
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return w.pending[ready[i]].mod.Before(w.pending[ready[j]].mod)
+	})
+	for _, path := range ready {
+		w.ignore(path)
+	}
+	return ready
+}
+
+// exportWithProfile cuts path with profile and returns the output path.
+func exportWithProfile(ctx context.Context, path string, profile watchProfile) (string, error) {
+	if problem := video.CheckInput(ctx, path); problem != nil {
+		return "", problem
+	}
+	props, err := video.GetVideoProperties(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	in, out := profile.TrimStart, props.Duration-profile.TrimEnd
+	if out-in < time.Second {
+		return "", errors.New("too short to trim")
+	}
+
+	opts := video.DefaultExportOptions(path, props)
+	opts.AspectRatio = video.AspectOriginal
+	opts.InPoint, opts.OutPoint = in, out
+	opts.Container = profile.Container
+
+	progress := make(chan float64, 100)
+	go func() {
+		for range progress {
+		}
+	}()
+	output, _, err := video.ExportWithProgress(ctx, opts, progress)
+	return output, err
+}
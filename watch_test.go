@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeRecording writes size bytes to dir/name, modified at mod.
+func writeRecording(t *testing.T, dir, name string, size int, mod time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWatcherSettles(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	const settle = 4 * time.Second
+	writeRecording(t, dir, "old.mp4", 10, start)
+	w := newWatcher(dir)
+
+	rec := writeRecording(t, dir, "rec.mkv", 100, start)
+	writeRecording(t, dir, "notes.txt", 10, start)
+	writeRecording(t, dir, "empty.mp4", 0, start)
+	if err := os.Mkdir(filepath.Join(dir, "clips.mp4"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	const sec = time.Second
+	steps := []struct {
+		at   time.Duration
+		grow int // bytes the recording has when checked, 0 leaves it
+		mod  time.Duration
+		want []string
+	}{
+		{0, 0, 0, nil},                  // first seen
+		{2 * sec, 0, 0, nil},            // unchanged, not for long enough
+		{3 * sec, 200, 3 * sec, nil},    // still being written
+		{6 * sec, 0, 0, nil},            // 3s since it changed
+		{7 * sec, 0, 7 * sec, nil},      // touched, same size
+		{10 * sec, 0, 0, nil},           // 3s since the touch
+		{11 * sec, 0, 0, []string{rec}}, // settled
+		{20 * sec, 0, 0, nil},           // reported once
+	}
+	for _, s := range steps {
+		if s.grow > 0 || s.mod > 0 {
+			size := s.grow
+			if size == 0 {
+				info, err := os.Stat(rec)
+				if err != nil {
+					t.Fatal(err)
+				}
+				size = int(info.Size())
+			}
+			writeRecording(t, dir, "rec.mkv", size, start.Add(s.mod))
+		}
+		if got := w.ready(start.Add(s.at), settle); !slices.Equal(got, s.want) {
+			t.Errorf("at %v: ready = %q, want %q", s.at, got, s.want)
+		}
+	}
+}
+
+func TestWatcherOrderAndIgnore(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	w := newWatcher(dir)
+
+	second := writeRecording(t, dir, "a.mp4", 10, start.Add(time.Minute))
+	first := writeRecording(t, dir, "b.mov", 10, start)
+	export := writeRecording(t, dir, "b_trimmed.mov", 10, start)
+	w.ready(start, time.Second)
+	w.ignore(export)
+
+	got := w.ready(start.Add(time.Second), time.Second)
+	if want := []string{first, second}; !slices.Equal(got, want) {
+		t.Errorf("ready = %q, want %q oldest first, without the ignored export", got, want)
+	}
+}